@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+	"gopkg.in/yaml.v3"
+)
+
+// Аннотации, через которые результат проверки виден в интерфейсе Argo CD
+const (
+	annotationStatus   = "yamlvalid.io/status"
+	annotationFindings = "yamlvalid.io/findings"
+	annotationContext  = "yamlvalid.io/context"
+)
+
+// writeArgoCD выводит проверенные манифесты с аннотациями результата, как того ждёт
+// команда generate плагина управления конфигурацией Argo CD. Выводятся все документы файла:
+// пропущенный документ Argo CD удалил бы из кластера.
+func writeArgoCD(w io.Writer, results []fileResult, metadata map[string]string) error {
+	first := true
+	for _, result := range results {
+		documents, err := decodeDocuments(result.source)
+		if err != nil {
+			// Неразобранный файл не передаём в Argo CD — синхронизация упадёт с кодом выхода 1
+			continue
+		}
+
+		for i, root := range documents {
+			status := "passed"
+			var messages []string
+			for _, finding := range documentFindings(result.findings, documents, i) {
+				if finding.Severity == validator.SeverityError {
+					status = "failed"
+				}
+				messages = append(messages, fmt.Sprintf("%s %s", finding.RuleID, finding.Message))
+			}
+			annotate(root.Content[0], annotationStatus, status)
+			if len(messages) > 0 {
+				annotate(root.Content[0], annotationFindings, strconv.Itoa(len(messages))+": "+strings.Join(messages, "; "))
+			}
+			if len(metadata) > 0 {
+				annotate(root.Content[0], annotationContext, formatMetadata(metadata))
+			}
+
+			if !first {
+				fmt.Fprintln(w, "---")
+			}
+			first = false
+			encoder := yaml.NewEncoder(w)
+			encoder.SetIndent(2)
+			if err := encoder.Encode(root); err != nil {
+				return err
+			}
+			if err := encoder.Close(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// decodeDocuments разбирает все непустые документы YAML-потока
+func decodeDocuments(source []byte) ([]*yaml.Node, error) {
+	var documents []*yaml.Node
+	decoder := yaml.NewDecoder(bytes.NewReader(source))
+	for {
+		var root yaml.Node
+		err := decoder.Decode(&root)
+		if errors.Is(err, io.EOF) {
+			return documents, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(root.Content) > 0 {
+			documents = append(documents, &root)
+		}
+	}
+}
+
+// documentFindings возвращает находки i-го документа: по строке, с которой начинается документ.
+// Находки без позиции относятся к первому документу — его проверяет Validate.
+func documentFindings(findings []validator.Finding, documents []*yaml.Node, i int) []validator.Finding {
+	if len(documents) == 1 {
+		return findings
+	}
+	var own []validator.Finding
+	for _, finding := range findings {
+		index := 0
+		for j, document := range documents {
+			if finding.Line >= document.Content[0].Line {
+				index = j
+			}
+		}
+		if index == i {
+			own = append(own, finding)
+		}
+	}
+	return own
+}
+
+// annotate записывает metadata.annotations[key], создавая недостающие узлы
+func annotate(document *yaml.Node, key, value string) {
+	if document.Kind != yaml.MappingNode {
+		return
+	}
+	metadata := mappingValue(document, "metadata")
+	if metadata == nil {
+		return
+	}
+	annotations := mappingValue(metadata, "annotations")
+	if annotations == nil {
+		return
+	}
+	for i := 0; i+1 < len(annotations.Content); i += 2 {
+		if annotations.Content[i].Value == key {
+			annotations.Content[i+1].SetString(value)
+			return
+		}
+	}
+	valueNode := &yaml.Node{}
+	valueNode.SetString(value)
+	annotations.Content = append(annotations.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, valueNode)
+}
+
+// mappingValue возвращает отображение по ключу, добавляя пустое при отсутствии;
+// nil, если по ключу лежит не отображение
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			if value := mapping.Content[i+1]; value.Kind == yaml.MappingNode {
+				return value
+			}
+			if value := mapping.Content[i+1]; value.Tag == "!!null" {
+				value.Kind, value.Tag, value.Value = yaml.MappingNode, "!!map", ""
+				return value
+			}
+			return nil
+		}
+	}
+	value := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+	return value
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+	"gopkg.in/yaml.v3"
+)
+
+func TestWriteArgoCDMultipleDocuments(t *testing.T) {
+	source := []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+`)
+	results := []fileResult{{
+		file:   "app.yaml",
+		source: source,
+		findings: []validator.Finding{
+			{RuleID: "YV1", Severity: validator.SeverityError, Message: "app.yaml: data is required", Line: 7},
+		},
+	}}
+
+	var out bytes.Buffer
+	if err := writeArgoCD(&out, results, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	documents, err := decodeDocuments(out.Bytes())
+	if err != nil {
+		t.Fatalf("output is not YAML: %v\n%s", err, out.String())
+	}
+	if len(documents) != 2 {
+		t.Fatalf("got %d documents, want 2:\n%s", len(documents), out.String())
+	}
+	want := []struct {
+		kind, status, findings string
+	}{
+		{"Deployment", "passed", ""},
+		{"ConfigMap", "failed", "1: YV1 app.yaml: data is required"},
+	}
+	for i, document := range documents {
+		var manifest struct {
+			Kind     string `yaml:"kind"`
+			Metadata struct {
+				Annotations map[string]string `yaml:"annotations"`
+			} `yaml:"metadata"`
+		}
+		if err := document.Decode(&manifest); err != nil {
+			t.Fatal(err)
+		}
+		if manifest.Kind != want[i].kind {
+			t.Errorf("document %d: kind %q, want %q", i, manifest.Kind, want[i].kind)
+		}
+		if got := manifest.Metadata.Annotations[annotationStatus]; got != want[i].status {
+			t.Errorf("document %d: status %q, want %q", i, got, want[i].status)
+		}
+		if got := manifest.Metadata.Annotations[annotationFindings]; got != want[i].findings {
+			t.Errorf("document %d: findings %q, want %q", i, got, want[i].findings)
+		}
+	}
+}
+
+func TestWriteArgoCDSkipsUnparsableFiles(t *testing.T) {
+	results := []fileResult{
+		{file: "broken.yaml", source: []byte("kind: [")},
+		{file: "ok.yaml", source: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n")},
+	}
+	var out bytes.Buffer
+	if err := writeArgoCD(&out, results, nil); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(out.String(), "kind:") != 1 || strings.HasPrefix(out.String(), "---") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(out.Bytes(), &node); err != nil {
+		t.Errorf("output is not YAML: %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+// baselineFile — находки, принятые как существующие; хранится в репозитории, и
+// в последующих запусках эти находки не выводятся и не влияют на код выхода
+type baselineFile struct {
+	Findings []baselineEntry `json:"findings"`
+}
+
+// baselineEntry — принятая находка; отпечаток не зависит от позиции, поэтому
+// правка соседних строк не делает находку новой
+type baselineEntry struct {
+	File        string `json:"file"`
+	RuleID      string `json:"ruleId"`
+	Fingerprint string `json:"fingerprint"`
+	// Message — только для человека, читающего файл
+	Message string `json:"message"`
+}
+
+func (e baselineEntry) key() string {
+	return e.File + "\x00" + e.Fingerprint
+}
+
+// writeBaseline записывает все текущие находки в path
+func writeBaseline(path string, results []fileResult) (int, error) {
+	baseline := baselineFile{Findings: []baselineEntry{}}
+	for _, result := range results {
+		for _, finding := range result.findings {
+			baseline.Findings = append(baseline.Findings, baselineEntry{
+				File:        filepath.ToSlash(result.file),
+				RuleID:      finding.RuleID,
+				Fingerprint: finding.Fingerprint(),
+				Message:     finding.Message,
+			})
+		}
+	}
+	sort.SliceStable(baseline.Findings, func(i, j int) bool {
+		return baseline.Findings[i].key() < baseline.Findings[j].key()
+	})
+	data, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	return len(baseline.Findings), os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// applyBaseline убирает из результатов находки, записанные в базовом файле path. Одинаковые
+// находки учитываются поштучно: если их стало больше, лишние остаются. О записях, которым
+// больше не соответствует ни одна находка, сообщается в w.
+func applyBaseline(w io.Writer, results []fileResult, path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%s does not exist, run with --write-baseline to create it", path)
+	} else if err != nil {
+		return err
+	}
+	var baseline baselineFile
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+
+	remaining := map[string]int{}
+	for _, entry := range baseline.Findings {
+		remaining[entry.key()]++
+	}
+	matched := 0
+	for i := range results {
+		var kept []validator.Finding
+		for _, finding := range results[i].findings {
+			key := baselineEntry{File: filepath.ToSlash(results[i].file), Fingerprint: finding.Fingerprint()}.key()
+			if remaining[key] > 0 {
+				remaining[key]--
+				matched++
+				continue
+			}
+			kept = append(kept, finding)
+		}
+		results[i].findings = kept
+	}
+
+	fixed := 0
+	for _, count := range remaining {
+		fixed += count
+	}
+	if fixed > 0 {
+		fmt.Fprintf(w, "baseline: %d findings no longer occur, run with --write-baseline to remove them from %s\n", fixed, path)
+	}
+	if matched > 0 {
+		fmt.Fprintf(w, "baseline: %d existing findings hidden\n", matched)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+// budgetStatus — сколько находок правила найдено во всём наборе и сколько допускает бюджет
+type budgetStatus struct {
+	rule   validator.Rule
+	count  int
+	budget int
+}
+
+func (b budgetStatus) exceeded() bool {
+	return b.count > b.budget
+}
+
+// checkBudgets считает находки правил, для которых в конфигурации задан бюджет
+func checkBudgets(results []fileResult, budgets map[string]int) []budgetStatus {
+	if len(budgets) == 0 {
+		return nil
+	}
+	counts := countFindings(results)
+
+	var statuses []budgetStatus
+	for key, budget := range budgets {
+		rule, _ := validator.FindRuleByKey(key)
+		statuses = append(statuses, budgetStatus{rule: rule, count: counts[rule.ID], budget: budget})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].rule.ID < statuses[j].rule.ID
+	})
+	return statuses
+}
+
+// countFindings считает находки по правилам, включая сгруппированные под первопричиной;
+// рекомендательные файлы не учитываются
+func countFindings(results []fileResult) map[string]int {
+	counts := map[string]int{}
+	var count func(findings []validator.Finding)
+	count = func(findings []validator.Finding) {
+		for _, finding := range findings {
+			counts[finding.RuleID]++
+			count(finding.Related)
+		}
+	}
+	for _, result := range results {
+		if !result.advisory {
+			count(result.findings)
+		}
+	}
+	return counts
+}
+
+// tolerated возвращает правила, число находок которых укладывается в бюджет
+func tolerated(budgets []budgetStatus) map[string]bool {
+	within := map[string]bool{}
+	for _, b := range budgets {
+		if !b.exceeded() {
+			within[b.rule.ID] = true
+		}
+	}
+	return within
+}
+
+func writeBudgets(w io.Writer, opts reportOptions) {
+	for _, b := range opts.budgets {
+		if b.exceeded() {
+			fmt.Fprintln(w, opts.paint(ansiRed, fmt.Sprintf("budget %s exceeded: %d findings, %d allowed", b.rule.Name, b.count, b.budget)))
+		} else {
+			fmt.Fprintf(w, "budget %s: %d of %d findings tolerated\n", b.rule.Name, b.count, b.budget)
+		}
+	}
+}
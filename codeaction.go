@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/internal/yamledit"
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+// Виды действий LSP
+const (
+	lspQuickFix = "quickfix"
+	lspRewrite  = "refactor.rewrite"
+	lspFixAll   = "source.fixAll"
+)
+
+type lspCodeActionParams struct {
+	TextDocument lspTextDocument `json:"textDocument"`
+	Range        lspRange        `json:"range"`
+	Context      struct {
+		// Only — виды действий, которые запросил редактор; пусто — все
+		Only []string `json:"only"`
+	} `json:"context"`
+}
+
+type lspTextEdit struct {
+	Range   lspRange `json:"range"`
+	NewText string   `json:"newText"`
+}
+
+type lspWorkspaceEdit struct {
+	Changes map[string][]lspTextEdit `json:"changes"`
+}
+
+type lspCodeAction struct {
+	Title       string           `json:"title"`
+	Kind        string           `json:"kind"`
+	Diagnostics []lspDiagnostic  `json:"diagnostics,omitempty"`
+	IsPreferred bool             `json:"isPreferred,omitempty"`
+	Edit        lspWorkspaceEdit `json:"edit"`
+}
+
+// codeActions предлагает исправления находок на выделенных строках, явный protocol
+// для портов без него и исправление всех находок документа сразу
+func (l *lspServer) codeActions(params lspCodeActionParams) []lspCodeAction {
+	uri := params.TextDocument.URI
+	data, ok := l.documents[uri]
+	if !ok {
+		return nil
+	}
+	lines := strings.Split(string(data), "\n")
+	from, to := params.Range.Start.Line+1, params.Range.End.Line+1
+
+	actions := []lspCodeAction{}
+	add := func(title, kind string, fixed []byte, diagnostics []lspDiagnostic) {
+		if !wantedAction(params.Context.Only, kind) {
+			return
+		}
+		edit := lineEdit(string(data), string(fixed))
+		actions = append(actions, lspCodeAction{
+			Title:       title,
+			Kind:        kind,
+			Diagnostics: diagnostics,
+			IsPreferred: kind == lspQuickFix,
+			Edit:        lspWorkspaceEdit{Changes: map[string][]lspTextEdit{uri: {edit}}},
+		})
+	}
+
+	findings := l.s.validateDocument(data, uriPath(uri))
+	var fixes []validator.Finding
+	for _, finding := range findings {
+		if !fixable(finding) {
+			continue
+		}
+		fixes = append(fixes, finding)
+		if finding.Line < from || finding.Line > to {
+			continue
+		}
+		if fixed, _, err := applyFixes(data, []validator.Finding{finding}); err == nil {
+			add(fixTitle(finding), lspQuickFix, fixed, []lspDiagnostic{l.diagnostic(uri, finding, lines)})
+		}
+	}
+
+	for _, port := range portsWithoutProtocol(data, from, to) {
+		doc, err := yamledit.Parse(data)
+		if err == nil && doc.Set(0, port.Field("protocol"), "TCP") == nil {
+			add(fmt.Sprintf("Add protocol: TCP to %s", port), lspRewrite, doc.Bytes(), nil)
+		}
+	}
+
+	if len(fixes) > 1 {
+		if fixed, applied, err := applyFixes(data, fixes); err == nil {
+			add(fmt.Sprintf("Fix all %d yamlvalid problems", applied), lspFixAll, fixed, nil)
+		}
+	}
+	return actions
+}
+
+// wantedAction сообщает, входит ли вид действия в запрошенные: refactor.rewrite входит в refactor
+func wantedAction(only []string, kind string) bool {
+	if len(only) == 0 {
+		return true
+	}
+	for _, prefix := range only {
+		if kind == prefix || strings.HasPrefix(kind, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// fixTitle описывает исправление для меню редактора
+func fixTitle(finding validator.Finding) string {
+	if finding.Remediation.Action == validator.ActionRemove {
+		return fmt.Sprintf("Remove %s", finding.Path)
+	}
+	value, err := json.Marshal(finding.Remediation.Value)
+	if err != nil {
+		return fmt.Sprintf("Fix %s", finding.Path)
+	}
+	if finding.RuleID == validator.RuleAmbiguousScalar {
+		return fmt.Sprintf("Quote %s as %s", finding.Path, value)
+	}
+	return fmt.Sprintf("Set %s to %s", finding.Path, value)
+}
+
+// portsWithoutProtocol возвращает пути к портам контейнеров без protocol,
+// которые пересекаются со строками from..to (с единицы)
+func portsWithoutProtocol(data []byte, from, to int) []validator.FieldPath {
+	var root yaml.Node
+	if yaml.Unmarshal(data, &root) != nil {
+		return nil
+	}
+	var paths []validator.FieldPath
+	containers := validator.FieldPath("spec.containers")
+	node := containers.Resolve(&root)
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return nil
+	}
+	for i := range node.Content {
+		ports := containers.Index(i).Field("ports")
+		list := ports.Resolve(&root)
+		if list == nil || list.Kind != yaml.SequenceNode {
+			continue
+		}
+		for j, port := range list.Content {
+			if port.Kind != yaml.MappingNode || len(port.Content) == 0 || ports.Index(j).Field("protocol").Resolve(&root) != nil {
+				continue
+			}
+			if first, last := port.Line, port.Content[len(port.Content)-1].Line; first <= to && last >= from {
+				paths = append(paths, ports.Index(j))
+			}
+		}
+	}
+	return paths
+}
+
+// lineEdit заменяет только изменившиеся строки, чтобы редактор не терял курсор и закладки
+func lineEdit(before, after string) lspTextEdit {
+	oldLines := strings.SplitAfter(before, "\n")
+	newLines := strings.SplitAfter(after, "\n")
+	prefix := 0
+	for prefix < len(oldLines) && prefix < len(newLines) && oldLines[prefix] == newLines[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(oldLines)-prefix && suffix < len(newLines)-prefix && oldLines[len(oldLines)-1-suffix] == newLines[len(newLines)-1-suffix] {
+		suffix++
+	}
+
+	end := lspPosition{Line: len(oldLines) - suffix}
+	if end.Line == len(oldLines) {
+		// Заменяется последняя строка без перевода строки
+		end = lspPosition{Line: len(oldLines) - 1, Character: len([]rune(oldLines[len(oldLines)-1]))}
+	}
+	return lspTextEdit{
+		Range:   lspRange{Start: lspPosition{Line: prefix}, End: end},
+		NewText: strings.Join(newLines[prefix:len(newLines)-suffix], ""),
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+// frameSource возвращает строки файла, к которым относятся позиции находок. Позиции уже
+// переведены в координаты исходного файла, поэтому для отрендеренных и извлечённых документов
+// читается сам файл; имя документа file#N указывает на файл file.
+func frameSource(result fileResult) []string {
+	name := result.file
+	if i := strings.LastIndex(name, "#"); i > 0 {
+		if _, err := os.Stat(name); err != nil {
+			name = name[:i]
+		}
+	}
+	if data, err := os.ReadFile(name); err == nil {
+		return strings.Split(string(data), "\n")
+	}
+	if result.mapLine == nil {
+		return strings.Split(string(result.source), "\n")
+	}
+	return nil
+}
+
+// Строк контекста вокруг строки с нарушением по умолчанию (--code-frame-context)
+const defaultFrameContext = 2
+
+// frameDocument — документ многодокументного файла, в котором находится строка
+type frameDocument struct {
+	// index — номер документа с единицы; 0, если документ в файле один
+	index int
+	// first и last — строки документа (с единицы) без разделителей ---
+	first, last int
+	// source — шаблон Helm из комментария "# Source:" в начале документа
+	source string
+}
+
+// documentAt находит документ, содержащий строку line. Разделитель перед первым документом
+// и пустые документы не считаются.
+func documentAt(lines []string, line int) frameDocument {
+	var documents []frameDocument
+	current := frameDocument{first: 1}
+	content := false
+	for i, text := range lines {
+		if isDocumentSeparator(text) {
+			if content {
+				current.last = i
+				documents = append(documents, current)
+			}
+			current, content = frameDocument{first: i + 2}, false
+			continue
+		}
+		trimmed := strings.TrimSpace(text)
+		if source, found := strings.CutPrefix(trimmed, "# Source: "); found && current.source == "" {
+			current.source = source
+		}
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			content = true
+		}
+	}
+	current.last = len(lines)
+	documents = append(documents, current)
+
+	for i, document := range documents {
+		if line <= document.last || i == len(documents)-1 {
+			if len(documents) > 1 {
+				document.index = i + 1
+			}
+			return document
+		}
+	}
+	return current
+}
+
+// writeCodeFrame печатает строку с нарушением, указатель под колонкой и по opts.frameContext
+// строк до и после неё в пределах документа. В многодокументном файле перед фрагментом
+// указывается номер документа и шаблон Helm, из которого он получен:
+//
+//	  --- document 2, # Source: web/templates/service.yaml
+//	  11 |   - port: 80
+//	> 12 |       protocol: tcp
+//	     |                 ^
+//	  13 |   selector:
+func writeCodeFrame(w io.Writer, lines []string, finding validator.Finding, opts reportOptions) {
+	context := opts.frameContext
+	if finding.Line < 1 || finding.Line > len(lines) {
+		return
+	}
+	document := documentAt(lines, finding.Line)
+	if document.index > 0 {
+		header := fmt.Sprintf("--- document %d", document.index)
+		if document.source != "" {
+			header += ", # Source: " + document.source
+		}
+		fmt.Fprintf(w, "  %s\n", header)
+	}
+	first := max(finding.Line-context, document.first, 1)
+	last := min(finding.Line+context, document.last, len(lines))
+	for last > finding.Line && strings.TrimSpace(lines[last-1]) == "" {
+		last--
+	}
+	width := len(fmt.Sprint(last))
+
+	for number := first; number <= last; number++ {
+		text := strings.TrimRight(lines[number-1], "\r")
+		marker := " "
+		if number == finding.Line && context > 0 {
+			marker = ">"
+		}
+		fmt.Fprintf(w, "%s %*d | %s\n", marker, width, number, text)
+		if number == finding.Line && finding.Column >= 1 {
+			fmt.Fprintf(w, "  %s | %s%s\n", strings.Repeat(" ", width), caretPadding(text, finding.Column), opts.paintSeverity(finding.Severity, "^"))
+		}
+	}
+}
+
+// caretPadding возвращает отступ до колонки; табуляции переносятся в отступ, чтобы указатель
+// совпал с колонкой в любом терминале
+func caretPadding(text string, column int) string {
+	var pad strings.Builder
+	for i, r := range []rune(text) {
+		if i >= column-1 {
+			break
+		}
+		if r == '\t' {
+			pad.WriteRune('\t')
+		} else {
+			pad.WriteRune(' ')
+		}
+	}
+	return pad.String()
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+// codeQualityIssue — находка в формате отчёта GitLab Code Quality (подмножество Code Climate)
+type codeQualityIssue struct {
+	Type        string              `json:"type"`
+	CheckName   string              `json:"check_name"`
+	Description string              `json:"description"`
+	Content     *codeQualityContent `json:"content,omitempty"`
+	Fingerprint string              `json:"fingerprint"`
+	Severity    string              `json:"severity"`
+	Location    codeQualityLocation `json:"location"`
+}
+
+type codeQualityContent struct {
+	Body string `json:"body"`
+}
+
+type codeQualityLocation struct {
+	Path  string           `json:"path"`
+	Lines codeQualityLines `json:"lines"`
+}
+
+type codeQualityLines struct {
+	Begin int `json:"begin"`
+}
+
+// codeQualitySeverity переводит уровень находки в уровень Code Quality
+func codeQualitySeverity(severity validator.Severity) string {
+	switch severity {
+	case validator.SeverityWarning:
+		return "minor"
+	case validator.SeverityInfo:
+		return "info"
+	}
+	return "major"
+}
+
+// writeCodeQuality печатает отчёт GitLab Code Quality: GitLab показывает его в merge request
+// и сравнивает находки веток по fingerprint. Отпечаток не зависит от строки, поэтому
+// находка, сдвинутая правкой выше, не считается новой.
+func writeCodeQuality(w io.Writer, results []fileResult, opts reportOptions) error {
+	issues := []codeQualityIssue{}
+	seen := map[string]int{}
+	for _, result := range results {
+		file := result.file
+		// Фрагменты file#2 показываем в своём файле
+		if i := strings.IndexByte(file, '#'); i >= 0 {
+			file = file[:i]
+		}
+		for _, finding := range result.findings {
+			severity := codeQualitySeverity(finding.Severity)
+			// Находки рекомендательных каталогов не должны выглядеть как блокирующие
+			if result.advisory && severity == "major" {
+				severity = "minor"
+			}
+			// GitLab требует номер строки; находка без позиции относится к началу файла
+			line := finding.Line
+			if line < 1 {
+				line = 1
+			}
+			issue := codeQualityIssue{
+				Type:        "issue",
+				CheckName:   finding.RuleID,
+				Description: finding.Message,
+				Fingerprint: codeQualityFingerprint(result.file, finding, seen),
+				Severity:    severity,
+				Location:    codeQualityLocation{Path: filepath.ToSlash(file), Lines: codeQualityLines{Begin: line}},
+			}
+			if url := opts.config.docURL(finding.RuleID); url != "" {
+				issue.Content = &codeQualityContent{Body: url}
+			}
+			issues = append(issues, issue)
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(issues)
+}
+
+// codeQualityFingerprint — отпечаток находки, уникальный в отчёте: к Finding.Fingerprint
+// добавляются файл и номер повторения одинаковой находки в нём
+func codeQualityFingerprint(file string, finding validator.Finding, seen map[string]int) string {
+	key := filepath.ToSlash(file) + "\x00" + finding.Fingerprint()
+	occurrence := seen[key]
+	seen[key]++
+	sum := sha256.Sum256([]byte(key + "\x00" + strconv.Itoa(occurrence)))
+	return hex.EncodeToString(sum[:16])
+}
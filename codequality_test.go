@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+func TestWriteCodeQuality(t *testing.T) {
+	image := validator.Finding{RuleID: "YV101", Severity: validator.SeverityError, Message: "app.yaml: container[0].image must be in domain registry.bigbrother.io", Path: "spec.containers[0].image", Line: 7, Column: 14}
+	results := []fileResult{
+		{file: "app.yaml", findings: []validator.Finding{
+			image,
+			{RuleID: "YV102", Severity: validator.SeverityWarning, Message: "app.yaml: tag", Path: "spec.containers[0].image", Line: 7},
+			{RuleID: "YV001", Severity: validator.SeverityInfo, Message: "app.yaml: no position"},
+			// Та же находка ещё раз: отпечатки в отчёте не должны совпадать
+			image,
+		}},
+		{file: "dump.yaml#2", findings: []validator.Finding{image}},
+		{file: "advisory/app.yaml", advisory: true, findings: []validator.Finding{image}},
+	}
+	config := &Config{Docs: DocsConfig{URL: "https://wiki.example.com/{id}"}}
+
+	var out bytes.Buffer
+	if err := writeCodeQuality(&out, results, reportOptions{config: config}); err != nil {
+		t.Fatal(err)
+	}
+	var issues []codeQualityIssue
+	if err := json.Unmarshal(out.Bytes(), &issues); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out.String())
+	}
+	if len(issues) != 6 {
+		t.Fatalf("got %d issues, want 6:\n%s", len(issues), out.String())
+	}
+
+	tests := []struct {
+		index    int
+		severity string
+		path     string
+		line     int
+	}{
+		{0, "major", "app.yaml", 7},
+		{1, "minor", "app.yaml", 7},
+		{2, "info", "app.yaml", 1},
+		{4, "major", "dump.yaml", 7},
+		{5, "minor", "advisory/app.yaml", 7},
+	}
+	for _, tt := range tests {
+		issue := issues[tt.index]
+		if issue.Severity != tt.severity || issue.Location.Path != tt.path || issue.Location.Lines.Begin != tt.line {
+			t.Errorf("issue %d = %s %s:%d, want %s %s:%d", tt.index, issue.Severity, issue.Location.Path, issue.Location.Lines.Begin, tt.severity, tt.path, tt.line)
+		}
+	}
+	if first := issues[0]; first.Type != "issue" || first.CheckName != "YV101" || first.Description != image.Message {
+		t.Errorf("issue = %+v", first)
+	}
+	if content := issues[0].Content; content == nil || content.Body != "https://wiki.example.com/YV101" {
+		t.Errorf("content = %+v, want the documentation URL", content)
+	}
+
+	fingerprints := map[string]bool{}
+	for _, issue := range issues {
+		if fingerprints[issue.Fingerprint] {
+			t.Errorf("duplicate fingerprint %s", issue.Fingerprint)
+		}
+		fingerprints[issue.Fingerprint] = true
+	}
+
+	// Сдвиг находки на другую строку отпечаток не меняет
+	moved := image
+	moved.Line = 20
+	out.Reset()
+	if err := writeCodeQuality(&out, []fileResult{{file: "app.yaml", findings: []validator.Finding{moved}}}, reportOptions{config: &Config{}}); err != nil {
+		t.Fatal(err)
+	}
+	var movedIssues []codeQualityIssue
+	if err := json.Unmarshal(out.Bytes(), &movedIssues); err != nil {
+		t.Fatal(err)
+	}
+	if movedIssues[0].Fingerprint != issues[0].Fingerprint {
+		t.Errorf("fingerprint changed with the line: %s != %s", movedIssues[0].Fingerprint, issues[0].Fingerprint)
+	}
+	if movedIssues[0].Content != nil {
+		t.Errorf("content = %+v without a documentation URL", movedIssues[0].Content)
+	}
+}
+
+func TestWriteCodeQualityEmpty(t *testing.T) {
+	var out bytes.Buffer
+	if err := writeCodeQuality(&out, nil, reportOptions{config: &Config{}}); err != nil {
+		t.Fatal(err)
+	}
+	// GitLab ожидает массив и при отсутствии находок
+	if got := out.String(); got != "[]\n" {
+		t.Errorf("output = %q, want an empty array", got)
+	}
+}
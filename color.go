@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+// Коды ANSI для текстового отчёта
+const (
+	ansiBold   = "1"
+	ansiRed    = "31"
+	ansiYellow = "33"
+)
+
+// useColor решает, раскрашивать ли отчёт: только в терминале и без --no-color,
+// NO_COLOR (https://no-color.org) и TERM=dumb
+func useColor(noColor bool, f *os.File) bool {
+	if noColor || os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return isTerminal(f)
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// paint оборачивает текст кодом ANSI, если цвет включён
+func (o reportOptions) paint(code, text string) string {
+	if !o.color {
+		return text
+	}
+	return "\x1b[" + code + "m" + text + "\x1b[0m"
+}
+
+// paintSeverity выделяет ошибки красным, предупреждения — жёлтым
+func (o reportOptions) paintSeverity(severity validator.Severity, text string) string {
+	switch severity {
+	case validator.SeverityError:
+		return o.paint(ansiRed, text)
+	case validator.SeverityWarning:
+		return o.paint(ansiYellow, text)
+	}
+	return text
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+// runCompare сравнивает две ревизии манифестов и сообщает об изменениях неизменяемых полей,
+// которые API отклонит при kubectl apply
+func runCompare(args []string) {
+	flagSet := flag.NewFlagSet("yamlvalid compare", flag.ExitOnError)
+	common := addCommonFlags(flagSet)
+	flagSet.Usage = func() {
+		fmt.Println("Usage: yamlvalid compare [flags] <old.yaml> <new.yaml>")
+		flagSet.PrintDefaults()
+	}
+	flagSet.Parse(args)
+
+	if flagSet.NArg() != 2 {
+		flagSet.Usage()
+		os.Exit(exitUsage)
+	}
+	s := common.session()
+	oldName, newName := flagSet.Arg(0), flagSet.Arg(1)
+
+	before, err := os.ReadFile(oldName)
+	if err != nil {
+		fmt.Printf("Error reading file: %v\n", err)
+		os.Exit(exitIO)
+	}
+	after, err := os.ReadFile(newName)
+	if err != nil {
+		fmt.Printf("Error reading file: %v\n", err)
+		os.Exit(exitIO)
+	}
+	findings, err := validator.ImmutableChanges(before, after, newName)
+	if err != nil {
+		fmt.Printf("Validation failed: invalid YAML format: %v\n", err)
+		os.Exit(exitUnparsable)
+	}
+	findings = validator.FilterFindings(findings, s.selection)
+
+	if len(findings) == 0 && s.report.format == "text" && !s.report.quiet {
+		fmt.Println("No immutable fields changed")
+		return
+	}
+	results := []fileResult{{file: newName, findings: findings, source: after}}
+	s.report.checked = len(results)
+	if err := writeReport(os.Stdout, results, s.report); err != nil {
+		fmt.Printf("Error writing report: %v\n", err)
+		os.Exit(exitIO)
+	}
+	if code := resultExitCode(results, nil); code != exitValid {
+		os.Exit(code)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+// jsonCompletion — вариант автодополнения для редактора
+type jsonCompletion struct {
+	Label         string `json:"label"`
+	Kind          string `json:"kind"`
+	Detail        string `json:"detail,omitempty"`
+	Documentation string `json:"documentation,omitempty"`
+}
+
+// runComplete печатает ключи или значения, допустимые по схеме в позиции курсора
+func runComplete(args []string) {
+	flagSet := flag.NewFlagSet("yamlvalid complete", flag.ExitOnError)
+	at := flagSet.String("at", "", "cursor position as file.yaml:line:column (1-based)")
+	output := flagSet.String("output", "text", "output format: text or json")
+	flagSet.Usage = func() {
+		fmt.Println("Usage: yamlvalid complete --at <file.yaml:line:column> [flags]")
+		flagSet.PrintDefaults()
+	}
+	flagSet.Parse(args)
+
+	if flagSet.NArg() != 0 || *at == "" {
+		flagSet.Usage()
+		os.Exit(exitUsage)
+	}
+	filename, line, column, err := parsePosition(*at)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		fmt.Printf("Error reading file: %v\n", err)
+		os.Exit(exitIO)
+	}
+
+	completions := validator.Complete(data, line, column)
+	switch *output {
+	case "text":
+		for _, c := range completions {
+			fmt.Printf("%s\t%s\t%s\n", c.Label, c.Detail, c.Documentation)
+		}
+	case "json":
+		list := []jsonCompletion{}
+		for _, c := range completions {
+			list = append(list, jsonCompletion{Label: c.Label, Kind: c.Kind, Detail: c.Detail, Documentation: c.Documentation})
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(list)
+	default:
+		fmt.Printf("Error: unknown output format %q\n", *output)
+		os.Exit(exitUsage)
+	}
+}
+
+// parsePosition разбирает позицию file:line:column; имя файла может содержать двоеточия
+func parsePosition(at string) (string, int, int, error) {
+	parts := strings.Split(at, ":")
+	if len(parts) < 3 {
+		return "", 0, 0, fmt.Errorf("position must be file:line:column, got %q", at)
+	}
+	line, err1 := strconv.Atoi(parts[len(parts)-2])
+	column, err2 := strconv.Atoi(parts[len(parts)-1])
+	if err1 != nil || err2 != nil || line < 1 || column < 1 {
+		return "", 0, 0, fmt.Errorf("position must be file:line:column, got %q", at)
+	}
+	return strings.Join(parts[:len(parts)-2], ":"), line, column, nil
+}
@@ -0,0 +1,424 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+	"gopkg.in/yaml.v3"
+)
+
+type Config struct {
+	// Зафиксированная версия набора правил, например 2024.1
+	RulesetVersion     string `yaml:"rulesetVersion"`
+	EnableExperimental bool   `yaml:"enableExperimental"`
+	// Окружение по умолчанию для уровней правил; флаг --env его перекрывает
+	Environment string `yaml:"environment"`
+	// Strict — сообщать о полях, которых нет в схеме типа (как флаг --strict)
+	Strict bool `yaml:"strict"`
+	// Groups — включённые группы правил, например dead-resource (как флаг --group)
+	Groups []string `yaml:"groups"`
+	// PSSLevel — уровень Pod Security Standards: privileged, baseline или restricted (как флаг --pss-level)
+	PSSLevel string `yaml:"pssLevel"`
+	// Enable и Disable — правила или группы правил по имени или идентификатору, включённые
+	// или выключенные явно (как флаги --enable и --disable)
+	Enable  []string `yaml:"enable"`
+	Disable []string `yaml:"disable"`
+	// Severity — уровни правил по имени или идентификатору: error, warning или info
+	Severity map[string]validator.Severity `yaml:"severity"`
+	// StrictWarnings — предупреждения тоже проваливают проверку (как флаг --strict-warnings)
+	StrictWarnings bool `yaml:"strictWarnings"`
+	// Registries — реестры, из которых разрешено брать образы; по умолчанию registry.bigbrother.io
+	Registries []string `yaml:"registries"`
+	// HostPath — политика томов hostPath: без секции правило host-path не выполняется,
+	// пустая секция запрещает hostPath совсем
+	HostPath *validator.HostPathPolicy `yaml:"hostPath"`
+	// LimitRatios — во сколько раз лимиты cpu и memory превышают запросы (правило resource-defaults);
+	// по умолчанию вдвое
+	LimitRatios *validator.LimitRatios `yaml:"limitRatios"`
+	// QoS — минимальный класс QoS подов (правило qos-class)
+	QoS QoSConfig `yaml:"qos"`
+	// ImageTags — требования к тегам образов (правило image-tag)
+	ImageTags ImageTagsConfig `yaml:"imageTags"`
+	// Output — формат отчёта по умолчанию; флаг --output его перекрывает
+	Output string `yaml:"output"`
+	// Что делать с файлами, которые не похожи на манифесты Kubernetes: report (по умолчанию) или skip
+	NonKubernetes string     `yaml:"nonKubernetes"`
+	Docs          DocsConfig `yaml:"docs"`
+	// Правила декларативного DSL
+	Rules []validator.CustomRule `yaml:"rules"`
+	// Plugins — каталог подключаемых модулей правил (plugins.go); путь относительно файла конфигурации
+	Plugins string `yaml:"plugins"`
+	// CRDDir — каталог с CustomResourceDefinition (crd.go); путь относительно файла конфигурации
+	CRDDir string `yaml:"crdDir"`
+	// Budgets — сколько находок правила (по имени или идентификатору) допускается во всём наборе,
+	// прежде чем проверка завершится ошибкой
+	Budgets map[string]int `yaml:"budgets"`
+	Scan    ScanConfig     `yaml:"scan"`
+	// Advisory — каталоги или шаблоны путей, находки в которых выводятся, но не влияют на код выхода
+	Advisory []string `yaml:"advisory"`
+	// Metadata — сведения о запуске (коммит, идентификатор конвейера), которые попадают во все отчёты;
+	// значения вида ${CI_COMMIT_SHA} берутся из переменных окружения
+	Metadata map[string]string `yaml:"metadata"`
+	// Telemetry — анонимная статистика срабатываний правил (telemetry.go)
+	Telemetry TelemetryConfig `yaml:"telemetry"`
+}
+
+// ScanConfig — какие файлы проверяются при обходе каталога с -r
+type ScanConfig struct {
+	// Include — окончания имён проверяемых файлов или шаблоны путей; по умолчанию .yaml и .yml
+	Include []string `yaml:"include"`
+	// Exclude — окончания имён или шаблоны путей, которые пропускаются, например .tpl.yaml или charts/**
+	Exclude []string `yaml:"exclude"`
+}
+
+// QoSConfig — минимальный класс QoS: общий и для отдельных окружений (--env)
+type QoSConfig struct {
+	Minimum      string            `yaml:"minimum"`
+	Environments map[string]string `yaml:"environments"`
+}
+
+// minimum возвращает класс, который требуется в окружении; пусто — проверка не нужна
+func (q QoSConfig) minimum(environment string) string {
+	if class, ok := q.Environments[environment]; ok {
+		return class
+	}
+	return q.Minimum
+}
+
+// ImageTagsConfig — формат тегов образов: semver или регулярное выражение
+type ImageTagsConfig struct {
+	// Format — semver; пусто — подходит любой тег
+	Format string `yaml:"format"`
+	// Pattern — регулярное выражение для тега вместо Format
+	Pattern string `yaml:"pattern"`
+	// ForbidPrerelease — окружения (--env), в которых теги pre-release вроде 1.2.3-rc.1 запрещены
+	ForbidPrerelease []string `yaml:"forbidPrerelease"`
+}
+
+// policy возвращает политику тегов для окружения; nil — достаточно наличия тега
+func (c ImageTagsConfig) policy(environment string) *validator.ImageTagPolicy {
+	if c.Format == "" && c.Pattern == "" && len(c.ForbidPrerelease) == 0 {
+		return nil
+	}
+	policy := &validator.ImageTagPolicy{
+		SemVer:           c.Format == "semver",
+		ForbidPrerelease: environment != "" && slices.Contains(c.ForbidPrerelease, environment),
+		Environment:      environment,
+	}
+	if c.Pattern != "" {
+		// Выражение уже проверено при чтении конфигурации
+		policy.Pattern = regexp.MustCompile(c.Pattern)
+	}
+	return policy
+}
+
+type DocsConfig struct {
+	// Шаблон ссылки для всех правил, например https://wiki.example.com/yamlvalid/{id}
+	URL string `yaml:"url"`
+	// Переопределения по идентификатору правила
+	Rules map[string]string `yaml:"rules"`
+}
+
+// projectConfig — файл конфигурации проекта, который ищется в рабочем каталоге и его предках
+const projectConfig = ".yamlvalid.yaml"
+
+// findProjectConfig возвращает путь к ближайшему .yamlvalid.yaml от рабочего каталога вверх; пусто, если его нет
+func findProjectConfig() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	for {
+		path := filepath.Join(dir, projectConfig)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// loadConfig читает конфигурацию; без явного пути — .yamlvalid.yaml проекта, если он есть
+func loadConfig(path string) (*Config, error) {
+	config := &Config{}
+	if path == "" {
+		path = findProjectConfig()
+	}
+	if path == "" {
+		return config, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	if config.RulesetVersion != "" {
+		if err := validator.CheckRulesetVersion(config.RulesetVersion); err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+	}
+	switch config.NonKubernetes {
+	case "", "report", "skip":
+	default:
+		return nil, fmt.Errorf("%s: nonKubernetes must be 'report' or 'skip'", path)
+	}
+	for i, rule := range config.Rules {
+		// Скрипт правила ищется относительно файла конфигурации, как и каталог модулей
+		if rule.Script != "" {
+			script := rule.Script
+			if !filepath.IsAbs(script) {
+				script = filepath.Join(filepath.Dir(path), script)
+			}
+			source, err := os.ReadFile(script)
+			if err != nil {
+				return nil, fmt.Errorf("%s: rules[%d]: %v", path, i, err)
+			}
+			rule.ScriptSource = string(source)
+		}
+		if err := validator.RegisterRule(rule); err != nil {
+			return nil, fmt.Errorf("%s: rules[%d]: %v", path, i, err)
+		}
+	}
+	// Модули загружаются до проверки enable, disable и severity: те могут ссылаться на их правила
+	if config.Plugins != "" {
+		dir := config.Plugins
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(filepath.Dir(path), dir)
+		}
+		if err := loadPlugins(dir); err != nil {
+			return nil, fmt.Errorf("%s: plugins: %w", path, err)
+		}
+	}
+	if config.CRDDir != "" {
+		dir := config.CRDDir
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(filepath.Dir(path), dir)
+		}
+		if err := loadCRDs(dir); err != nil {
+			return nil, fmt.Errorf("%s: crdDir: %w", path, err)
+		}
+	}
+	for key, budget := range config.Budgets {
+		if _, ok := validator.FindRuleByKey(key); !ok {
+			return nil, fmt.Errorf("%s: budgets.%s: unknown rule", path, key)
+		}
+		if budget < 0 {
+			return nil, fmt.Errorf("%s: budgets.%s: budget must not be negative", path, key)
+		}
+	}
+	if err := checkOutputFormat(config.Output); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	for _, entries := range [][]string{config.Enable, config.Disable} {
+		if _, err := ruleIDs(entries); err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+	}
+	for key, severity := range config.Severity {
+		if _, ok := validator.FindRuleByKey(key); !ok {
+			return nil, fmt.Errorf("%s: severity.%s: unknown rule", path, key)
+		}
+		switch severity {
+		case validator.SeverityError, validator.SeverityWarning, validator.SeverityInfo:
+		default:
+			return nil, fmt.Errorf("%s: severity.%s: must be error, warning or info", path, key)
+		}
+	}
+	for i, registry := range config.Registries {
+		if registry == "" || strings.Contains(registry, "://") {
+			return nil, fmt.Errorf("%s: registries[%d]: must be a registry host, e.g. registry.example.com", path, i)
+		}
+	}
+	if config.HostPath != nil {
+		for i, allowed := range config.HostPath.Allowed {
+			if !strings.HasPrefix(allowed, "/") {
+				return nil, fmt.Errorf("%s: hostPath.allowed[%d]: must be an absolute path", path, i)
+			}
+		}
+	}
+	if ratios := config.LimitRatios; ratios != nil {
+		if (ratios.CPU != 0 && ratios.CPU < 1) || (ratios.Memory != 0 && ratios.Memory < 1) {
+			return nil, fmt.Errorf("%s: limitRatios: must be at least 1, limits cannot be lower than requests", path)
+		}
+	}
+	classes := validator.QoSClasses()
+	if config.QoS.Minimum != "" && !slices.Contains(classes, config.QoS.Minimum) {
+		return nil, fmt.Errorf("%s: qos.minimum: must be one of %s", path, strings.Join(classes, ", "))
+	}
+	environments := make([]string, 0, len(config.QoS.Environments))
+	for environment := range config.QoS.Environments {
+		environments = append(environments, environment)
+	}
+	sort.Strings(environments)
+	for _, environment := range environments {
+		if class := config.QoS.Environments[environment]; class != "" && !slices.Contains(classes, class) {
+			return nil, fmt.Errorf("%s: qos.environments.%s: must be one of %s", path, environment, strings.Join(classes, ", "))
+		}
+	}
+	switch config.ImageTags.Format {
+	case "", "semver":
+	default:
+		return nil, fmt.Errorf("%s: imageTags.format: must be semver", path)
+	}
+	if config.ImageTags.Pattern != "" {
+		if config.ImageTags.Format != "" {
+			return nil, fmt.Errorf("%s: imageTags: format and pattern cannot be used together", path)
+		}
+		if _, err := regexp.Compile(config.ImageTags.Pattern); err != nil {
+			return nil, fmt.Errorf("%s: imageTags.pattern: %v", path, err)
+		}
+	}
+	if endpoint := config.Telemetry.Endpoint; endpoint != "" && !strings.HasPrefix(endpoint, "https://") && !strings.HasPrefix(endpoint, "http://") {
+		return nil, fmt.Errorf("%s: telemetry.endpoint: must be an http or https URL", path)
+	}
+	for id := range config.Docs.Rules {
+		if _, ok := validator.FindRule(id); !ok {
+			return nil, fmt.Errorf("%s: docs.rules.%s: unknown rule", path, id)
+		}
+	}
+	return config, nil
+}
+
+// warnings сообщает о ссылках конфигурации на устаревшие и удалённые правила: в docs.rules,
+// enable, disable, severity и budgets, — и о попытке выключить обязательное правило. Вызывается
+// до того, как enable и disable переведены в идентификаторы: группа правил, в которую входит
+// устаревшее правило, предупреждения не даёт.
+func (c *Config) warnings() []string {
+	var warnings []string
+	check := func(location, key string) {
+		rule, _ := validator.FindRuleByKey(key)
+		var warning string
+		switch rule.State {
+		case validator.StateDeprecated:
+			warning = fmt.Sprintf("%s: rule %s is deprecated and will be removed", location, rule.Name)
+		case validator.StateRemoved:
+			warning = fmt.Sprintf("%s: rule %s has been removed and no longer runs", location, rule.Name)
+		default:
+			return
+		}
+		if replacement, ok := validator.FindRule(rule.ReplacedBy); ok {
+			warning += "; use " + replacement.Name + " instead"
+		}
+		warnings = append(warnings, warning)
+	}
+
+	for _, id := range sortedKeys(c.Docs.Rules) {
+		check("docs.rules."+id, id)
+	}
+	for _, list := range []struct {
+		name    string
+		entries []string
+	}{{"enable", c.Enable}, {"disable", c.Disable}} {
+		for i, entry := range list.entries {
+			for _, key := range strings.Split(entry, ",") {
+				if key = strings.TrimSpace(key); key == "" || slices.Contains(validator.RuleGroups(), key) {
+					continue
+				}
+				location := fmt.Sprintf("%s[%d]", list.name, i)
+				check(location, key)
+				if rule, _ := validator.FindRuleByKey(key); rule.Mandatory && list.name == "disable" {
+					warnings = append(warnings, fmt.Sprintf("%s: rule %s cannot be disabled and still runs", location, rule.Name))
+				}
+			}
+		}
+	}
+	for _, key := range sortedKeys(c.Severity) {
+		check("severity."+key, key)
+	}
+	for _, key := range sortedKeys(c.Budgets) {
+		check("budgets."+key, key)
+	}
+	return warnings
+}
+
+// sortedKeys возвращает ключи раздела конфигурации по порядку, чтобы предупреждения не менялись между запусками
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ruleIDs переводит список правил и групп через запятую в идентификаторы правил
+func ruleIDs(entries []string) ([]string, error) {
+	var ids []string
+	for _, entry := range entries {
+		for _, key := range strings.Split(entry, ",") {
+			key = strings.TrimSpace(key)
+			if key == "" {
+				continue
+			}
+			if slices.Contains(validator.RuleGroups(), key) {
+				for _, rule := range validator.Rules() {
+					if rule.Group == key {
+						ids = append(ids, rule.ID)
+					}
+				}
+				continue
+			}
+			rule, ok := validator.FindRuleByKey(key)
+			if !ok {
+				return nil, fmt.Errorf("unknown rule %q", key)
+			}
+			ids = append(ids, rule.ID)
+		}
+	}
+	return ids, nil
+}
+
+// severities возвращает переопределённые уровни по идентификаторам правил
+func (c *Config) severities() map[string]validator.Severity {
+	severities := map[string]validator.Severity{}
+	for key, severity := range c.Severity {
+		if rule, ok := validator.FindRuleByKey(key); ok {
+			severities[rule.ID] = severity
+		}
+	}
+	return severities
+}
+
+func (c *Config) docURL(id string) string {
+	rule, ok := validator.FindRule(id)
+	if !ok {
+		return ""
+	}
+	if url, exists := c.Docs.Rules[id]; exists {
+		return validator.DocURL(url, rule)
+	}
+	return validator.DocURL(c.Docs.URL, rule)
+}
+
+// isAdvisory сообщает, попадает ли файл в каталог, отмеченный как рекомендательный
+func (c *Config) isAdvisory(file string) bool {
+	// Фрагменты file#2 относятся к своему файлу
+	if i := strings.IndexByte(file, '#'); i >= 0 {
+		file = file[:i]
+	}
+	name := strings.Split(filepath.ToSlash(filepath.Clean(file)), "/")
+	for _, entry := range c.Advisory {
+		for _, pattern := range expandBraces(filepath.ToSlash(filepath.Clean(entry))) {
+			if !hasGlobMeta(pattern) {
+				pattern += "/**"
+			}
+			if matchSegments(strings.Split(pattern, "/"), name) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+func TestConfigWarnings(t *testing.T) {
+	check := func(map[string]interface{}, string) []validator.Finding { return nil }
+	for _, rule := range []validator.Rule{
+		{ID: "TEST901", Name: "old-rule", State: validator.StateDeprecated},
+		{ID: "TEST902", Name: "gone-rule", State: validator.StateRemoved},
+		{ID: "TEST903", Name: "renamed-rule", State: validator.StateRemoved, ReplacedBy: "YV102"},
+	} {
+		if err := validator.RegisterExternalRule(validator.ExternalRule{Rule: rule, Check: check}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config := &Config{
+		Docs:     DocsConfig{Rules: map[string]string{"TEST901": "https://wiki.example.com/old"}},
+		Enable:   []string{"image-tag, old-rule", "TEST902"},
+		Disable:  []string{"gone-rule", "yaml-syntax", "cis-service-account-token", "renamed-rule"},
+		Severity: map[string]validator.Severity{"old-rule": validator.SeverityInfo, "image-tag": validator.SeverityWarning},
+		Budgets:  map[string]int{"TEST902": 3},
+	}
+	want := []string{
+		"docs.rules.TEST901: rule old-rule is deprecated and will be removed",
+		"enable[0]: rule old-rule is deprecated and will be removed",
+		"enable[1]: rule gone-rule has been removed and no longer runs",
+		"disable[0]: rule gone-rule has been removed and no longer runs",
+		"disable[1]: rule yaml-syntax cannot be disabled and still runs",
+		// Устаревшее встроенное правило и удалённое с заменой называют замену
+		"disable[2]: rule cis-service-account-token is deprecated and will be removed; use service-account-token instead",
+		"disable[3]: rule renamed-rule has been removed and no longer runs; use image-tag instead",
+		"severity.old-rule: rule old-rule is deprecated and will be removed",
+		"budgets.TEST902: rule gone-rule has been removed and no longer runs",
+	}
+	if got := config.warnings(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got warnings\n%q\nwant\n%q", got, want)
+	}
+
+	// Группы правил не раскрываются: устаревшее правило группы само по себе не называлось
+	config = &Config{Enable: []string{"cis"}}
+	if got := config.warnings(); len(got) != 0 {
+		t.Errorf("group produced warnings %q", got)
+	}
+}
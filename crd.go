@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+// Каталоги, определения CRD из которых уже зарегистрированы: повторная регистрация типа — ошибка
+var loadedCRDs struct {
+	sync.Mutex
+	dirs map[string]bool
+}
+
+// loadCRDs регистрирует типы из CustomResourceDefinition во всех файлах YAML и JSON каталога,
+// включая вложенные каталоги, например config/crd/bases
+func loadCRDs(dir string) error {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	loadedCRDs.Lock()
+	defer loadedCRDs.Unlock()
+	if loadedCRDs.dirs[dir] {
+		return nil
+	}
+
+	registered := 0
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ext := filepath.Ext(path); entry.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		kinds, warnings, err := validator.RegisterCRD(data)
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		for _, warning := range warnings {
+			fmt.Fprintf(os.Stderr, "warning: %s: %s\n", path, warning)
+		}
+		registered += len(kinds)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if registered == 0 {
+		return fmt.Errorf("no CustomResourceDefinition found in %s", dir)
+	}
+
+	if loadedCRDs.dirs == nil {
+		loadedCRDs.dirs = map[string]bool{}
+	}
+	loadedCRDs.dirs[dir] = true
+	return nil
+}
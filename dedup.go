@@ -0,0 +1,65 @@
+package main
+
+import (
+	"crypto/sha256"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+// findingCache хранит находки для документов с одинаковым содержимым. Проверка выводит
+// из имени файла только признаки validator.FilenameTraits, поэтому они входят в ключ;
+// само имя встречается лишь в сообщениях и заменяется при выдаче.
+type findingCache struct {
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]cachedFindings
+	// perFile — каждый документ проверяется заново: правила подключаемых модулей получают
+	// имя файла, и их находки могут зависеть не только от содержимого
+	perFile bool
+}
+
+// cachedFindings — находки документа и имя файла, под которым он был проверен
+type cachedFindings struct {
+	findings []validator.Finding
+	filename string
+}
+
+// validate возвращает находки для документа, проверяя одинаковые документы один раз
+func (c *findingCache) validate(data []byte, filename string, validate func(data []byte, filename string) []validator.Finding) []validator.Finding {
+	if c.perFile {
+		return validate(data, filename)
+	}
+	key := sha256.Sum256(append([]byte(validator.FilenameTraits(filename)+"\x00"), data...))
+
+	c.mu.Lock()
+	cached, hit := c.entries[key]
+	c.mu.Unlock()
+	if !hit {
+		cached = cachedFindings{findings: validate(data, filename), filename: filename}
+		c.mu.Lock()
+		if c.entries == nil {
+			c.entries = map[[sha256.Size]byte]cachedFindings{}
+		}
+		c.entries[key] = cached
+		c.mu.Unlock()
+	}
+	// Сообщения называют файл полным путём или базовым именем; полный путь заменяется первым
+	renamer := strings.NewReplacer(cached.filename, filename, filepath.Base(cached.filename), filepath.Base(filename))
+	return renameFindings(cached.findings, renamer)
+}
+
+// renameFindings копирует находки, подставляя в сообщения имя другого файла
+func renameFindings(findings []validator.Finding, renamer *strings.Replacer) []validator.Finding {
+	if findings == nil {
+		return nil
+	}
+	renamed := make([]validator.Finding, len(findings))
+	for i, finding := range findings {
+		finding.Message = renamer.Replace(finding.Message)
+		finding.Related = renameFindings(finding.Related, renamer)
+		renamed[i] = finding
+	}
+	return renamed
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+func TestFindingCacheRenamesFindings(t *testing.T) {
+	data := []byte("apiVersion: v1\nkind: Pod\nmetadata:\n  labels: {}\nspec:\n  containers:\n    - name: web\n      image: nginx:1.25\n")
+	var cache findingCache
+	calls := 0
+	validate := func(data []byte, filename string) []validator.Finding {
+		calls++
+		return validator.Validate(data, filename)
+	}
+
+	want := cache.validate(data, "chart/web.yaml", validate)
+	if len(want) == 0 {
+		t.Fatal("no findings for the fixture")
+	}
+	got := cache.validate(data, "other/pod.yaml", validate)
+	if calls != 1 {
+		t.Errorf("validated %d times, want once", calls)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d findings, want %d", len(got), len(want))
+	}
+	for i := range got {
+		wantMessage := strings.NewReplacer("chart/web.yaml", "other/pod.yaml", "web.yaml", "pod.yaml").Replace(want[i].Message)
+		if got[i].Message != wantMessage {
+			t.Errorf("message = %q, want %q", got[i].Message, wantMessage)
+		}
+		if strings.Contains(got[i].Message, "web.yaml") {
+			t.Errorf("message %q still names the first file", got[i].Message)
+		}
+	}
+
+	// Имя docker-compose.yaml меняет распознавание, и документ проверяется заново
+	cache.validate(data, "docker-compose.yaml", validate)
+	if calls != 2 {
+		t.Errorf("validated %d times, want a separate validation for a Compose file name", calls)
+	}
+
+	// С правилами подключаемых модулей кэш не используется
+	perFile := findingCache{perFile: true}
+	perFile.validate(data, "a/web.yaml", validate)
+	perFile.validate(data, "b/web.yaml", validate)
+	if calls != 4 {
+		t.Errorf("validated %d times, want every document validated with plugin rules", calls)
+	}
+}
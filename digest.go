@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Типы манифестов, дайджест которых записывается в ссылку на образ: списки платформ и одиночные образы
+var manifestTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// Время на один запрос к реестру: недоступный реестр не должен заметно задерживать проверку
+const registryTimeout = 5 * time.Second
+
+// registryClient узнаёт дайджесты образов через Registry HTTP API v2 без учётных данных:
+// анонимный токен запрашивается, если реестр его требует
+type registryClient struct {
+	http *http.Client
+	// Куда печатать предупреждения об ошибках запросов, по одному на образ
+	warnings io.Writer
+	mu       sync.Mutex
+	cache    map[string]resolvedDigest
+}
+
+// resolvedDigest — результат запроса дайджеста; ошибки тоже кэшируются, чтобы не повторять запрос
+// для каждого контейнера с тем же образом
+type resolvedDigest struct {
+	digest string
+	err    error
+}
+
+func newRegistryClient() *registryClient {
+	return &registryClient{http: &http.Client{Timeout: registryTimeout}, warnings: os.Stderr, cache: map[string]resolvedDigest{}}
+}
+
+// splitImage разбирает ссылку repo:tag на адрес реестра, репозиторий и тег; образы без
+// реестра берутся из Docker Hub, а официальные образы Docker Hub — из library/
+func splitImage(image string) (host, repository, tag string) {
+	host = "registry-1.docker.io"
+	repository = image
+	if first, rest, found := strings.Cut(image, "/"); found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		host, repository = first, rest
+	}
+	if host == "docker.io" || host == "index.docker.io" {
+		host = "registry-1.docker.io"
+	}
+	if host == "registry-1.docker.io" && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+	tag = "latest"
+	if colon := strings.LastIndex(repository, ":"); colon >= 0 {
+		repository, tag = repository[:colon], repository[colon+1:]
+	}
+	return host, repository, tag
+}
+
+// resolve возвращает дайджест манифеста тега; одинаковые образы запрашиваются один раз,
+// об ошибке печатается одно предупреждение
+func (c *registryClient) resolve(image string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if result, cached := c.cache[image]; cached {
+		return result.digest, result.err
+	}
+	digest, err := c.lookup(image)
+	c.cache[image] = resolvedDigest{digest: digest, err: err}
+	if err != nil {
+		fmt.Fprintf(c.warnings, "warning: resolving the digest of %s failed: %v\n", image, err)
+	}
+	return digest, err
+}
+
+// lookup запрашивает дайджест манифеста тега у реестра
+func (c *registryClient) lookup(image string) (string, error) {
+	host, repository, tag := splitImage(image)
+	manifest := fmt.Sprintf("https://%s/v2/%s/manifests/%s", host, repository, url.PathEscape(tag))
+	response, err := c.head(manifest, "")
+	if err != nil {
+		return "", err
+	}
+	if response.StatusCode == http.StatusUnauthorized {
+		token, err := c.token(response.Header.Get("WWW-Authenticate"))
+		if err != nil {
+			return "", err
+		}
+		if response, err = c.head(manifest, token); err != nil {
+			return "", err
+		}
+	}
+	switch {
+	case response.StatusCode == http.StatusNotFound:
+		return "", fmt.Errorf("%s:%s not found in %s", repository, tag, host)
+	case response.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("HEAD %s: %s", manifest, response.Status)
+	}
+	digest := response.Header.Get("Docker-Content-Digest")
+	if !strings.HasPrefix(digest, "sha256:") {
+		return "", fmt.Errorf("%s did not return a sha256 digest", host)
+	}
+	return digest, nil
+}
+
+func (c *registryClient) head(manifest, token string) (*http.Response, error) {
+	request, err := http.NewRequest(http.MethodHead, manifest, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", strings.Join(manifestTypes, ", "))
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	response, err := c.http.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	response.Body.Close()
+	return response, nil
+}
+
+// token получает анонимный токен по заголовку WWW-Authenticate: Bearer realm="...",service="...",scope="..."
+func (c *registryClient) token(challenge string) (string, error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", fmt.Errorf("registry requires %s authentication, which is not supported", scheme)
+	}
+	values := map[string]string{}
+	for _, param := range strings.Split(params, ",") {
+		if key, value, found := strings.Cut(strings.TrimSpace(param), "="); found {
+			values[key] = strings.Trim(value, `"`)
+		}
+	}
+	realm, err := url.Parse(values["realm"])
+	if err != nil || realm.Scheme != "https" {
+		return "", fmt.Errorf("registry returned an invalid token realm %q", values["realm"])
+	}
+	query := realm.Query()
+	for _, key := range []string{"service", "scope"} {
+		if values[key] != "" {
+			query.Set(key, values[key])
+		}
+	}
+	realm.RawQuery = query.Encode()
+
+	response, err := c.http.Get(realm.String())
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GET %s: %s", realm.Redacted(), response.Status)
+	}
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(response.Body, 1<<20)).Decode(&body); err != nil {
+		return "", fmt.Errorf("token response: %v", err)
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	if body.AccessToken != "" {
+		return body.AccessToken, nil
+	}
+	return "", fmt.Errorf("registry returned no token, the image may require credentials")
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+const testDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+// testRegistry запускает реестр, который знает только образ shop/web:1.0
+func testRegistry(t *testing.T) (*registryClient, string, *int32, *bytes.Buffer) {
+	t.Helper()
+	var requests int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.URL.Path != "/v2/shop/web/manifests/1.0" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Docker-Content-Digest", testDigest)
+	}))
+	t.Cleanup(server.Close)
+
+	var warnings bytes.Buffer
+	client := &registryClient{http: server.Client(), warnings: &warnings, cache: map[string]resolvedDigest{}}
+	return client, strings.TrimPrefix(server.URL, "https://"), &requests, &warnings
+}
+
+func TestRegistryClientCachesDigests(t *testing.T) {
+	client, host, requests, warnings := testRegistry(t)
+	for i := 0; i < 3; i++ {
+		digest, err := client.resolve(host + "/shop/web:1.0")
+		if err != nil || digest != testDigest {
+			t.Fatalf("got %q, %v", digest, err)
+		}
+	}
+	if *requests != 1 {
+		t.Errorf("registry asked %d times, want once", *requests)
+	}
+	if warnings.Len() != 0 {
+		t.Errorf("unexpected warnings: %s", warnings)
+	}
+}
+
+func TestRegistryClientCachesErrors(t *testing.T) {
+	client, host, requests, warnings := testRegistry(t)
+	for i := 0; i < 3; i++ {
+		if _, err := client.resolve(host + "/shop/missing:1.0"); err == nil {
+			t.Fatal("expected an error for a missing image")
+		}
+	}
+	if *requests != 1 {
+		t.Errorf("registry asked %d times, want once", *requests)
+	}
+	if got := strings.Count(warnings.String(), "warning: "); got != 1 {
+		t.Errorf("got %d warnings, want one:\n%s", got, warnings)
+	}
+}
+
+func TestImageDigestFindingIgnoresResolverErrors(t *testing.T) {
+	client, host, _, _ := testRegistry(t)
+
+	manifest := func(image string) []byte {
+		return []byte("apiVersion: v1\nkind: Pod\nmetadata:\n  name: web\nspec:\n  containers:\n    - name: web\n      image: " + image + "\n")
+	}
+	message := func(image string, resolve validator.DigestResolver) (string, *validator.Remediation) {
+		for _, finding := range validator.ValidateWith(manifest(image), "pod.yaml", validator.Options{DigestResolver: resolve}) {
+			if finding.RuleID == "YV116" {
+				return finding.Message, finding.Remediation
+			}
+		}
+		t.Fatalf("no image-digest finding for %s", image)
+		return "", nil
+	}
+
+	want, _ := message(host+"/shop/missing:1.0", nil)
+	got, remediation := message(host+"/shop/missing:1.0", client.resolve)
+	if got != want {
+		t.Errorf("resolver error changed the message:\n got %q\nwant %q", got, want)
+	}
+	if remediation.Value != nil {
+		t.Errorf("remediation has a value %v without a digest", remediation.Value)
+	}
+	if _, remediation := message(host+"/shop/web:1.0", client.resolve); remediation.Value != host+"/shop/web:1.0@"+testDigest {
+		t.Errorf("remediation value = %v", remediation.Value)
+	}
+}
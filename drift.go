@@ -0,0 +1,333 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+// kubeconfig — поля файла kubeconfig, нужные для чтения объектов
+type kubeconfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Contexts       []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster   string `yaml:"cluster"`
+			User      string `yaml:"user"`
+			Namespace string `yaml:"namespace"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+	Clusters []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server                   string `yaml:"server"`
+			CertificateAuthority     string `yaml:"certificate-authority"`
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+			InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Users []struct {
+		Name string `yaml:"name"`
+		User struct {
+			Token                 string      `yaml:"token"`
+			TokenFile             string      `yaml:"tokenFile"`
+			ClientCertificate     string      `yaml:"client-certificate"`
+			ClientCertificateData string      `yaml:"client-certificate-data"`
+			ClientKey             string      `yaml:"client-key"`
+			ClientKeyData         string      `yaml:"client-key-data"`
+			Exec                  interface{} `yaml:"exec"`
+			AuthProvider          interface{} `yaml:"auth-provider"`
+		} `yaml:"user"`
+	} `yaml:"users"`
+}
+
+// clusterClient читает объекты из API-сервера по учётным данным контекста kubeconfig
+type clusterClient struct {
+	server    string
+	token     string
+	namespace string
+	http      *http.Client
+	// Ресурсы API по apiVersion из discovery: имя ресурса REST API и область видимости по kind
+	resources map[string]map[string]apiResource
+}
+
+// apiResource — ресурс из ответа discovery (/api/v1, /apis/<group>/<version>)
+type apiResource struct {
+	Name       string `json:"name"`
+	Kind       string `json:"kind"`
+	Namespaced bool   `json:"namespaced"`
+}
+
+// runDrift сравнивает манифесты с объектами кластера и печатает расхождения вместе с находками проверки
+func runDrift(args []string) {
+	flagSet := flag.NewFlagSet("yamlvalid drift", flag.ExitOnError)
+	common := addCommonFlags(flagSet)
+	kubeconfigPath := flagSet.String("kubeconfig", "", "path to the kubeconfig file (default: $KUBECONFIG or ~/.kube/config)")
+	contextName := flagSet.String("context", "", "kubeconfig context to use (default: current-context)")
+	flagSet.Usage = func() {
+		fmt.Println("Usage: yamlvalid drift [flags] <file|directory|glob>...")
+		flagSet.PrintDefaults()
+	}
+	paths := parseInterspersed(flagSet, args)
+
+	if len(paths) == 0 {
+		flagSet.Usage()
+		os.Exit(exitUsage)
+	}
+	s := common.session()
+	client, err := newClusterClient(*kubeconfigPath, *contextName)
+	if err != nil {
+		fmt.Printf("Error reading kubeconfig: %v\n", err)
+		os.Exit(exitUsage)
+	}
+
+	var results []fileResult
+	for _, name := range manifestFiles(paths, s.config.Scan) {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			fmt.Printf("Error reading file: %v\n", err)
+			os.Exit(exitIO)
+		}
+		findings := s.validate(data, name)
+		drift, err := validator.Drift(data, name, client.namespace, client.get)
+		if err != nil {
+			fmt.Printf("Error reading cluster: %s: %v\n", name, err)
+			os.Exit(exitIO)
+		}
+		findings = append(findings, validator.FilterFindings(drift, s.selection)...)
+		results = append(results, fileResult{file: name, findings: findings, source: data})
+	}
+	s.report.summary = len(results) > 1
+	s.finish(results)
+}
+
+// newClusterClient настраивает клиента по контексту kubeconfig; поддерживаются токены и клиентские сертификаты
+func newClusterClient(path, contextName string) (*clusterClient, error) {
+	if path == "" {
+		path = os.Getenv("KUBECONFIG")
+		// KUBECONFIG может перечислять несколько файлов; берём первый
+		if i := strings.IndexRune(path, filepath.ListSeparator); i >= 0 {
+			path = path[:i]
+		}
+	}
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(home, ".kube", "config")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config kubeconfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if contextName == "" {
+		contextName = config.CurrentContext
+	}
+
+	client := &clusterClient{namespace: "default", resources: map[string]map[string]apiResource{}}
+	var clusterName, userName string
+	found := false
+	for _, context := range config.Contexts {
+		if context.Name == contextName {
+			clusterName, userName, found = context.Context.Cluster, context.Context.User, true
+			if context.Context.Namespace != "" {
+				client.namespace = context.Context.Namespace
+			}
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("%s: context %q not found", path, contextName)
+	}
+
+	transport := &tls.Config{}
+	for _, cluster := range config.Clusters {
+		if cluster.Name != clusterName {
+			continue
+		}
+		client.server = strings.TrimSuffix(cluster.Cluster.Server, "/")
+		transport.InsecureSkipVerify = cluster.Cluster.InsecureSkipTLSVerify
+		ca, err := kubeconfigData(cluster.Cluster.CertificateAuthorityData, cluster.Cluster.CertificateAuthority, path)
+		if err != nil {
+			return nil, fmt.Errorf("cluster %s: certificate authority: %v", clusterName, err)
+		}
+		if ca != nil {
+			transport.RootCAs = x509.NewCertPool()
+			if !transport.RootCAs.AppendCertsFromPEM(ca) {
+				return nil, fmt.Errorf("cluster %s: certificate authority contains no certificates", clusterName)
+			}
+		}
+	}
+	if client.server == "" {
+		return nil, fmt.Errorf("%s: cluster %q not found", path, clusterName)
+	}
+	userFound := userName == ""
+	for _, user := range config.Users {
+		if user.Name != userName {
+			continue
+		}
+		userFound = true
+		switch {
+		case user.User.Exec != nil:
+			return nil, fmt.Errorf("user %s: exec credential plugins are not supported, use a token or client certificate", userName)
+		case user.User.AuthProvider != nil:
+			return nil, fmt.Errorf("user %s: auth-provider plugins are not supported, use a token or client certificate", userName)
+		}
+		client.token = user.User.Token
+		if user.User.TokenFile != "" {
+			token, err := os.ReadFile(resolveKubeconfigPath(user.User.TokenFile, path))
+			if err != nil {
+				return nil, fmt.Errorf("user %s: %v", userName, err)
+			}
+			client.token = strings.TrimSpace(string(token))
+		}
+		cert, err := kubeconfigData(user.User.ClientCertificateData, user.User.ClientCertificate, path)
+		if err != nil {
+			return nil, fmt.Errorf("user %s: client certificate: %v", userName, err)
+		}
+		key, err := kubeconfigData(user.User.ClientKeyData, user.User.ClientKey, path)
+		if err != nil {
+			return nil, fmt.Errorf("user %s: client key: %v", userName, err)
+		}
+		if cert != nil && key != nil {
+			pair, err := tls.X509KeyPair(cert, key)
+			if err != nil {
+				return nil, fmt.Errorf("user %s: %v", userName, err)
+			}
+			transport.Certificates = []tls.Certificate{pair}
+		}
+	}
+	if !userFound {
+		return nil, fmt.Errorf("%s: user %q not found", path, userName)
+	}
+	client.http = &http.Client{Timeout: 30 * time.Second, Transport: &http.Transport{TLSClientConfig: transport}}
+	return client, nil
+}
+
+// kubeconfigData возвращает содержимое поля *-data (base64) либо файла, на который ссылается поле
+func kubeconfigData(data, file, kubeconfigPath string) ([]byte, error) {
+	if data != "" {
+		return base64.StdEncoding.DecodeString(data)
+	}
+	if file != "" {
+		return os.ReadFile(resolveKubeconfigPath(file, kubeconfigPath))
+	}
+	return nil, nil
+}
+
+// resolveKubeconfigPath — относительные пути в kubeconfig отсчитываются от его каталога
+func resolveKubeconfigPath(file, kubeconfigPath string) string {
+	if filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(filepath.Dir(kubeconfigPath), file)
+}
+
+// get читает объект через REST API; nil, если объекта нет
+func (c *clusterClient) get(apiVersion, kind, namespace, name string) (map[string]interface{}, error) {
+	resource, err := c.resource(apiVersion, kind)
+	if err != nil {
+		return nil, err
+	}
+	path := apiPrefix(apiVersion)
+	if resource.Namespaced {
+		path += "/namespaces/" + url.PathEscape(namespace)
+	}
+	path += "/" + resource.Name + "/" + url.PathEscape(name)
+
+	var object map[string]interface{}
+	if found, err := c.getJSON(path, &object); err != nil || !found {
+		return nil, err
+	}
+	return object, nil
+}
+
+// resource находит ресурс REST API для kind через discovery: имена ресурсов не выводятся из kind
+// по правилам английского языка (Endpoints → endpoints), а CRD задают их сами
+func (c *clusterClient) resource(apiVersion, kind string) (apiResource, error) {
+	resources, cached := c.resources[apiVersion]
+	if !cached {
+		var list struct {
+			Resources []apiResource `json:"resources"`
+		}
+		found, err := c.getJSON(apiPrefix(apiVersion), &list)
+		if err != nil {
+			return apiResource{}, err
+		}
+		if !found {
+			return apiResource{}, fmt.Errorf("apiVersion %s is not served by the cluster", apiVersion)
+		}
+		resources = map[string]apiResource{}
+		for _, resource := range list.Resources {
+			// Подресурсы (deployments/status) повторяют kind ресурса, по ним объекты не читаются
+			if !strings.Contains(resource.Name, "/") {
+				resources[resource.Kind] = resource
+			}
+		}
+		c.resources[apiVersion] = resources
+	}
+	resource, ok := resources[kind]
+	if !ok {
+		return apiResource{}, fmt.Errorf("kind %s is not served by the cluster in %s", kind, apiVersion)
+	}
+	return resource, nil
+}
+
+// apiPrefix возвращает путь группы API: /api/v1 для основной группы, /apis/<group>/<version> для остальных
+func apiPrefix(apiVersion string) string {
+	if strings.Contains(apiVersion, "/") {
+		return "/apis/" + apiVersion
+	}
+	return "/api/" + apiVersion
+}
+
+// getJSON выполняет GET и разбирает ответ в value; false, если сервер ответил 404
+func (c *clusterClient) getJSON(path string, value interface{}) (bool, error) {
+	request, err := http.NewRequest(http.MethodGet, c.server+path, nil)
+	if err != nil {
+		return false, err
+	}
+	request.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		request.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	response, err := c.http.Do(request)
+	if err != nil {
+		return false, err
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(response.Body, 64<<20))
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case response.StatusCode == http.StatusNotFound:
+		return false, nil
+	case response.StatusCode == http.StatusUnauthorized:
+		return false, fmt.Errorf("GET %s: %s, check the credentials of the kubeconfig user", path, response.Status)
+	case response.StatusCode != http.StatusOK:
+		return false, fmt.Errorf("GET %s: %s", path, response.Status)
+	}
+	if err := json.Unmarshal(body, value); err != nil {
+		return false, fmt.Errorf("GET %s: %v", path, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestClusterClientUsesDiscovery(t *testing.T) {
+	discoveries := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1":
+			discoveries++
+			w.Write([]byte(`{"resources": [
+				{"name": "endpoints", "kind": "Endpoints", "namespaced": true},
+				{"name": "namespaces", "kind": "Namespace", "namespaced": false},
+				{"name": "namespaces/status", "kind": "Namespace", "namespaced": false}
+			]}`))
+		case "/api/v1/namespaces/web/endpoints/api":
+			w.Write([]byte(`{"kind": "Endpoints", "metadata": {"name": "api"}}`))
+		case "/api/v1/namespaces/web":
+			w.Write([]byte(`{"kind": "Namespace", "metadata": {"name": "web"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	client := &clusterClient{server: server.URL, http: server.Client(), resources: map[string]map[string]apiResource{}}
+
+	for _, object := range []struct{ kind, namespace, name string }{{"Endpoints", "web", "api"}, {"Namespace", "", "web"}} {
+		got, err := client.get("v1", object.kind, object.namespace, object.name)
+		if err != nil || got == nil || got["kind"] != object.kind {
+			t.Errorf("get %s: %v, %v", object.kind, got, err)
+		}
+	}
+	if got, err := client.get("v1", "Endpoints", "web", "missing"); got != nil || err != nil {
+		t.Errorf("missing object: %v, %v", got, err)
+	}
+	if discoveries != 1 {
+		t.Errorf("discovery requested %d times, want once", discoveries)
+	}
+	if _, err := client.get("v1", "Gadget", "web", "g"); err == nil || !strings.Contains(err.Error(), "not served") {
+		t.Errorf("unknown kind: %v", err)
+	}
+	if _, err := client.get("example.com/v1", "Widget", "web", "w"); err == nil || !strings.Contains(err.Error(), "not served") {
+		t.Errorf("unknown apiVersion: %v", err)
+	}
+}
+
+func TestNewClusterClientRejectsUnsupportedCredentials(t *testing.T) {
+	const kubeconfig = `current-context: test
+contexts:
+  - name: test
+    context: {cluster: test, user: %s}
+clusters:
+  - name: test
+    cluster: {server: "https://127.0.0.1:6443"}
+users:
+  - name: exec
+    user:
+      exec: {command: aws}
+  - name: provider
+    user:
+      auth-provider: {name: oidc}
+  - name: token
+    user: {token: secret}
+`
+	tests := []struct{ user, want string }{
+		{"exec", "exec credential plugins are not supported"},
+		{"provider", "auth-provider plugins are not supported"},
+		{"missing", `user "missing" not found`},
+		{"token", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.user, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config")
+			if err := os.WriteFile(path, []byte(strings.Replace(kubeconfig, "%s", tt.user, 1)), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := newClusterClient(path, "")
+			if tt.want == "" && err != nil || tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+				t.Errorf("got %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+// Переменные окружения, которые влияют на сетевые обращения внешних инструментов
+var proxyVariables = []string{"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy"}
+
+// runEnv печатает сведения о платформе, сборке и окружении, от которых зависит результат проверки
+func runEnv(args []string) {
+	flagSet := flag.NewFlagSet("yamlvalid env", flag.ExitOnError)
+	configPath := flagSet.String("config", "", "path to yamlvalid config file (default: .yamlvalid.yaml in the working directory or its parents)")
+	jsonnetBinary := flagSet.String("jsonnet-bin", "jsonnet", "jsonnet interpreter used by yamlvalid jsonnet")
+	flagSet.Usage = func() {
+		fmt.Println("Usage: yamlvalid env [flags]")
+		flagSet.PrintDefaults()
+	}
+	flagSet.Parse(args)
+
+	if flagSet.NArg() != 0 {
+		flagSet.Usage()
+		os.Exit(exitUsage)
+	}
+	writeEnv(os.Stdout, *configPath, *jsonnetBinary)
+}
+
+func writeEnv(w io.Writer, configPath, jsonnetBinary string) {
+	fmt.Fprintf(w, "platform: %s/%s\n", runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(w, "go: %s\n", runtime.Version())
+	fmt.Fprintf(w, "ruleset: %s\n", validator.CurrentRulesetVersion())
+
+	// Сборка: модуль и настройки, по которым её можно воспроизвести
+	if info, ok := debug.ReadBuildInfo(); ok {
+		fmt.Fprintf(w, "module: %s %s\n", info.Main.Path, info.Main.Version)
+		for _, setting := range info.Settings {
+			fmt.Fprintf(w, "build %s: %s\n", setting.Key, setting.Value)
+		}
+	} else {
+		fmt.Fprintln(w, "build: no build information")
+	}
+
+	// Конфигурация ищется так же, как при проверке: без --config — в рабочем каталоге и выше
+	switch {
+	case configPath != "":
+		if abs, err := filepath.Abs(configPath); err == nil {
+			configPath = abs
+		}
+		if _, err := os.Stat(configPath); err != nil {
+			fmt.Fprintf(w, "config: %s (%v)\n", configPath, err)
+		} else {
+			fmt.Fprintf(w, "config: %s\n", configPath)
+		}
+	case findProjectConfig() != "":
+		fmt.Fprintf(w, "config: %s (found from the working directory)\n", findProjectConfig())
+	default:
+		fmt.Fprintf(w, "config: none (no %s in the working directory or its parents)\n", projectConfig)
+	}
+	// Находки кэшируются в памяти на время запуска; на диск пишется только запись телеметрии
+	if record := telemetryRecord(); record != "" {
+		fmt.Fprintf(w, "cache: %s (telemetry record; findings are cached in memory per run)\n", filepath.Dir(record))
+	} else {
+		fmt.Fprintln(w, "cache: in-memory, per run (no user cache directory)")
+	}
+
+	if path, err := exec.LookPath(jsonnetBinary); err == nil {
+		fmt.Fprintf(w, "jsonnet: %s\n", path)
+	} else {
+		fmt.Fprintf(w, "jsonnet: not found (%s)\n", jsonnetBinary)
+	}
+	for _, name := range proxyVariables {
+		if value, ok := os.LookupEnv(name); ok {
+			fmt.Fprintf(w, "%s: %s\n", name, redactUserinfo(value))
+		}
+	}
+}
+
+// redactUserinfo скрывает user:password@ в адресе прокси: вывод env прикладывают к отчётам об ошибках
+func redactUserinfo(value string) string {
+	prefix, rest := "", value
+	if i := strings.Index(value, "://"); i >= 0 {
+		prefix, rest = value[:i+3], value[i+3:]
+	}
+	authority := rest
+	if i := strings.IndexAny(rest, "/?#"); i >= 0 {
+		authority = rest[:i]
+	}
+	if at := strings.LastIndex(authority, "@"); at >= 0 {
+		return prefix + "REDACTED@" + rest[at+1:]
+	}
+	return value
+}
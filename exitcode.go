@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+// Коды выхода: обёртки отличают «манифест сломан» (1, 4) от «проверка не смогла
+// выполниться» (2, 3)
+const (
+	exitValid = 0
+	// Найдены ошибки проверки
+	exitFindings = 1
+	// Неверные флаги, аргументы или конфигурация
+	exitUsage = 2
+	// Файл не удалось прочитать или записать
+	exitIO = 3
+	// Файл не разбирается как YAML или не рендерится
+	exitUnparsable = 4
+)
+
+// configExitCode отличает нечитаемый файл конфигурации от неверного содержимого
+func configExitCode(err error) int {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return exitIO
+	}
+	return exitUsage
+}
+
+// resultExitCode возвращает код выхода по находкам: неразобранный YAML важнее
+// остальных ошибок, рекомендательные файлы и находки в пределах бюджета не учитываются
+func resultExitCode(results []fileResult, budgets []budgetStatus) int {
+	for _, result := range results {
+		if result.advisory {
+			continue
+		}
+		for _, finding := range result.findings {
+			if finding.RuleID == validator.RuleYAMLSyntax {
+				return exitUnparsable
+			}
+		}
+	}
+	if hasErrors(results, budgets) {
+		return exitFindings
+	}
+	return exitValid
+}
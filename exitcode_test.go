@@ -0,0 +1,19 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+func TestDisabledSyntaxRuleStillFailsUnparsable(t *testing.T) {
+	disable, err := ruleIDs([]string{"yaml-syntax"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &session{config: &Config{}, selection: validator.RuleSelection{RulesetVersion: validator.CurrentRulesetVersion(), Disable: disable}}
+	results := []fileResult{{file: "bad.yaml", findings: s.validate([]byte("kind: [Pod\n"), "bad.yaml")}}
+	if code := resultExitCode(results, nil); code != exitUnparsable {
+		t.Errorf("exit code = %d, want %d (exitUnparsable)", code, exitUnparsable)
+	}
+}
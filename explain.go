@@ -0,0 +1,92 @@
+package main
+
+import (
+	"embed"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+// Документация встроенных правил: зачем правило, нарушающий и правильный пример, исправление
+//
+//go:embed docs/rules
+var ruleDocs embed.FS
+
+// ruleDoc возвращает документацию правила; пусто для правил без неё, например правил DSL
+func ruleDoc(id string) string {
+	data, err := ruleDocs.ReadFile("docs/rules/" + id + ".md")
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// runExplain печатает документацию правила по идентификатору или имени, без аргументов — список правил
+func runExplain(args []string) {
+	flagSet := flag.NewFlagSet("yamlvalid explain", flag.ExitOnError)
+	configPath := flagSet.String("config", "", "path to yamlvalid config file with custom rules (default: .yamlvalid.yaml in the working directory or its parents)")
+	flagSet.Usage = func() {
+		fmt.Println("Usage: yamlvalid explain [flags] [<rule-id|rule-name>]")
+		flagSet.PrintDefaults()
+	}
+	args = parseInterspersed(flagSet, args)
+
+	if len(args) > 1 {
+		flagSet.Usage()
+		os.Exit(exitUsage)
+	}
+	config, err := loadConfig(*configPath)
+	if err != nil {
+		fmt.Printf("Error reading config: %v\n", err)
+		os.Exit(configExitCode(err))
+	}
+
+	if len(args) == 0 {
+		for _, rule := range validator.Rules() {
+			if rule.State != validator.StateRemoved {
+				fmt.Printf("%s  %-28s %s\n", rule.ID, rule.Name, rule.Description)
+			}
+		}
+		return
+	}
+	rule, ok := validator.FindRuleByKey(args[0])
+	if !ok {
+		fmt.Printf("Error: unknown rule %q\n", args[0])
+		os.Exit(exitUsage)
+	}
+	writeExplanation(os.Stdout, rule, config)
+}
+
+func writeExplanation(w io.Writer, rule validator.Rule, config *Config) {
+	fmt.Fprintf(w, "%s %s\n", rule.ID, rule.Name)
+	if rule.Description != "" {
+		fmt.Fprintf(w, "\n%s\n", rule.Description)
+	}
+
+	severity := rule.Severity
+	if severity == "" {
+		severity = validator.SeverityError
+	}
+	details := []string{fmt.Sprintf("severity %s", severity), string(rule.State)}
+	if replacement, ok := validator.FindRule(rule.ReplacedBy); ok {
+		details = append(details, "replaced by "+replacement.Name)
+	}
+	if rule.Since != "" {
+		details = append(details, "since "+rule.Since)
+	}
+	if rule.Group != "" {
+		details = append(details, "group "+rule.Group)
+	}
+	fmt.Fprintf(w, "\n%s\n", strings.Join(details, ", "))
+
+	if doc := ruleDoc(rule.ID); doc != "" {
+		fmt.Fprintf(w, "\n%s", doc)
+	}
+	if url := config.docURL(rule.ID); url != "" {
+		fmt.Fprintf(w, "\nSee %s\n", url)
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// snippet — фрагмент YAML, извлечённый из файла другого формата
+type snippet struct {
+	name string
+	data []byte
+	// Номер строки исходного файла, предшествующей первой строке фрагмента
+	lineOffset int
+	// Фрагмент сгенерирован заново, и позиции в нём не соответствуют исходному файлу
+	generated bool
+}
+
+// extractYAML извлекает YAML из файла: frontmatter — Markdown front matter,
+// directive — блоки между комментариями yamlvalid:begin и yamlvalid:end,
+// documents — документы, разделённые строками ---, например в текстовых дампах,
+// terraform — ресурсы kubernetes_manifest из плана terraform show -json,
+// snapshot — снимки cdk8s (dist/*.k8s.yaml) и JSON предпросмотра Pulumi
+func extractYAML(data []byte, filename, mode string) ([]snippet, error) {
+	switch mode {
+	case "terraform":
+		return extractTerraform(data, filename)
+	case "snapshot":
+		return extractSnapshot(data, filename)
+	}
+
+	lines := strings.SplitAfter(string(data), "\n")
+
+	var snippets []snippet
+	add := func(from, to int) {
+		snippets = append(snippets, snippet{
+			data:       []byte(strings.Join(lines[from:to], "")),
+			lineOffset: from,
+		})
+	}
+
+	switch mode {
+	case "frontmatter":
+		if len(lines) == 0 || !isDocumentSeparator(lines[0]) {
+			return nil, nil
+		}
+		for i := 1; i < len(lines); i++ {
+			if isDocumentSeparator(lines[i]) || strings.TrimSpace(lines[i]) == "..." {
+				add(1, i)
+				break
+			}
+		}
+	case "directive":
+		begin := -1
+		for i, line := range lines {
+			switch {
+			case strings.Contains(line, "yamlvalid:begin"):
+				begin = i + 1
+			case strings.Contains(line, "yamlvalid:end") && begin >= 0:
+				add(begin, i)
+				begin = -1
+			}
+		}
+		if begin >= 0 {
+			return nil, fmt.Errorf("%s:%d: yamlvalid:begin without yamlvalid:end", filename, begin)
+		}
+	case "documents":
+		start := 0
+		for i := 0; i <= len(lines); i++ {
+			if i < len(lines) && !isDocumentSeparator(lines[i]) {
+				continue
+			}
+			// Берём только фрагменты, которые разбираются как отображение YAML
+			var document map[string]interface{}
+			chunk := strings.Join(lines[start:i], "")
+			if yaml.Unmarshal([]byte(chunk), &document) == nil && len(document) > 0 {
+				add(start, i)
+			}
+			start = i + 1
+		}
+	default:
+		return nil, fmt.Errorf("unknown extract mode %q", mode)
+	}
+
+	for i := range snippets {
+		snippets[i].name = filename
+		if len(snippets) > 1 {
+			snippets[i].name = fmt.Sprintf("%s#%d", filename, i+1)
+		}
+	}
+	return snippets, nil
+}
+
+func isDocumentSeparator(line string) bool {
+	return strings.TrimRight(line, " \t\r\n") == "---"
+}
+
+// terraformModule — модуль в planned_values плана Terraform
+type terraformModule struct {
+	Resources []struct {
+		Address string `json:"address"`
+		Type    string `json:"type"`
+		Values  struct {
+			Manifest interface{} `json:"manifest"`
+		} `json:"values"`
+	} `json:"resources"`
+	ChildModules []terraformModule `json:"child_modules"`
+}
+
+// extractTerraform достаёт тела ресурсов kubernetes_manifest из JSON-плана; фрагменты называются по адресу ресурса
+func extractTerraform(data []byte, filename string) ([]snippet, error) {
+	var plan struct {
+		FormatVersion string `json:"format_version"`
+		PlannedValues struct {
+			RootModule terraformModule `json:"root_module"`
+		} `json:"planned_values"`
+	}
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("%s: not a Terraform JSON plan: %v", filename, err)
+	}
+	if plan.FormatVersion == "" {
+		return nil, fmt.Errorf("%s: not a Terraform JSON plan (run terraform show -json)", filename)
+	}
+
+	var snippets []snippet
+	var walk func(module terraformModule) error
+	walk = func(module terraformModule) error {
+		for _, resource := range module.Resources {
+			if resource.Type != "kubernetes_manifest" || resource.Values.Manifest == nil {
+				continue
+			}
+			manifest, err := json.MarshalIndent(resource.Values.Manifest, "", "  ")
+			if err != nil {
+				return err
+			}
+			snippets = append(snippets, snippet{name: filename + "#" + resource.Address, data: manifest, generated: true})
+		}
+		for _, child := range module.ChildModules {
+			if err := walk(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return snippets, walk(plan.PlannedValues.RootModule)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/internal/yamledit"
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+// fixable сообщает, можно ли применить подсказку без участия человека
+func fixable(finding validator.Finding) bool {
+	r := finding.Remediation
+	return r != nil && (r.Action == validator.ActionRemove || (r.Action == validator.ActionSet && r.Value != nil))
+}
+
+// applyFixes применяет к первому документу подсказки с однозначным значением и возвращает
+// исправленный текст и число применённых исправлений; остальной текст файла не меняется
+func applyFixes(source []byte, findings []validator.Finding) ([]byte, int, error) {
+	doc, err := yamledit.Parse(source)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	applied := 0
+	for _, finding := range findings {
+		if !fixable(finding) {
+			continue
+		}
+		switch finding.Remediation.Action {
+		case validator.ActionSet:
+			err = doc.Set(0, finding.Path, finding.Remediation.Value)
+		case validator.ActionRemove:
+			err = doc.Delete(0, finding.Path)
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		applied++
+	}
+	return doc.Bytes(), applied, nil
+}
+
+// filePatch — предлагаемые исправления одного файла
+type filePatch struct {
+	file  string
+	diff  string
+	fixes int
+}
+
+// collectFixes строит патчи для файлов, проверенных как есть: документы после
+// рендеринга, извлечения или из --stream не совпадают с файлом на диске и пропускаются
+func collectFixes(results []fileResult) []filePatch {
+	var patches []filePatch
+	for _, result := range results {
+		if !hasFixes(result.findings) {
+			continue
+		}
+		original, err := os.ReadFile(result.file)
+		if err != nil || !bytes.Equal(original, result.source) {
+			continue
+		}
+		fixed, applied, err := applyFixes(original, result.findings)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %s: fixes not applied: %v\n", result.file, err)
+			continue
+		}
+		name := filepath.ToSlash(result.file)
+		if diff := unifiedDiff("a/"+name, "b/"+name, string(original), string(fixed)); diff != "" {
+			patches = append(patches, filePatch{file: result.file, diff: diff, fixes: applied})
+		}
+	}
+	return patches
+}
+
+func hasFixes(findings []validator.Finding) bool {
+	for _, finding := range findings {
+		if fixable(finding) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeFixes записывает патчи в один файл либо, если output — каталог
+// (существующий или с / на конце), по патчу на файл
+func writeFixes(output string, patches []filePatch) error {
+	info, err := os.Stat(output)
+	perFile := strings.HasSuffix(output, "/") || (err == nil && info.IsDir())
+	if !perFile {
+		var all strings.Builder
+		for _, patch := range patches {
+			all.WriteString(patch.diff)
+		}
+		return os.WriteFile(output, []byte(all.String()), 0o644)
+	}
+
+	if err := os.MkdirAll(output, 0o755); err != nil {
+		return err
+	}
+	for _, patch := range patches {
+		name := strings.NewReplacer("/", "_", "\\", "_").Replace(filepath.Clean(patch.file)) + ".patch"
+		if err := os.WriteFile(filepath.Join(output, name), []byte(patch.diff), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
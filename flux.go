@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Переменная подстановки Flux: ${var}, ${var:-default}, ${var:=default}; $${var} экранирует подстановку
+var fluxVariable = regexp.MustCompile(`\$?\$\{([_a-zA-Z][_a-zA-Z0-9]*)(:?[-=]([^}]*))?\}`)
+
+// Аннотация, отключающая подстановку для ресурса, как в Flux
+const fluxSubstituteAnnotation = "kustomize.toolkit.fluxcd.io/substitute"
+
+// loadSubstitutions читает переменные из манифестов ConfigMap и Secret, как postBuild.substituteFrom;
+// при совпадении имён побеждает источник, указанный позже
+func loadSubstitutions(paths []string) (map[string]string, error) {
+	vars := map[string]string{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		for {
+			var object struct {
+				Kind       string            `yaml:"kind"`
+				Data       map[string]string `yaml:"data"`
+				StringData map[string]string `yaml:"stringData"`
+			}
+			if err := decoder.Decode(&object); errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("%s: %v", path, err)
+			}
+
+			switch object.Kind {
+			case "ConfigMap":
+				for key, value := range object.Data {
+					vars[key] = value
+				}
+			case "Secret":
+				for key, value := range object.Data {
+					decoded, err := base64.StdEncoding.DecodeString(value)
+					if err != nil {
+						return nil, fmt.Errorf("%s: data.%s: %v", path, key, err)
+					}
+					vars[key] = string(decoded)
+				}
+				for key, value := range object.StringData {
+					vars[key] = value
+				}
+			default:
+				return nil, fmt.Errorf("%s: expected ConfigMap or Secret, got %q", path, object.Kind)
+			}
+		}
+	}
+	return vars, nil
+}
+
+// substituteFlux выполняет подстановку переменных Flux post-build; неизвестные переменные
+// без значения по умолчанию заменяются пустой строкой, как в Flux без strict-режима
+func substituteFlux(data []byte, vars map[string]string) []byte {
+	var document struct {
+		Metadata struct {
+			Annotations map[string]string `yaml:"annotations"`
+		} `yaml:"metadata"`
+	}
+	if yaml.Unmarshal(data, &document) == nil && document.Metadata.Annotations[fluxSubstituteAnnotation] == "disabled" {
+		return data
+	}
+
+	return fluxVariable.ReplaceAllFunc(data, func(match []byte) []byte {
+		if bytes.HasPrefix(match, []byte("$$")) {
+			return match[1:]
+		}
+		groups := fluxVariable.FindSubmatch(match)
+		name, operator, fallback := string(groups[1]), string(groups[2]), string(groups[3])
+		value, set := vars[name]
+		switch {
+		case strings.HasPrefix(operator, ":") && value == "":
+			return []byte(fallback)
+		case operator != "" && !set:
+			return []byte(fallback)
+		}
+		return []byte(value)
+	})
+}
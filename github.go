@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+// writeGitHub печатает находки командами рабочего процесса GitHub Actions
+// (::error file=...,line=...::message), которые Actions показывает прямо в диффе PR
+func writeGitHub(w io.Writer, results []fileResult) {
+	for _, result := range results {
+		file := result.file
+		// Фрагменты file#2 показываем в своём файле
+		if i := strings.IndexByte(file, '#'); i >= 0 {
+			file = file[:i]
+		}
+		for _, finding := range result.findings {
+			command := githubCommand(finding.Severity)
+			// Находки рекомендательных каталогов не должны выглядеть как блокирующие
+			if result.advisory && command == "error" {
+				command = "warning"
+			}
+			properties := []string{"file=" + githubProperty(filepath.ToSlash(file))}
+			if finding.Line > 0 {
+				properties = append(properties, fmt.Sprintf("line=%d", finding.Line))
+				if finding.Column > 0 {
+					properties = append(properties, fmt.Sprintf("col=%d", finding.Column))
+				}
+			}
+			title := finding.RuleID
+			if rule, ok := validator.FindRule(finding.RuleID); ok && rule.Name != "" {
+				title += " " + rule.Name
+			}
+			properties = append(properties, "title="+githubProperty(title))
+			fmt.Fprintf(w, "::%s %s::%s\n", command, strings.Join(properties, ","), githubData(finding.Message))
+		}
+	}
+}
+
+func githubCommand(severity validator.Severity) string {
+	switch severity {
+	case validator.SeverityWarning:
+		return "warning"
+	case validator.SeverityInfo:
+		return "notice"
+	}
+	return "error"
+}
+
+// githubData экранирует текст команды по правилам GitHub Actions
+func githubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// githubProperty экранирует значение свойства: дополнительно двоеточие и запятая
+func githubProperty(s string) string {
+	return strings.NewReplacer(":", "%3A", ",", "%2C").Replace(githubData(s))
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// hasGlobMeta сообщает, содержит ли аргумент шаблон, который нужно раскрыть
+func hasGlobMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[{")
+}
+
+// expandGlob раскрывает шаблон в стиле doublestar: ** совпадает с любым числом каталогов,
+// {a,b} — с любым из вариантов. Раскрытие не зависит от оболочки, поэтому одинаково работает
+// в sh, PowerShell и cmd.
+func expandGlob(pattern string) ([]string, error) {
+	seen := map[string]bool{}
+	var files []string
+	for _, alternative := range expandBraces(filepath.ToSlash(pattern)) {
+		root, rest := splitGlobRoot(alternative)
+		if _, err := os.Stat(root); err != nil {
+			continue
+		}
+		segments := strings.Split(rest, "/")
+		if _, err := path.Match(rest, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+		}
+		err := filepath.WalkDir(root, func(name string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() {
+				return err
+			}
+			relative, err := filepath.Rel(root, name)
+			if err != nil {
+				return err
+			}
+			if matchSegments(segments, strings.Split(filepath.ToSlash(relative), "/")) && !seen[name] {
+				seen[name] = true
+				files = append(files, name)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files match %q", pattern)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// splitGlobRoot отделяет каталог без шаблонных символов, с которого начинается обход
+func splitGlobRoot(pattern string) (root, rest string) {
+	segments := strings.Split(pattern, "/")
+	i := 0
+	for i < len(segments)-1 && !hasGlobMeta(segments[i]) {
+		i++
+	}
+	root = strings.Join(segments[:i], "/")
+	if root == "" && strings.HasPrefix(pattern, "/") {
+		root = "/"
+	} else if root == "" {
+		root = "."
+	}
+	return filepath.FromSlash(root), strings.Join(segments[i:], "/")
+}
+
+func matchSegments(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchSegments(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], name[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], name[1:])
+}
+
+// expandBraces раскрывает {a,b} в отдельные шаблоны, включая вложенные
+func expandBraces(pattern string) []string {
+	start := strings.IndexByte(pattern, '{')
+	if start < 0 {
+		return []string{pattern}
+	}
+	depth := 0
+	for end := start; end < len(pattern); end++ {
+		switch pattern[end] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth > 0 {
+				continue
+			}
+			var expanded []string
+			for _, option := range splitTopLevel(pattern[start+1 : end]) {
+				expanded = append(expanded, expandBraces(pattern[:start]+option+pattern[end+1:])...)
+			}
+			return expanded
+		}
+	}
+	return []string{pattern}
+}
+
+// splitTopLevel делит варианты по запятым, не заходя во вложенные скобки
+func splitTopLevel(options string) []string {
+	var parts []string
+	depth, last := 0, 0
+	for i, c := range options {
+		switch c {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, options[last:i])
+				last = i + 1
+			}
+		}
+	}
+	return append(parts, options[last:])
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+// runGraph печатает граф ссылок между ресурсами набора: рабочие нагрузки → ConfigMap, Secret,
+// PersistentVolumeClaim и Service, которые их выбирают
+func runGraph(args []string) {
+	flagSet := flag.NewFlagSet("yamlvalid graph", flag.ExitOnError)
+	configPath := flagSet.String("config", "", "path to yamlvalid config file (default: .yamlvalid.yaml in the working directory or its parents)")
+	format := flagSet.String("format", "dot", "output format: dot or json")
+	flagSet.Usage = func() {
+		fmt.Println("Usage: yamlvalid graph [flags] <file|directory|glob>...")
+		flagSet.PrintDefaults()
+	}
+	paths := parseInterspersed(flagSet, args)
+
+	if len(paths) == 0 {
+		flagSet.Usage()
+		os.Exit(exitUsage)
+	}
+	if *format != "dot" && *format != "json" {
+		fmt.Printf("Error: --format must be dot or json, got %q\n", *format)
+		os.Exit(exitUsage)
+	}
+	config, err := loadConfig(*configPath)
+	if err != nil {
+		fmt.Printf("Error reading config: %v\n", err)
+		os.Exit(configExitCode(err))
+	}
+
+	// Набор считается полным: ссылки на отсутствующие ресурсы попадают в граф как Missing
+	set := validator.DocumentSet{CheckReferences: true}
+	for _, name := range manifestFiles(paths, config.Scan) {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			fmt.Printf("Error reading file: %v\n", err)
+			os.Exit(exitIO)
+		}
+		documents, _ := extractYAML(data, name, "documents")
+		for _, document := range documents {
+			set.Add(document.data, name)
+		}
+	}
+
+	graph := set.Graph()
+	if *format == "json" {
+		err = writeGraphJSON(os.Stdout, graph)
+	} else {
+		err = writeGraphDot(os.Stdout, graph)
+	}
+	if err != nil {
+		fmt.Printf("Error writing graph: %v\n", err)
+		os.Exit(exitIO)
+	}
+}
+
+// parseInterspersed разбирает флаги и до, и после путей: yamlvalid graph dir/ --format dot
+func parseInterspersed(flagSet *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		flagSet.Parse(args)
+		args = flagSet.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		if args[0] == "--" {
+			return append(positional, args[1:]...)
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// writeGraphDot печатает граф для Graphviz; отсутствующие ресурсы и optional-ссылки — пунктиром
+func writeGraphDot(w io.Writer, graph validator.Graph) error {
+	var b strings.Builder
+	b.WriteString("digraph yamlvalid {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+	for _, node := range graph.Nodes {
+		attributes := fmt.Sprintf("label=%q", fmt.Sprintf("%s\n%s/%s", node.Kind, node.Namespace, node.Name))
+		if node.Missing {
+			attributes += ", style=dashed, color=red"
+		}
+		fmt.Fprintf(&b, "  %q [%s];\n", node.ID(), attributes)
+	}
+	for _, edge := range graph.Edges {
+		attributes := fmt.Sprintf("label=%q", edge.Label)
+		if edge.Optional {
+			attributes += ", style=dashed"
+		}
+		fmt.Fprintf(&b, "  %q -> %q [%s];\n", edge.From, edge.To, attributes)
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+type graphNodeJSON struct {
+	ID        string `json:"id"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	File      string `json:"file,omitempty"`
+	Line      int    `json:"line,omitempty"`
+	Missing   bool   `json:"missing,omitempty"`
+}
+
+type graphEdgeJSON struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Label    string `json:"label"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+func writeGraphJSON(w io.Writer, graph validator.Graph) error {
+	out := struct {
+		Nodes []graphNodeJSON `json:"nodes"`
+		Edges []graphEdgeJSON `json:"edges"`
+	}{Nodes: []graphNodeJSON{}, Edges: []graphEdgeJSON{}}
+	for _, node := range graph.Nodes {
+		out.Nodes = append(out.Nodes, graphNodeJSON{ID: node.ID(), Kind: node.Kind, Namespace: node.Namespace, Name: node.Name, File: node.File, Line: node.Line, Missing: node.Missing})
+	}
+	for _, edge := range graph.Edges {
+		out.Edges = append(out.Edges, graphEdgeJSON{From: edge.From, To: edge.To, Label: edge.Label, Optional: edge.Optional})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
@@ -0,0 +1,1070 @@
+package starlark
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// universe — имена, доступные каждому скрипту
+var universe map[string]Value
+
+func init() {
+	universe = map[string]Value{
+		"None":      None,
+		"True":      Bool(true),
+		"False":     Bool(false),
+		"abs":       NewBuiltin("abs", builtinAbs),
+		"all":       NewBuiltin("all", builtinAll),
+		"any":       NewBuiltin("any", builtinAny),
+		"bool":      NewBuiltin("bool", builtinBool),
+		"dict":      NewBuiltin("dict", builtinDict),
+		"enumerate": NewBuiltin("enumerate", builtinEnumerate),
+		"fail":      NewBuiltin("fail", builtinFail),
+		"float":     NewBuiltin("float", builtinFloat),
+		"getattr":   NewBuiltin("getattr", builtinGetattr),
+		"hasattr":   NewBuiltin("hasattr", builtinHasattr),
+		"int":       NewBuiltin("int", builtinInt),
+		"len":       NewBuiltin("len", builtinLen),
+		"list":      NewBuiltin("list", builtinList),
+		"max":       NewBuiltin("max", builtinMax),
+		"min":       NewBuiltin("min", builtinMin),
+		"print":     NewBuiltin("print", builtinPrint),
+		"range":     NewBuiltin("range", builtinRange),
+		"repr":      NewBuiltin("repr", builtinRepr),
+		"reversed":  NewBuiltin("reversed", builtinReversed),
+		"sorted":    NewBuiltin("sorted", builtinSorted),
+		"str":       NewBuiltin("str", builtinStr),
+		"tuple":     NewBuiltin("tuple", builtinTuple),
+		"type":      NewBuiltin("type", builtinType),
+		"zip":       NewBuiltin("zip", builtinZip),
+	}
+}
+
+// UnpackArgs разбирает аргументы встроенной функции по именам параметров;
+// имя с суффиксом ? — необязательный параметр, его отсутствие даёт nil
+func UnpackArgs(fn string, args Tuple, kwargs map[string]Value, params ...string) ([]Value, error) {
+	values := make([]Value, len(params))
+	if len(args) > len(params) {
+		return nil, fmt.Errorf("%s: got %d arguments, want at most %d", fn, len(args), len(params))
+	}
+	copy(values, args)
+	for name, value := range kwargs {
+		found := false
+		for i, param := range params {
+			if strings.TrimSuffix(param, "?") != name {
+				continue
+			}
+			if values[i] != nil {
+				return nil, fmt.Errorf("%s: got multiple values for parameter %s", fn, name)
+			}
+			values[i] = value
+			found = true
+		}
+		if !found {
+			return nil, fmt.Errorf("%s: unexpected keyword argument %s", fn, name)
+		}
+	}
+	for i, param := range params {
+		if values[i] == nil && !strings.HasSuffix(param, "?") {
+			return nil, fmt.Errorf("%s: missing argument for %s", fn, param)
+		}
+	}
+	return values, nil
+}
+
+func builtinAbs(thread *Thread, args Tuple, kwargs map[string]Value) (Value, error) {
+	values, err := UnpackArgs("abs", args, kwargs, "x")
+	if err != nil {
+		return nil, err
+	}
+	switch x := values[0].(type) {
+	case Int:
+		if x < 0 {
+			return unary("-", x)
+		}
+		return x, nil
+	case Float:
+		return Float(math.Abs(float64(x))), nil
+	}
+	return nil, fmt.Errorf("abs: got %s, want int or float", values[0].Type())
+}
+
+func builtinAll(thread *Thread, args Tuple, kwargs map[string]Value) (Value, error) {
+	values, err := UnpackArgs("all", args, kwargs, "x")
+	if err != nil {
+		return nil, err
+	}
+	result := true
+	err = iterate(values[0], func(item Value) (bool, error) {
+		result = item.Truth()
+		return result, nil
+	})
+	return Bool(result), err
+}
+
+func builtinAny(thread *Thread, args Tuple, kwargs map[string]Value) (Value, error) {
+	values, err := UnpackArgs("any", args, kwargs, "x")
+	if err != nil {
+		return nil, err
+	}
+	result := false
+	err = iterate(values[0], func(item Value) (bool, error) {
+		result = item.Truth()
+		return !result, nil
+	})
+	return Bool(result), err
+}
+
+func builtinBool(thread *Thread, args Tuple, kwargs map[string]Value) (Value, error) {
+	values, err := UnpackArgs("bool", args, kwargs, "x?")
+	if err != nil || values[0] == nil {
+		return Bool(false), err
+	}
+	return Bool(values[0].Truth()), nil
+}
+
+func builtinDict(thread *Thread, args Tuple, kwargs map[string]Value) (Value, error) {
+	if len(args) > 1 {
+		return nil, fmt.Errorf("dict: got %d positional arguments, want at most 1", len(args))
+	}
+	d := NewDict()
+	if len(args) == 1 {
+		if err := dictUpdate(thread, d, args[0]); err != nil {
+			return nil, err
+		}
+	}
+	names := make([]string, 0, len(kwargs))
+	for name := range kwargs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		d.SetKey(String(name), kwargs[name])
+	}
+	return d, nil
+}
+
+// dictUpdate добавляет в словарь пары из другого словаря или из последовательности пар
+func dictUpdate(thread *Thread, d *Dict, source Value) error {
+	if other, ok := source.(*Dict); ok {
+		for i, key := range other.keys {
+			if err := d.SetKey(key, other.values[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return iterate(source, func(item Value) (bool, error) {
+		pair, err := iterableValues(thread, item)
+		if err != nil || len(pair) != 2 {
+			return false, fmt.Errorf("dict: want a sequence of key/value pairs")
+		}
+		return true, d.SetKey(pair[0], pair[1])
+	})
+}
+
+func builtinEnumerate(thread *Thread, args Tuple, kwargs map[string]Value) (Value, error) {
+	values, err := UnpackArgs("enumerate", args, kwargs, "x", "start?")
+	if err != nil {
+		return nil, err
+	}
+	start := Int(0)
+	if values[1] != nil {
+		i, ok := values[1].(Int)
+		if !ok {
+			return nil, fmt.Errorf("enumerate: start must be int")
+		}
+		start = i
+	}
+	items, err := iterableValues(thread, values[0])
+	if err != nil {
+		return nil, err
+	}
+	if err := thread.allocate(int64(2 * len(items))); err != nil {
+		return nil, err
+	}
+	pairs := make([]Value, len(items))
+	for i, item := range items {
+		pairs[i] = Tuple{start + Int(i), item}
+	}
+	return NewList(pairs), nil
+}
+
+func builtinFail(thread *Thread, args Tuple, kwargs map[string]Value) (Value, error) {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = AsString(arg)
+	}
+	return nil, fmt.Errorf("fail: %s", strings.Join(parts, " "))
+}
+
+func builtinFloat(thread *Thread, args Tuple, kwargs map[string]Value) (Value, error) {
+	values, err := UnpackArgs("float", args, kwargs, "x?")
+	if err != nil {
+		return nil, err
+	}
+	switch x := values[0].(type) {
+	case nil:
+		return Float(0), nil
+	case Int:
+		return Float(x), nil
+	case Float:
+		return x, nil
+	case Bool:
+		if x {
+			return Float(1), nil
+		}
+		return Float(0), nil
+	case String:
+		f, err := strconv.ParseFloat(strings.TrimSpace(string(x)), 64)
+		if err != nil {
+			return nil, fmt.Errorf("float: invalid literal %s", x)
+		}
+		return Float(f), nil
+	}
+	return nil, fmt.Errorf("float: got %s, want number or string", values[0].Type())
+}
+
+func builtinGetattr(thread *Thread, args Tuple, kwargs map[string]Value) (Value, error) {
+	values, err := UnpackArgs("getattr", args, kwargs, "x", "name", "default?")
+	if err != nil {
+		return nil, err
+	}
+	name, ok := values[1].(String)
+	if !ok {
+		return nil, fmt.Errorf("getattr: name must be string")
+	}
+	value, err := attribute(values[0], string(name))
+	if err != nil && values[2] != nil {
+		return values[2], nil
+	}
+	return value, err
+}
+
+func builtinHasattr(thread *Thread, args Tuple, kwargs map[string]Value) (Value, error) {
+	values, err := UnpackArgs("hasattr", args, kwargs, "x", "name")
+	if err != nil {
+		return nil, err
+	}
+	name, ok := values[1].(String)
+	if !ok {
+		return nil, fmt.Errorf("hasattr: name must be string")
+	}
+	_, err = attribute(values[0], string(name))
+	return Bool(err == nil), nil
+}
+
+func builtinInt(thread *Thread, args Tuple, kwargs map[string]Value) (Value, error) {
+	values, err := UnpackArgs("int", args, kwargs, "x?", "base?")
+	if err != nil {
+		return nil, err
+	}
+	switch x := values[0].(type) {
+	case nil:
+		return Int(0), nil
+	case Int:
+		return x, nil
+	case Float:
+		f := math.Trunc(float64(x))
+		if math.IsNaN(f) || math.Abs(f) >= 1<<63 {
+			return nil, fmt.Errorf("int: cannot convert %s to int", x)
+		}
+		return Int(f), nil
+	case Bool:
+		if x {
+			return Int(1), nil
+		}
+		return Int(0), nil
+	case String:
+		base := 10
+		if b, ok := values[1].(Int); ok {
+			base = int(b)
+		}
+		i, err := strconv.ParseInt(strings.TrimSpace(string(x)), base, 64)
+		if err != nil {
+			return nil, fmt.Errorf("int: invalid literal %s", x)
+		}
+		return Int(i), nil
+	}
+	return nil, fmt.Errorf("int: got %s, want number or string", values[0].Type())
+}
+
+func builtinLen(thread *Thread, args Tuple, kwargs map[string]Value) (Value, error) {
+	values, err := UnpackArgs("len", args, kwargs, "x")
+	if err != nil {
+		return nil, err
+	}
+	switch x := values[0].(type) {
+	case String:
+		return Int(len(x)), nil
+	case *List:
+		return Int(len(x.elems)), nil
+	case Tuple:
+		return Int(len(x)), nil
+	case *Dict:
+		return Int(len(x.keys)), nil
+	case rangeValue:
+		return Int(x.Len()), nil
+	}
+	return nil, fmt.Errorf("len: value of type %s has no len", values[0].Type())
+}
+
+func builtinList(thread *Thread, args Tuple, kwargs map[string]Value) (Value, error) {
+	values, err := UnpackArgs("list", args, kwargs, "x?")
+	if err != nil || values[0] == nil {
+		return NewList(nil), err
+	}
+	items, err := iterableValues(thread, values[0])
+	if err != nil {
+		return nil, err
+	}
+	return NewList(items), nil
+}
+
+// extremum возвращает наименьший или наибольший элемент; key — необязательная функция ключа
+func extremum(thread *Thread, fn string, args Tuple, kwargs map[string]Value, want int) (Value, error) {
+	key := kwargs["key"]
+	delete(kwargs, "key")
+	if len(kwargs) > 0 {
+		return nil, fmt.Errorf("%s: unexpected keyword arguments", fn)
+	}
+	items := []Value(args)
+	if len(args) == 1 {
+		var err error
+		if items, err = iterableValues(thread, args[0]); err != nil {
+			return nil, err
+		}
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%s: empty sequence", fn)
+	}
+	var best, bestKey Value
+	for _, item := range items {
+		itemKey := item
+		if key != nil && key != None {
+			var err error
+			if itemKey, err = call(thread, key, []Value{item}, nil); err != nil {
+				return nil, err
+			}
+		}
+		if best != nil {
+			cmp, err := compare(itemKey, bestKey)
+			if err != nil {
+				return nil, err
+			}
+			if cmp != want {
+				continue
+			}
+		}
+		best, bestKey = item, itemKey
+	}
+	return best, nil
+}
+
+func builtinMax(thread *Thread, args Tuple, kwargs map[string]Value) (Value, error) {
+	return extremum(thread, "max", args, kwargs, 1)
+}
+
+func builtinMin(thread *Thread, args Tuple, kwargs map[string]Value) (Value, error) {
+	return extremum(thread, "min", args, kwargs, -1)
+}
+
+func builtinPrint(thread *Thread, args Tuple, kwargs map[string]Value) (Value, error) {
+	if thread.Print == nil {
+		return None, nil
+	}
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = AsString(arg)
+	}
+	thread.Print(strings.Join(parts, " "))
+	return None, nil
+}
+
+func builtinRange(thread *Thread, args Tuple, kwargs map[string]Value) (Value, error) {
+	if len(kwargs) > 0 || len(args) == 0 || len(args) > 3 {
+		return nil, fmt.Errorf("range: want 1 to 3 positional arguments")
+	}
+	var bounds []int64
+	for _, arg := range args {
+		i, ok := arg.(Int)
+		if !ok {
+			return nil, fmt.Errorf("range: got %s, want int", arg.Type())
+		}
+		bounds = append(bounds, int64(i))
+	}
+	r := rangeValue{stop: bounds[0], step: 1}
+	if len(bounds) > 1 {
+		r.start, r.stop = bounds[0], bounds[1]
+	}
+	if len(bounds) > 2 {
+		if r.step = bounds[2]; r.step == 0 {
+			return nil, fmt.Errorf("range: step argument must not be zero")
+		}
+	}
+	return r, nil
+}
+
+func builtinRepr(thread *Thread, args Tuple, kwargs map[string]Value) (Value, error) {
+	values, err := UnpackArgs("repr", args, kwargs, "x")
+	if err != nil {
+		return nil, err
+	}
+	return String(values[0].String()), nil
+}
+
+func builtinReversed(thread *Thread, args Tuple, kwargs map[string]Value) (Value, error) {
+	values, err := UnpackArgs("reversed", args, kwargs, "x")
+	if err != nil {
+		return nil, err
+	}
+	items, err := iterableValues(thread, values[0])
+	if err != nil {
+		return nil, err
+	}
+	if err := thread.allocate(int64(len(items))); err != nil {
+		return nil, err
+	}
+	reversed := make([]Value, len(items))
+	for i, item := range items {
+		reversed[len(items)-1-i] = item
+	}
+	return NewList(reversed), nil
+}
+
+func builtinSorted(thread *Thread, args Tuple, kwargs map[string]Value) (Value, error) {
+	values, err := UnpackArgs("sorted", args, kwargs, "x", "key?", "reverse?")
+	if err != nil {
+		return nil, err
+	}
+	items, err := iterableValues(thread, values[0])
+	if err != nil {
+		return nil, err
+	}
+	if err := thread.step(int64(len(items))); err != nil {
+		return nil, err
+	}
+	keys := items
+	if key := values[1]; key != nil && key != None {
+		keys = make([]Value, len(items))
+		for i, item := range items {
+			if keys[i], err = call(thread, key, []Value{item}, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	reverse := values[2] != nil && values[2].Truth()
+	var sortErr error
+	sort.SliceStable(order, func(i, j int) bool {
+		cmp, err := compare(keys[order[i]], keys[order[j]])
+		if err != nil && sortErr == nil {
+			sortErr = err
+		}
+		if reverse {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+	if sortErr != nil {
+		return nil, sortErr
+	}
+	sorted := make([]Value, len(items))
+	for i, j := range order {
+		sorted[i] = items[j]
+	}
+	return NewList(sorted), nil
+}
+
+func builtinStr(thread *Thread, args Tuple, kwargs map[string]Value) (Value, error) {
+	values, err := UnpackArgs("str", args, kwargs, "x?")
+	if err != nil || values[0] == nil {
+		return String(""), err
+	}
+	return String(AsString(values[0])), nil
+}
+
+func builtinTuple(thread *Thread, args Tuple, kwargs map[string]Value) (Value, error) {
+	values, err := UnpackArgs("tuple", args, kwargs, "x?")
+	if err != nil || values[0] == nil {
+		return Tuple{}, err
+	}
+	items, err := iterableValues(thread, values[0])
+	return Tuple(items), err
+}
+
+func builtinType(thread *Thread, args Tuple, kwargs map[string]Value) (Value, error) {
+	values, err := UnpackArgs("type", args, kwargs, "x")
+	if err != nil {
+		return nil, err
+	}
+	return String(values[0].Type()), nil
+}
+
+func builtinZip(thread *Thread, args Tuple, kwargs map[string]Value) (Value, error) {
+	if len(kwargs) > 0 {
+		return nil, fmt.Errorf("zip: unexpected keyword arguments")
+	}
+	var columns [][]Value
+	shortest := -1
+	for _, arg := range args {
+		items, err := iterableValues(thread, arg)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, items)
+		if shortest < 0 || len(items) < shortest {
+			shortest = len(items)
+		}
+	}
+	if err := thread.allocate(int64(shortest * (len(columns) + 1))); err != nil {
+		return nil, err
+	}
+	rows := make([]Value, 0, shortest)
+	for i := 0; i < shortest; i++ {
+		row := make(Tuple, len(columns))
+		for j := range columns {
+			row[j] = columns[j][i]
+		}
+		rows = append(rows, row)
+	}
+	return NewList(rows), nil
+}
+
+// attribute возвращает метод значения, привязанный к нему
+func attribute(x Value, name string) (Value, error) {
+	var methods map[string]func(thread *Thread, recv Value, args Tuple, kwargs map[string]Value) (Value, error)
+	switch x.(type) {
+	case String:
+		methods = stringMethods
+	case *List:
+		methods = listMethods
+	case *Dict:
+		methods = dictMethods
+	}
+	method, ok := methods[name]
+	if !ok {
+		return nil, fmt.Errorf("%s has no .%s field or method", x.Type(), name)
+	}
+	return &Builtin{name: name, recv: x, fn: func(thread *Thread, args Tuple, kwargs map[string]Value) (Value, error) {
+		return method(thread, x, args, kwargs)
+	}}, nil
+}
+
+var stringMethods = map[string]func(thread *Thread, recv Value, args Tuple, kwargs map[string]Value) (Value, error){
+	"capitalize": stringTransform(func(s string) string {
+		if s == "" {
+			return s
+		}
+		return strings.ToUpper(s[:1]) + strings.ToLower(s[1:])
+	}),
+	"count": func(thread *Thread, recv Value, args Tuple, kwargs map[string]Value) (Value, error) {
+		values, err := UnpackArgs("count", args, kwargs, "sub")
+		if err != nil {
+			return nil, err
+		}
+		sub, err := stringArg("count", values[0])
+		return Int(strings.Count(string(recv.(String)), sub)), err
+	},
+	"elems": func(thread *Thread, recv Value, args Tuple, kwargs map[string]Value) (Value, error) {
+		s := string(recv.(String))
+		if err := thread.allocate(int64(utf8.RuneCountInString(s))); err != nil {
+			return nil, err
+		}
+		elems := make([]Value, 0, len(s))
+		for _, r := range s {
+			elems = append(elems, String(string(r)))
+		}
+		return NewList(elems), nil
+	},
+	"endswith": stringAffix("endswith", strings.HasSuffix),
+	"find": func(thread *Thread, recv Value, args Tuple, kwargs map[string]Value) (Value, error) {
+		values, err := UnpackArgs("find", args, kwargs, "sub")
+		if err != nil {
+			return nil, err
+		}
+		sub, err := stringArg("find", values[0])
+		return Int(strings.Index(string(recv.(String)), sub)), err
+	},
+	"format": func(thread *Thread, recv Value, args Tuple, kwargs map[string]Value) (Value, error) {
+		return format(string(recv.(String)), args, kwargs)
+	},
+	"isalnum": stringPredicate(func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }),
+	"isalpha": stringPredicate(unicode.IsLetter),
+	"isdigit": stringPredicate(unicode.IsDigit),
+	"islower": func(thread *Thread, recv Value, args Tuple, kwargs map[string]Value) (Value, error) {
+		s := string(recv.(String))
+		return Bool(s != strings.ToUpper(s) && s == strings.ToLower(s)), nil
+	},
+	"isupper": func(thread *Thread, recv Value, args Tuple, kwargs map[string]Value) (Value, error) {
+		s := string(recv.(String))
+		return Bool(s != strings.ToLower(s) && s == strings.ToUpper(s)), nil
+	},
+	"join": func(thread *Thread, recv Value, args Tuple, kwargs map[string]Value) (Value, error) {
+		values, err := UnpackArgs("join", args, kwargs, "iterable")
+		if err != nil {
+			return nil, err
+		}
+		items, err := iterableValues(thread, values[0])
+		if err != nil {
+			return nil, err
+		}
+		parts := make([]string, len(items))
+		size := 0
+		for i, item := range items {
+			s, ok := item.(String)
+			if !ok {
+				return nil, fmt.Errorf("join: in list, want string, got %s", item.Type())
+			}
+			parts[i] = string(s)
+			size += len(s)
+		}
+		if err := thread.allocate(int64(size + len(recv.(String))*len(parts))); err != nil {
+			return nil, err
+		}
+		return String(strings.Join(parts, string(recv.(String)))), nil
+	},
+	"lower": stringTransform(strings.ToLower),
+	"lstrip": stringTrim("lstrip", strings.TrimLeft, func(s string) string {
+		return strings.TrimLeftFunc(s, unicode.IsSpace)
+	}),
+	"partition": func(thread *Thread, recv Value, args Tuple, kwargs map[string]Value) (Value, error) {
+		values, err := UnpackArgs("partition", args, kwargs, "sep")
+		if err != nil {
+			return nil, err
+		}
+		sep, err := stringArg("partition", values[0])
+		if err != nil {
+			return nil, err
+		}
+		before, after, found := strings.Cut(string(recv.(String)), sep)
+		if !found {
+			return Tuple{recv, String(""), String("")}, nil
+		}
+		return Tuple{String(before), String(sep), String(after)}, nil
+	},
+	"removeprefix": stringAffixTrim("removeprefix", strings.TrimPrefix),
+	"removesuffix": stringAffixTrim("removesuffix", strings.TrimSuffix),
+	"replace": func(thread *Thread, recv Value, args Tuple, kwargs map[string]Value) (Value, error) {
+		values, err := UnpackArgs("replace", args, kwargs, "old", "new", "count?")
+		if err != nil {
+			return nil, err
+		}
+		old, err := stringArg("replace", values[0])
+		if err != nil {
+			return nil, err
+		}
+		replacement, err := stringArg("replace", values[1])
+		if err != nil {
+			return nil, err
+		}
+		count := -1
+		if n, ok := values[2].(Int); ok {
+			count = int(n)
+		}
+		s := string(recv.(String))
+		if err := thread.allocate(int64(len(s) + len(replacement)*strings.Count(s, old))); err != nil {
+			return nil, err
+		}
+		return String(strings.Replace(s, old, replacement, count)), nil
+	},
+	"rstrip": stringTrim("rstrip", strings.TrimRight, func(s string) string {
+		return strings.TrimRightFunc(s, unicode.IsSpace)
+	}),
+	"split": func(thread *Thread, recv Value, args Tuple, kwargs map[string]Value) (Value, error) {
+		values, err := UnpackArgs("split", args, kwargs, "sep?", "maxsplit?")
+		if err != nil {
+			return nil, err
+		}
+		s := string(recv.(String))
+		limit := -1
+		if n, ok := values[1].(Int); ok && n >= 0 {
+			limit = int(n) + 1
+		}
+		var parts []string
+		if values[0] == nil || values[0] == None {
+			parts = strings.Fields(s)
+			if limit > 0 && len(parts) > limit {
+				parts = append(parts[:limit-1], strings.Join(parts[limit-1:], " "))
+			}
+		} else {
+			sep, err := stringArg("split", values[0])
+			if err != nil {
+				return nil, err
+			}
+			if sep == "" {
+				return nil, fmt.Errorf("split: empty separator")
+			}
+			parts = strings.SplitN(s, sep, limit)
+		}
+		if err := thread.allocate(int64(len(parts))); err != nil {
+			return nil, err
+		}
+		items := make([]Value, len(parts))
+		for i, part := range parts {
+			items[i] = String(part)
+		}
+		return NewList(items), nil
+	},
+	"startswith": stringAffix("startswith", strings.HasPrefix),
+	"strip":      stringTrim("strip", strings.Trim, strings.TrimSpace),
+	"title": stringTransform(func(s string) string {
+		// Заглавной становится буква, перед которой нет другой буквы
+		afterLetter := false
+		return strings.Map(func(r rune) rune {
+			mapped := unicode.ToLower(r)
+			if !afterLetter {
+				mapped = unicode.ToUpper(r)
+			}
+			afterLetter = unicode.IsLetter(r)
+			return mapped
+		}, s)
+	}),
+	"upper": stringTransform(strings.ToUpper),
+}
+
+func stringArg(fn string, v Value) (string, error) {
+	s, ok := v.(String)
+	if !ok {
+		return "", fmt.Errorf("%s: got %s, want string", fn, v.Type())
+	}
+	return string(s), nil
+}
+
+func stringTransform(transform func(string) string) func(*Thread, Value, Tuple, map[string]Value) (Value, error) {
+	return func(thread *Thread, recv Value, args Tuple, kwargs map[string]Value) (Value, error) {
+		if len(args) > 0 || len(kwargs) > 0 {
+			return nil, fmt.Errorf("string method takes no arguments")
+		}
+		return String(transform(string(recv.(String)))), nil
+	}
+}
+
+func stringPredicate(predicate func(rune) bool) func(*Thread, Value, Tuple, map[string]Value) (Value, error) {
+	return func(thread *Thread, recv Value, args Tuple, kwargs map[string]Value) (Value, error) {
+		s := string(recv.(String))
+		if s == "" {
+			return Bool(false), nil
+		}
+		for _, r := range s {
+			if !predicate(r) {
+				return Bool(false), nil
+			}
+		}
+		return Bool(true), nil
+	}
+}
+
+// stringAffix реализует startswith и endswith; аргумент — строка или кортеж строк
+func stringAffix(fn string, test func(s, affix string) bool) func(*Thread, Value, Tuple, map[string]Value) (Value, error) {
+	return func(thread *Thread, recv Value, args Tuple, kwargs map[string]Value) (Value, error) {
+		values, err := UnpackArgs(fn, args, kwargs, "x")
+		if err != nil {
+			return nil, err
+		}
+		candidates := []Value{values[0]}
+		if tuple, ok := values[0].(Tuple); ok {
+			candidates = tuple
+		}
+		for _, candidate := range candidates {
+			affix, err := stringArg(fn, candidate)
+			if err != nil {
+				return nil, err
+			}
+			if test(string(recv.(String)), affix) {
+				return Bool(true), nil
+			}
+		}
+		return Bool(false), nil
+	}
+}
+
+func stringAffixTrim(fn string, trim func(s, affix string) string) func(*Thread, Value, Tuple, map[string]Value) (Value, error) {
+	return func(thread *Thread, recv Value, args Tuple, kwargs map[string]Value) (Value, error) {
+		values, err := UnpackArgs(fn, args, kwargs, "x")
+		if err != nil {
+			return nil, err
+		}
+		affix, err := stringArg(fn, values[0])
+		return String(trim(string(recv.(String)), affix)), err
+	}
+}
+
+// stringTrim реализует strip, lstrip и rstrip: без аргумента срезаются пробельные символы
+func stringTrim(fn string, trim func(s, cutset string) string, trimSpace func(string) string) func(*Thread, Value, Tuple, map[string]Value) (Value, error) {
+	return func(thread *Thread, recv Value, args Tuple, kwargs map[string]Value) (Value, error) {
+		values, err := UnpackArgs(fn, args, kwargs, "chars?")
+		if err != nil {
+			return nil, err
+		}
+		s := string(recv.(String))
+		if values[0] == nil || values[0] == None {
+			return String(trimSpace(s)), nil
+		}
+		cutset, err := stringArg(fn, values[0])
+		return String(trim(s, cutset)), err
+	}
+}
+
+// format реализует str.format с полями {}, {0} и {name}
+func format(template string, args Tuple, kwargs map[string]Value) (Value, error) {
+	var b strings.Builder
+	auto := 0
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+		switch {
+		case c == '{' && i+1 < len(template) && template[i+1] == '{':
+			b.WriteByte('{')
+			i++
+		case c == '}' && i+1 < len(template) && template[i+1] == '}':
+			b.WriteByte('}')
+			i++
+		case c == '{':
+			end := strings.IndexByte(template[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("format: unmatched '{'")
+			}
+			field := template[i+1 : i+end]
+			i += end
+			var value Value
+			switch n, err := strconv.Atoi(field); {
+			case field == "":
+				if auto >= len(args) {
+					return nil, fmt.Errorf("format: not enough arguments")
+				}
+				value = args[auto]
+				auto++
+			case err == nil:
+				if n < 0 || n >= len(args) {
+					return nil, fmt.Errorf("format: index %d out of range", n)
+				}
+				value = args[n]
+			default:
+				var ok bool
+				if value, ok = kwargs[field]; !ok {
+					return nil, fmt.Errorf("format: keyword %s not found", field)
+				}
+			}
+			b.WriteString(AsString(value))
+		case c == '}':
+			return nil, fmt.Errorf("format: single '}' in format")
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return String(b.String()), nil
+}
+
+var listMethods = map[string]func(thread *Thread, recv Value, args Tuple, kwargs map[string]Value) (Value, error){
+	"append": func(thread *Thread, recv Value, args Tuple, kwargs map[string]Value) (Value, error) {
+		values, err := UnpackArgs("append", args, kwargs, "x")
+		if err != nil {
+			return nil, err
+		}
+		list := recv.(*List)
+		if err := list.checkMutable(); err != nil {
+			return nil, err
+		}
+		if err := thread.allocate(1); err != nil {
+			return nil, err
+		}
+		list.elems = append(list.elems, values[0])
+		return None, nil
+	},
+	"clear": func(thread *Thread, recv Value, args Tuple, kwargs map[string]Value) (Value, error) {
+		list := recv.(*List)
+		if err := list.checkMutable(); err != nil {
+			return nil, err
+		}
+		list.elems = nil
+		return None, nil
+	},
+	"extend": func(thread *Thread, recv Value, args Tuple, kwargs map[string]Value) (Value, error) {
+		values, err := UnpackArgs("extend", args, kwargs, "x")
+		if err != nil {
+			return nil, err
+		}
+		list := recv.(*List)
+		if err := list.checkMutable(); err != nil {
+			return nil, err
+		}
+		items, err := iterableValues(thread, values[0])
+		if err != nil {
+			return nil, err
+		}
+		list.elems = append(list.elems, items...)
+		return None, nil
+	},
+	"index": func(thread *Thread, recv Value, args Tuple, kwargs map[string]Value) (Value, error) {
+		values, err := UnpackArgs("index", args, kwargs, "x")
+		if err != nil {
+			return nil, err
+		}
+		for i, item := range recv.(*List).elems {
+			if eq, err := equal(item, values[0]); err != nil || eq {
+				return Int(i), err
+			}
+		}
+		return nil, fmt.Errorf("index: value not in list")
+	},
+	"insert": func(thread *Thread, recv Value, args Tuple, kwargs map[string]Value) (Value, error) {
+		values, err := UnpackArgs("insert", args, kwargs, "index", "x")
+		if err != nil {
+			return nil, err
+		}
+		list := recv.(*List)
+		if err := list.checkMutable(); err != nil {
+			return nil, err
+		}
+		i, ok := values[0].(Int)
+		if !ok {
+			return nil, fmt.Errorf("insert: index must be int")
+		}
+		n := Int(len(list.elems))
+		if i < 0 {
+			i += n
+		}
+		if err := thread.allocate(1); err != nil {
+			return nil, err
+		}
+		i = Int(math.Max(0, math.Min(float64(i), float64(n))))
+		list.elems = append(list.elems[:i:i], append([]Value{values[1]}, list.elems[i:]...)...)
+		return None, nil
+	},
+	"pop": func(thread *Thread, recv Value, args Tuple, kwargs map[string]Value) (Value, error) {
+		values, err := UnpackArgs("pop", args, kwargs, "index?")
+		if err != nil {
+			return nil, err
+		}
+		list := recv.(*List)
+		if err := list.checkMutable(); err != nil {
+			return nil, err
+		}
+		index := Value(Int(-1))
+		if values[0] != nil {
+			index = values[0]
+		}
+		i, err := sequenceIndex(index, len(list.elems))
+		if err != nil {
+			return nil, fmt.Errorf("pop: %v", err)
+		}
+		item := list.elems[i]
+		list.elems = append(list.elems[:i:i], list.elems[i+1:]...)
+		return item, nil
+	},
+	"remove": func(thread *Thread, recv Value, args Tuple, kwargs map[string]Value) (Value, error) {
+		values, err := UnpackArgs("remove", args, kwargs, "x")
+		if err != nil {
+			return nil, err
+		}
+		list := recv.(*List)
+		if err := list.checkMutable(); err != nil {
+			return nil, err
+		}
+		for i, item := range list.elems {
+			if eq, err := equal(item, values[0]); err != nil {
+				return nil, err
+			} else if eq {
+				list.elems = append(list.elems[:i:i], list.elems[i+1:]...)
+				return None, nil
+			}
+		}
+		return nil, fmt.Errorf("remove: element not found")
+	},
+}
+
+var dictMethods = map[string]func(thread *Thread, recv Value, args Tuple, kwargs map[string]Value) (Value, error){
+	"clear": func(thread *Thread, recv Value, args Tuple, kwargs map[string]Value) (Value, error) {
+		d := recv.(*Dict)
+		if err := d.checkMutable(); err != nil {
+			return nil, err
+		}
+		d.keys, d.values, d.index = nil, nil, map[string]int{}
+		return None, nil
+	},
+	"get": func(thread *Thread, recv Value, args Tuple, kwargs map[string]Value) (Value, error) {
+		values, err := UnpackArgs("get", args, kwargs, "key", "default?")
+		if err != nil {
+			return nil, err
+		}
+		value, found, err := recv.(*Dict).Get(values[0])
+		if err != nil || found {
+			return value, err
+		}
+		if values[1] != nil {
+			return values[1], nil
+		}
+		return None, nil
+	},
+	"items": func(thread *Thread, recv Value, args Tuple, kwargs map[string]Value) (Value, error) {
+		d := recv.(*Dict)
+		if err := thread.allocate(int64(3 * len(d.keys))); err != nil {
+			return nil, err
+		}
+		items := make([]Value, len(d.keys))
+		for i, key := range d.keys {
+			items[i] = Tuple{key, d.values[i]}
+		}
+		return NewList(items), nil
+	},
+	"keys": func(thread *Thread, recv Value, args Tuple, kwargs map[string]Value) (Value, error) {
+		d := recv.(*Dict)
+		if err := thread.allocate(int64(len(d.keys))); err != nil {
+			return nil, err
+		}
+		return NewList(append([]Value(nil), d.keys...)), nil
+	},
+	"pop": func(thread *Thread, recv Value, args Tuple, kwargs map[string]Value) (Value, error) {
+		values, err := UnpackArgs("pop", args, kwargs, "key", "default?")
+		if err != nil {
+			return nil, err
+		}
+		value, found, err := recv.(*Dict).delete(values[0])
+		switch {
+		case err != nil:
+			return nil, err
+		case found:
+			return value, nil
+		case values[1] != nil:
+			return values[1], nil
+		}
+		return nil, fmt.Errorf("pop: missing key %s", values[0])
+	},
+	"setdefault": func(thread *Thread, recv Value, args Tuple, kwargs map[string]Value) (Value, error) {
+		values, err := UnpackArgs("setdefault", args, kwargs, "key", "default?")
+		if err != nil {
+			return nil, err
+		}
+		d := recv.(*Dict)
+		value, found, err := d.Get(values[0])
+		if err != nil || found {
+			return value, err
+		}
+		if values[1] == nil {
+			values[1] = None
+		}
+		return values[1], d.SetKey(values[0], values[1])
+	},
+	"update": func(thread *Thread, recv Value, args Tuple, kwargs map[string]Value) (Value, error) {
+		update, err := builtinDict(thread, args, kwargs)
+		if err != nil {
+			return nil, err
+		}
+		return None, dictUpdate(thread, recv.(*Dict), update)
+	},
+	"values": func(thread *Thread, recv Value, args Tuple, kwargs map[string]Value) (Value, error) {
+		d := recv.(*Dict)
+		if err := thread.allocate(int64(len(d.values))); err != nil {
+			return nil, err
+		}
+		return NewList(append([]Value(nil), d.values...)), nil
+	},
+}
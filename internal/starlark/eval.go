@@ -0,0 +1,849 @@
+// Package starlark — интерпретатор подмножества Starlark для пользовательских правил.
+// Поддерживаются def, lambda, if, for, comprehension, списки, кортежи, словари и
+// основные встроенные функции; load, while и рекурсия запрещены, как и в самом Starlark.
+// В отличие от Starlark, нет операторов ** и << (и остальных побитовых) и множеств
+// (set(...), {1, 2}); выражение с ними — ошибка разбора, которая называет конструкцию.
+// Цепочки сравнений вида a < b < c запрещены, как и в самом Starlark.
+// Скрипт не имеет доступа к файлам, сети и часам, а Limits ограничивают число шагов,
+// размер создаваемых значений и время вызова, так что правило не может подвесить
+// или исчерпать процесс.
+package starlark
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Error — ошибка разбора или выполнения скрипта с позицией в исходном тексте
+type Error struct {
+	Filename string
+	Pos      Pos
+	Msg      string
+	// cause — исходная ошибка выполнения, например превышение пределов
+	cause error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s", e.Filename, e.Pos.Line, e.Pos.Col, e.Msg)
+}
+
+func (e *Error) Unwrap() error { return e.cause }
+
+// ErrLimit сообщает, что скрипт исчерпал шаги или время; его можно найти через errors.Is
+var ErrLimit = errors.New("script exceeded its execution limit")
+
+// limitError — превышение пределов; errors.Is(err, ErrLimit) для неё истинно
+type limitError struct{ msg string }
+
+func (e *limitError) Error() string { return e.msg }
+func (e *limitError) Is(target error) bool {
+	return target == ErrLimit
+}
+
+// Limits ограничивают выполнение скрипта, чтобы правило не могло зависнуть или съесть процесс:
+// это важно в режиме сервера, где один процесс проверяет документы часами
+type Limits struct {
+	// MaxSteps — предел шагов: операторов, итераций циклов, вызовов и элементов созданных
+	// строк и списков; 0 — без предела
+	MaxSteps int64
+	// Timeout — предел времени одного вызова; 0 — без предела
+	Timeout time.Duration
+	// MaxAllocs — предел элементов списков, кортежей и словарей и байтов строк, созданных
+	// за один вызов; проверяется до выделения памяти. 0 — без предела
+	MaxAllocs int64
+}
+
+// Thread — состояние одного выполнения. Значения после Exec заморожены, поэтому
+// разные потоки могут одновременно вызывать функции одной программы.
+type Thread struct {
+	Limits Limits
+	// Local — данные вызывающего кода, доступные встроенным функциям через thread.Local
+	Local interface{}
+	// Print получает вывод print(); nil — вывод отбрасывается
+	Print func(msg string)
+
+	steps    int64
+	allocs   int64
+	deadline time.Time
+	// clockAt — шаг, на котором часы опрашивались последний раз
+	clockAt int64
+	// Вызываемые функции: Starlark запрещает рекурсию, и стек не может расти бесконечно
+	stack []*Function
+}
+
+// step учитывает n шагов и проверяет пределы
+func (t *Thread) step(n int64) error {
+	t.steps += n
+	if t.Limits.MaxSteps > 0 && t.steps > t.Limits.MaxSteps {
+		return &limitError{fmt.Sprintf("script exceeded the limit of %d steps", t.Limits.MaxSteps)}
+	}
+	// Часы опрашиваются не на каждом шаге: это заметно дороже самого шага
+	if !t.deadline.IsZero() && t.steps-t.clockAt >= 256 {
+		t.clockAt = t.steps
+		if time.Now().After(t.deadline) {
+			return &limitError{fmt.Sprintf("script exceeded the time limit of %s", t.Limits.Timeout)}
+		}
+	}
+	return nil
+}
+
+// allocate учитывает n создаваемых элементов или байтов: каждый стоит шаг, а их сумма
+// ограничена MaxAllocs. Вызывается до выделения памяти, чтобы предел срабатывал раньше,
+// чем процесс её исчерпает.
+func (t *Thread) allocate(n int64) error {
+	if err := t.step(n); err != nil {
+		return err
+	}
+	t.allocs += n
+	if t.Limits.MaxAllocs > 0 && t.allocs > t.Limits.MaxAllocs {
+		return &limitError{fmt.Sprintf("script exceeded the limit of %d allocated elements", t.Limits.MaxAllocs)}
+	}
+	return nil
+}
+
+// begin сбрасывает счётчики перед вызовом извне
+func (t *Thread) begin() {
+	t.steps, t.allocs, t.clockAt = 0, 0, 0
+	t.deadline = time.Time{}
+	if t.Limits.Timeout > 0 {
+		t.deadline = time.Now().Add(t.Limits.Timeout)
+	}
+}
+
+// Program — разобранный скрипт
+type Program struct {
+	filename string
+	body     []stmt
+}
+
+// Compile разбирает исходный текст скрипта
+func Compile(filename, src string) (*Program, error) {
+	body, err := parse(filename, src)
+	if err != nil {
+		return nil, err
+	}
+	return &Program{filename: filename, body: body}, nil
+}
+
+// module — глобальные имена выполненной программы и предопределённые имена вызывающего кода
+type module struct {
+	filename    string
+	globals     map[string]Value
+	predeclared map[string]Value
+}
+
+// Exec выполняет верхний уровень программы и возвращает её глобальные имена.
+// Они замораживаются: функции программы можно вызывать из разных потоков.
+func (p *Program) Exec(thread *Thread, predeclared map[string]Value) (map[string]Value, error) {
+	mod := &module{filename: p.filename, globals: map[string]Value{}, predeclared: predeclared}
+	fr := &frame{module: mod, locals: mod.globals}
+	thread.begin()
+	if _, _, err := fr.execBlock(thread, p.body); err != nil {
+		return nil, err
+	}
+	for _, value := range mod.globals {
+		freeze(value)
+	}
+	return mod.globals, nil
+}
+
+// Call вызывает функцию с позиционными аргументами
+func Call(thread *Thread, fn Value, args ...Value) (Value, error) {
+	thread.begin()
+	return call(thread, fn, args, nil)
+}
+
+// Function — функция, объявленная через def или lambda
+type Function struct {
+	name     string
+	params   []param
+	defaults []Value
+	body     []stmt
+	// result — тело lambda; у def оно nil
+	result expr
+	locals map[string]bool
+	// closure — кадр, в котором функция объявлена
+	closure *frame
+}
+
+func (f *Function) Type() string   { return "function" }
+func (f *Function) String() string { return fmt.Sprintf("<function %s>", f.name) }
+func (f *Function) Truth() bool    { return true }
+
+// Name возвращает имя функции
+func (f *Function) Name() string { return f.name }
+
+// Builtin — функция, реализованная на Go
+type Builtin struct {
+	name string
+	fn   func(thread *Thread, args Tuple, kwargs map[string]Value) (Value, error)
+	// recv — значение, к которому привязан метод, например список для append
+	recv Value
+}
+
+// NewBuiltin создаёт встроенную функцию
+func NewBuiltin(name string, fn func(thread *Thread, args Tuple, kwargs map[string]Value) (Value, error)) *Builtin {
+	return &Builtin{name: name, fn: fn}
+}
+
+func (b *Builtin) Type() string { return "builtin_function_or_method" }
+func (b *Builtin) Truth() bool  { return true }
+
+func (b *Builtin) String() string {
+	if b.recv != nil {
+		return fmt.Sprintf("<built-in method %s of %s value>", b.name, b.recv.Type())
+	}
+	return fmt.Sprintf("<built-in function %s>", b.name)
+}
+
+// frame — область имён: верхний уровень модуля, вызов функции или comprehension
+type frame struct {
+	module *module
+	locals map[string]Value
+	// localNames — имена, которые присваиваются в этой области; nil у верхнего уровня
+	localNames map[string]bool
+	parent     *frame
+}
+
+func (fr *frame) lookup(name string) (Value, error) {
+	for f := fr; f != nil; f = f.parent {
+		if value, ok := f.locals[name]; ok {
+			return value, nil
+		}
+		if f.localNames[name] {
+			return nil, fmt.Errorf("local variable %s referenced before assignment", name)
+		}
+	}
+	if value, ok := fr.module.predeclared[name]; ok {
+		return value, nil
+	}
+	if value, ok := universe[name]; ok {
+		return value, nil
+	}
+	return nil, fmt.Errorf("undefined: %s", name)
+}
+
+// errorAt добавляет к ошибке позицию, если у неё позиции ещё нет
+func (fr *frame) errorAt(pos Pos, err error) error {
+	var located *Error
+	if err == nil || errors.As(err, &located) {
+		return err
+	}
+	return &Error{Filename: fr.module.filename, Pos: pos, Msg: err.Error(), cause: err}
+}
+
+type control int
+
+const (
+	controlNone control = iota
+	controlBreak
+	controlContinue
+	controlReturn
+)
+
+func (fr *frame) execBlock(thread *Thread, body []stmt) (control, Value, error) {
+	for _, s := range body {
+		ctl, value, err := fr.exec(thread, s)
+		if err != nil || ctl != controlNone {
+			return ctl, value, err
+		}
+	}
+	return controlNone, nil, nil
+}
+
+func (fr *frame) exec(thread *Thread, s stmt) (control, Value, error) {
+	if err := thread.step(1); err != nil {
+		return controlNone, nil, fr.errorAt(s.position(), err)
+	}
+	switch s := s.(type) {
+	case *exprStmt:
+		_, err := fr.eval(thread, s.x)
+		return controlNone, nil, err
+	case *assignStmt:
+		return controlNone, nil, fr.assignStatement(thread, s)
+	case *defStmt:
+		fn, err := fr.function(thread, s.name, s.params, s.body, nil)
+		if err != nil {
+			return controlNone, nil, err
+		}
+		fr.locals[s.name] = fn
+		return controlNone, nil, nil
+	case *ifStmt:
+		cond, err := fr.eval(thread, s.cond)
+		if err != nil {
+			return controlNone, nil, err
+		}
+		if cond.Truth() {
+			return fr.execBlock(thread, s.then)
+		}
+		return fr.execBlock(thread, s.otherwise)
+	case *forStmt:
+		return fr.forStatement(thread, s)
+	case *returnStmt:
+		if s.value == nil {
+			return controlReturn, None, nil
+		}
+		value, err := fr.eval(thread, s.value)
+		return controlReturn, value, err
+	case *branchStmt:
+		switch s.keyword {
+		case "break":
+			return controlBreak, nil, nil
+		case "continue":
+			return controlContinue, nil, nil
+		}
+		return controlNone, nil, nil
+	}
+	return controlNone, nil, fr.errorAt(s.position(), fmt.Errorf("unsupported statement"))
+}
+
+func (fr *frame) assignStatement(thread *Thread, s *assignStmt) error {
+	value, err := fr.eval(thread, s.value)
+	if err != nil {
+		return err
+	}
+	if s.op != "=" {
+		current, err := fr.eval(thread, s.target)
+		if err != nil {
+			return err
+		}
+		op := s.op[:len(s.op)-1]
+		// x += y для списка дополняет тот же список, как list.extend
+		if list, ok := current.(*List); ok && op == "+" {
+			if err := list.checkMutable(); err != nil {
+				return fr.errorAt(s.pos, err)
+			}
+			items, err := iterableValues(thread, value)
+			if err != nil {
+				return fr.errorAt(s.pos, err)
+			}
+			list.elems = append(list.elems, items...)
+			return nil
+		}
+		if value, err = binary(thread, op, current, value); err != nil {
+			return fr.errorAt(s.pos, err)
+		}
+	}
+	return fr.assign(thread, s.target, value)
+}
+
+// assign присваивает значение переменной, элементу или нескольким переменным сразу
+func (fr *frame) assign(thread *Thread, target expr, value Value) error {
+	switch t := target.(type) {
+	case *identExpr:
+		fr.locals[t.name] = value
+		return nil
+	case *indexExpr:
+		container, err := fr.eval(thread, t.operand)
+		if err != nil {
+			return err
+		}
+		index, err := fr.eval(thread, t.index)
+		if err != nil {
+			return err
+		}
+		return fr.errorAt(t.pos, setIndex(container, index, value))
+	case *tupleExpr:
+		return fr.unpack(thread, t.items, value, t.pos)
+	case *listExpr:
+		return fr.unpack(thread, t.items, value, t.pos)
+	}
+	return fr.errorAt(target.position(), fmt.Errorf("cannot assign to this expression"))
+}
+
+func (fr *frame) unpack(thread *Thread, targets []expr, value Value, pos Pos) error {
+	items, err := iterableValues(thread, value)
+	if err != nil {
+		return fr.errorAt(pos, err)
+	}
+	if len(items) != len(targets) {
+		return fr.errorAt(pos, fmt.Errorf("cannot unpack %d values into %d variables", len(items), len(targets)))
+	}
+	for i, target := range targets {
+		if err := fr.assign(thread, target, items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (fr *frame) forStatement(thread *Thread, s *forStmt) (control, Value, error) {
+	iterable, err := fr.eval(thread, s.iterable)
+	if err != nil {
+		return controlNone, nil, err
+	}
+	var result Value
+	var ctl control
+	err = iterate(iterable, func(item Value) (bool, error) {
+		if err := thread.step(1); err != nil {
+			return false, fr.errorAt(s.pos, err)
+		}
+		if err := fr.assign(thread, s.vars, item); err != nil {
+			return false, err
+		}
+		c, value, err := fr.execBlock(thread, s.body)
+		if err != nil {
+			return false, err
+		}
+		switch c {
+		case controlBreak:
+			return false, nil
+		case controlReturn:
+			ctl, result = c, value
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return controlNone, nil, fr.errorAt(s.pos, err)
+	}
+	return ctl, result, nil
+}
+
+// function создаёт функцию; значения по умолчанию вычисляются один раз при объявлении
+func (fr *frame) function(thread *Thread, name string, params []param, body []stmt, result expr) (*Function, error) {
+	fn := &Function{name: name, params: params, body: body, result: result, closure: fr, locals: boundNames(params, body)}
+	for _, p := range params {
+		if p.defaultValue == nil {
+			fn.defaults = append(fn.defaults, nil)
+			continue
+		}
+		value, err := fr.eval(thread, p.defaultValue)
+		if err != nil {
+			return nil, err
+		}
+		freeze(value)
+		fn.defaults = append(fn.defaults, value)
+	}
+	return fn, nil
+}
+
+// boundNames собирает имена, которым функция присваивает значения: они локальны во всём её теле
+func boundNames(params []param, body []stmt) map[string]bool {
+	names := map[string]bool{}
+	for _, p := range params {
+		names[p.name] = true
+	}
+	var targets func(x expr)
+	targets = func(x expr) {
+		switch x := x.(type) {
+		case *identExpr:
+			names[x.name] = true
+		case *tupleExpr:
+			for _, item := range x.items {
+				targets(item)
+			}
+		case *listExpr:
+			for _, item := range x.items {
+				targets(item)
+			}
+		}
+	}
+	var walk func(stmts []stmt)
+	walk = func(stmts []stmt) {
+		for _, s := range stmts {
+			switch s := s.(type) {
+			case *assignStmt:
+				targets(s.target)
+			case *defStmt:
+				names[s.name] = true
+			case *forStmt:
+				targets(s.vars)
+				walk(s.body)
+			case *ifStmt:
+				walk(s.then)
+				walk(s.otherwise)
+			}
+		}
+	}
+	walk(body)
+	return names
+}
+
+func call(thread *Thread, fn Value, args []Value, kwargs map[string]Value) (Value, error) {
+	switch fn := fn.(type) {
+	case *Builtin:
+		if kwargs == nil {
+			kwargs = map[string]Value{}
+		}
+		return fn.fn(thread, Tuple(args), kwargs)
+	case *Function:
+		return fn.call(thread, args, kwargs)
+	}
+	return nil, fmt.Errorf("invalid call of non-function (%s)", fn.Type())
+}
+
+func (fn *Function) call(thread *Thread, args []Value, kwargs map[string]Value) (Value, error) {
+	for _, active := range thread.stack {
+		if active == fn {
+			return nil, fmt.Errorf("function %s called recursively", fn.name)
+		}
+	}
+	if err := thread.step(1); err != nil {
+		return nil, err
+	}
+	if len(args) > len(fn.params) {
+		return nil, fmt.Errorf("%s: got %d arguments, want at most %d", fn.name, len(args), len(fn.params))
+	}
+	fr := &frame{module: fn.closure.module, locals: map[string]Value{}, localNames: fn.locals, parent: fn.closure}
+	for i, value := range args {
+		fr.locals[fn.params[i].name] = value
+	}
+	for name, value := range kwargs {
+		known := false
+		for i, p := range fn.params {
+			if p.name != name {
+				continue
+			}
+			if i < len(args) {
+				return nil, fmt.Errorf("%s: got multiple values for parameter %s", fn.name, name)
+			}
+			known = true
+		}
+		if !known {
+			return nil, fmt.Errorf("%s: unexpected keyword argument %s", fn.name, name)
+		}
+		fr.locals[name] = value
+	}
+	for i, p := range fn.params {
+		if _, ok := fr.locals[p.name]; ok {
+			continue
+		}
+		if fn.defaults[i] == nil {
+			return nil, fmt.Errorf("%s: missing argument for %s", fn.name, p.name)
+		}
+		fr.locals[p.name] = fn.defaults[i]
+	}
+
+	thread.stack = append(thread.stack, fn)
+	defer func() { thread.stack = thread.stack[:len(thread.stack)-1] }()
+	if fn.result != nil {
+		return fr.eval(thread, fn.result)
+	}
+	_, result, err := fr.execBlock(thread, fn.body)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return None, nil
+	}
+	return result, nil
+}
+
+func (fr *frame) eval(thread *Thread, x expr) (Value, error) {
+	switch x := x.(type) {
+	case *identExpr:
+		value, err := fr.lookup(x.name)
+		return value, fr.errorAt(x.pos, err)
+	case *literalExpr:
+		return x.value, nil
+	case *listExpr:
+		items, err := fr.evalAll(thread, x.items)
+		if err != nil {
+			return nil, err
+		}
+		return NewList(items), nil
+	case *tupleExpr:
+		items, err := fr.evalAll(thread, x.items)
+		if err != nil {
+			return nil, err
+		}
+		return Tuple(items), nil
+	case *dictExpr:
+		d := NewDict()
+		for i := range x.keys {
+			key, err := fr.eval(thread, x.keys[i])
+			if err != nil {
+				return nil, err
+			}
+			value, err := fr.eval(thread, x.values[i])
+			if err != nil {
+				return nil, err
+			}
+			if _, exists, _ := d.Get(key); exists {
+				return nil, fr.errorAt(x.keys[i].position(), fmt.Errorf("duplicate key %s in dict literal", key))
+			}
+			if err := d.SetKey(key, value); err != nil {
+				return nil, fr.errorAt(x.keys[i].position(), err)
+			}
+		}
+		return d, nil
+	case *comprehensionExpr:
+		return fr.comprehension(thread, x)
+	case *unaryExpr:
+		operand, err := fr.eval(thread, x.operand)
+		if err != nil {
+			return nil, err
+		}
+		value, err := unary(x.op, operand)
+		return value, fr.errorAt(x.pos, err)
+	case *binaryExpr:
+		return fr.binaryExpr(thread, x)
+	case *condExpr:
+		cond, err := fr.eval(thread, x.cond)
+		if err != nil {
+			return nil, err
+		}
+		if cond.Truth() {
+			return fr.eval(thread, x.then)
+		}
+		return fr.eval(thread, x.otherwise)
+	case *callExpr:
+		return fr.callExpr(thread, x)
+	case *indexExpr:
+		operand, err := fr.eval(thread, x.operand)
+		if err != nil {
+			return nil, err
+		}
+		index, err := fr.eval(thread, x.index)
+		if err != nil {
+			return nil, err
+		}
+		value, err := getIndex(operand, index)
+		return value, fr.errorAt(x.pos, err)
+	case *sliceExpr:
+		return fr.slice(thread, x)
+	case *dotExpr:
+		operand, err := fr.eval(thread, x.operand)
+		if err != nil {
+			return nil, err
+		}
+		value, err := attribute(operand, x.name)
+		return value, fr.errorAt(x.pos, err)
+	case *lambdaExpr:
+		return fr.function(thread, "lambda", x.params, nil, x.body)
+	}
+	return nil, fr.errorAt(x.position(), fmt.Errorf("unsupported expression"))
+}
+
+func (fr *frame) evalAll(thread *Thread, exprs []expr) ([]Value, error) {
+	values := make([]Value, len(exprs))
+	for i, x := range exprs {
+		value, err := fr.eval(thread, x)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+func (fr *frame) binaryExpr(thread *Thread, x *binaryExpr) (Value, error) {
+	left, err := fr.eval(thread, x.left)
+	if err != nil {
+		return nil, err
+	}
+	// and и or вычисляют правую часть только при необходимости
+	switch x.op {
+	case "and":
+		if !left.Truth() {
+			return left, nil
+		}
+		return fr.eval(thread, x.right)
+	case "or":
+		if left.Truth() {
+			return left, nil
+		}
+		return fr.eval(thread, x.right)
+	}
+	right, err := fr.eval(thread, x.right)
+	if err != nil {
+		return nil, err
+	}
+	value, err := binary(thread, x.op, left, right)
+	return value, fr.errorAt(x.pos, err)
+}
+
+func (fr *frame) callExpr(thread *Thread, x *callExpr) (Value, error) {
+	fn, err := fr.eval(thread, x.fn)
+	if err != nil {
+		return nil, err
+	}
+	var args []Value
+	var kwargs map[string]Value
+	for i, arg := range x.args {
+		value, err := fr.eval(thread, arg)
+		if err != nil {
+			return nil, err
+		}
+		if x.names[i] == "" {
+			args = append(args, value)
+			continue
+		}
+		if kwargs == nil {
+			kwargs = map[string]Value{}
+		}
+		kwargs[x.names[i]] = value
+	}
+	value, err := call(thread, fn, args, kwargs)
+	return value, fr.errorAt(x.pos, err)
+}
+
+func (fr *frame) slice(thread *Thread, x *sliceExpr) (Value, error) {
+	operand, err := fr.eval(thread, x.operand)
+	if err != nil {
+		return nil, err
+	}
+	var bounds [3]Value
+	for i, part := range []expr{x.start, x.stop, x.step} {
+		if part == nil {
+			bounds[i] = None
+			continue
+		}
+		if bounds[i], err = fr.eval(thread, part); err != nil {
+			return nil, err
+		}
+	}
+	value, err := slice(operand, bounds[0], bounds[1], bounds[2])
+	return value, fr.errorAt(x.pos, err)
+}
+
+// comprehension вычисляет [body for ...] или {key: body for ...} в собственной области имён
+func (fr *frame) comprehension(thread *Thread, x *comprehensionExpr) (Value, error) {
+	scope := &frame{module: fr.module, locals: map[string]Value{}, localNames: map[string]bool{}, parent: fr}
+	for _, c := range x.clauses {
+		if c.vars != nil {
+			for name := range boundNames(nil, []stmt{&forStmt{vars: c.vars}}) {
+				scope.localNames[name] = true
+			}
+		}
+	}
+	var items []Value
+	result := NewDict()
+	var loop func(i int) error
+	loop = func(i int) error {
+		if i == len(x.clauses) {
+			if err := thread.allocate(1); err != nil {
+				return fr.errorAt(x.pos, err)
+			}
+			value, err := scope.eval(thread, x.body)
+			if err != nil {
+				return err
+			}
+			if x.key == nil {
+				items = append(items, value)
+				return nil
+			}
+			key, err := scope.eval(thread, x.key)
+			if err != nil {
+				return err
+			}
+			return scope.errorAt(x.key.position(), result.SetKey(key, value))
+		}
+		c := x.clauses[i]
+		if c.vars == nil {
+			cond, err := scope.eval(thread, c.cond)
+			if err != nil || !cond.Truth() {
+				return err
+			}
+			return loop(i + 1)
+		}
+		// Первое итерируемое вычисляется во внешней области, как в Python
+		source := scope
+		if i == 0 {
+			source = fr
+		}
+		iterable, err := source.eval(thread, c.iterable)
+		if err != nil {
+			return err
+		}
+		return scope.errorAt(x.pos, iterate(iterable, func(item Value) (bool, error) {
+			if err := scope.assign(thread, c.vars, item); err != nil {
+				return false, err
+			}
+			return true, loop(i + 1)
+		}))
+	}
+	if err := loop(0); err != nil {
+		return nil, err
+	}
+	if x.key != nil {
+		return result, nil
+	}
+	return NewList(items), nil
+}
+
+// iterate обходит список, кортеж, ключи словаря или range; fn возвращает false, чтобы остановиться
+func iterate(v Value, fn func(Value) (bool, error)) error {
+	switch v := v.(type) {
+	case *List:
+		// Замороженные значения общие для потоков, а менять их всё равно нельзя
+		if !v.frozen {
+			v.iterating++
+			defer func() { v.iterating-- }()
+		}
+		for _, item := range v.elems {
+			if more, err := fn(item); err != nil || !more {
+				return err
+			}
+		}
+		return nil
+	case *Dict:
+		if !v.frozen {
+			v.iterating++
+			defer func() { v.iterating-- }()
+		}
+		for _, key := range v.keys {
+			if more, err := fn(key); err != nil || !more {
+				return err
+			}
+		}
+		return nil
+	case Tuple:
+		for _, item := range v {
+			if more, err := fn(item); err != nil || !more {
+				return err
+			}
+		}
+		return nil
+	case rangeValue:
+		for i := 0; i < v.Len(); i++ {
+			if more, err := fn(v.index(i)); err != nil || !more {
+				return err
+			}
+		}
+		return nil
+	case String:
+		return fmt.Errorf("string is not iterable: use .elems()")
+	}
+	return fmt.Errorf("%s is not iterable", v.Type())
+}
+
+// iterableValues собирает элементы итерируемого значения в срез; каждый элемент учитывается
+// по мере обхода, так что list(range(1000000000)) остановится на пределе, не заняв память
+func iterableValues(thread *Thread, v Value) ([]Value, error) {
+	var items []Value
+	err := iterate(v, func(item Value) (bool, error) {
+		if err := thread.allocate(1); err != nil {
+			return false, err
+		}
+		items = append(items, item)
+		return true, nil
+	})
+	return items, err
+}
+
+func unary(op string, x Value) (Value, error) {
+	switch op {
+	case "not":
+		return Bool(!x.Truth()), nil
+	case "-":
+		switch x := x.(type) {
+		case Int:
+			if x == math.MinInt64 {
+				return nil, fmt.Errorf("integer overflow")
+			}
+			return -x, nil
+		case Float:
+			return -x, nil
+		}
+	case "+":
+		switch x.(type) {
+		case Int, Float:
+			return x, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown unary op: %s%s", op, x.Type())
+}
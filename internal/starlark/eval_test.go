@@ -0,0 +1,247 @@
+package starlark
+
+import (
+	"errors"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// run выполняет скрипт и возвращает значение глобального имени result
+func run(t *testing.T, limits Limits, src string) (interface{}, error) {
+	t.Helper()
+	program, err := Compile("test.star", src)
+	if err != nil {
+		return nil, err
+	}
+	globals, err := program.Exec(&Thread{Limits: limits}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return ToGo(globals["result"]), nil
+}
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want interface{}
+	}{
+		{"arithmetic", "result = 1 + 2 * 3 - 8 // 3", 5},
+		{"float", "result = 7 / 2", 3.5},
+		{"string methods", `result = "a,b,c".split(",")`, []interface{}{"a", "b", "c"}},
+		{"join", `result = "-".join(["x", "y"])`, "x-y"},
+		{"percent format", `result = "%s=%d" % ("n", 3)`, "n=3"},
+		{"list comprehension", "result = [x * x for x in range(5) if x % 2 == 0]", []interface{}{0, 4, 16}},
+		{"dict comprehension", `result = {k: len(k) for k in ["a", "bb"]}`, map[string]interface{}{"a": 1, "bb": 2}},
+		{"function", "def f(x, y=2):\n    return x * y\nresult = f(3) + f(1, y=10)", 16},
+		{"lambda and sorted", `result = sorted(["bb", "a", "ccc"], key=lambda s: len(s))`, []interface{}{"a", "bb", "ccc"}},
+		{"for and break", "result = 0\nfor i in range(10):\n    if i == 4:\n        break\n    result += i", 6},
+		{"dict methods", `d = {"a": 1}
+d["b"] = 2
+result = [k for k, v in d.items()]`, []interface{}{"a", "b"}},
+		{"slice", "result = [1, 2, 3, 4][1:3]", []interface{}{2, 3}},
+		{"in and not in", `result = ("a" in ["a"], 3 not in {"x": 1})`, []interface{}{true, true}},
+		{"name set", "set = [1]\nresult = set", []interface{}{1}},
+		{"conditional expression", `result = "yes" if len([1]) else "no"`, "yes"},
+		{"enumerate and zip", "result = [(i, a + b) for i, (a, b) in enumerate(zip([1, 2], [10, 20]))]",
+			[]interface{}{[]interface{}{0, 11}, []interface{}{1, 22}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := run(t, Limits{MaxSteps: 10000}, tt.src)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		want    string
+		message string
+	}{
+		{"unclosed bracket", "result = [1, 2", "test.star:1", "syntax error"},
+		{"bad indentation", "def f():\nreturn 1", "test.star:2", ""},
+		// Конструкции Starlark, которых нет в поддерживаемом подмножестве
+		{"power operator", "result = 2 ** 10", "test.star:1:12", "operator ** is not supported"},
+		{"power assignment", "x = 2\nx **= 2", "test.star:2:3", "operator ** is not supported"},
+		{"shift operator", "result = 1 << 4", "test.star:1:12", "bitwise operators are not supported"},
+		{"bitwise or", "result = 1 | 2", "test.star:1:12", "bitwise operators are not supported"},
+		{"bitwise and", "result = [1 & 2]", "test.star:1:13", "bitwise operators are not supported"},
+		{"bitwise xor assignment", "x = 1\nx ^= 2", "test.star:2:3", "bitwise operators are not supported"},
+		{"bitwise not", "result = ~1", "test.star:1:10", "bitwise operators are not supported"},
+		{"chained comparison", "result = 1 < 2 < 3", "test.star:1:16", "comparison operators cannot be chained"},
+		{"chained membership", `result = "a" in "ab" in ["ab"]`, "test.star:1:22", "comparison operators cannot be chained"},
+		{"set literal", "result = {1, 2}", "test.star:1:10", "sets are not supported"},
+		{"set comprehension", "result = {x for x in [1]}", "test.star:1:10", "sets are not supported"},
+		{"set call", "result = set([1])", "test.star:1:10", "sets are not supported"},
+		{"while", "while True:\n    pass", "test.star:1", ""},
+		{"load", `load("x.star", "f")`, "test.star:1", "load is not supported"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile("test.star", tt.src)
+			if err == nil {
+				t.Fatal("expected a compile error")
+			}
+			var scriptErr *Error
+			if !errors.As(err, &scriptErr) {
+				t.Fatalf("error %v is not *Error", err)
+			}
+			if !strings.HasPrefix(err.Error(), tt.want) {
+				t.Errorf("error %q does not start with %q", err, tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("error %q does not mention %q", err, tt.message)
+			}
+		})
+	}
+}
+
+func TestRuntimeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"recursion", "def f(n):\n    return f(n)\nresult = f(1)", "called recursively"},
+		{"mutating a list before freeze", "def f(x):\n    x.append(1)\nl = []\nf(l)\nresult = l", ""},
+		{"unknown name", "result = missing", "undefined"},
+		{"type error", `result = "a" + 1`, "unknown binary op"},
+		{"fail", `fail("boom")`, "boom"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := run(t, Limits{MaxSteps: 10000}, tt.src)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("got error %v, want one containing %q", err, tt.want)
+			}
+			if errors.Is(err, ErrLimit) {
+				t.Errorf("%v must not be a limit error", err)
+			}
+		})
+	}
+}
+
+func TestCallFrozenDocument(t *testing.T) {
+	program, err := Compile("test.star", "def check(doc):\n    doc[\"kind\"] = \"x\"")
+	if err != nil {
+		t.Fatal(err)
+	}
+	globals, err := program.Exec(&Thread{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	document := FromGo(map[string]interface{}{"kind": "Pod"})
+	_, err = Call(&Thread{Limits: Limits{MaxSteps: 100}}, globals["check"].(*Function), document)
+	if err == nil || !strings.Contains(err.Error(), "frozen") {
+		t.Fatalf("got %v, want an error about the frozen dict", err)
+	}
+}
+
+func TestStepLimit(t *testing.T) {
+	_, err := run(t, Limits{MaxSteps: 1000}, "result = 0\nfor i in range(1000000):\n    result += i")
+	if !errors.Is(err, ErrLimit) {
+		t.Fatalf("got %v, want ErrLimit", err)
+	}
+	if !strings.Contains(err.Error(), "1000 steps") {
+		t.Errorf("error %q does not name the step limit", err)
+	}
+}
+
+func TestStepLimitStopsBuiltinIteration(t *testing.T) {
+	started := time.Now()
+	_, err := run(t, Limits{MaxSteps: 1000}, "result = list(range(3000000))")
+	if !errors.Is(err, ErrLimit) {
+		t.Fatalf("got %v, want ErrLimit", err)
+	}
+	// Предел должен сработать на тысячном элементе, а не после сборки всего списка
+	if elapsed := time.Since(started); elapsed > 50*time.Millisecond {
+		t.Errorf("limit stopped the script after %s", elapsed)
+	}
+}
+
+func TestTimeLimit(t *testing.T) {
+	started := time.Now()
+	_, err := run(t, Limits{Timeout: 50 * time.Millisecond}, "result = 0\nfor i in range(1000000000):\n    result += i")
+	if !errors.Is(err, ErrLimit) {
+		t.Fatalf("got %v, want ErrLimit", err)
+	}
+	if !strings.Contains(err.Error(), "time limit") {
+		t.Errorf("error %q does not name the time limit", err)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("time limit of 50ms stopped the script after %s", elapsed)
+	}
+}
+
+func TestAllocationLimit(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{"list of range", "result = list(range(100000000))"},
+		{"tuple of range", "result = tuple(range(100000000))"},
+		{"comprehension", "result = [i for i in range(100000000)]"},
+		{"string repeat", `result = "x" * 1000000000`},
+		{"list repeat", "result = [0] * 1000000000"},
+		{"append in loop", "result = []\nfor i in range(100000000):\n    result.append(i)"},
+		{"sorted", "result = sorted(range(100000000))"},
+		{"join", `result = ",".join(["abcdef"] * 1000)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var before, after runtime.MemStats
+			runtime.GC()
+			runtime.ReadMemStats(&before)
+			// Шаги не ограничены: память должен удержать именно MaxAllocs
+			_, err := run(t, Limits{MaxAllocs: 1000}, tt.src)
+			runtime.ReadMemStats(&after)
+			if !errors.Is(err, ErrLimit) {
+				t.Fatalf("got %v, want ErrLimit", err)
+			}
+			if !strings.Contains(err.Error(), "allocated elements") {
+				t.Errorf("error %q does not name the allocation limit", err)
+			}
+			if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1<<20 {
+				t.Errorf("script allocated %d bytes before the limit stopped it", allocated)
+			}
+		})
+	}
+}
+
+func TestLimitsResetPerCall(t *testing.T) {
+	program, err := Compile("test.star", "def check(n):\n    return len(list(range(n)))")
+	if err != nil {
+		t.Fatal(err)
+	}
+	globals, err := program.Exec(&Thread{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	thread := &Thread{Limits: Limits{MaxSteps: 1000, MaxAllocs: 600}}
+	for i := 0; i < 3; i++ {
+		got, err := Call(thread, globals["check"].(*Function), Int(500))
+		if err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+		if got != Int(500) {
+			t.Fatalf("call %d: got %v", i, got)
+		}
+	}
+}
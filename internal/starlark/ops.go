@@ -0,0 +1,567 @@
+package starlark
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// binary вычисляет бинарный оператор, кроме and и or
+func binary(thread *Thread, op string, x, y Value) (Value, error) {
+	switch op {
+	case "==":
+		eq, err := equal(x, y)
+		return Bool(eq), err
+	case "!=":
+		eq, err := equal(x, y)
+		return Bool(!eq), err
+	case "<", "<=", ">", ">=":
+		cmp, err := compare(x, y)
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case "<":
+			return Bool(cmp < 0), nil
+		case "<=":
+			return Bool(cmp <= 0), nil
+		case ">":
+			return Bool(cmp > 0), nil
+		}
+		return Bool(cmp >= 0), nil
+	case "in", "not in":
+		found, err := contains(y, x)
+		if err != nil {
+			return nil, err
+		}
+		return Bool(found == (op == "in")), nil
+	}
+
+	if xi, ok := x.(Int); ok {
+		if yi, ok := y.(Int); ok {
+			return intBinary(op, xi, yi)
+		}
+	}
+	if xf, ok := toFloat(x); ok {
+		if yf, ok := toFloat(y); ok {
+			return floatBinary(op, xf, yf)
+		}
+	}
+
+	switch op {
+	case "+":
+		switch x := x.(type) {
+		case String:
+			if y, ok := y.(String); ok {
+				if err := thread.allocate(int64(len(x) + len(y))); err != nil {
+					return nil, err
+				}
+				return x + y, nil
+			}
+		case *List:
+			if y, ok := y.(*List); ok {
+				if err := thread.allocate(int64(len(x.elems) + len(y.elems))); err != nil {
+					return nil, err
+				}
+				return NewList(append(append([]Value(nil), x.elems...), y.elems...)), nil
+			}
+		case Tuple:
+			if y, ok := y.(Tuple); ok {
+				if err := thread.allocate(int64(len(x) + len(y))); err != nil {
+					return nil, err
+				}
+				return append(append(Tuple(nil), x...), y...), nil
+			}
+		}
+	case "*":
+		if _, ok := x.(Int); ok {
+			x, y = y, x
+		}
+		if n, ok := y.(Int); ok {
+			return repeat(thread, x, n)
+		}
+	case "%":
+		if format, ok := x.(String); ok {
+			return percentFormat(string(format), y)
+		}
+	}
+	return nil, fmt.Errorf("unknown binary op: %s %s %s", x.Type(), op, y.Type())
+}
+
+// repeat повторяет строку, список или кортеж n раз; размер результата учитывается до
+// выделения, чтобы "x" * 1000000000 упёрся в предел, а не в память
+func repeat(thread *Thread, x Value, n Int) (Value, error) {
+	if n < 0 {
+		n = 0
+	}
+	var size int
+	switch x := x.(type) {
+	case String:
+		size = len(x)
+	case *List:
+		size = len(x.elems)
+	case Tuple:
+		size = len(x)
+	default:
+		return nil, fmt.Errorf("unknown binary op: %s * int", x.Type())
+	}
+	if size > 0 && int64(n) > math.MaxInt32/int64(size) {
+		return nil, fmt.Errorf("repeat count %d too large", n)
+	}
+	if err := thread.allocate(int64(size) * int64(n)); err != nil {
+		return nil, err
+	}
+	switch x := x.(type) {
+	case String:
+		return String(strings.Repeat(string(x), int(n))), nil
+	case *List:
+		elems := make([]Value, 0, len(x.elems)*int(n))
+		for i := 0; i < int(n); i++ {
+			elems = append(elems, x.elems...)
+		}
+		return NewList(elems), nil
+	}
+	tuple := x.(Tuple)
+	elems := make(Tuple, 0, len(tuple)*int(n))
+	for i := 0; i < int(n); i++ {
+		elems = append(elems, tuple...)
+	}
+	return elems, nil
+}
+
+func toFloat(v Value) (float64, bool) {
+	switch v := v.(type) {
+	case Int:
+		return float64(v), true
+	case Float:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func intBinary(op string, x, y Int) (Value, error) {
+	a, b := int64(x), int64(y)
+	switch op {
+	case "+":
+		if b > 0 && a > math.MaxInt64-b || b < 0 && a < math.MinInt64-b {
+			return nil, fmt.Errorf("integer overflow")
+		}
+		return Int(a + b), nil
+	case "-":
+		if b < 0 && a > math.MaxInt64+b || b > 0 && a < math.MinInt64+b {
+			return nil, fmt.Errorf("integer overflow")
+		}
+		return Int(a - b), nil
+	case "*":
+		if a != 0 && ((a*b)/a != b || a == -1 && b == math.MinInt64 || b == -1 && a == math.MinInt64) {
+			return nil, fmt.Errorf("integer overflow")
+		}
+		return Int(a * b), nil
+	case "/":
+		if b == 0 {
+			return nil, fmt.Errorf("floating-point division by zero")
+		}
+		return Float(float64(a) / float64(b)), nil
+	case "//":
+		if b == 0 {
+			return nil, fmt.Errorf("integer division by zero")
+		}
+		q := a / b
+		if a%b != 0 && (a < 0) != (b < 0) {
+			q--
+		}
+		return Int(q), nil
+	case "%":
+		if b == 0 {
+			return nil, fmt.Errorf("integer modulo by zero")
+		}
+		r := a % b
+		if r != 0 && (r < 0) != (b < 0) {
+			r += b
+		}
+		return Int(r), nil
+	}
+	return nil, fmt.Errorf("unknown binary op: int %s int", op)
+}
+
+func floatBinary(op string, a, b float64) (Value, error) {
+	switch op {
+	case "+":
+		return Float(a + b), nil
+	case "-":
+		return Float(a - b), nil
+	case "*":
+		return Float(a * b), nil
+	case "/":
+		if b == 0 {
+			return nil, fmt.Errorf("floating-point division by zero")
+		}
+		return Float(a / b), nil
+	case "//":
+		if b == 0 {
+			return nil, fmt.Errorf("floating-point division by zero")
+		}
+		return Float(math.Floor(a / b)), nil
+	case "%":
+		if b == 0 {
+			return nil, fmt.Errorf("floating-point modulo by zero")
+		}
+		r := math.Mod(a, b)
+		if r != 0 && (r < 0) != (b < 0) {
+			r += b
+		}
+		return Float(r), nil
+	}
+	return nil, fmt.Errorf("unknown binary op: float %s float", op)
+}
+
+// equal сравнивает значения на равенство; списки, кортежи и словари — поэлементно
+func equal(x, y Value) (bool, error) {
+	if xf, ok := toFloat(x); ok {
+		yf, ok := toFloat(y)
+		return ok && xf == yf, nil
+	}
+	switch x := x.(type) {
+	case NoneType, Bool, String:
+		return x == y, nil
+	case *List:
+		if y, ok := y.(*List); ok {
+			return equalSlices(x.elems, y.elems)
+		}
+	case Tuple:
+		if y, ok := y.(Tuple); ok {
+			return equalSlices(x, y)
+		}
+	case *Dict:
+		y, ok := y.(*Dict)
+		if !ok || len(x.keys) != len(y.keys) {
+			return false, nil
+		}
+		for i, key := range x.keys {
+			other, found, err := y.Get(key)
+			if err != nil || !found {
+				return false, err
+			}
+			if eq, err := equal(x.values[i], other); err != nil || !eq {
+				return false, err
+			}
+		}
+		return true, nil
+	case rangeValue:
+		y, ok := y.(rangeValue)
+		return ok && x.Len() == y.Len() && (x.Len() == 0 || x.start == y.start && (x.Len() == 1 || x.step == y.step)), nil
+	default:
+		return x == y, nil
+	}
+	return false, nil
+}
+
+func equalSlices(x, y []Value) (bool, error) {
+	if len(x) != len(y) {
+		return false, nil
+	}
+	for i := range x {
+		if eq, err := equal(x[i], y[i]); err != nil || !eq {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// compare упорядочивает числа, строки, списки и кортежи; возвращает -1, 0 или 1
+func compare(x, y Value) (int, error) {
+	if xf, ok := toFloat(x); ok {
+		if yf, ok := toFloat(y); ok {
+			switch {
+			case xf < yf:
+				return -1, nil
+			case xf > yf:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+	switch x := x.(type) {
+	case String:
+		if y, ok := y.(String); ok {
+			return strings.Compare(string(x), string(y)), nil
+		}
+	case Bool:
+		if y, ok := y.(Bool); ok {
+			switch {
+			case x == y:
+				return 0, nil
+			case !bool(x):
+				return -1, nil
+			}
+			return 1, nil
+		}
+	case *List:
+		if y, ok := y.(*List); ok {
+			return compareSlices(x.elems, y.elems)
+		}
+	case Tuple:
+		if y, ok := y.(Tuple); ok {
+			return compareSlices(x, y)
+		}
+	}
+	return 0, fmt.Errorf("unsupported comparison: %s < %s", x.Type(), y.Type())
+}
+
+func compareSlices(x, y []Value) (int, error) {
+	for i := 0; i < len(x) && i < len(y); i++ {
+		eq, err := equal(x[i], y[i])
+		if err != nil {
+			return 0, err
+		}
+		if !eq {
+			return compare(x[i], y[i])
+		}
+	}
+	switch {
+	case len(x) < len(y):
+		return -1, nil
+	case len(x) > len(y):
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// contains реализует оператор in: подстрока, элемент последовательности или ключ словаря
+func contains(container, x Value) (bool, error) {
+	switch c := container.(type) {
+	case String:
+		s, ok := x.(String)
+		if !ok {
+			return false, fmt.Errorf("'in <string>' requires string as left operand, not %s", x.Type())
+		}
+		return strings.Contains(string(c), string(s)), nil
+	case *Dict:
+		_, found, err := c.Get(x)
+		return found, err
+	case *List, Tuple, rangeValue:
+		found := false
+		err := iterate(c, func(item Value) (bool, error) {
+			eq, err := equal(item, x)
+			found = eq
+			return !eq && err == nil, err
+		})
+		return found, err
+	}
+	return false, fmt.Errorf("unknown binary op: %s in %s", x.Type(), container.Type())
+}
+
+// sequenceIndex переводит индекс, возможно отрицательный, в позицию последовательности длины n
+func sequenceIndex(index Value, n int) (int, error) {
+	i, ok := index.(Int)
+	if !ok {
+		return 0, fmt.Errorf("index must be int, not %s", index.Type())
+	}
+	if i < 0 {
+		i += Int(n)
+	}
+	if i < 0 || i >= Int(n) {
+		return 0, fmt.Errorf("index %d out of range [0:%d]", index.(Int), n)
+	}
+	return int(i), nil
+}
+
+func getIndex(x, index Value) (Value, error) {
+	switch x := x.(type) {
+	case *List:
+		i, err := sequenceIndex(index, len(x.elems))
+		if err != nil {
+			return nil, err
+		}
+		return x.elems[i], nil
+	case Tuple:
+		i, err := sequenceIndex(index, len(x))
+		if err != nil {
+			return nil, err
+		}
+		return x[i], nil
+	case String:
+		i, err := sequenceIndex(index, len(x))
+		if err != nil {
+			return nil, err
+		}
+		return x[i : i+1], nil
+	case rangeValue:
+		i, err := sequenceIndex(index, x.Len())
+		if err != nil {
+			return nil, err
+		}
+		return x.index(i), nil
+	case *Dict:
+		value, found, err := x.Get(index)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, fmt.Errorf("key %s not in dict", index)
+		}
+		return value, nil
+	}
+	return nil, fmt.Errorf("unhandled index operation %s[%s]", x.Type(), index.Type())
+}
+
+func setIndex(x, index, value Value) error {
+	switch x := x.(type) {
+	case *List:
+		if err := x.checkMutable(); err != nil {
+			return err
+		}
+		i, err := sequenceIndex(index, len(x.elems))
+		if err != nil {
+			return err
+		}
+		x.elems[i] = value
+		return nil
+	case *Dict:
+		return x.SetKey(index, value)
+	}
+	return fmt.Errorf("%s value does not support item assignment", x.Type())
+}
+
+// slice вычисляет x[start:stop:step] по правилам Python
+func slice(x, start, stop, step Value) (Value, error) {
+	var n int
+	switch x := x.(type) {
+	case String:
+		n = len(x)
+	case *List:
+		n = len(x.elems)
+	case Tuple:
+		n = len(x)
+	default:
+		return nil, fmt.Errorf("invalid slice operand %s", x.Type())
+	}
+	s := int64(1)
+	if step != None {
+		i, ok := step.(Int)
+		if !ok || i == 0 {
+			return nil, fmt.Errorf("slice step must be a non-zero int")
+		}
+		s = int64(i)
+	}
+	var lo, hi int64
+	if s > 0 {
+		lo, hi = 0, int64(n)
+	} else {
+		lo, hi = int64(n)-1, -1
+	}
+	bound := func(v Value, def int64) (int64, error) {
+		if v == None {
+			return def, nil
+		}
+		i, ok := v.(Int)
+		if !ok {
+			return 0, fmt.Errorf("slice index must be int, not %s", v.Type())
+		}
+		b := int64(i)
+		if b < 0 {
+			b += int64(n)
+		}
+		switch {
+		case b < 0 && s > 0:
+			b = 0
+		case b < 0:
+			b = -1
+		case b > int64(n) && s > 0:
+			b = int64(n)
+		case b >= int64(n) && s < 0:
+			b = int64(n) - 1
+		}
+		return b, nil
+	}
+	from, err := bound(start, lo)
+	if err != nil {
+		return nil, err
+	}
+	to, err := bound(stop, hi)
+	if err != nil {
+		return nil, err
+	}
+	var positions []int
+	for i := from; s > 0 && i < to || s < 0 && i > to; i += s {
+		positions = append(positions, int(i))
+	}
+	switch x := x.(type) {
+	case String:
+		var b strings.Builder
+		for _, i := range positions {
+			b.WriteByte(x[i])
+		}
+		return String(b.String()), nil
+	case *List:
+		elems := make([]Value, len(positions))
+		for j, i := range positions {
+			elems[j] = x.elems[i]
+		}
+		return NewList(elems), nil
+	}
+	tuple := x.(Tuple)
+	elems := make(Tuple, len(positions))
+	for j, i := range positions {
+		elems[j] = tuple[i]
+	}
+	return elems, nil
+}
+
+// percentFormat реализует "format" % args с подстановками %s, %r, %d, %x и %%
+func percentFormat(format string, args Value) (Value, error) {
+	values := []Value{args}
+	if tuple, ok := args.(Tuple); ok {
+		values = tuple
+	}
+	var b strings.Builder
+	next := 0
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c != '%' {
+			b.WriteByte(c)
+			continue
+		}
+		if i+1 >= len(format) {
+			return nil, fmt.Errorf("incomplete format")
+		}
+		i++
+		verb := format[i]
+		if verb == '%' {
+			b.WriteByte('%')
+			continue
+		}
+		if next >= len(values) {
+			return nil, fmt.Errorf("not enough arguments for format string")
+		}
+		value := values[next]
+		next++
+		switch verb {
+		case 's':
+			b.WriteString(AsString(value))
+		case 'r':
+			b.WriteString(value.String())
+		case 'd', 'i':
+			switch v := value.(type) {
+			case Int:
+				b.WriteString(v.String())
+			case Float:
+				b.WriteString(Int(math.Trunc(float64(v))).String())
+			default:
+				return nil, fmt.Errorf("%%%c format requires integer: %s", verb, value.Type())
+			}
+		case 'x':
+			v, ok := value.(Int)
+			if !ok {
+				return nil, fmt.Errorf("%%x format requires integer: %s", value.Type())
+			}
+			fmt.Fprintf(&b, "%x", int64(v))
+		default:
+			return nil, fmt.Errorf("unsupported format character '%c'", verb)
+		}
+	}
+	if next < len(values) {
+		return nil, fmt.Errorf("too many arguments for format string")
+	}
+	return String(b.String()), nil
+}
@@ -0,0 +1,951 @@
+package starlark
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Узлы синтаксического дерева. Каждый хранит позицию для сообщений об ошибках.
+
+type expr interface{ position() Pos }
+
+type stmt interface{ position() Pos }
+
+type (
+	identExpr struct {
+		pos  Pos
+		name string
+	}
+	literalExpr struct {
+		pos   Pos
+		value Value
+	}
+	listExpr struct {
+		pos   Pos
+		items []expr
+	}
+	tupleExpr struct {
+		pos   Pos
+		items []expr
+	}
+	dictExpr struct {
+		pos    Pos
+		keys   []expr
+		values []expr
+	}
+	// comprehensionExpr — [x for x in y if c] или {k: v for ...}; key задан только для словаря
+	comprehensionExpr struct {
+		pos     Pos
+		key     expr
+		body    expr
+		clauses []clause
+	}
+	unaryExpr struct {
+		pos     Pos
+		op      string
+		operand expr
+	}
+	binaryExpr struct {
+		pos         Pos
+		op          string
+		left, right expr
+	}
+	condExpr struct {
+		pos                   Pos
+		cond, then, otherwise expr
+	}
+	callExpr struct {
+		pos   Pos
+		fn    expr
+		args  []expr
+		names []string // имя для именованного аргумента, "" для позиционного
+	}
+	indexExpr struct {
+		pos     Pos
+		operand expr
+		index   expr
+	}
+	sliceExpr struct {
+		pos               Pos
+		operand           expr
+		start, stop, step expr
+	}
+	dotExpr struct {
+		pos     Pos
+		operand expr
+		name    string
+	}
+	lambdaExpr struct {
+		pos    Pos
+		params []param
+		body   expr
+	}
+)
+
+// clause — часть comprehension: for vars in iterable или if cond
+type clause struct {
+	vars     expr
+	iterable expr
+	cond     expr
+}
+
+type param struct {
+	name         string
+	defaultValue expr
+}
+
+type (
+	exprStmt struct {
+		pos Pos
+		x   expr
+	}
+	assignStmt struct {
+		pos    Pos
+		op     string // "=" или составное присваивание, например "+="
+		target expr
+		value  expr
+	}
+	defStmt struct {
+		pos    Pos
+		name   string
+		params []param
+		body   []stmt
+	}
+	ifStmt struct {
+		pos       Pos
+		cond      expr
+		then      []stmt
+		otherwise []stmt
+	}
+	forStmt struct {
+		pos      Pos
+		vars     expr
+		iterable expr
+		body     []stmt
+	}
+	returnStmt struct {
+		pos   Pos
+		value expr
+	}
+	// branchStmt — break, continue или pass
+	branchStmt struct {
+		pos     Pos
+		keyword string
+	}
+)
+
+func (e *identExpr) position() Pos         { return e.pos }
+func (e *literalExpr) position() Pos       { return e.pos }
+func (e *listExpr) position() Pos          { return e.pos }
+func (e *tupleExpr) position() Pos         { return e.pos }
+func (e *dictExpr) position() Pos          { return e.pos }
+func (e *comprehensionExpr) position() Pos { return e.pos }
+func (e *unaryExpr) position() Pos         { return e.pos }
+func (e *binaryExpr) position() Pos        { return e.pos }
+func (e *condExpr) position() Pos          { return e.pos }
+func (e *callExpr) position() Pos          { return e.pos }
+func (e *indexExpr) position() Pos         { return e.pos }
+func (e *sliceExpr) position() Pos         { return e.pos }
+func (e *dotExpr) position() Pos           { return e.pos }
+func (e *lambdaExpr) position() Pos        { return e.pos }
+func (s *exprStmt) position() Pos          { return s.pos }
+func (s *assignStmt) position() Pos        { return s.pos }
+func (s *defStmt) position() Pos           { return s.pos }
+func (s *ifStmt) position() Pos            { return s.pos }
+func (s *forStmt) position() Pos           { return s.pos }
+func (s *returnStmt) position() Pos        { return s.pos }
+func (s *branchStmt) position() Pos        { return s.pos }
+
+type parser struct {
+	filename string
+	tokens   []token
+	next     int
+	// Вложенность def и lambda: return допустим только внутри функции
+	inFunction int
+	// Вложенность циклов: break и continue допустимы только внутри цикла
+	inLoop int
+}
+
+func parse(filename, src string) ([]stmt, error) {
+	tokens, err := scan(filename, src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{filename: filename, tokens: tokens}
+	var body []stmt
+	for p.peek().kind != tokenEOF {
+		stmts, err := p.statement()
+		if err != nil {
+			return nil, err
+		}
+		body = append(body, stmts...)
+	}
+	return body, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *parser) take() token {
+	tok := p.tokens[p.next]
+	if tok.kind != tokenEOF {
+		p.next++
+	}
+	return tok
+}
+
+// is сообщает, что следующая лексема — оператор или ключевое слово text
+func (p *parser) is(text string) bool {
+	tok := p.peek()
+	return (tok.kind == tokenOp || tok.kind == tokenKeyword) && tok.text == text
+}
+
+func (p *parser) accept(text string) bool {
+	if p.is(text) {
+		p.take()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(text string) error {
+	if !p.accept(text) {
+		return p.unexpected(fmt.Sprintf("expected %s", text))
+	}
+	return nil
+}
+
+// Операторы Starlark, которых нет в поддерживаемом подмножестве, и что писать вместо них
+var unsupportedOperators = map[string]string{
+	"**": "operator ** is not supported: multiply explicitly",
+	"|":  "bitwise operators are not supported",
+	"&":  "bitwise operators are not supported",
+	"^":  "bitwise operators are not supported",
+	"~":  "bitwise operators are not supported",
+	"<<": "bitwise operators are not supported",
+	">>": "bitwise operators are not supported",
+}
+
+func (p *parser) unexpected(what string) error {
+	tok := p.peek()
+	if tok.kind == tokenOp {
+		if msg, ok := unsupportedOperators[strings.TrimSuffix(tok.text, "=")]; ok {
+			return p.errorf(tok.pos, "%s", msg)
+		}
+	}
+	return &Error{Filename: p.filename, Pos: tok.pos, Msg: fmt.Sprintf("syntax error: %s, got %s", what, tok.text)}
+}
+
+func (p *parser) errorf(pos Pos, format string, args ...interface{}) error {
+	return &Error{Filename: p.filename, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) statement() ([]stmt, error) {
+	switch {
+	case p.is("def"):
+		s, err := p.defStatement()
+		return []stmt{s}, err
+	case p.is("if"):
+		s, err := p.ifStatement()
+		return []stmt{s}, err
+	case p.is("for"):
+		s, err := p.forStatement()
+		return []stmt{s}, err
+	case p.is("while"):
+		return nil, p.errorf(p.peek().pos, "while loops are not allowed: use for over a range")
+	}
+	return p.simpleStatements()
+}
+
+func (p *parser) defStatement() (stmt, error) {
+	pos := p.take().pos
+	name := p.peek()
+	if name.kind != tokenName {
+		return nil, p.unexpected("expected function name")
+	}
+	p.take()
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	params, err := p.params(")")
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	p.inFunction++
+	loops := p.inLoop
+	p.inLoop = 0
+	body, err := p.suite()
+	p.inFunction--
+	p.inLoop = loops
+	if err != nil {
+		return nil, err
+	}
+	return &defStmt{pos: pos, name: name.text, params: params, body: body}, nil
+}
+
+// params разбирает параметры функции до закрывающей лексемы end
+func (p *parser) params(end string) ([]param, error) {
+	var params []param
+	seen := map[string]bool{}
+	for !p.is(end) {
+		tok := p.peek()
+		if tok.kind != tokenName {
+			return nil, p.unexpected("expected parameter name")
+		}
+		p.take()
+		if seen[tok.text] {
+			return nil, p.errorf(tok.pos, "duplicate parameter %s", tok.text)
+		}
+		seen[tok.text] = true
+		param := param{name: tok.text}
+		if p.accept("=") {
+			value, err := p.test()
+			if err != nil {
+				return nil, err
+			}
+			param.defaultValue = value
+		} else if len(params) > 0 && params[len(params)-1].defaultValue != nil {
+			return nil, p.errorf(tok.pos, "parameter %s without a default follows one with a default", tok.text)
+		}
+		params = append(params, param)
+		if !p.accept(",") {
+			break
+		}
+	}
+	return params, nil
+}
+
+func (p *parser) ifStatement() (stmt, error) {
+	pos := p.take().pos
+	cond, err := p.test()
+	if err != nil {
+		return nil, err
+	}
+	then, err := p.suite()
+	if err != nil {
+		return nil, err
+	}
+	s := &ifStmt{pos: pos, cond: cond, then: then}
+	switch {
+	case p.is("elif"):
+		elif, err := p.ifStatement()
+		if err != nil {
+			return nil, err
+		}
+		s.otherwise = []stmt{elif}
+	case p.accept("else"):
+		if s.otherwise, err = p.suite(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (p *parser) forStatement() (stmt, error) {
+	pos := p.take().pos
+	vars, err := p.targets()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("in"); err != nil {
+		return nil, err
+	}
+	iterable, err := p.expressionList()
+	if err != nil {
+		return nil, err
+	}
+	p.inLoop++
+	body, err := p.suite()
+	p.inLoop--
+	if err != nil {
+		return nil, err
+	}
+	return &forStmt{pos: pos, vars: vars, iterable: iterable, body: body}, nil
+}
+
+// targets разбирает переменные цикла: имя или несколько имён через запятую
+func (p *parser) targets() (expr, error) {
+	pos := p.peek().pos
+	var items []expr
+	for {
+		target, err := p.primary()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.checkTarget(target); err != nil {
+			return nil, err
+		}
+		items = append(items, target)
+		if !p.accept(",") || p.is("in") {
+			break
+		}
+	}
+	if len(items) == 1 {
+		return items[0], nil
+	}
+	return &tupleExpr{pos: pos, items: items}, nil
+}
+
+func (p *parser) suite() ([]stmt, error) {
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenNewline {
+		return p.simpleStatements()
+	}
+	p.take()
+	if p.peek().kind != tokenIndent {
+		return nil, p.unexpected("expected an indented block")
+	}
+	p.take()
+	var body []stmt
+	for p.peek().kind != tokenDedent && p.peek().kind != tokenEOF {
+		stmts, err := p.statement()
+		if err != nil {
+			return nil, err
+		}
+		body = append(body, stmts...)
+	}
+	p.take()
+	return body, nil
+}
+
+// simpleStatements разбирает строку из простых операторов через точку с запятой
+func (p *parser) simpleStatements() ([]stmt, error) {
+	var stmts []stmt
+	for {
+		s, err := p.simpleStatement()
+		if err != nil {
+			return nil, err
+		}
+		stmts = append(stmts, s)
+		if !p.accept(";") || p.peek().kind == tokenNewline {
+			break
+		}
+	}
+	if p.peek().kind != tokenNewline {
+		return nil, p.unexpected("expected end of line")
+	}
+	p.take()
+	return stmts, nil
+}
+
+func (p *parser) simpleStatement() (stmt, error) {
+	tok := p.peek()
+	switch {
+	case p.is("return"):
+		p.take()
+		if p.inFunction == 0 {
+			return nil, p.errorf(tok.pos, "return outside function")
+		}
+		s := &returnStmt{pos: tok.pos}
+		if p.peek().kind != tokenNewline && !p.is(";") {
+			value, err := p.expressionList()
+			if err != nil {
+				return nil, err
+			}
+			s.value = value
+		}
+		return s, nil
+	case p.is("break"), p.is("continue"):
+		p.take()
+		if p.inLoop == 0 {
+			return nil, p.errorf(tok.pos, "%s outside loop", tok.text)
+		}
+		return &branchStmt{pos: tok.pos, keyword: tok.text}, nil
+	case p.is("pass"):
+		p.take()
+		return &branchStmt{pos: tok.pos, keyword: tok.text}, nil
+	case p.is("load"):
+		return nil, p.errorf(tok.pos, "load is not supported: a rule script must be self-contained")
+	}
+
+	x, err := p.expressionList()
+	if err != nil {
+		return nil, err
+	}
+	op := p.peek()
+	switch op.text {
+	case "=", "+=", "-=", "*=", "/=", "//=", "%=":
+		if op.kind != tokenOp {
+			break
+		}
+		p.take()
+		if err := p.checkTarget(x); err != nil {
+			return nil, err
+		}
+		if _, isTuple := x.(*tupleExpr); isTuple && op.text != "=" {
+			return nil, p.errorf(op.pos, "%s cannot assign to several variables", op.text)
+		}
+		value, err := p.expressionList()
+		if err != nil {
+			return nil, err
+		}
+		return &assignStmt{pos: op.pos, op: op.text, target: x, value: value}, nil
+	}
+	return &exprStmt{pos: tok.pos, x: x}, nil
+}
+
+// checkTarget проверяет, что выражению можно присвоить значение
+func (p *parser) checkTarget(target expr) error {
+	switch t := target.(type) {
+	case *identExpr, *indexExpr:
+		return nil
+	case *tupleExpr:
+		for _, item := range t.items {
+			if err := p.checkTarget(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *listExpr:
+		for _, item := range t.items {
+			if err := p.checkTarget(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return p.errorf(target.position(), "cannot assign to this expression")
+}
+
+// expressionList разбирает одно выражение или кортеж без скобок: a, b
+func (p *parser) expressionList() (expr, error) {
+	pos := p.peek().pos
+	first, err := p.test()
+	if err != nil {
+		return nil, err
+	}
+	if !p.is(",") {
+		return first, nil
+	}
+	items := []expr{first}
+	for p.accept(",") {
+		if p.endOfList() {
+			break
+		}
+		item, err := p.test()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return &tupleExpr{pos: pos, items: items}, nil
+}
+
+// endOfList сообщает, что после запятой список закончился
+func (p *parser) endOfList() bool {
+	tok := p.peek()
+	if tok.kind == tokenNewline || tok.kind == tokenEOF {
+		return true
+	}
+	return tok.kind == tokenOp && (tok.text == "=" || tok.text == ")" || tok.text == "]" || tok.text == "}" || tok.text == ";" || tok.text == ":")
+}
+
+// test — выражение верхнего уровня: условное выражение или lambda
+func (p *parser) test() (expr, error) {
+	if p.is("lambda") {
+		return p.lambda()
+	}
+	x, err := p.orTest()
+	if err != nil {
+		return nil, err
+	}
+	if !p.is("if") {
+		return x, nil
+	}
+	pos := p.take().pos
+	cond, err := p.orTest()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("else"); err != nil {
+		return nil, err
+	}
+	otherwise, err := p.test()
+	if err != nil {
+		return nil, err
+	}
+	return &condExpr{pos: pos, cond: cond, then: x, otherwise: otherwise}, nil
+}
+
+func (p *parser) lambda() (expr, error) {
+	pos := p.take().pos
+	params, err := p.params(":")
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	p.inFunction++
+	body, err := p.test()
+	p.inFunction--
+	if err != nil {
+		return nil, err
+	}
+	return &lambdaExpr{pos: pos, params: params, body: body}, nil
+}
+
+func (p *parser) orTest() (expr, error) {
+	x, err := p.andTest()
+	if err != nil {
+		return nil, err
+	}
+	for p.is("or") {
+		pos := p.take().pos
+		y, err := p.andTest()
+		if err != nil {
+			return nil, err
+		}
+		x = &binaryExpr{pos: pos, op: "or", left: x, right: y}
+	}
+	return x, nil
+}
+
+func (p *parser) andTest() (expr, error) {
+	x, err := p.notTest()
+	if err != nil {
+		return nil, err
+	}
+	for p.is("and") {
+		pos := p.take().pos
+		y, err := p.notTest()
+		if err != nil {
+			return nil, err
+		}
+		x = &binaryExpr{pos: pos, op: "and", left: x, right: y}
+	}
+	return x, nil
+}
+
+func (p *parser) notTest() (expr, error) {
+	if p.is("not") {
+		pos := p.take().pos
+		x, err := p.notTest()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryExpr{pos: pos, op: "not", operand: x}, nil
+	}
+	return p.comparison()
+}
+
+var comparisons = map[string]bool{"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true}
+
+// comparison разбирает сравнение; как и в Starlark, цепочки a < b < c запрещены
+func (p *parser) comparison() (expr, error) {
+	x, err := p.arith()
+	if err != nil {
+		return nil, err
+	}
+	op, pos, ok := p.comparisonOperator()
+	if !ok {
+		return x, nil
+	}
+	y, err := p.arith()
+	if err != nil {
+		return nil, err
+	}
+	if _, next, chained := p.comparisonOperator(); chained {
+		return nil, p.errorf(next, "comparison operators cannot be chained: join separate comparisons with and")
+	}
+	return &binaryExpr{pos: pos, op: op, left: x, right: y}, nil
+}
+
+// comparisonOperator забирает оператор сравнения, включая in и not in
+func (p *parser) comparisonOperator() (string, Pos, bool) {
+	tok := p.peek()
+	switch {
+	case tok.kind == tokenOp && comparisons[tok.text], p.is("in"):
+		p.take()
+		return tok.text, tok.pos, true
+	case p.is("not") && p.tokens[p.next+1].kind == tokenKeyword && p.tokens[p.next+1].text == "in":
+		p.take()
+		p.take()
+		return "not in", tok.pos, true
+	}
+	return "", Pos{}, false
+}
+
+func (p *parser) arith() (expr, error) {
+	x, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for p.is("+") || p.is("-") {
+		tok := p.take()
+		y, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		x = &binaryExpr{pos: tok.pos, op: tok.text, left: x, right: y}
+	}
+	return x, nil
+}
+
+func (p *parser) term() (expr, error) {
+	x, err := p.factor()
+	if err != nil {
+		return nil, err
+	}
+	for p.is("*") || p.is("/") || p.is("//") || p.is("%") {
+		tok := p.take()
+		y, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		x = &binaryExpr{pos: tok.pos, op: tok.text, left: x, right: y}
+	}
+	return x, nil
+}
+
+func (p *parser) factor() (expr, error) {
+	if p.is("-") || p.is("+") {
+		tok := p.take()
+		x, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryExpr{pos: tok.pos, op: tok.text, operand: x}, nil
+	}
+	return p.primary()
+}
+
+// primary разбирает операнд с вызовами, индексами, срезами и обращениями к методам
+func (p *parser) primary() (expr, error) {
+	x, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		switch {
+		case p.accept("("):
+			call := &callExpr{pos: tok.pos, fn: x}
+			if err := p.arguments(call); err != nil {
+				return nil, err
+			}
+			x = call
+		case p.accept("["):
+			if x, err = p.subscript(x, tok.pos); err != nil {
+				return nil, err
+			}
+		case p.accept("."):
+			name := p.peek()
+			if name.kind != tokenName {
+				return nil, p.unexpected("expected attribute name")
+			}
+			p.take()
+			x = &dotExpr{pos: name.pos, operand: x, name: name.text}
+		default:
+			return x, nil
+		}
+	}
+}
+
+func (p *parser) arguments(call *callExpr) error {
+	named := false
+	for !p.is(")") {
+		name := ""
+		if p.peek().kind == tokenName && p.tokens[p.next+1].kind == tokenOp && p.tokens[p.next+1].text == "=" {
+			name = p.take().text
+			p.take()
+			for _, previous := range call.names {
+				if previous == name {
+					return p.errorf(p.peek().pos, "keyword argument %s repeated", name)
+				}
+			}
+			named = true
+		} else if named {
+			return p.errorf(p.peek().pos, "positional argument follows keyword argument")
+		}
+		arg, err := p.test()
+		if err != nil {
+			return err
+		}
+		call.args = append(call.args, arg)
+		call.names = append(call.names, name)
+		if !p.accept(",") {
+			break
+		}
+	}
+	return p.expect(")")
+}
+
+// subscript разбирает x[i] или срез x[start:stop:step] после открывающей скобки
+func (p *parser) subscript(x expr, pos Pos) (expr, error) {
+	var parts [3]expr
+	colons := 0
+	for {
+		if !p.is(":") && !p.is("]") {
+			part, err := p.test()
+			if err != nil {
+				return nil, err
+			}
+			parts[colons] = part
+		}
+		if colons == 2 || !p.accept(":") {
+			break
+		}
+		colons++
+	}
+	if err := p.expect("]"); err != nil {
+		return nil, err
+	}
+	if colons == 0 {
+		if parts[0] == nil {
+			return nil, p.errorf(pos, "empty index")
+		}
+		return &indexExpr{pos: pos, operand: x, index: parts[0]}, nil
+	}
+	return &sliceExpr{pos: pos, operand: x, start: parts[0], stop: parts[1], step: parts[2]}, nil
+}
+
+func (p *parser) operand() (expr, error) {
+	tok := p.peek()
+	switch tok.kind {
+	case tokenName:
+		p.take()
+		if tok.text == "set" && p.is("(") {
+			return nil, p.errorf(tok.pos, "sets are not supported: use a dict or a list")
+		}
+		return &identExpr{pos: tok.pos, name: tok.text}, nil
+	case tokenInt:
+		p.take()
+		return &literalExpr{pos: tok.pos, value: Int(tok.value.(int64))}, nil
+	case tokenFloat:
+		p.take()
+		return &literalExpr{pos: tok.pos, value: Float(tok.value.(float64))}, nil
+	case tokenString:
+		p.take()
+		return &literalExpr{pos: tok.pos, value: String(tok.value.(string))}, nil
+	}
+	switch {
+	case p.accept("("):
+		if p.accept(")") {
+			return &tupleExpr{pos: tok.pos}, nil
+		}
+		x, err := p.test()
+		if err != nil {
+			return nil, err
+		}
+		if p.accept(")") {
+			return x, nil
+		}
+		items := []expr{x}
+		for p.accept(",") && !p.is(")") {
+			item, err := p.test()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return &tupleExpr{pos: tok.pos, items: items}, nil
+	case p.accept("["):
+		return p.list(tok.pos)
+	case p.accept("{"):
+		return p.dict(tok.pos)
+	}
+	return nil, p.unexpected("expected an expression")
+}
+
+func (p *parser) list(pos Pos) (expr, error) {
+	if p.accept("]") {
+		return &listExpr{pos: pos}, nil
+	}
+	first, err := p.test()
+	if err != nil {
+		return nil, err
+	}
+	if p.is("for") {
+		clauses, err := p.clauses()
+		if err != nil {
+			return nil, err
+		}
+		return &comprehensionExpr{pos: pos, body: first, clauses: clauses}, p.expect("]")
+	}
+	items := []expr{first}
+	for p.accept(",") && !p.is("]") {
+		item, err := p.test()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return &listExpr{pos: pos, items: items}, p.expect("]")
+}
+
+func (p *parser) dict(pos Pos) (expr, error) {
+	d := &dictExpr{pos: pos}
+	if p.accept("}") {
+		return d, nil
+	}
+	for {
+		key, err := p.test()
+		if err != nil {
+			return nil, err
+		}
+		if p.is(",") || p.is("}") || (len(d.keys) == 0 && p.is("for")) {
+			return nil, p.errorf(pos, "sets are not supported: use a dict or a list")
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		value, err := p.test()
+		if err != nil {
+			return nil, err
+		}
+		if len(d.keys) == 0 && p.is("for") {
+			clauses, err := p.clauses()
+			if err != nil {
+				return nil, err
+			}
+			return &comprehensionExpr{pos: pos, key: key, body: value, clauses: clauses}, p.expect("}")
+		}
+		d.keys = append(d.keys, key)
+		d.values = append(d.values, value)
+		if !p.accept(",") || p.is("}") {
+			break
+		}
+	}
+	return d, p.expect("}")
+}
+
+// clauses разбирает цепочку for ... in ... и if ... в comprehension
+func (p *parser) clauses() ([]clause, error) {
+	var clauses []clause
+	for {
+		switch {
+		case p.accept("for"):
+			vars, err := p.targets()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("in"); err != nil {
+				return nil, err
+			}
+			// Итерируемое без условного выражения: иначе if следующего условия будет съеден
+			iterable, err := p.orTest()
+			if err != nil {
+				return nil, err
+			}
+			clauses = append(clauses, clause{vars: vars, iterable: iterable})
+		case p.accept("if"):
+			cond, err := p.orTest()
+			if err != nil {
+				return nil, err
+			}
+			clauses = append(clauses, clause{cond: cond})
+		default:
+			return clauses, nil
+		}
+	}
+}
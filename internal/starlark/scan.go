@@ -0,0 +1,376 @@
+package starlark
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNewline
+	tokenIndent
+	tokenDedent
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+	tokenKeyword
+	tokenOp
+)
+
+// Pos — позиция в исходном тексте скрипта
+type Pos struct {
+	Line, Col int
+}
+
+type token struct {
+	kind  tokenKind
+	text  string
+	pos   Pos
+	value interface{} // int64, float64 или string для литералов
+}
+
+var keywords = map[string]bool{
+	"and": true, "break": true, "continue": true, "def": true, "elif": true, "else": true,
+	"for": true, "if": true, "in": true, "lambda": true, "load": true, "not": true,
+	"or": true, "pass": true, "return": true, "while": true,
+}
+
+// Слова Python, которые в Starlark зарезервированы и не могут быть именами
+var reserved = map[string]bool{
+	"as": true, "assert": true, "async": true, "await": true, "class": true, "del": true,
+	"except": true, "finally": true, "from": true, "global": true, "import": true, "is": true,
+	"nonlocal": true, "raise": true, "try": true, "with": true, "yield": true,
+}
+
+// Операторы от длинных к коротким, чтобы "//=" не разобрался как "/" и "/=". Операторы
+// ** и побитовые тоже разбираются на лексемы, чтобы парсер назвал их в ошибке.
+var operators = []string{
+	"//=", "**=", "<<=", ">>=",
+	"**", "<<", ">>", "|=", "&=", "^=",
+	"==", "!=", "<=", ">=", "+=", "-=", "*=", "/=", "%=", "//", "->",
+	"+", "-", "*", "/", "%", "<", ">", "=", "(", ")", "[", "]", "{", "}", ",", ":", ".", ";", "|", "&", "^", "~",
+}
+
+// scanner разбивает исходный текст на лексемы, включая отступы и переводы строк
+type scanner struct {
+	filename string
+	src      string
+	offset   int
+	line     int
+	col      int
+	// Вложенность скобок: внутри них переводы строк и отступы не значимы
+	depth   int
+	indents []int
+	tokens  []token
+}
+
+func scan(filename, src string) ([]token, error) {
+	s := &scanner{filename: filename, src: src, line: 1, col: 1, indents: []int{0}}
+	if err := s.run(); err != nil {
+		return nil, err
+	}
+	return s.tokens, nil
+}
+
+func (s *scanner) errorf(pos Pos, format string, args ...interface{}) error {
+	return &Error{Filename: s.filename, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (s *scanner) emit(kind tokenKind, text string, pos Pos, value interface{}) {
+	s.tokens = append(s.tokens, token{kind: kind, text: text, pos: pos, value: value})
+}
+
+func (s *scanner) peek(n int) byte {
+	if s.offset+n < len(s.src) {
+		return s.src[s.offset+n]
+	}
+	return 0
+}
+
+func (s *scanner) advance(n int) {
+	for i := 0; i < n && s.offset < len(s.src); i++ {
+		if s.src[s.offset] == '\n' {
+			s.line++
+			s.col = 1
+		} else {
+			s.col++
+		}
+		s.offset++
+	}
+}
+
+func (s *scanner) pos() Pos {
+	return Pos{Line: s.line, Col: s.col}
+}
+
+func (s *scanner) run() error {
+	atLineStart := true
+	for {
+		if atLineStart && s.depth == 0 {
+			blank, err := s.indentation()
+			if err != nil {
+				return err
+			}
+			if blank {
+				continue
+			}
+			atLineStart = false
+		}
+		if s.offset >= len(s.src) {
+			break
+		}
+		c := s.peek(0)
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			s.advance(1)
+		case c == '#':
+			for s.offset < len(s.src) && s.peek(0) != '\n' {
+				s.advance(1)
+			}
+		case c == '\\' && s.peek(1) == '\n':
+			s.advance(2)
+		case c == '\n':
+			if s.depth == 0 {
+				s.emit(tokenNewline, "newline", s.pos(), nil)
+				atLineStart = true
+			}
+			s.advance(1)
+		case isIdentStart(c):
+			if err := s.scanName(); err != nil {
+				return err
+			}
+		case c >= '0' && c <= '9' || c == '.' && s.peek(1) >= '0' && s.peek(1) <= '9':
+			if err := s.scanNumber(); err != nil {
+				return err
+			}
+		case c == '"' || c == '\'':
+			if err := s.scanString(false); err != nil {
+				return err
+			}
+		default:
+			if err := s.scanOperator(); err != nil {
+				return err
+			}
+		}
+	}
+	if n := len(s.tokens); n > 0 && s.tokens[n-1].kind != tokenNewline && s.tokens[n-1].kind != tokenDedent {
+		s.emit(tokenNewline, "newline", s.pos(), nil)
+	}
+	for len(s.indents) > 1 {
+		s.indents = s.indents[:len(s.indents)-1]
+		s.emit(tokenDedent, "dedent", s.pos(), nil)
+	}
+	s.emit(tokenEOF, "end of file", s.pos(), nil)
+	return nil
+}
+
+// indentation читает отступ в начале строки и выдаёт INDENT или DEDENT;
+// пустые строки и строки из одного комментария пропускаются целиком
+func (s *scanner) indentation() (blank bool, err error) {
+	start := s.pos()
+	width := 0
+	for {
+		switch s.peek(0) {
+		case ' ':
+			width++
+			s.advance(1)
+			continue
+		case '\t':
+			return false, s.errorf(s.pos(), "use spaces for indentation, not tabs")
+		case '\r':
+			s.advance(1)
+			continue
+		}
+		break
+	}
+	switch c := s.peek(0); {
+	case s.offset >= len(s.src):
+		return false, nil
+	case c == '\n':
+		s.advance(1)
+		return true, nil
+	case c == '#':
+		for s.offset < len(s.src) && s.peek(0) != '\n' {
+			s.advance(1)
+		}
+		s.advance(1)
+		return true, nil
+	}
+	current := s.indents[len(s.indents)-1]
+	switch {
+	case width > current:
+		s.indents = append(s.indents, width)
+		s.emit(tokenIndent, "indent", start, nil)
+	case width < current:
+		for width < s.indents[len(s.indents)-1] {
+			s.indents = s.indents[:len(s.indents)-1]
+			s.emit(tokenDedent, "dedent", start, nil)
+		}
+		if width != s.indents[len(s.indents)-1] {
+			return false, s.errorf(s.pos(), "unindent does not match any outer indentation level")
+		}
+	}
+	return false, nil
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || c >= '0' && c <= '9'
+}
+
+func (s *scanner) scanName() error {
+	pos := s.pos()
+	start := s.offset
+	for s.offset < len(s.src) && isIdentPart(s.peek(0)) {
+		s.advance(1)
+	}
+	name := s.src[start:s.offset]
+	// Префикс r перед кавычкой — сырая строка без обработки экранирования
+	if name == "r" && (s.peek(0) == '"' || s.peek(0) == '\'') {
+		s.offset, s.col = start, pos.Col
+		s.advance(1)
+		return s.scanString(true)
+	}
+	switch {
+	case keywords[name]:
+		s.emit(tokenKeyword, name, pos, nil)
+	case reserved[name]:
+		return s.errorf(pos, "keyword %s is reserved", name)
+	default:
+		s.emit(tokenName, name, pos, nil)
+	}
+	return nil
+}
+
+func (s *scanner) scanNumber() error {
+	pos := s.pos()
+	start := s.offset
+	isFloat := false
+	if s.peek(0) == '0' && (s.peek(1) == 'x' || s.peek(1) == 'X' || s.peek(1) == 'o' || s.peek(1) == 'O' || s.peek(1) == 'b' || s.peek(1) == 'B') {
+		s.advance(2)
+		for isIdentPart(s.peek(0)) {
+			s.advance(1)
+		}
+	} else {
+		for s.peek(0) >= '0' && s.peek(0) <= '9' {
+			s.advance(1)
+		}
+		if s.peek(0) == '.' {
+			isFloat = true
+			s.advance(1)
+			for s.peek(0) >= '0' && s.peek(0) <= '9' {
+				s.advance(1)
+			}
+		}
+		if s.peek(0) == 'e' || s.peek(0) == 'E' {
+			isFloat = true
+			s.advance(1)
+			if s.peek(0) == '+' || s.peek(0) == '-' {
+				s.advance(1)
+			}
+			for s.peek(0) >= '0' && s.peek(0) <= '9' {
+				s.advance(1)
+			}
+		}
+	}
+	text := s.src[start:s.offset]
+	if isFloat {
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return s.errorf(pos, "invalid float literal %s", text)
+		}
+		s.emit(tokenFloat, text, pos, f)
+		return nil
+	}
+	if len(text) > 1 && text[0] == '0' && text[1] >= '0' && text[1] <= '9' {
+		return s.errorf(pos, "invalid int literal %s: use 0o prefix for octal", text)
+	}
+	i, err := strconv.ParseInt(text, 0, 64)
+	if err != nil {
+		return s.errorf(pos, "invalid int literal %s", text)
+	}
+	s.emit(tokenInt, text, pos, i)
+	return nil
+}
+
+func (s *scanner) scanString(raw bool) error {
+	pos := s.pos()
+	quote := s.src[s.offset : s.offset+1]
+	triple := strings.HasPrefix(s.src[s.offset:], strings.Repeat(quote, 3))
+	if triple {
+		quote = strings.Repeat(quote, 3)
+	}
+	s.advance(len(quote))
+	var b strings.Builder
+	for {
+		if s.offset >= len(s.src) {
+			return s.errorf(pos, "unterminated string literal")
+		}
+		if strings.HasPrefix(s.src[s.offset:], quote) {
+			s.advance(len(quote))
+			break
+		}
+		c := s.peek(0)
+		if c == '\n' && !triple {
+			return s.errorf(pos, "unterminated string literal")
+		}
+		if c != '\\' {
+			b.WriteByte(c)
+			s.advance(1)
+			continue
+		}
+		next := s.peek(1)
+		if raw {
+			// В сырой строке обратная косая черта остаётся, но экранирует кавычку
+			b.WriteByte(c)
+			b.WriteByte(next)
+			s.advance(2)
+			continue
+		}
+		s.advance(2)
+		switch next {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case '0':
+			b.WriteByte(0)
+		case '\\', '\'', '"':
+			b.WriteByte(next)
+		case '\n':
+		default:
+			return s.errorf(pos, "invalid escape sequence \\%c", next)
+		}
+	}
+	s.emit(tokenString, "string", pos, b.String())
+	return nil
+}
+
+func (s *scanner) scanOperator() error {
+	pos := s.pos()
+	for _, op := range operators {
+		if strings.HasPrefix(s.src[s.offset:], op) {
+			switch op {
+			case "(", "[", "{":
+				s.depth++
+			case ")", "]", "}":
+				if s.depth > 0 {
+					s.depth--
+				}
+			}
+			s.advance(len(op))
+			s.emit(tokenOp, op, pos, nil)
+			return nil
+		}
+	}
+	return s.errorf(pos, "unexpected character %q", s.peek(0))
+}
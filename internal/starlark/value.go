@@ -0,0 +1,398 @@
+package starlark
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Value — значение Starlark
+type Value interface {
+	// Type возвращает имя типа, как его вернёт type()
+	Type() string
+	// String возвращает представление, как его вернёт repr()
+	String() string
+	Truth() bool
+}
+
+type (
+	NoneType struct{}
+	Bool     bool
+	Int      int64
+	Float    float64
+	String   string
+	Tuple    []Value
+)
+
+// None — единственное значение NoneType
+var None = NoneType{}
+
+// List — изменяемый список; после заморозки или во время обхода менять его нельзя
+type List struct {
+	elems     []Value
+	frozen    bool
+	iterating int
+}
+
+// Dict — словарь, сохраняющий порядок добавления ключей
+type Dict struct {
+	keys      []Value
+	values    []Value
+	index     map[string]int
+	frozen    bool
+	iterating int
+}
+
+// rangeValue — ленивая последовательность range(): большой диапазон не занимает памяти
+type rangeValue struct {
+	start, stop, step int64
+}
+
+func (NoneType) Type() string     { return "NoneType" }
+func (NoneType) String() string   { return "None" }
+func (NoneType) Truth() bool      { return false }
+func (b Bool) Type() string       { return "bool" }
+func (b Bool) Truth() bool        { return bool(b) }
+func (i Int) Type() string        { return "int" }
+func (i Int) String() string      { return strconv.FormatInt(int64(i), 10) }
+func (i Int) Truth() bool         { return i != 0 }
+func (f Float) Type() string      { return "float" }
+func (f Float) Truth() bool       { return f != 0 }
+func (s String) Type() string     { return "string" }
+func (s String) String() string   { return strconv.Quote(string(s)) }
+func (s String) Truth() bool      { return s != "" }
+func (t Tuple) Type() string      { return "tuple" }
+func (t Tuple) Truth() bool       { return len(t) > 0 }
+func (l *List) Type() string      { return "list" }
+func (l *List) Truth() bool       { return len(l.elems) > 0 }
+func (d *Dict) Type() string      { return "dict" }
+func (d *Dict) Truth() bool       { return len(d.keys) > 0 }
+func (r rangeValue) Type() string { return "range" }
+func (r rangeValue) Truth() bool  { return r.Len() > 0 }
+
+func (b Bool) String() string {
+	if b {
+		return "True"
+	}
+	return "False"
+}
+
+func (f Float) String() string {
+	switch {
+	case math.IsInf(float64(f), 1):
+		return "+inf"
+	case math.IsInf(float64(f), -1):
+		return "-inf"
+	case math.IsNaN(float64(f)):
+		return "nan"
+	}
+	s := strconv.FormatFloat(float64(f), 'g', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
+
+func (t Tuple) String() string {
+	if len(t) == 1 {
+		return "(" + t[0].String() + ",)"
+	}
+	return "(" + joinValues(t) + ")"
+}
+
+func (l *List) String() string { return "[" + joinValues(l.elems) + "]" }
+
+func (d *Dict) String() string {
+	parts := make([]string, len(d.keys))
+	for i, key := range d.keys {
+		parts[i] = key.String() + ": " + d.values[i].String()
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+func (r rangeValue) String() string {
+	if r.step == 1 {
+		return fmt.Sprintf("range(%d, %d)", r.start, r.stop)
+	}
+	return fmt.Sprintf("range(%d, %d, %d)", r.start, r.stop, r.step)
+}
+
+func joinValues(values []Value) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = v.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+// NewList создаёт список из элементов
+func NewList(elems []Value) *List {
+	return &List{elems: elems}
+}
+
+// Len возвращает число элементов
+func (l *List) Len() int { return len(l.elems) }
+
+// Index возвращает элемент i
+func (l *List) Index(i int) Value { return l.elems[i] }
+
+func (l *List) checkMutable() error {
+	if l.frozen {
+		return fmt.Errorf("cannot modify frozen list")
+	}
+	if l.iterating > 0 {
+		return fmt.Errorf("cannot modify list during iteration")
+	}
+	return nil
+}
+
+// NewDict создаёт пустой словарь
+func NewDict() *Dict {
+	return &Dict{index: map[string]int{}}
+}
+
+// Len возвращает число ключей
+func (d *Dict) Len() int { return len(d.keys) }
+
+// Get возвращает значение по ключу
+func (d *Dict) Get(key Value) (Value, bool, error) {
+	k, err := hashKey(key)
+	if err != nil {
+		return nil, false, err
+	}
+	if i, ok := d.index[k]; ok {
+		return d.values[i], true, nil
+	}
+	return nil, false, nil
+}
+
+// SetKey записывает значение по ключу
+func (d *Dict) SetKey(key, value Value) error {
+	if err := d.checkMutable(); err != nil {
+		return err
+	}
+	k, err := hashKey(key)
+	if err != nil {
+		return err
+	}
+	if i, ok := d.index[k]; ok {
+		d.values[i] = value
+		return nil
+	}
+	d.index[k] = len(d.keys)
+	d.keys = append(d.keys, key)
+	d.values = append(d.values, value)
+	return nil
+}
+
+func (d *Dict) delete(key Value) (Value, bool, error) {
+	if err := d.checkMutable(); err != nil {
+		return nil, false, err
+	}
+	k, err := hashKey(key)
+	if err != nil {
+		return nil, false, err
+	}
+	i, ok := d.index[k]
+	if !ok {
+		return nil, false, nil
+	}
+	value := d.values[i]
+	d.keys = append(d.keys[:i:i], d.keys[i+1:]...)
+	d.values = append(d.values[:i:i], d.values[i+1:]...)
+	delete(d.index, k)
+	for key, j := range d.index {
+		if j > i {
+			d.index[key] = j - 1
+		}
+	}
+	return value, true, nil
+}
+
+func (d *Dict) checkMutable() error {
+	if d.frozen {
+		return fmt.Errorf("cannot modify frozen dict")
+	}
+	if d.iterating > 0 {
+		return fmt.Errorf("cannot modify dict during iteration")
+	}
+	return nil
+}
+
+func (r rangeValue) Len() int {
+	var n int64
+	switch {
+	case r.step > 0 && r.start < r.stop:
+		n = (r.stop - r.start + r.step - 1) / r.step
+	case r.step < 0 && r.start > r.stop:
+		n = (r.start - r.stop - r.step - 1) / -r.step
+	}
+	return int(n)
+}
+
+func (r rangeValue) index(i int) Value {
+	return Int(r.start + int64(i)*r.step)
+}
+
+// hashKey возвращает ключ словаря для значения; изменяемые значения ключами быть не могут.
+// Целое и равное ему дробное дают один ключ, как и требует равенство 1 == 1.0.
+func hashKey(v Value) (string, error) {
+	switch v := v.(type) {
+	case NoneType:
+		return "n", nil
+	case Bool:
+		return "b" + v.String(), nil
+	case Int:
+		return "i" + v.String(), nil
+	case Float:
+		if f := float64(v); f == math.Trunc(f) && math.Abs(f) < 1<<63 {
+			return "i" + strconv.FormatInt(int64(f), 10), nil
+		}
+		return "f" + v.String(), nil
+	case String:
+		return "s" + string(v), nil
+	case Tuple:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			k, err := hashKey(item)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = strconv.Quote(k)
+		}
+		return "t" + strings.Join(parts, ","), nil
+	}
+	return "", fmt.Errorf("unhashable type: %s", v.Type())
+}
+
+// freeze делает значение и всё достижимое из него неизменяемым
+func freeze(v Value) {
+	switch v := v.(type) {
+	case *List:
+		if v.frozen {
+			return
+		}
+		v.frozen = true
+		for _, item := range v.elems {
+			freeze(item)
+		}
+	case *Dict:
+		if v.frozen {
+			return
+		}
+		v.frozen = true
+		for _, item := range v.values {
+			freeze(item)
+		}
+	case Tuple:
+		for _, item := range v {
+			freeze(item)
+		}
+	}
+}
+
+// FromGo переводит дерево разобранного YAML или JSON в значения Starlark; результат заморожен,
+// так что скрипт не может изменить проверяемый документ
+func FromGo(v interface{}) Value {
+	value := fromGo(v)
+	freeze(value)
+	return value
+}
+
+func fromGo(v interface{}) Value {
+	switch v := v.(type) {
+	case nil:
+		return None
+	case Value:
+		return v
+	case bool:
+		return Bool(v)
+	case int:
+		return Int(v)
+	case int64:
+		return Int(v)
+	case uint64:
+		if v > math.MaxInt64 {
+			return Float(v)
+		}
+		return Int(v)
+	case float64:
+		return Float(v)
+	case string:
+		return String(v)
+	case []interface{}:
+		elems := make([]Value, len(v))
+		for i, item := range v {
+			elems[i] = fromGo(item)
+		}
+		return NewList(elems)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		// Порядок ключей Go-словаря случаен; сортировка делает обход в скрипте воспроизводимым
+		sort.Strings(keys)
+		d := NewDict()
+		for _, key := range keys {
+			d.SetKey(String(key), fromGo(v[key]))
+		}
+		return d
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			converted[fmt.Sprint(key)] = value
+		}
+		return fromGo(converted)
+	}
+	return String(fmt.Sprint(v))
+}
+
+// ToGo переводит значение Starlark в значения Go того же вида, что даёт разбор YAML
+func ToGo(v Value) interface{} {
+	switch v := v.(type) {
+	case NoneType:
+		return nil
+	case Bool:
+		return bool(v)
+	case Int:
+		return int(v)
+	case Float:
+		return float64(v)
+	case String:
+		return string(v)
+	case Tuple:
+		return toGoSlice(v)
+	case *List:
+		return toGoSlice(v.elems)
+	case *Dict:
+		m := make(map[string]interface{}, len(v.keys))
+		for i, key := range v.keys {
+			if s, ok := key.(String); ok {
+				m[string(s)] = ToGo(v.values[i])
+			} else {
+				m[key.String()] = ToGo(v.values[i])
+			}
+		}
+		return m
+	}
+	return v.String()
+}
+
+func toGoSlice(values []Value) []interface{} {
+	items := make([]interface{}, len(values))
+	for i, item := range values {
+		items[i] = ToGo(item)
+	}
+	return items
+}
+
+// AsString возвращает строку без кавычек: для строк их содержимое, для остальных значений repr
+func AsString(v Value) string {
+	if s, ok := v.(String); ok {
+		return string(s)
+	}
+	return v.String()
+}
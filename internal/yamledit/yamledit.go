@@ -0,0 +1,531 @@
+// Package yamledit правит YAML по путям к полям, меняя только затронутые участки
+// исходного текста: комментарии, якоря, порядок ключей и отступы остальных строк
+// сохраняются. На нём строятся исправления --fix и форматирование.
+package yamledit
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+	"gopkg.in/yaml.v3"
+)
+
+// Document — текст YAML-файла, возможно из нескольких документов, и его разбор.
+// После каждой правки текст разбирается заново, поэтому пути всегда указывают на актуальное дерево.
+type Document struct {
+	lines []string
+	roots []*yaml.Node
+}
+
+// Parse разбирает исходный текст
+func Parse(source []byte) (*Document, error) {
+	d := &Document{}
+	if err := d.reset(source); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *Document) reset(source []byte) error {
+	var roots []*yaml.Node
+	decoder := yaml.NewDecoder(bytes.NewReader(source))
+	for {
+		root := &yaml.Node{}
+		err := decoder.Decode(root)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		roots = append(roots, root)
+	}
+	d.lines = strings.SplitAfter(string(source), "\n")
+	d.roots = roots
+	return nil
+}
+
+// Bytes возвращает текущий текст
+func (d *Document) Bytes() []byte {
+	return []byte(strings.Join(d.lines, ""))
+}
+
+// Len возвращает число документов в файле
+func (d *Document) Len() int {
+	return len(d.roots)
+}
+
+// Set записывает значение поля. Отсутствующий ключ добавляется в конец блочного отображения.
+// Якорь заменяемого значения сохраняется, тег — нет: он мог бы противоречить новому значению.
+func (d *Document) Set(doc int, path validator.FieldPath, value interface{}) error {
+	text, err := render(value)
+	if err != nil {
+		return err
+	}
+	target, err := d.lookup(doc, path)
+	if err != nil {
+		return err
+	}
+	if target.node == nil {
+		return d.add(target, path, text)
+	}
+
+	node := target.node
+	line := node.Line - 1
+	start := d.offset(node)
+	content, anchor := skipProperties(d.text(line), start)
+	inline := target.key == nil || target.key.Line == node.Line
+	flow := target.parent.Style&yaml.FlowStyle != 0
+	multiline := isBlock(text)
+
+	// Скаляр в одну строку меняем на месте, сохраняя комментарий после него
+	if node.Kind == yaml.ScalarNode && !multiline {
+		if end, ok := scalarEnd(d.text(line), content, flow); ok && d.regionEnd(line, target.indent, node) == line {
+			return d.splice(line, start, line, end, anchor+text)
+		}
+	}
+	if flow {
+		return fmt.Errorf("%s: editing inside flow collections is not supported", path)
+	}
+
+	last := d.regionEnd(line, target.indent, node)
+	lastEnd := len(d.text(last))
+	if last == line {
+		// Комментарий после старого значения остаётся на строке ключа
+		if end, ok := scalarEnd(d.text(line), content, false); ok && node.Kind == yaml.ScalarNode {
+			lastEnd = end
+		}
+	}
+
+	switch {
+	case target.key == nil:
+		// Элемент последовательности: первая строка остаётся после "- "
+		return d.splice(line, start, last, lastEnd, anchor+indentLines(text, node.Column-1, false))
+	case !inline && !multiline:
+		// Блочная коллекция, заменённая скаляром, переезжает на строку ключа
+		keyLine := target.key.Line - 1
+		return d.splice(keyLine, d.colonEnd(target.key), last, lastEnd, " "+text)
+	case !inline:
+		return d.splice(line, start, last, lastEnd, indentLines(text, node.Column-1, false))
+	case !multiline:
+		text = anchor + text
+		if start > 0 && d.text(line)[start-1] != ' ' {
+			text = " " + text
+		}
+		return d.splice(line, start, last, lastEnd, text)
+	default:
+		// Скаляр на строке ключа стал блоком: значение переносится на следующие строки,
+		// якорь и комментарий остаются на строке ключа
+		from := d.colonEnd(target.key)
+		head := ""
+		if anchor != "" {
+			head = " " + strings.TrimSpace(anchor)
+		}
+		tail := ""
+		if last == line {
+			if comment := strings.TrimSpace(d.text(line)[lastEnd:]); comment != "" {
+				tail = " " + comment
+			}
+			lastEnd = len(d.text(line))
+		}
+		if strings.HasPrefix(text, "|") || strings.HasPrefix(text, ">") {
+			header, rest, _ := strings.Cut(text, "\n")
+			return d.splice(line, from, last, lastEnd, head+" "+header+tail+"\n"+indentLines(rest, target.indent, true))
+		}
+		return d.splice(line, from, last, lastEnd, head+tail+"\n"+indentLines(text, target.indent+2, true))
+	}
+}
+
+// Delete удаляет поле отображения или элемент последовательности вместе с комментариями над ним
+func (d *Document) Delete(doc int, path validator.FieldPath) error {
+	target, err := d.lookup(doc, path)
+	if err != nil {
+		return err
+	}
+	if target.node == nil {
+		return nil
+	}
+	if target.parent.Style&yaml.FlowStyle != 0 {
+		return fmt.Errorf("%s: editing inside flow collections is not supported", path)
+	}
+
+	// Элемент, ставший последним, заменяем пустой коллекцией, иначе родитель стал бы null
+	if len(target.parent.Content) == 1 || (target.key != nil && len(target.parent.Content) == 2) {
+		empty := interface{}([]interface{}{})
+		if target.key != nil {
+			empty = map[string]interface{}{}
+		}
+		return d.Set(doc, path.Parent(), empty)
+	}
+
+	first := d.text(target.line())
+	start := d.byteOffset(target.line(), target.column())
+	last := d.regionEnd(target.line(), target.indent, target.node)
+	if strings.TrimSpace(first[:start]) == "" || (target.key == nil && strings.TrimSpace(first[:start]) == "-") {
+		from := d.headComment(target.line(), target.indent)
+		d.lines = append(d.lines[:from], d.lines[last+1:]...)
+		return d.reset(d.Bytes())
+	}
+
+	// Первый ключ отображения на строке "- ": его место занимает следующий ключ
+	if target.key == nil {
+		return fmt.Errorf("%s: nested sequences on one line are not supported", path)
+	}
+	next := target.parent.Content[target.index+2]
+	if next.Line-1 <= last {
+		return fmt.Errorf("%s: cannot delete the field sharing a line with the next one", path)
+	}
+	nextLine := next.Line - 1
+	prefix := first[:start]
+	rest := d.text(nextLine)[d.byteOffset(nextLine, next.Column):]
+	d.lines[nextLine] = prefix + rest + d.newline(nextLine)
+	d.lines = append(d.lines[:target.line()], d.lines[last+1:]...)
+	return d.reset(d.Bytes())
+}
+
+// Rename переименовывает ключ отображения, не трогая значение
+func (d *Document) Rename(doc int, path validator.FieldPath, name string) error {
+	target, err := d.lookup(doc, path)
+	if err != nil {
+		return err
+	}
+	if target.key == nil || target.node == nil {
+		return fmt.Errorf("%s: no such field", path)
+	}
+	if sibling, _ := d.lookup(doc, path.Parent().Field(name)); sibling != nil && sibling.node != nil {
+		return fmt.Errorf("%s: field %q already exists", path.Parent(), name)
+	}
+	text, err := render(name)
+	if err != nil {
+		return err
+	}
+	line := target.key.Line - 1
+	start, _ := skipProperties(d.text(line), d.offset(target.key))
+	end, ok := scalarEnd(d.text(line), start, target.parent.Style&yaml.FlowStyle != 0)
+	if !ok {
+		return fmt.Errorf("%s: multi-line keys are not supported", path)
+	}
+	return d.splice(line, start, line, end, text)
+}
+
+// add добавляет отсутствующий ключ в конец блочного отображения
+func (d *Document) add(target *target, path validator.FieldPath, text string) error {
+	parent := target.parent
+	if target.key == nil {
+		return fmt.Errorf("%s: no such element", path)
+	}
+	if parent.Kind != yaml.MappingNode || parent.Style&yaml.FlowStyle != 0 || len(parent.Content) == 0 {
+		return fmt.Errorf("%s: can only add fields to a non-empty block mapping", path)
+	}
+	name, err := render(target.key.Value)
+	if err != nil {
+		return err
+	}
+	firstKey := parent.Content[0]
+	indent := firstKey.Column - 1
+	lastKey := parent.Content[len(parent.Content)-2]
+	last := d.regionEnd(lastKey.Line-1, indent, parent.Content[len(parent.Content)-1])
+
+	entry := strings.Repeat(" ", indent) + name + ":"
+	if isBlock(text) {
+		entry += "\n" + indentLines(text, indent+2, true)
+	} else {
+		entry += " " + text
+	}
+	newline := d.newline(last)
+	if newline == "" {
+		d.lines[last] += "\n"
+		newline = "\n"
+	}
+	d.lines = append(d.lines[:last+1], append([]string{entry + newline}, d.lines[last+1:]...)...)
+	return d.reset(d.Bytes())
+}
+
+// target — найденное по пути поле и его окружение
+type target struct {
+	parent *yaml.Node
+	// key — узел ключа; nil для элемента последовательности. Для отсутствующего
+	// ключа — узел с одним лишь именем
+	key   *yaml.Node
+	node  *yaml.Node
+	index int
+	// Отступ владельца: ключа либо дефиса элемента
+	indent int
+}
+
+func (t *target) line() int {
+	if t.key != nil {
+		return t.key.Line - 1
+	}
+	return t.node.Line - 1
+}
+
+func (t *target) column() int {
+	if t.key != nil {
+		return t.key.Column
+	}
+	return t.node.Column
+}
+
+func (d *Document) lookup(doc int, path validator.FieldPath) (*target, error) {
+	if doc < 0 || doc >= len(d.roots) {
+		return nil, fmt.Errorf("document %d does not exist", doc)
+	}
+	segments, err := path.Segments()
+	if err != nil {
+		return nil, err
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("empty path")
+	}
+
+	node := d.roots[doc]
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	for i, segment := range segments {
+		if node.Kind == yaml.AliasNode {
+			return nil, fmt.Errorf("%s: path goes through an alias; edit the anchored value instead", path)
+		}
+		found := &target{parent: node}
+		switch {
+		case segment.IsIndex && node.Kind == yaml.SequenceNode:
+			if segment.Index < 0 || segment.Index >= len(node.Content) {
+				return nil, fmt.Errorf("%s: index out of range", path)
+			}
+			found.node, found.index = node.Content[segment.Index], segment.Index
+			found.indent = node.Column - 1
+		case !segment.IsIndex && node.Kind == yaml.MappingNode:
+			for j := 0; j+1 < len(node.Content); j += 2 {
+				if node.Content[j].Value == segment.Key {
+					found.key, found.node, found.index = node.Content[j], node.Content[j+1], j
+				}
+			}
+			if found.key == nil {
+				found.key = &yaml.Node{Kind: yaml.ScalarNode, Value: segment.Key}
+			} else {
+				found.indent = found.key.Column - 1
+			}
+		default:
+			return nil, fmt.Errorf("%s: no such field", path)
+		}
+		if found.node == nil {
+			if i < len(segments)-1 {
+				return nil, fmt.Errorf("%s: no such field", path)
+			}
+			return found, nil
+		}
+		if i == len(segments)-1 {
+			return found, nil
+		}
+		node = found.node
+	}
+	return nil, nil
+}
+
+// regionEnd возвращает последнюю строку значения, начинающегося на строке first:
+// строки значения отступают дальше владельца. Пустые строки и комментарии на уровне
+// владельца и левее относятся к следующему полю.
+func (d *Document) regionEnd(first, indent int, node *yaml.Node) int {
+	compact := node != nil && node.Kind == yaml.SequenceNode && node.Column-1 == indent && node.Line-1 > first
+	last := first
+	for i := first + 1; i < len(d.lines); i++ {
+		text := d.text(i)
+		trimmed := strings.TrimLeft(text, " ")
+		depth := len(text) - len(trimmed)
+		switch {
+		case trimmed == "":
+		case depth > indent:
+			last = i
+		case trimmed[0] == '#':
+		case compact && depth == indent && (trimmed == "-" || strings.HasPrefix(trimmed, "- ")):
+			last = i
+		default:
+			return last
+		}
+	}
+	return last
+}
+
+// headComment возвращает первую строку комментариев, стоящих вплотную над строкой line
+// с тем же отступом
+func (d *Document) headComment(line, indent int) int {
+	for line > 0 {
+		text := d.text(line - 1)
+		trimmed := strings.TrimLeft(text, " ")
+		if !strings.HasPrefix(trimmed, "#") || len(text)-len(trimmed) != indent {
+			break
+		}
+		line--
+	}
+	return line
+}
+
+// colonEnd возвращает смещение сразу после двоеточия за ключом
+func (d *Document) colonEnd(key *yaml.Node) int {
+	line := key.Line - 1
+	text := d.text(line)
+	start, _ := skipProperties(text, d.offset(key))
+	end, _ := scalarEnd(text, start, false)
+	if i := strings.IndexByte(text[end:], ':'); i >= 0 {
+		return end + i + 1
+	}
+	return len(text)
+}
+
+// splice заменяет текст от (fromLine, from) до (toLine, to) и разбирает результат заново
+func (d *Document) splice(fromLine, from, toLine, to int, text string) error {
+	head := d.lines[fromLine][:from]
+	tail := d.lines[toLine][to:]
+	replaced := strings.SplitAfter(head+text+tail, "\n")
+	if replaced[len(replaced)-1] == "" {
+		replaced = replaced[:len(replaced)-1]
+	}
+	lines := append([]string{}, d.lines[:fromLine]...)
+	lines = append(lines, replaced...)
+	lines = append(lines, d.lines[toLine+1:]...)
+
+	previous := d.lines
+	d.lines = lines
+	if err := d.reset(d.Bytes()); err != nil {
+		d.lines = previous
+		return fmt.Errorf("edit produced invalid YAML: %v", err)
+	}
+	return nil
+}
+
+// text возвращает строку без перевода строки
+func (d *Document) text(line int) string {
+	return strings.TrimRight(d.lines[line], "\r\n")
+}
+
+func (d *Document) newline(line int) string {
+	return d.lines[line][len(d.text(line)):]
+}
+
+// offset переводит позицию узла в смещение в байтах внутри строки
+func (d *Document) offset(node *yaml.Node) int {
+	return d.byteOffset(node.Line-1, node.Column)
+}
+
+// byteOffset переводит колонку (с единицы, в символах) в смещение в байтах
+func (d *Document) byteOffset(line, column int) int {
+	runes := []rune(d.lines[line])
+	if column-1 > len(runes) {
+		return len(d.lines[line])
+	}
+	return len(string(runes[:column-1]))
+}
+
+// skipProperties пропускает якорь и тег перед значением; возвращает смещение
+// самого значения и якорь вместе с пробелом после него
+func skipProperties(text string, start int) (int, string) {
+	anchor := ""
+	for start < len(text) && (text[start] == '&' || text[start] == '!') {
+		end := start
+		for end < len(text) && text[end] != ' ' {
+			end++
+		}
+		next := end
+		for next < len(text) && text[next] == ' ' {
+			next++
+		}
+		if text[start] == '&' {
+			anchor = text[start:end] + " "
+		}
+		start = next
+	}
+	return start, anchor
+}
+
+// scalarEnd находит конец скаляра, начинающегося в text со смещения start;
+// ok = false для блочных и многострочных скаляров
+func scalarEnd(text string, start int, flow bool) (int, bool) {
+	if start >= len(text) {
+		return start, false
+	}
+	switch text[start] {
+	case '|', '>':
+		return start, false
+	case '"':
+		for i := start + 1; i < len(text); i++ {
+			switch text[i] {
+			case '\\':
+				i++
+			case '"':
+				return i + 1, true
+			}
+		}
+		return start, false
+	case '\'':
+		for i := start + 1; i < len(text); i++ {
+			if text[i] == '\'' {
+				if i+1 < len(text) && text[i+1] == '\'' {
+					i++
+					continue
+				}
+				return i + 1, true
+			}
+		}
+		return start, false
+	}
+	// Простой скаляр заканчивается перед комментарием или двоеточием ключа
+	end := len(text)
+	for _, stop := range []string{" #", ": "} {
+		if i := strings.Index(text[start:end], stop); i >= 0 {
+			end = start + i
+		}
+	}
+	if strings.HasSuffix(text[start:end], ":") {
+		end--
+	}
+	if flow {
+		if i := strings.IndexAny(text[start:end], ",]}"); i >= 0 {
+			end = start + i
+		}
+	}
+	return start + len(strings.TrimRight(text[start:end], " \t")), true
+}
+
+// render записывает значение в YAML с отступом 2 без завершающего перевода строки
+func render(value interface{}) (string, error) {
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(value); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(out.String(), "\n"), nil
+}
+
+// isBlock сообщает, занимает ли записанное значение отдельные строки: многострочный текст
+// либо блочная коллекция, даже из одного элемента
+func isBlock(text string) bool {
+	if strings.Contains(text, "\n") {
+		return true
+	}
+	var node yaml.Node
+	if yaml.Unmarshal([]byte(text), &node) != nil || len(node.Content) == 0 {
+		return false
+	}
+	value := node.Content[0]
+	return (value.Kind == yaml.MappingNode || value.Kind == yaml.SequenceNode) && value.Style&yaml.FlowStyle == 0
+}
+
+// indentLines добавляет отступ к строкам текста; first — и к первой строке
+func indentLines(text string, indent int, first bool) string {
+	pad := strings.Repeat(" ", indent)
+	lines := strings.Split(text, "\n")
+	for i := range lines {
+		if (i > 0 || first) && lines[i] != "" {
+			lines[i] = pad + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,115 @@
+package yamledit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+// edit — одна правка документа doc файла
+type edit struct {
+	op    string // set, delete или rename
+	doc   int
+	path  validator.FieldPath
+	value interface{}
+}
+
+func (e edit) apply(d *Document) error {
+	switch e.op {
+	case "set":
+		return d.Set(e.doc, e.path, e.value)
+	case "delete":
+		return d.Delete(e.doc, e.path)
+	default:
+		return d.Rename(e.doc, e.path, e.value.(string))
+	}
+}
+
+func TestEdits(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		edit   edit
+		want   string
+		// err — часть ожидаемой ошибки; текст при ошибке не меняется
+		err string
+	}{
+		// Комментарии
+		{"set keeps line comment", "a: 1 # keep\nb: 2\n", edit{"set", 0, "a", 3}, "a: 3 # keep\nb: 2\n", ""},
+		{"set keeps comments around", "# head\na: 1\n# between\nb: 2 # tail\n", edit{"set", 0, "b", "x"}, "# head\na: 1\n# between\nb: x # tail\n", ""},
+		{"delete removes head comment", "# about a\na: 1\n# about b\nb: 2\n", edit{"delete", 0, "b", nil}, "# about a\na: 1\n", ""},
+		{"add before trailing comment", "a: 1\n# end\n", edit{"set", 0, "b", 2}, "a: 1\nb: 2\n# end\n", ""},
+		{"scalar to block keeps comment on key line", "a: 1 # note\nb: 2\n", edit{"set", 0, "a", []interface{}{"x"}}, "a: # note\n  - x\nb: 2\n", ""},
+		{"rename keeps comment", "old: 1 # c\n", edit{"rename", 0, "old", "new"}, "new: 1 # c\n", ""},
+
+		// Якоря
+		{"set keeps anchor", "base: &b 1\nref: *b\n", edit{"set", 0, "base", 2}, "base: &b 2\nref: *b\n", ""},
+		{"block value keeps anchor on key line", "a: &x 1\n", edit{"set", 0, "a", map[string]interface{}{"k": "v"}}, "a: &x\n  k: v\n", ""},
+		{"edit through alias", "base: &b\n  x: 1\nref: *b\n", edit{"set", 0, "ref.x", 2}, "", "through an alias"},
+		{"tag is dropped", "a: !!str 1\n", edit{"set", 0, "a", 2}, "a: 2\n", ""},
+		{"anchor and tag", "a: &x !!str 1 # c\n", edit{"set", 0, "a", 2}, "a: &x 2 # c\n", ""},
+		{"anchored sequence item", "l:\n  - &x !!str 1\n  - *x\n", edit{"set", 0, "l[0]", 2}, "l:\n  - &x 2\n  - *x\n", ""},
+		{"block collection to scalar keeps anchor", "a: &x\n  k: v\nb: *x\n", edit{"set", 0, "a", 1}, "a: &x 1\nb: *x\n", ""},
+
+		// Поточный стиль
+		{"scalar in flow sequence", "list: [1, 2]\n", edit{"set", 0, "list[0]", 5}, "list: [5, 2]\n", ""},
+		{"scalar in flow mapping", "m: {a: 1, b: 2}\n", edit{"set", 0, "m.b", 3}, "m: {a: 1, b: 3}\n", ""},
+		{"replace flow collection", "m: {a: 1}\nb: 2\n", edit{"set", 0, "m", map[string]interface{}{"c": 3}}, "m:\n  c: 3\nb: 2\n", ""},
+		{"delete inside flow", "m: {a: 1, b: 2}\n", edit{"delete", 0, "m.a", nil}, "", "flow collections"},
+		{"add to flow mapping", "m: {a: 1}\n", edit{"set", 0, "m.b", 2}, "", "non-empty block mapping"},
+		{"block value inside flow", "m: {a: 1}\n", edit{"set", 0, "m.a", []interface{}{1, 2}}, "", "flow collections"},
+
+		// CRLF
+		{"set with CRLF", "a: 1\r\nb: 2\r\n", edit{"set", 0, "a", 3}, "a: 3\r\nb: 2\r\n", ""},
+		{"add with CRLF", "a: 1\r\nb: 2\r\n", edit{"set", 0, "c", 3}, "a: 1\r\nb: 2\r\nc: 3\r\n", ""},
+		{"delete with CRLF", "a: 1\r\nb: 2\r\n", edit{"delete", 0, "a", nil}, "b: 2\r\n", ""},
+		{"add without final newline", "a: 1", edit{"set", 0, "b", 2}, "a: 1\nb: 2\n", ""},
+
+		// Несколько документов
+		{"set in second document", "a: 1\n---\na: 1\n", edit{"set", 1, "a", 2}, "a: 1\n---\na: 2\n", ""},
+		{"add to first document", "a: 1\n---\nb: 1\n", edit{"set", 0, "c", 2}, "a: 1\nc: 2\n---\nb: 1\n", ""},
+		{"delete in second document", "a: 1\n---\nb: 1\nc: 2\n", edit{"delete", 1, "b", nil}, "a: 1\n---\nc: 2\n", ""},
+		{"missing document", "a: 1\n---\na: 2\n", edit{"set", 2, "a", 3}, "", "document 2 does not exist"},
+
+		// Последовательности
+		{"delete sequence item", "l:\n  - a\n  - b\n", edit{"delete", 0, "l[0]", nil}, "l:\n  - b\n", ""},
+		{"delete last item leaves empty list", "l:\n  - a\n", edit{"delete", 0, "l[0]", nil}, "l: []\n", ""},
+		{"delete last field leaves empty mapping", "m:\n  a: 1\n", edit{"delete", 0, "m.a", nil}, "m: {}\n", ""},
+		{"rename to existing field", "a: 1\nb: 2\n", edit{"rename", 0, "a", "b"}, "", "already exists"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := Parse([]byte(tt.source))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = tt.edit.apply(d)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.err)
+				}
+				if got := string(d.Bytes()); got != tt.source {
+					t.Errorf("failed edit changed the text:\n%q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(d.Bytes()); got != tt.want {
+				t.Errorf("got:\n%q\nwant:\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLen(t *testing.T) {
+	d, err := Parse([]byte("a: 1\n---\nb: 2\n---\n# only a comment\nc: 3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", d.Len())
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// runJsonnet вычисляет Jsonnet-файл внешним интерпретатором (--jsonnet-bin, CLI go-jsonnet)
+// и проверяет полученные манифесты
+func runJsonnet(args []string) {
+	fs := flag.NewFlagSet("yamlvalid jsonnet", flag.ExitOnError)
+	common := addCommonFlags(fs)
+	binary := fs.String("jsonnet-bin", "jsonnet", "jsonnet interpreter to run (go-jsonnet CLI)")
+	var jpaths, extVars multiFlag
+	fs.Var(&jpaths, "J", "library search directory, may be repeated")
+	fs.Var(&extVars, "ext-str", "external variable var=value, may be repeated")
+	fs.Usage = func() {
+		fmt.Println("Usage: yamlvalid jsonnet [flags] <file.jsonnet>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+
+	filename := fs.Arg(0)
+	s := common.session()
+
+	cmdArgs := []string{}
+	for _, dir := range jpaths {
+		cmdArgs = append(cmdArgs, "-J", dir)
+	}
+	for _, v := range extVars {
+		cmdArgs = append(cmdArgs, "--ext-str", v)
+	}
+	cmdArgs = append(cmdArgs, filename)
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(*binary, cmdArgs...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Printf("Error evaluating jsonnet: %v\n%s", err, stderr.String())
+		// Не удалось запустить интерпретатор — это не ошибка в самом файле
+		var execErr *exec.Error
+		if errors.As(err, &execErr) {
+			os.Exit(exitIO)
+		}
+		os.Exit(exitUnparsable)
+	}
+
+	manifests, err := splitJsonnetOutput(stdout.Bytes())
+	if err != nil {
+		fmt.Printf("Error evaluating jsonnet: %s: %v\n", filename, err)
+		os.Exit(exitUnparsable)
+	}
+
+	// Исходник нужен только для позиций; если он не читается, находки указывают на первую строку
+	source, _ := os.ReadFile(filename)
+	results := s.track(filename, func() []fileResult {
+		var results []fileResult
+		for _, m := range manifests {
+			name := filename + m.name
+			results = append(results, fileResult{
+				file:        name,
+				findings:    s.validate(m.data, name),
+				source:      m.data,
+				mapLine:     jsonnetLines(source, m.data),
+				dropColumns: true,
+			})
+		}
+		return results
+	})
+	s.finish(results)
+}
+
+// Ключ в строке сгенерированного JSON: "name": ...
+var jsonKeyLine = regexp.MustCompile(`^\s*("(?:[^"\\]|\\.)*")\s*:`)
+
+// jsonnetLines переводит строку сгенерированного JSON в строку исходника Jsonnet. Позиции
+// интерпретатор не сообщает, поэтому поле ищется в исходнике по имени ключа и засчитывается,
+// только если такое поле там одно; иначе находка указывает на первую строку файла.
+func jsonnetLines(source, generated []byte) func(line int) int {
+	generatedLines := strings.Split(string(generated), "\n")
+	sourceLines := strings.Split(string(source), "\n")
+	return func(line int) int {
+		if line < 1 || line > len(generatedLines) {
+			return 1
+		}
+		match := jsonKeyLine.FindStringSubmatch(generatedLines[line-1])
+		if match == nil {
+			return 1
+		}
+		var key string
+		if err := json.Unmarshal([]byte(match[1]), &key); err != nil {
+			return 1
+		}
+		quoted := regexp.QuoteMeta(key)
+		// Поле Jsonnet: name, "name" или 'name', затем :, ::, ::: или +:
+		field := regexp.MustCompile(`(^|[\s{,])(` + quoted + `|"` + quoted + `"|'` + quoted + `')\s*\+?:`)
+		found := 0
+		for i, text := range sourceLines {
+			trimmed := strings.TrimSpace(text)
+			if strings.HasPrefix(trimmed, "//") || strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+			if field.MatchString(text) {
+				if found != 0 {
+					return 1
+				}
+				found = i + 1
+			}
+		}
+		if found == 0 {
+			return 1
+		}
+		return found
+	}
+}
+
+type jsonnetManifest struct {
+	name string
+	data []byte
+}
+
+// splitJsonnetOutput разбирает результат: один манифест, массив манифестов
+// или объект вида {"file.yaml": {...}}, как при jsonnet -m. Объект делится по ключам,
+// только если каждое его значение — манифест; иначе он проверяется как один манифест,
+// в котором, например, забыт apiVersion.
+func splitJsonnetOutput(output []byte) ([]jsonnetManifest, error) {
+	var value interface{}
+	if err := json.Unmarshal(output, &value); err != nil {
+		return nil, err
+	}
+
+	var manifests []jsonnetManifest
+	add := func(name string, v interface{}) error {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		manifests = append(manifests, jsonnetManifest{name: name, data: data})
+		return nil
+	}
+
+	switch val := value.(type) {
+	case []interface{}:
+		for i, item := range val {
+			if err := add(fmt.Sprintf("[%d]", i), item); err != nil {
+				return nil, err
+			}
+		}
+	case map[string]interface{}:
+		if isJsonnetManifest(val) || !allJsonnetManifests(val) {
+			return manifests, add("", val)
+		}
+		keys := make([]string, 0, len(val))
+		for key := range val {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := add("["+key+"]", val[key]); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("expected an object or an array of manifests")
+	}
+	return manifests, nil
+}
+
+// isJsonnetManifest сообщает, что значение похоже на манифест Kubernetes
+func isJsonnetManifest(v interface{}) bool {
+	object, ok := v.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	_, hasAPIVersion := object["apiVersion"]
+	_, hasKind := object["kind"]
+	return hasAPIVersion || hasKind
+}
+
+// allJsonnetManifests сообщает, что объект — набор манифестов по именам файлов
+func allJsonnetManifests(object map[string]interface{}) bool {
+	if len(object) == 0 {
+		return false
+	}
+	for _, v := range object {
+		if !isJsonnetManifest(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// multiFlag собирает значения повторяющегося флага
+type multiFlag []string
+
+func (m *multiFlag) String() string {
+	return strings.Join(*m, ",")
+}
+
+func (m *multiFlag) Set(value string) error {
+	*m = append(*m, value)
+	return nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitJsonnetOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []string
+	}{
+		{"single manifest", `{"apiVersion": "v1", "kind": "ConfigMap"}`, []string{""}},
+		{"array", `[{"kind": "ConfigMap"}, {"kind": "Service"}]`, []string{"[0]", "[1]"}},
+		{"multi-file object", `{"b.yaml": {"apiVersion": "v1", "kind": "Service"}, "a.yaml": {"apiVersion": "v1", "kind": "ConfigMap"}}`,
+			[]string{"[a.yaml]", "[b.yaml]"}},
+		// Манифест без apiVersion не делится по ключам metadata, data и т. п.
+		{"manifest missing apiVersion", `{"kind": "ConfigMap", "metadata": {"name": "a"}, "data": {"k": "v"}}`, []string{""}},
+		{"object of plain values", `{"metadata": {"name": "a"}, "spec": {"replicas": 1}}`, []string{""}},
+		{"mixed object", `{"a.yaml": {"apiVersion": "v1", "kind": "ConfigMap"}, "notes": "text"}`, []string{""}},
+		{"empty object", `{}`, []string{""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifests, err := splitJsonnetOutput([]byte(tt.output))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, m := range manifests {
+				names = append(names, m.name)
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("got manifests %q, want %q", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitJsonnetOutputRejectsScalars(t *testing.T) {
+	if _, err := splitJsonnetOutput([]byte(`"text"`)); err == nil {
+		t.Error("expected an error for a string result")
+	}
+}
+
+func TestJsonnetLines(t *testing.T) {
+	source := []byte(`local app = 'web';
+{
+  apiVersion: 'apps/v1',
+  kind: 'Deployment',
+  metadata: { name: app },
+  spec: {
+    // image: закомментированное поле не считается
+    template: { spec: { containers: [
+      { name: app, "image": 'nginx', ports+: [] },
+    ] } },
+  },
+}
+`)
+	manifests, err := splitJsonnetOutput([]byte(`{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "web"},
+		"spec": {"template": {"spec": {"containers": [{"name": "web", "image": "nginx", "ports": []}]}}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	generated := manifests[0].data
+	lineOf := func(key string) int {
+		for i, line := range strings.Split(string(generated), "\n") {
+			if strings.Contains(line, `"`+key+`":`) {
+				return i + 1
+			}
+		}
+		t.Fatalf("no %s in the generated JSON", key)
+		return 0
+	}
+
+	mapLine := jsonnetLines(source, generated)
+	tests := []struct {
+		key  string
+		want int
+	}{
+		{"kind", 4},
+		{"metadata", 5},
+		// Ключ в кавычках и поле с +:
+		{"image", 9},
+		{"ports", 9},
+		// Поле name в исходнике дважды: строка неизвестна
+		{"name", 1},
+	}
+	for _, tt := range tests {
+		if got := mapLine(lineOf(tt.key)); got != tt.want {
+			t.Errorf("%s: line %d, want %d", tt.key, got, tt.want)
+		}
+	}
+	// Строка без ключа и строка за пределами документа
+	if got := mapLine(1); got != 1 {
+		t.Errorf("opening brace: line %d, want 1", got)
+	}
+	if got := mapLine(1000); got != 1 {
+		t.Errorf("line past the end: line %d, want 1", got)
+	}
+	// Без исходника находки указывают на первую строку
+	if got := jsonnetLines(nil, generated)(lineOf("kind")); got != 1 {
+		t.Errorf("without source: line %d, want 1", got)
+	}
+}
@@ -0,0 +1,462 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/textproto"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+// Коды ошибок JSON-RPC
+const (
+	lspParseError     = -32700
+	lspMethodNotFound = -32601
+	lspInvalidParams  = -32602
+)
+
+// lspRequest — входящий запрос или уведомление; у уведомлений нет ID
+type lspRequest struct {
+	ID     *json.RawMessage `json:"id"`
+	Method string           `json:"method"`
+	Params json.RawMessage  `json:"params"`
+}
+
+type lspResponse struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Result  interface{}      `json:"result"`
+}
+
+type lspErrorResponse struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Error   lspError         `json:"error"`
+}
+
+type lspError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type lspNotification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+// lspPosition — позиция в документе с нуля; character считается в символах строки
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+type lspLocation struct {
+	URI   string   `json:"uri"`
+	Range lspRange `json:"range"`
+}
+
+type lspTextDocument struct {
+	URI  string `json:"uri"`
+	Text string `json:"text"`
+}
+
+type lspPositionParams struct {
+	TextDocument lspTextDocument `json:"textDocument"`
+	Position     lspPosition     `json:"position"`
+}
+
+type lspDiagnostic struct {
+	Range              lspRange                `json:"range"`
+	Severity           int                     `json:"severity"`
+	Code               string                  `json:"code"`
+	CodeDescription    *lspCodeDescription     `json:"codeDescription,omitempty"`
+	Source             string                  `json:"source"`
+	Message            string                  `json:"message"`
+	RelatedInformation []lspRelatedInformation `json:"relatedInformation,omitempty"`
+}
+
+type lspCodeDescription struct {
+	Href string `json:"href"`
+}
+
+type lspRelatedInformation struct {
+	Location lspLocation `json:"location"`
+	Message  string      `json:"message"`
+}
+
+type lspMarkup struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+// lspServer обслуживает редактор по Language Server Protocol через stdin и stdout
+type lspServer struct {
+	s   *session
+	out io.Writer
+	mu  sync.Mutex
+	// Открытые документы по URI; синхронизация полная — редактор присылает весь текст
+	documents map[string][]byte
+	shutdown  bool
+	// Каталоги рабочей области и YAML-файлы в них по путям, как они лежат на диске
+	roots []string
+	files map[string][]byte
+	// Редактор умеет подписывать сервер на изменения файлов на диске
+	watch bool
+	// URI, для которых отправлены непустые диагностики, — их нужно очистить, когда находки исчезнут
+	published map[string]bool
+}
+
+// runLSP запускает языковой сервер: диагностики открытых документов и файлов рабочей
+// области с проверкой связей между ними, описание поля под курсором и быстрые исправления
+func runLSP(args []string) {
+	flagSet := flag.NewFlagSet("yamlvalid lsp", flag.ExitOnError)
+	common := addCommonFlags(flagSet)
+	flagSet.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: yamlvalid lsp [flags]")
+		flagSet.PrintDefaults()
+	}
+	flagSet.Parse(args)
+	if flagSet.NArg() != 0 {
+		flagSet.Usage()
+		os.Exit(exitUsage)
+	}
+
+	server := &lspServer{s: common.session(), out: os.Stdout, documents: map[string][]byte{}, files: map[string][]byte{}, published: map[string]bool{}}
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		body, err := readLSPMessage(reader)
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading message: %v\n", err)
+			os.Exit(exitIO)
+		}
+		server.handle(body)
+	}
+}
+
+// readLSPMessage читает одно сообщение с заголовком Content-Length
+func readLSPMessage(r *bufio.Reader) ([]byte, error) {
+	header, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		if err == io.EOF {
+			return nil, err
+		}
+		return nil, fmt.Errorf("invalid header: %v", err)
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid Content-Length %q", header.Get("Content-Length"))
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+func (l *lspServer) send(message interface{}) {
+	body, err := json.Marshal(message)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error encoding message: %v\n", err)
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Fprintf(l.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+func (l *lspServer) reply(id *json.RawMessage, result interface{}) {
+	l.send(lspResponse{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+func (l *lspServer) fail(id *json.RawMessage, code int, message string) {
+	l.send(lspErrorResponse{JSONRPC: "2.0", ID: id, Error: lspError{Code: code, Message: message}})
+}
+
+func (l *lspServer) handle(body []byte) {
+	var request lspRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		l.fail(nil, lspParseError, err.Error())
+		return
+	}
+
+	switch request.Method {
+	case "initialize":
+		var params lspInitializeParams
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			l.fail(request.ID, lspInvalidParams, err.Error())
+			return
+		}
+		l.roots = params.workspaceRoots()
+		l.watch = params.Capabilities.Workspace.DidChangeWatchedFiles.DynamicRegistration && len(l.roots) > 0
+		l.reply(request.ID, map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync":       map[string]interface{}{"openClose": true, "change": 1},
+				"hoverProvider":          true,
+				"documentSymbolProvider": true,
+				"codeActionProvider": map[string]interface{}{
+					"codeActionKinds": []string{lspQuickFix, lspRewrite, lspFixAll},
+				},
+			},
+			"serverInfo": map[string]string{"name": "yamlvalid"},
+		})
+	case "initialized":
+		if l.watch {
+			l.watchFiles()
+		}
+		l.indexWorkspace()
+		l.publish()
+	case "workspace/didChangeWatchedFiles":
+		var params lspWatchedFilesParams
+		if json.Unmarshal(request.Params, &params) == nil {
+			l.reloadFiles(params)
+			l.publish()
+		}
+	case "", "$/cancelRequest", "$/setTrace":
+		// Пустой method — ответ редактора на запрос сервера
+	case "shutdown":
+		l.shutdown = true
+		l.reply(request.ID, nil)
+	case "exit":
+		// По спецификации LSP код 1 означает выход без предшествующего shutdown
+		if !l.shutdown {
+			os.Exit(1)
+		}
+		os.Exit(exitValid)
+	case "textDocument/didOpen":
+		var params struct {
+			TextDocument lspTextDocument `json:"textDocument"`
+		}
+		if json.Unmarshal(request.Params, &params) == nil {
+			l.documents[params.TextDocument.URI] = []byte(params.TextDocument.Text)
+			l.publish()
+		}
+	case "textDocument/didChange":
+		var params struct {
+			TextDocument   lspTextDocument   `json:"textDocument"`
+			ContentChanges []lspTextDocument `json:"contentChanges"`
+		}
+		if json.Unmarshal(request.Params, &params) == nil && len(params.ContentChanges) > 0 {
+			l.documents[params.TextDocument.URI] = []byte(params.ContentChanges[len(params.ContentChanges)-1].Text)
+			l.publish()
+		}
+	case "textDocument/didClose":
+		var params struct {
+			TextDocument lspTextDocument `json:"textDocument"`
+		}
+		if json.Unmarshal(request.Params, &params) == nil {
+			// Файл рабочей области остаётся в наборе в том виде, в каком он сохранён на диске
+			delete(l.documents, params.TextDocument.URI)
+			l.publish()
+		}
+	case "textDocument/hover":
+		var params lspPositionParams
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			l.fail(request.ID, lspInvalidParams, err.Error())
+			return
+		}
+		l.reply(request.ID, l.hover(params))
+	case "textDocument/documentSymbol":
+		var params struct {
+			TextDocument lspTextDocument `json:"textDocument"`
+		}
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			l.fail(request.ID, lspInvalidParams, err.Error())
+			return
+		}
+		l.reply(request.ID, l.documentSymbols(params.TextDocument.URI))
+	case "textDocument/codeAction":
+		var params lspCodeActionParams
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			l.fail(request.ID, lspInvalidParams, err.Error())
+			return
+		}
+		l.reply(request.ID, l.codeActions(params))
+	default:
+		if request.ID != nil {
+			l.fail(request.ID, lspMethodNotFound, fmt.Sprintf("method %q is not supported", request.Method))
+		}
+	}
+}
+
+// diagnostic превращает находку в диагностику LSP со ссылкой на документацию правила
+func (l *lspServer) diagnostic(uri string, finding validator.Finding, lines []string) lspDiagnostic {
+	diagnostic := lspDiagnostic{
+		Range:    findingRange(finding, lines),
+		Severity: lspSeverity(finding.Severity),
+		Code:     finding.RuleID,
+		Source:   "yamlvalid",
+		Message:  finding.Message,
+	}
+	if href := l.s.config.docURL(finding.RuleID); href != "" {
+		diagnostic.CodeDescription = &lspCodeDescription{Href: href}
+	}
+	for _, related := range finding.Related {
+		diagnostic.RelatedInformation = append(diagnostic.RelatedInformation, lspRelatedInformation{
+			Location: lspLocation{URI: uri, Range: findingRange(related, lines)},
+			Message:  related.Message,
+		})
+	}
+	for _, ref := range finding.References {
+		position := lspPosition{Line: max(ref.Line-1, 0), Character: max(ref.Column-1, 0)}
+		diagnostic.RelatedInformation = append(diagnostic.RelatedInformation, lspRelatedInformation{
+			Location: lspLocation{URI: l.fileURI(ref.File), Range: lspRange{Start: position, End: position}},
+			Message:  ref.Message,
+		})
+	}
+	return diagnostic
+}
+
+// findingRange выделяет от позиции поля до конца строки; находки без позиции
+// относятся к началу документа
+func findingRange(finding validator.Finding, lines []string) lspRange {
+	if finding.Line < 1 || finding.Line > len(lines) {
+		return lspRange{}
+	}
+	start := lspPosition{Line: finding.Line - 1, Character: max(finding.Column-1, 0)}
+	end := lspPosition{Line: start.Line, Character: len([]rune(strings.TrimRight(lines[start.Line], "\r")))}
+	if end.Character < start.Character {
+		end.Character = start.Character
+	}
+	return lspRange{Start: start, End: end}
+}
+
+func lspSeverity(severity validator.Severity) int {
+	switch severity {
+	case validator.SeverityWarning:
+		return 2
+	case validator.SeverityInfo:
+		return 3
+	default:
+		return 1
+	}
+}
+
+// hover описывает поле под курсором по схеме типа документа; nil — описания нет
+func (l *lspServer) hover(params lspPositionParams) interface{} {
+	data, ok := l.documents[params.TextDocument.URI]
+	if !ok {
+		return nil
+	}
+	info := validator.FieldAt(data, params.Position.Line+1, params.Position.Character+1)
+	if info == nil {
+		return nil
+	}
+	return map[string]interface{}{"contents": lspMarkup{Kind: "markdown", Value: l.fieldDoc(info)}}
+}
+
+// fieldDoc форматирует описание поля: тип, ограничения и ссылки на правила
+func (l *lspServer) fieldDoc(info *validator.FieldInfo) string {
+	var b strings.Builder
+	schema := info.Schema
+	fmt.Fprintf(&b, "**%s**", info.Path)
+	if schema.Type != "" {
+		fmt.Fprintf(&b, " `%s`", schema.Type)
+	}
+	if info.Required {
+		b.WriteString(" · required")
+	}
+	b.WriteString("\n")
+	if schema.Description != "" {
+		fmt.Fprintf(&b, "\n%s\n", schema.Description)
+	}
+
+	var constraints []string
+	if len(schema.Enum) > 0 {
+		constraints = append(constraints, "one of: `"+strings.Join(schema.Enum, "`, `")+"`")
+	}
+	if schema.Pattern != "" {
+		constraints = append(constraints, "pattern: `"+schema.Pattern+"`")
+	}
+	if schema.Minimum != nil {
+		constraints = append(constraints, "minimum: "+strconv.FormatFloat(*schema.Minimum, 'g', -1, 64))
+	}
+	if schema.Maximum != nil {
+		constraints = append(constraints, "maximum: "+strconv.FormatFloat(*schema.Maximum, 'g', -1, 64))
+	}
+	if len(constraints) > 0 {
+		b.WriteString("\n")
+		for _, c := range constraints {
+			fmt.Fprintf(&b, "- %s\n", c)
+		}
+	}
+
+	if len(info.Rules) > 0 {
+		links := make([]string, len(info.Rules))
+		for i, rule := range info.Rules {
+			links[i] = rule.ID + " " + rule.Name
+			if href := l.s.config.docURL(rule.ID); href != "" {
+				links[i] = fmt.Sprintf("[%s](%s)", links[i], href)
+			}
+		}
+		fmt.Fprintf(&b, "\nRules: %s\n", strings.Join(links, ", "))
+	}
+	return b.String()
+}
+
+// lspSymbol — DocumentSymbol: иерархический элемент структуры документа
+type lspSymbol struct {
+	Name           string      `json:"name"`
+	Detail         string      `json:"detail,omitempty"`
+	Kind           int         `json:"kind"`
+	Range          lspRange    `json:"range"`
+	SelectionRange lspRange    `json:"selectionRange"`
+	Children       []lspSymbol `json:"children,omitempty"`
+}
+
+// Виды символов LSP для ресурса, контейнера и тома
+var lspSymbolKinds = map[string]int{
+	validator.SymbolResource:  5,
+	validator.SymbolContainer: 19,
+	validator.SymbolVolume:    8,
+}
+
+// documentSymbols возвращает структуру открытого документа: ресурсы, их контейнеры и тома
+func (l *lspServer) documentSymbols(uri string) []lspSymbol {
+	data, ok := l.documents[uri]
+	if !ok {
+		return nil
+	}
+	var convert func(symbols []validator.Symbol) []lspSymbol
+	convert = func(symbols []validator.Symbol) []lspSymbol {
+		result := []lspSymbol{}
+		for _, symbol := range symbols {
+			start := lspPosition{Line: symbol.Line - 1, Character: symbol.Column - 1}
+			result = append(result, lspSymbol{
+				Name:           symbol.Name,
+				Detail:         symbol.Detail,
+				Kind:           lspSymbolKinds[symbol.Kind],
+				Range:          lspRange{Start: start, End: lspPosition{Line: symbol.EndLine - 1, Character: symbol.EndColumn - 1}},
+				SelectionRange: lspRange{Start: start, End: start},
+				Children:       convert(symbol.Children),
+			})
+		}
+		return result
+	}
+	return convert(validator.Symbols(data))
+}
+
+// uriPath превращает file:// URI в путь к файлу; остальные URI используются как есть
+func uriPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	return u.Path
+}
@@ -1,33 +1,706 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
 )
 
+// commonFlags — флаги, общие для всех команд, которые проверяют манифесты
+type commonFlags struct {
+	configPath         *string
+	output             *string
+	rulesetVersion     *string
+	enableExperimental *bool
+	explain            *bool
+	compose            *bool
+	deterministic      *bool
+	environment        *string
+	ratchet            *string
+	ratchetUpdate      *bool
+	baseline           *string
+	writeBaseline      *bool
+	progress           *string
+	metadata           multiFlag
+	noColor            *bool
+	quiet              *bool
+	summaryOnly        *bool
+	checkReferences    *bool
+	codeFrame          *string
+	frameContext       *int
+	strict             *bool
+	groups             multiFlag
+	categories         multiFlag
+	pssLevel           *string
+	strictWarnings     *bool
+	enable             multiFlag
+	disable            multiFlag
+	resolveDigests     *bool
+	validateOutput     *bool
+	telemetry          *bool
+	plugins            *string
+	crdDir             *string
+	// Набор флагов — чтобы отличить явно заданный флаг от значения по умолчанию
+	flags *flag.FlagSet
+}
+
+func addCommonFlags(fs *flag.FlagSet) *commonFlags {
+	f := &commonFlags{
+		flags:              fs,
+		configPath:         fs.String("config", "", "path to yamlvalid config file (default: .yamlvalid.yaml in the working directory or its parents)"),
+		output:             fs.String("output", "text", "output format: text, json, sarif, codequality, tap, github or argocd"),
+		rulesetVersion:     fs.String("ruleset-version", "", "pin the rule set to a released version, e.g. 2024.1 (default: current)"),
+		enableExperimental: fs.Bool("enable-experimental", false, "run rules that are still experimental"),
+		explain:            fs.Bool("explain", false, "print rule description and documentation link for every finding"),
+		compose:            fs.Bool("compose", false, "check Docker Compose files against a minimal Compose schema instead of skipping them"),
+		deterministic:      fs.Bool("deterministic", false, "CI mode: sort findings canonically and zero durations in reports"),
+		environment:        fs.String("env", "", "target environment, e.g. dev or prod, selecting per-environment rule severities"),
+		ratchet:            fs.String("ratchet", "", "state file with per-rule finding counts; fail only if a count increases"),
+		ratchetUpdate:      fs.Bool("ratchet-update", false, "write current counts to the --ratchet state file when none increased"),
+		baseline:           fs.String("baseline", "", "file with accepted existing findings; they are not reported and do not fail the run"),
+		writeBaseline:      fs.Bool("write-baseline", false, "record all current findings in the --baseline file instead of reporting them"),
+		progress:           fs.String("progress", "", "write progress events to stderr while validating: json"),
+		noColor:            fs.Bool("no-color", false, "disable colored text output (also NO_COLOR); colors are used only on a terminal"),
+		quiet:              fs.Bool("quiet", false, "print nothing; report the result only through the exit code"),
+		summaryOnly:        fs.Bool("summary", false, "print only the number of errors, warnings and info findings per file"),
+		checkReferences:    fs.Bool("check-references", false, "check Service selectors and ConfigMap references between the files; use when they are the complete set of manifests"),
+		codeFrame:          fs.String("code-frame", "auto", "print the source line with a caret under each finding: auto (on a terminal), always or never"),
+		frameContext:       fs.Int("code-frame-context", defaultFrameContext, "lines of source printed before and after the offending line in code frames"),
+		strict:             fs.Bool("strict", false, "report fields that the kind schema does not define, e.g. misspelled keys"),
+		strictWarnings:     fs.Bool("strict-warnings", false, "treat warnings as errors: report them as errors and fail the exit code on them"),
+		pssLevel:           fs.String("pss-level", "", "check pod specs against a Pod Security Standards level: privileged, baseline or restricted"),
+		plugins:            fs.String("plugins", "", "directory with rule plugins: Go plugins (.so, Linux only) or executables speaking the exec protocol"),
+		crdDir:             fs.String("crd-dir", "", "directory with CustomResourceDefinition manifests; custom resources of their kinds are checked against the CRD schemas"),
+		telemetry:          fs.Bool("telemetry", false, "send anonymous rule hit counts to telemetry.endpoint from the config; see yamlvalid telemetry status"),
+		validateOutput:     fs.Bool("validate-output", false, "check JSON reports and progress events against the schema printed by output-schema before writing them"),
+		resolveDigests:     fs.Bool("resolve-digests", false, "look up current image digests in the registry (network access) so that image-digest findings can be fixed"),
+	}
+	fs.Var(&f.groups, "group", "enable an opt-in rule group: strict, dead-resource (implies --check-references), pss-baseline, pss-restricted, cis, best-practice or supply-chain, may be repeated")
+	fs.Var(&f.categories, "include-categories", "comma-separated rule groups to enable, e.g. cis,dead-resource; same as --group")
+	fs.Var(&f.enable, "enable", "run a rule or rule group by ID or name, e.g. YV004 or ambiguous-scalar; comma-separated, may be repeated")
+	fs.Var(&f.disable, "disable", "skip a rule or rule group by ID or name, e.g. YV102 or image-tag; comma-separated, may be repeated")
+	fs.Var(&f.metadata, "metadata", "key=value attached to every report, e.g. commit=$CI_COMMIT_SHA, may be repeated")
+	return f
+}
+
+// set сообщает, задан ли флаг в командной строке явно
+func (f *commonFlags) set(name string) bool {
+	found := false
+	f.flags.Visit(func(flag *flag.Flag) {
+		if flag.Name == name {
+			found = true
+		}
+	})
+	return found
+}
+
+// session — загруженная конфигурация и выбранные правила для одного запуска
+type session struct {
+	config    *Config
+	selection validator.RuleSelection
+	// Настройки проверки из конфигурации: реестры, политики и версия набора правил
+	options validator.Options
+	report  reportOptions
+	compose bool
+	started time.Time
+	// Все проверенные документы — для правил уникальности между файлами
+	documents validator.DocumentSet
+	// Файл состояния --ratchet; пусто — код выхода определяется ошибками
+	ratchet       string
+	ratchetUpdate bool
+	// Базовый файл --baseline; с --write-baseline в него записываются текущие находки
+	baseline      string
+	writeBaseline bool
+	// Документы --stream без находок, которые не попали в results; защищено mu
+	cleanStreamed int
+	cache         findingCache
+	progress      validator.Progress
+	// Куда записать патч с предлагаемыми исправлениями (--fix-dry-run); пусто — не записывать
+	fixOutput string
+	// Комментарии yamlvalid:disable по файлам: находки связей между файлами подавляются в finish;
+	// защищено mu
+	suppressions map[string][]*validator.Suppression
+	// mu защищает состояние, которое меняет проверка файла: с таймаутом она идёт в отдельной горутине
+	mu sync.Mutex
+}
+
+func (f *commonFlags) session() *session {
+	config, err := loadConfig(*f.configPath)
+	if err != nil {
+		fmt.Printf("Error reading config: %v\n", err)
+		os.Exit(configExitCode(err))
+	}
+	return f.sessionFor(config)
+}
+
+// sessionFor применяет флаги к уже загруженной конфигурации и создаёт сессию проверки
+func (f *commonFlags) sessionFor(config *Config) *session {
+	if *f.rulesetVersion != "" {
+		if err := validator.CheckRulesetVersion(*f.rulesetVersion); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitUsage)
+		}
+		config.RulesetVersion = *f.rulesetVersion
+	}
+	if config.RulesetVersion == "" {
+		config.RulesetVersion = validator.CurrentRulesetVersion()
+	}
+	if *f.enableExperimental {
+		config.EnableExperimental = true
+	}
+	if *f.environment != "" {
+		config.Environment = *f.environment
+	}
+	if config.Output != "" && !f.set("output") {
+		*f.output = config.Output
+	}
+	if *f.strict {
+		config.Strict = true
+	}
+	if *f.strictWarnings {
+		config.StrictWarnings = true
+	}
+	if *f.telemetry {
+		config.Telemetry.Enabled = true
+	}
+	config.Groups = append(config.Groups, f.groups...)
+	for _, categories := range f.categories {
+		config.Groups = append(config.Groups, strings.Split(categories, ",")...)
+	}
+	if *f.plugins != "" {
+		if err := loadPlugins(*f.plugins); err != nil {
+			fmt.Printf("Error loading plugins: %v\n", err)
+			os.Exit(configExitCode(err))
+		}
+	}
+	if *f.crdDir != "" {
+		if err := loadCRDs(*f.crdDir); err != nil {
+			fmt.Printf("Error loading CRDs: %v\n", err)
+			os.Exit(configExitCode(err))
+		}
+	}
+	config.Enable = append(config.Enable, f.enable...)
+	config.Disable = append(config.Disable, f.disable...)
+	// Предупреждения собираются по правилам, как они названы, до раскрытия групп
+	warnings := config.warnings()
+	enable, err := ruleIDs(config.Enable)
+	if err == nil {
+		var disable []string
+		disable, err = ruleIDs(config.Disable)
+		config.Enable, config.Disable = enable, disable
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+	if config.Strict {
+		config.Groups = append(config.Groups, validator.GroupStrict)
+	}
+	if *f.pssLevel != "" {
+		config.PSSLevel = *f.pssLevel
+	}
+	if config.PSSLevel != "" {
+		groups, err := validator.PSSGroups(config.PSSLevel)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitUsage)
+		}
+		config.Groups = append(config.Groups, groups...)
+	}
+	for _, group := range config.Groups {
+		if !slices.Contains(validator.RuleGroups(), group) {
+			fmt.Printf("Error: unknown rule group %q (known: %s)\n", group, strings.Join(validator.RuleGroups(), ", "))
+			os.Exit(exitUsage)
+		}
+	}
+	switch {
+	case *f.quiet && *f.summaryOnly:
+		fmt.Println("Error: --quiet and --summary cannot be combined")
+		os.Exit(exitUsage)
+	case *f.quiet && *f.output == "argocd":
+		fmt.Println("Error: --quiet cannot be combined with --output argocd")
+		os.Exit(exitUsage)
+	case *f.writeBaseline && *f.baseline == "":
+		fmt.Println("Error: --write-baseline requires --baseline")
+		os.Exit(exitUsage)
+	case *f.summaryOnly && *f.output != "text":
+		fmt.Println("Error: --summary requires --output text")
+		os.Exit(exitUsage)
+	}
+	switch *f.codeFrame {
+	case "auto", "always", "never":
+	default:
+		fmt.Printf("Error: --code-frame must be auto, always or never, got %q\n", *f.codeFrame)
+		os.Exit(exitUsage)
+	}
+	if *f.frameContext < 0 {
+		fmt.Println("Error: --code-frame-context must not be negative")
+		os.Exit(exitUsage)
+	}
+	if *f.progress != "" && *f.progress != "json" {
+		fmt.Printf("Error: unknown progress format %q\n", *f.progress)
+		os.Exit(exitUsage)
+	}
+	if err := checkOutputFormat(*f.output); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+	metadata, err := runMetadata(config, f.metadata)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+	}
+
+	s := &session{
+		config: config,
+		selection: validator.RuleSelection{
+			RulesetVersion:     config.RulesetVersion,
+			EnableExperimental: config.EnableExperimental,
+			Environment:        config.Environment,
+			Groups:             config.Groups,
+			Severities:         config.severities(),
+			Enable:             config.Enable,
+			Disable:            config.Disable,
+			StrictWarnings:     config.StrictWarnings,
+		},
+		report:        reportOptions{format: *f.output, explain: *f.explain, config: config, deterministic: *f.deterministic, metadata: metadata},
+		compose:       *f.compose,
+		started:       time.Now(),
+		ratchet:       *f.ratchet,
+		ratchetUpdate: *f.ratchetUpdate,
+		baseline:      *f.baseline,
+		writeBaseline: *f.writeBaseline,
+	}
+	s.report.color = *f.output == "text" && useColor(*f.noColor, os.Stdout)
+	s.report.quiet, s.report.summaryOnly = *f.quiet, *f.summaryOnly
+	s.report.validateOutput = *f.validateOutput
+	s.cache.perFile = validator.HasExternalRules()
+	s.options = validator.Options{
+		RulesetVersion:    config.RulesetVersion,
+		MinimumQoS:        config.QoS.minimum(config.Environment),
+		AllowedRegistries: config.Registries,
+		HostPath:          config.HostPath,
+		LimitRatios:       config.LimitRatios,
+		ImageTags:         config.ImageTags.policy(config.Environment),
+	}
+	if err := s.options.Check(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+	// Реестр опрашивается, только если правило image-digest выполняется
+	if rule, _ := validator.FindRuleByKey("image-digest"); *f.resolveDigests && s.selection.Enabled(rule) {
+		s.options.DigestResolver = newRegistryClient().resolve
+	}
+	// Неиспользуемые ресурсы можно найти, только если набор манифестов полный
+	s.documents.CheckReferences = *f.checkReferences
+	for _, rule := range validator.Rules() {
+		if rule.Group == validator.GroupDeadResource && s.selection.Enabled(rule) {
+			s.documents.CheckReferences = true
+		}
+	}
+	s.report.codeFrames = *f.output == "text" && (*f.codeFrame == "always" || (*f.codeFrame == "auto" && isTerminal(os.Stdout)))
+	s.report.frameContext = *f.frameContext
+	if *f.progress == "json" {
+		s.progress.OnEvent = progressWriter(os.Stderr, s.report)
+	}
+	return s
+}
+
+// validate проверяет документ; документы с одинаковым содержимым, например
+// в отрендеренных чартах, проверяются один раз. Находки, отключённые комментариями
+// yamlvalid:disable, не возвращаются.
+func (s *session) validate(data []byte, filename string) []validator.Finding {
+	return s.validateContext(context.Background(), data, filename)
+}
+
+// validateContext — validate для проверки, которую может прервать таймаут: документ,
+// проверка которого закончилась после отмены ctx, не попадает в состояние сессии
+func (s *session) validateContext(ctx context.Context, data []byte, filename string) []validator.Finding {
+	return s.record(ctx, data, filename, s.cache.validate(data, filename, s.validateDocument))
+}
+
+// record добавляет проверенный документ в состояние сессии и применяет к его находкам
+// комментарии yamlvalid:disable
+func (s *session) record(ctx context.Context, data []byte, filename string, findings []validator.Finding) []validator.Finding {
+	suppressions := validator.ParseSuppressions(data)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ctx.Err() != nil {
+		return nil
+	}
+	s.documents.Add(data, filename)
+	if len(suppressions) == 0 {
+		return findings
+	}
+	if s.suppressions == nil {
+		s.suppressions = map[string][]*validator.Suppression{}
+	}
+	s.suppressions[filename] = suppressions
+	return validator.Suppress(findings, suppressions)
+}
+
+func (s *session) validateDocument(data []byte, filename string) []validator.Finding {
+	if s.compose && validator.LooksLikeCompose(data, filename) {
+		return validator.FilterFindings(validator.ValidateCompose(data, filename), s.selection)
+	}
+	findings := validator.FilterFindings(validator.ValidateWith(data, filename, s.options), s.selection)
+	if s.config.NonKubernetes == "skip" && len(findings) == 1 && findings[0].RuleID == validator.RuleNotKubernetes {
+		return nil
+	}
+	return findings
+}
+
+// exitSummary печатает последнюю строку stderr с итогом запуска, если вывод не отключён --quiet
+func (s *session) exitSummary(results []fileResult) {
+	if !s.report.quiet {
+		writeExitSummary(os.Stderr, results, s.report)
+	}
+}
+
+// finish печатает отчёт и завершает процесс с кодом exitFindings, если есть ошибки
+// либо, в режиме --ratchet, если выросло число находок какого-либо правила;
+// с кодом exitUnparsable, если какой-либо файл не разобрался как YAML
+func (s *session) finish(results []fileResult) {
+	// Прерванные по таймауту проверки могут ещё выполняться; состояние они уже не меняют
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	crossFile := s.documents.Validate()
+	for i := range results {
+		suppressions := s.suppressions[results[i].file]
+		related := validator.Suppress(validator.FilterFindings(crossFile[results[i].file], s.selection), suppressions)
+		related = append(related, validator.FilterFindings(validator.UnusedSuppressions(suppressions, results[i].file, s.selection), s.selection)...)
+		results[i].findings = append(results[i].findings, related...)
+		results[i].mapPositions()
+		s.progress.AddFindings(results[i].file, related)
+		results[i].advisory = s.config.isAdvisory(results[i].file)
+	}
+
+	s.progress.Finish()
+
+	if s.writeBaseline {
+		count, err := writeBaseline(s.baseline, results)
+		if err != nil {
+			fmt.Printf("Error writing baseline: %v\n", err)
+			os.Exit(exitIO)
+		}
+		fmt.Fprintf(os.Stderr, "%d findings written to %s\n", count, s.baseline)
+		return
+	}
+	if s.baseline != "" {
+		notes := io.Writer(os.Stderr)
+		if s.report.quiet {
+			notes = io.Discard
+		}
+		if err := applyBaseline(notes, results, s.baseline); err != nil {
+			fmt.Printf("Error reading baseline: %v\n", err)
+			os.Exit(exitUsage)
+		}
+	}
+
+	s.report.budgets = checkBudgets(results, s.config.Budgets)
+	s.report.checked = len(results) + s.cleanStreamed
+	s.report.duration = time.Since(s.started)
+	if s.report.deterministic {
+		s.report.duration = 0
+		sortResults(results)
+	}
+	if err := writeReport(os.Stdout, results, s.report); err != nil {
+		fmt.Printf("Error writing report: %v\n", err)
+		os.Exit(exitIO)
+	}
+	if s.fixOutput != "" {
+		patches := collectFixes(results)
+		if err := writeFixes(s.fixOutput, patches); err != nil {
+			fmt.Printf("Error writing fixes: %v\n", err)
+			os.Exit(exitIO)
+		}
+		fixes := 0
+		for _, patch := range patches {
+			fixes += patch.fixes
+		}
+		fmt.Fprintf(os.Stderr, "%d fixes in %d files written to %s\n", fixes, len(patches), s.fixOutput)
+	}
+	if s.ratchet != "" {
+		passed, err := checkRatchet(os.Stderr, results, s.ratchet, s.ratchetUpdate)
+		if err != nil {
+			fmt.Printf("Error checking ratchet: %v\n", err)
+			os.Exit(exitIO)
+		}
+		s.exitSummary(results)
+		s.sendTelemetry(results)
+		if !passed {
+			os.Exit(exitFindings)
+		}
+		return
+	}
+	s.exitSummary(results)
+	s.sendTelemetry(results)
+	if code := resultExitCode(results, s.report.budgets); code != exitValid {
+		os.Exit(code)
+	}
+}
+
 func main() {
-	if len(os.Args) != 2 {
-		fmt.Println("Usage: yamlvalid <path-to-yaml-file>")
-		os.Exit(1)
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "jsonnet":
+			runJsonnet(os.Args[2:])
+			return
+		case "selftest":
+			runSelftest(os.Args[2:])
+			return
+		case "policy":
+			runPolicy(os.Args[2:])
+			return
+		case "env":
+			runEnv(os.Args[2:])
+			return
+		case "complete":
+			runComplete(os.Args[2:])
+			return
+		case "lsp":
+			runLSP(os.Args[2:])
+			return
+		case "rename":
+			runRename(os.Args[2:])
+			return
+		case "graph":
+			runGraph(os.Args[2:])
+			return
+		case "compare":
+			runCompare(os.Args[2:])
+			return
+		case "drift":
+			runDrift(os.Args[2:])
+			return
+		case "explain":
+			runExplain(os.Args[2:])
+			return
+		case "output-schema":
+			runOutputSchema(os.Args[2:])
+			return
+		case "telemetry":
+			runTelemetry(os.Args[2:])
+			return
+		case "rule":
+			runRule(os.Args[2:])
+			return
+		}
+	}
+	runValidate(os.Args[1:])
+}
+
+func runValidate(args []string) {
+	fs := flag.NewFlagSet("yamlvalid", flag.ExitOnError)
+	common := addCommonFlags(fs)
+	render := fs.String("render", "", "render the file before validation: gotemplate or flux")
+	valuesPath := fs.String("values", "", "values file for --render gotemplate")
+	var substituteFrom multiFlag
+	fs.Var(&substituteFrom, "substitute-from", "ConfigMap or Secret manifest with variables for --render flux, may be repeated")
+	extract := fs.String("extract", "", "validate YAML embedded in the file: frontmatter, directive, documents, terraform or snapshot")
+	var recursive bool
+	fs.BoolVar(&recursive, "r", false, "validate all YAML files in the directory tree")
+	fs.BoolVar(&recursive, "recursive", false, "same as -r")
+	stream := fs.Bool("stream", false, "validate a multi-document file document by document without loading it into memory")
+	timeoutPerFile := fs.Duration("timeout-per-file", 0, "maximum time to validate one file, e.g. 2s (default: no limit)")
+	timeout := fs.Duration("timeout", 0, "maximum time for the whole run; files left unchecked get a timeout finding")
+	fixDryRun := fs.Bool("fix-dry-run", false, "write proposed fixes as a unified diff instead of changing files")
+	fixOutput := fs.String("fix-output", "fixes.patch", "patch file for --fix-dry-run, or a directory for one patch per file")
+	watch := fs.Bool("watch", false, "keep running and validate again whenever a YAML file under the paths is saved; directories are scanned as with -r")
+	fs.Usage = func() {
+		fmt.Println("Usage: yamlvalid [flags] <path-to-yaml-file|glob>...")
+		fmt.Println("       yamlvalid -r [flags] <directory>...")
+		fmt.Println("       yamlvalid --watch [flags] <directory|file>...")
+		fmt.Println("       yamlvalid jsonnet [flags] <file.jsonnet>")
+		fmt.Println("       yamlvalid selftest [flags]")
+		fmt.Println("       yamlvalid policy test [flags] <directory>")
+		fmt.Println("       yamlvalid env [flags]")
+		fmt.Println("       yamlvalid complete --at <file.yaml:line:column>")
+		fmt.Println("       yamlvalid lsp [flags]")
+		fmt.Println("       yamlvalid rename --kind <kind> --from <name> --to <name> <path>...")
+		fmt.Println("       yamlvalid graph [--format dot|json] <path>...")
+		fmt.Println("       yamlvalid compare [flags] <old.yaml> <new.yaml>")
+		fmt.Println("       yamlvalid drift [--kubeconfig <file>] [--context <name>] <path>...")
+		fmt.Println("       yamlvalid explain [<rule-id|rule-name>]")
+		fmt.Println("       yamlvalid output-schema")
+		fmt.Println("       yamlvalid telemetry status [flags]")
+		fmt.Println("       yamlvalid rule new [flags] <rule-name>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(exitUsage)
 	}
+	if *watch {
+		if *stream || *common.ratchet != "" {
+			fmt.Println("Error: --watch cannot be combined with --stream or --ratchet")
+			os.Exit(exitUsage)
+		}
+		runWatch(fs.Args(), args)
+		return
+	}
+
+	s := common.session()
+	opts := fileOptions{render: *render, extract: *extract}
+	// Argo CD получает все документы файла, поэтому и проверяется каждый документ, а не только первый
+	if s.report.format == "argocd" && opts.extract == "" && !*stream {
+		opts.extract = "documents"
+	}
+	switch *render {
+	case "", "gotemplate", "flux":
+	default:
+		fmt.Printf("Error: unknown renderer %q\n", *render)
+		os.Exit(exitUsage)
+	}
+	if *stream && (*render != "" || *extract != "") {
+		fmt.Println("Error: --stream cannot be combined with --render or --extract")
+		os.Exit(exitUsage)
+	}
+	if *stream && s.documents.KeepsDocuments() {
+		fmt.Println("Error: --stream cannot be combined with --check-references, dead-resource rules or unique rules: they keep every document in memory")
+		os.Exit(exitUsage)
+	}
+	if *fixDryRun {
+		s.fixOutput = *fixOutput
+	}
+	if *render == "gotemplate" {
+		values, err := loadValues(*valuesPath)
+		if err != nil {
+			fmt.Printf("Error reading values: %v\n", err)
+			os.Exit(exitIO)
+		}
+		opts.values = values
+	}
+	if *render == "flux" {
+		vars, err := loadSubstitutions(substituteFrom)
+		if err != nil {
+			fmt.Printf("Error reading substitutions: %v\n", err)
+			os.Exit(exitIO)
+		}
+		opts.substitutions = vars
+	}
+
+	var files []string
+	for _, arg := range fs.Args() {
+		switch {
+		case recursive:
+			found, err := scanDirectory(arg, s.config.Scan)
+			if err != nil {
+				fmt.Printf("Error scanning directory: %v\n", err)
+				os.Exit(exitIO)
+			}
+			files = append(files, found...)
+		case hasGlobMeta(arg):
+			// Шаблон раскрываем сами, если оболочка его не раскрыла (кавычки, Windows)
+			if _, err := os.Stat(arg); err == nil {
+				files = append(files, arg)
+				continue
+			}
+			found, err := expandGlob(arg)
+			if err != nil {
+				fmt.Printf("Error expanding pattern: %v\n", err)
+				os.Exit(exitIO)
+			}
+			files = append(files, found...)
+		default:
+			files = append(files, arg)
+		}
+	}
+	s.report.summary = recursive || len(files) > 1
+
+	limits := newTimeouts(*timeoutPerFile, *timeout)
+	var results []fileResult
+	for _, filename := range files {
+		filename := filename
+		results = append(results, s.track(filename, func() []fileResult {
+			return limits.run(filename, &s.mu, func(ctx context.Context) []fileResult {
+				if *stream {
+					return s.streamFile(ctx, filename)
+				}
+				return s.validateFile(ctx, filename, opts)
+			})
+		})...)
+	}
+	s.finish(results)
+}
 
-	filename := os.Args[1]
-	
+// fail печатает ошибку и завершает процесс. Проверка, прерванная по таймауту, процесс не
+// завершает, а останавливает свою горутину: о файле уже сообщено находкой timeout.
+func (s *session) fail(ctx context.Context, code int, format string, args ...interface{}) {
+	s.mu.Lock()
+	if ctx.Err() != nil {
+		s.mu.Unlock()
+		runtime.Goexit()
+	}
+	fmt.Printf(format, args...)
+	os.Exit(code)
+}
+
+// fileOptions — как подготовить файл к проверке: рендеринг и извлечение YAML
+type fileOptions struct {
+	render        string
+	values        map[string]interface{}
+	substitutions map[string]string
+	extract       string
+}
+
+// validateFile читает, рендерит и проверяет один файл; при ошибке чтения или рендеринга завершает
+// процесс. После отмены ctx следующие документы файла не проверяются.
+func (s *session) validateFile(ctx context.Context, filename string, opts fileOptions) []fileResult {
 	// Чтение файла
 	data, err := os.ReadFile(filename)
 	if err != nil {
-		fmt.Printf("Error reading file: %v\n", err)
-		os.Exit(1)
+		s.fail(ctx, exitIO, "Error reading file: %v\n", err)
 	}
 
-	// Валидация YAML
-	errors := validateYAML(data, filename)
-	if len(errors) > 0 {
-		for _, err := range errors {
-			fmt.Println(err)
+	var lines sourceMap
+	switch opts.render {
+	case "gotemplate":
+		data, lines, err = renderGoTemplate(data, filename, opts.values)
+		if err != nil {
+			s.fail(ctx, exitUnparsable, "Error rendering template: %v\n", err)
 		}
-		os.Exit(1)
+	case "flux":
+		data = substituteFlux(data, opts.substitutions)
 	}
 
-	fmt.Println("YAML is valid!")
-}
\ No newline at end of file
+	snippets := []snippet{{name: filename, data: data}}
+	if opts.extract != "" {
+		snippets, err = extractYAML(data, filename, opts.extract)
+		if err != nil {
+			s.fail(ctx, exitUnparsable, "Error extracting YAML: %v\n", err)
+		}
+	}
+
+	// Валидация YAML
+	var results []fileResult
+	for _, sn := range snippets {
+		if ctx.Err() != nil {
+			return nil
+		}
+		sn := sn
+		result := fileResult{file: sn.name, findings: s.validateContext(ctx, sn.data, sn.name), source: sn.data}
+		result.mapLine = func(line int) int {
+			if sn.generated {
+				return 0
+			}
+			return lines.line(line + sn.lineOffset)
+		}
+		results = append(results, result)
+	}
+	return results
+}
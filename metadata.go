@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// runMetadata собирает сведения о запуске: значения конфигурации с подстановкой переменных
+// окружения, поверх них пары --metadata key=value и окружение --env под ключом environment
+func runMetadata(config *Config, pairs []string) (map[string]string, error) {
+	metadata := map[string]string{}
+	for key, value := range config.Metadata {
+		metadata[key] = os.ExpandEnv(value)
+	}
+	for _, pair := range pairs {
+		key, value, found := strings.Cut(pair, "=")
+		if !found || key == "" {
+			return nil, fmt.Errorf("metadata must be key=value, got %q", pair)
+		}
+		metadata[key] = value
+	}
+	if _, exists := metadata["environment"]; !exists && config.Environment != "" {
+		metadata["environment"] = config.Environment
+	}
+	// Пустые значения, например неустановленные переменные CI, не выводим
+	for key, value := range metadata {
+		if value == "" {
+			delete(metadata, key)
+		}
+	}
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+	return metadata, nil
+}
+
+// formatMetadata записывает сведения о запуске строкой key=value, в порядке ключей
+func formatMetadata(metadata map[string]string) string {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+metadata[key])
+	}
+	return strings.Join(pairs, ", ")
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// JSON Schema отчёта --output json и событий --progress json; по нему обёртки
+// генерируют код разбора, а --validate-output проверяет собственный вывод
+//
+//go:embed schemas/output.schema.json
+var outputSchemaJSON []byte
+
+// Определения схемы, которым соответствует отчёт и строка событий
+const (
+	outputDefReport = "report"
+	outputDefEvent  = "event"
+)
+
+var outputSchemaDefs map[string]interface{}
+
+func init() {
+	var schema map[string]interface{}
+	if err := json.Unmarshal(outputSchemaJSON, &schema); err != nil {
+		panic(fmt.Sprintf("output schema: %v", err))
+	}
+	outputSchemaDefs, _ = schema["$defs"].(map[string]interface{})
+}
+
+// runOutputSchema печатает встроенную схему вывода
+func runOutputSchema(args []string) {
+	flagSet := flag.NewFlagSet("yamlvalid output-schema", flag.ExitOnError)
+	flagSet.Usage = func() {
+		fmt.Println("Usage: yamlvalid output-schema")
+		flagSet.PrintDefaults()
+	}
+	flagSet.Parse(args)
+
+	if flagSet.NArg() != 0 {
+		flagSet.Usage()
+		os.Exit(exitUsage)
+	}
+	os.Stdout.Write(outputSchemaJSON)
+}
+
+// checkOutput сверяет JSON-документ с определением def схемы вывода
+func checkOutput(data []byte, def string) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("output is not valid JSON: %v", err)
+	}
+	if problem := matchOutputSchema(value, outputSchemaDefs[def], "$"); problem != "" {
+		return fmt.Errorf("output does not match its schema: %s", problem)
+	}
+	return nil
+}
+
+// matchOutputSchema проверяет значение по подмножеству JSON Schema, которое использует
+// схема вывода: $ref, type, enum, required, properties, additionalProperties, items, minimum.
+// Возвращает описание первого расхождения или пустую строку.
+func matchOutputSchema(value interface{}, schema interface{}, path string) string {
+	rules, _ := schema.(map[string]interface{})
+	if ref, ok := rules["$ref"].(string); ok {
+		return matchOutputSchema(value, outputSchemaDefs[strings.TrimPrefix(ref, "#/$defs/")], path)
+	}
+	if typ, ok := rules["type"].(string); ok && !hasJSONType(value, typ) {
+		return fmt.Sprintf("%s must be of type %s", path, typ)
+	}
+	if enum, ok := rules["enum"].([]interface{}); ok {
+		found := false
+		for _, allowed := range enum {
+			if value == allowed {
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Sprintf("%s has unsupported value %v", path, value)
+		}
+	}
+	if minimum, ok := rules["minimum"].(float64); ok {
+		if number, ok := value.(json.Number); ok {
+			if n, err := number.Float64(); err == nil && n < minimum {
+				return fmt.Sprintf("%s must be at least %v", path, minimum)
+			}
+		}
+	}
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		required, _ := rules["required"].([]interface{})
+		for _, key := range required {
+			if _, exists := typed[key.(string)]; !exists {
+				return fmt.Sprintf("%s.%s is required", path, key)
+			}
+		}
+		properties, _ := rules["properties"].(map[string]interface{})
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			property, known := properties[key]
+			if !known {
+				switch additional := rules["additionalProperties"].(type) {
+				case bool:
+					if !additional {
+						return fmt.Sprintf("%s.%s is not defined by the schema", path, key)
+					}
+					continue
+				case map[string]interface{}:
+					property = additional
+				default:
+					continue
+				}
+			}
+			if problem := matchOutputSchema(typed[key], property, path+"."+key); problem != "" {
+				return problem
+			}
+		}
+	case []interface{}:
+		if items, ok := rules["items"]; ok {
+			for i, item := range typed {
+				if problem := matchOutputSchema(item, items, fmt.Sprintf("%s[%d]", path, i)); problem != "" {
+					return problem
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func hasJSONType(value interface{}, typ string) bool {
+	switch typ {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := number.Int64()
+		return err == nil
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	}
+	return true
+}
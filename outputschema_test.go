@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+// corpusResults проверяет неверные примеры встроенного корпуса: в их находках есть исправления,
+// связанные находки и позиции — всё, что попадает в отчёт
+func corpusResults(t *testing.T) []fileResult {
+	t.Helper()
+	fsys := os.DirFS("testdata/corpus/invalid")
+	names, err := fs.Glob(fsys, "*.yaml")
+	if err != nil || len(names) == 0 {
+		t.Fatalf("no corpus examples: %v", err)
+	}
+	var results []fileResult
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, fileResult{file: name, findings: validator.Validate(data, name), source: data})
+	}
+	return results
+}
+
+// withoutStderr подменяет os.Stderr: формат argocd печатает в него находки
+func withoutStderr(t *testing.T) {
+	t.Helper()
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = devNull
+	t.Cleanup(func() {
+		os.Stderr = stderr
+		devNull.Close()
+	})
+}
+
+func TestValidateOutputAllFormats(t *testing.T) {
+	results := corpusResults(t)
+	rule, _ := validator.FindRule("YV102")
+	withoutStderr(t)
+
+	for _, format := range []string{"text", "json", "sarif", "tap", "github", "argocd"} {
+		t.Run(format, func(t *testing.T) {
+			opts := reportOptions{
+				format:         format,
+				explain:        true,
+				config:         &Config{},
+				summary:        true,
+				checked:        len(results),
+				metadata:       map[string]string{"commit": "abc123"},
+				budgets:        []budgetStatus{{rule: rule, count: 3, budget: 1}},
+				validateOutput: true,
+			}
+			var out bytes.Buffer
+			if err := writeReport(&out, results, opts); err != nil {
+				t.Fatalf("self-check rejected the %s report: %v", format, err)
+			}
+			if out.Len() == 0 {
+				t.Fatalf("empty %s report", format)
+			}
+			// Схема описывает отчёт --output json: его сверяем и здесь, независимо от writeReport
+			if format == "json" {
+				if err := checkOutput(out.Bytes(), outputDefReport); err != nil {
+					t.Error(err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateOutputProgressEvents(t *testing.T) {
+	var out bytes.Buffer
+	progress := validator.Progress{OnEvent: progressWriter(&out, reportOptions{config: &Config{}, validateOutput: true, metadata: map[string]string{"commit": "abc123"}})}
+	for _, result := range corpusResults(t) {
+		progress.StartFile(result.file)
+		progress.FinishFile(result.file, result.findings)
+	}
+	progress.Finish()
+
+	events := 0
+	scanner := bufio.NewScanner(&out)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		events++
+		if err := checkOutput(scanner.Bytes(), outputDefEvent); err != nil {
+			t.Errorf("event %d: %v\n%s", events, err, scanner.Text())
+		}
+	}
+	if events == 0 {
+		t.Fatal("no progress events written")
+	}
+}
+
+func TestCheckOutputRejectsMismatches(t *testing.T) {
+	tests := []struct {
+		name, def, data, want string
+	}{
+		{"not json", outputDefReport, "valid: true", "not valid JSON"},
+		{"missing field", outputDefReport, `{"valid": true}`, "is required"},
+		{"wrong type", outputDefEvent, `{"event": "file_started", "file": 1}`, "$.file"},
+		{"unknown event", outputDefEvent, `{"event": "started"}`, "$.event"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkOutput([]byte(tt.data), tt.def)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want an error mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
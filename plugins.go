@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+// Подключаемые модули правил. Каталог может содержать:
+//   - модули Go (.so), собранные с go build -buildmode=plugin той же версией Go и модуля
+//     validator; модуль экспортирует функцию Rules() []validator.ExternalRule. Только Linux.
+//   - исполняемые файлы с протоколом exec: "<файл> describe" печатает JSON {"rules": [...]}
+//     с описанием правил, "<файл> check" читает из stdin {"file", "document"} и печатает
+//     {"findings": [{"ruleId", "path", "message"}]}. Работают на любой платформе.
+
+// Время на один вызов исполняемого модуля
+const pluginTimeout = 10 * time.Second
+
+// Каталоги, модули которых уже загружены: конфигурация читается несколькими командами
+var loadedPlugins struct {
+	sync.Mutex
+	dirs map[string]bool
+}
+
+// loadPlugins загружает правила всех модулей каталога в порядке имён файлов
+func loadPlugins(dir string) error {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	loadedPlugins.Lock()
+	defer loadedPlugins.Unlock()
+	if loadedPlugins.dirs[dir] {
+		return nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		var rules []validator.ExternalRule
+		switch {
+		case filepath.Ext(path) == ".so":
+			rules, err = openGoPlugin(path)
+		case executable(info):
+			rules, err = openExecPlugin(path)
+		default:
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("plugin %s: %v", path, err)
+		}
+		for _, rule := range rules {
+			if err := validator.RegisterExternalRule(rule); err != nil {
+				return fmt.Errorf("plugin %s: %v", path, err)
+			}
+		}
+	}
+
+	if loadedPlugins.dirs == nil {
+		loadedPlugins.dirs = map[string]bool{}
+	}
+	loadedPlugins.dirs[dir] = true
+	return nil
+}
+
+func executable(info os.FileInfo) bool {
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(filepath.Ext(info.Name()), ".exe")
+	}
+	return info.Mode().Perm()&0o111 != 0
+}
+
+// execRule — описание правила в ответе describe
+type execRule struct {
+	ID          string             `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Severity    validator.Severity `json:"severity"`
+	Group       string             `json:"group"`
+}
+
+// execFinding — находка в ответе check
+type execFinding struct {
+	RuleID  string `json:"ruleId"`
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// openExecPlugin запрашивает у исполняемого модуля его правила; проверка документа — один
+// вызов check на все правила модуля, результат которого правила делят через validator.Memo
+func openExecPlugin(path string) ([]validator.ExternalRule, error) {
+	output, err := runPlugin(path, "describe", nil)
+	if err != nil {
+		return nil, err
+	}
+	var described struct {
+		Rules []execRule `json:"rules"`
+	}
+	if err := json.Unmarshal(output, &described); err != nil {
+		return nil, fmt.Errorf("describe: invalid response: %v", err)
+	}
+
+	check := func(document map[string]interface{}, filename string) ([]execFinding, error) {
+		request, err := json.Marshal(map[string]interface{}{"file": filename, "document": document})
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(request)
+		result := validator.Memo("plugin:"+path, hex.EncodeToString(sum[:]), func() interface{} {
+			output, err := runPlugin(path, "check", request)
+			if err != nil {
+				return err
+			}
+			var checked struct {
+				Findings []execFinding `json:"findings"`
+			}
+			if err := json.Unmarshal(output, &checked); err != nil {
+				return fmt.Errorf("check: invalid response: %v", err)
+			}
+			return checked.Findings
+		})
+		if err, ok := result.(error); ok {
+			return nil, err
+		}
+		return result.([]execFinding), nil
+	}
+
+	rules := make([]validator.ExternalRule, 0, len(described.Rules))
+	for _, rule := range described.Rules {
+		id := rule.ID
+		rules = append(rules, validator.ExternalRule{
+			Rule: validator.Rule{ID: id, Name: rule.Name, Description: rule.Description, Severity: rule.Severity, Group: rule.Group},
+			Check: func(document map[string]interface{}, filename string) []validator.Finding {
+				found, err := check(document, filename)
+				if err != nil {
+					return []validator.Finding{{Message: fmt.Sprintf("%s: plugin %s failed: %v", filename, filepath.Base(path), err)}}
+				}
+				var findings []validator.Finding
+				for _, finding := range found {
+					if finding.RuleID == id {
+						findings = append(findings, validator.Finding{Path: validator.FieldPath(finding.Path), Message: finding.Message})
+					}
+				}
+				return findings
+			},
+		})
+	}
+	return rules, nil
+}
+
+// runPlugin вызывает исполняемый модуль с командой command и входом stdin
+func runPlugin(path, command string, stdin []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), pluginTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, path, command)
+	cmd.Stdin = bytes.NewReader(stdin)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("%s: %v: %s", command, err, message)
+		}
+		return nil, fmt.Errorf("%s: %v", command, err)
+	}
+	return output, nil
+}
@@ -0,0 +1,27 @@
+//go:build linux && cgo
+
+package main
+
+import (
+	"fmt"
+	"plugin"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+// openGoPlugin загружает модуль Go и получает его правила из функции Rules
+func openGoPlugin(path string) ([]validator.ExternalRule, error) {
+	module, err := plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	symbol, err := module.Lookup("Rules")
+	if err != nil {
+		return nil, err
+	}
+	rules, ok := symbol.(func() []validator.ExternalRule)
+	if !ok {
+		return nil, fmt.Errorf("Rules must be a func() []validator.ExternalRule, got %T", symbol)
+	}
+	return rules(), nil
+}
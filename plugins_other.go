@@ -0,0 +1,14 @@
+//go:build !linux || !cgo
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+// openGoPlugin: модули Go загружаются только в Linux со сборкой cgo
+func openGoPlugin(path string) ([]validator.ExternalRule, error) {
+	return nil, fmt.Errorf("Go plugins are only supported on Linux in builds with cgo; build the plugin as an executable (exec protocol)")
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+// Файл набора правил: конфигурация с rules и plugins, как .yamlvalid.yaml
+const policyFile = "policy.yaml"
+
+// Каталог примеров набора; ожидаемые находки примера задаются комментариями "# expect:"
+const policyExamplesDir = "examples"
+
+// runPolicy выполняет подкоманды policy; test проверяет наборы правил на их примерах,
+// чтобы авторы правил могли запускать их в CI, как conftest verify
+func runPolicy(args []string) {
+	flagSet := flag.NewFlagSet("yamlvalid policy", flag.ExitOnError)
+	common := addCommonFlags(flagSet)
+	flagSet.Usage = func() {
+		fmt.Println("Usage: yamlvalid policy test [flags] <directory>")
+		fmt.Println()
+		fmt.Printf("Every directory with a %s file is a policy bundle: its rules are loaded on their own\n", policyFile)
+		fmt.Printf("and checked against the manifests in %s/. A '%s RULE...' comment lists the findings\n", policyExamplesDir, expectPrefix)
+		fmt.Println("an example must produce; an example without one must pass the bundle's rules.")
+		fmt.Println()
+		flagSet.PrintDefaults()
+	}
+	args = parseInterspersed(flagSet, args)
+
+	if len(args) != 2 || args[0] != "test" {
+		flagSet.Usage()
+		os.Exit(exitUsage)
+	}
+
+	bundles, err := findPolicyBundles(args[1])
+	if err != nil {
+		fmt.Printf("Error reading policies: %v\n", err)
+		os.Exit(exitIO)
+	}
+	if len(bundles) == 0 {
+		fmt.Printf("Error reading policies: no %s found in %s\n", policyFile, args[1])
+		os.Exit(exitIO)
+	}
+
+	cases, failed := 0, 0
+	for _, bundle := range bundles {
+		n, f := testPolicyBundle(common, bundle)
+		cases += n
+		failed += f
+	}
+	fmt.Printf("%d bundles, %d cases, %d failed\n", len(bundles), cases, failed)
+	if failed > 0 {
+		os.Exit(exitFindings)
+	}
+}
+
+// findPolicyBundles возвращает каталоги с файлом набора правил в порядке обхода
+func findPolicyBundles(root string) ([]string, error) {
+	var bundles []string
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() && entry.Name() == policyFile {
+			bundles = append(bundles, filepath.Dir(path))
+		}
+		return nil
+	})
+	return bundles, err
+}
+
+// testPolicyBundle загружает правила набора отдельно от остальных и сверяет находки примеров
+// с ожидаемыми; возвращает число примеров и число несовпадений. Набор, который не загрузился,
+// считается одним несовпадением.
+func testPolicyBundle(common *commonFlags, bundle string) (cases, failed int) {
+	validator.ResetRules()
+	validator.ResetCache()
+	config, err := loadConfig(filepath.Join(bundle, policyFile))
+	if err != nil {
+		fmt.Printf("FAIL %s: %v\n", bundle, err)
+		return 1, 1
+	}
+	s := common.sessionFor(config)
+
+	// Сравниваются только правила набора: встроенные правила на примерах не важны,
+	// если пример не упоминает их явно
+	own := map[string]bool{}
+	for _, rule := range validator.Rules() {
+		if rule.Since == "" {
+			own[rule.ID] = true
+		}
+	}
+	if len(own) == 0 {
+		fmt.Printf("FAIL %s: %s defines no rules\n", bundle, policyFile)
+		return 1, 1
+	}
+
+	examples, err := policyExamples(filepath.Join(bundle, policyExamplesDir))
+	if err != nil {
+		fmt.Printf("FAIL %s: %v\n", bundle, err)
+		return 1, 1
+	}
+	if len(examples) == 0 {
+		fmt.Printf("FAIL %s: no examples in %s/\n", bundle, policyExamplesDir)
+		return 1, 1
+	}
+
+	for _, example := range examples {
+		cases++
+		data, err := os.ReadFile(example)
+		if err != nil {
+			fmt.Printf("Error reading file: %v\n", err)
+			os.Exit(exitIO)
+		}
+		want := expectedRules(data)
+		considered := map[string]bool{}
+		for _, id := range want {
+			considered[id] = true
+		}
+
+		// Пример может состоять из нескольких документов: учитываются находки каждого
+		var findings []validator.Finding
+		count := 0
+		streamDocuments(bytes.NewReader(data), func(document []byte, lineOffset int) {
+			count++
+			findings = append(findings, s.validate(document, fmt.Sprintf("%s#%d", example, count))...)
+		})
+		var got []string
+		for _, id := range firedRules(findings) {
+			if own[id] || considered[id] {
+				got = append(got, id)
+			}
+		}
+		sort.Strings(got)
+
+		if strings.Join(got, " ") != strings.Join(want, " ") {
+			failed++
+			fmt.Printf("FAIL %s: expected [%s], got [%s]\n", example, strings.Join(want, " "), strings.Join(got, " "))
+			for _, finding := range findings {
+				if own[finding.RuleID] || considered[finding.RuleID] {
+					fmt.Printf("       %s %s\n", finding.RuleID, finding.Message)
+				}
+			}
+			continue
+		}
+		fmt.Printf("ok   %s\n", example)
+	}
+	return cases, failed
+}
+
+// policyExamples возвращает файлы YAML каталога примеров, включая вложенные каталоги
+func policyExamples(dir string) ([]string, error) {
+	var examples []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return nil
+			}
+			return err
+		}
+		if ext := filepath.Ext(path); !entry.IsDir() && (ext == ".yaml" || ext == ".yml") {
+			examples = append(examples, path)
+		}
+		return nil
+	})
+	return examples, err
+}
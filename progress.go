@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+// jsonEvent — событие --progress json, одна строка JSON на событие
+type jsonEvent struct {
+	Event      string       `json:"event"`
+	File       string       `json:"file,omitempty"`
+	Finding    *jsonFinding `json:"finding,omitempty"`
+	Files      int          `json:"files,omitempty"`
+	Findings   *int         `json:"findings,omitempty"`
+	DurationMs int64        `json:"durationMs,omitempty"`
+	// Сведения о запуске — в событии run_finished
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// progressWriter возвращает обработчик, печатающий события построчно в формате JSON
+func progressWriter(w io.Writer, opts reportOptions) func(validator.Event) {
+	encoder := json.NewEncoder(w)
+	return func(event validator.Event) {
+		je := jsonEvent{Event: string(event.Type), File: event.File, Files: event.Files}
+		switch event.Type {
+		case validator.EventFinding:
+			jf := newJSONFinding(event.File, *event.Finding, opts)
+			je.Finding = &jf
+		case validator.EventFileFinished, validator.EventRunFinished:
+			je.Findings = &event.Findings
+		}
+		if event.Type == validator.EventRunFinished {
+			je.Metadata = opts.metadata
+		}
+		if !opts.deterministic {
+			je.DurationMs = event.Duration.Milliseconds()
+		}
+		if opts.validateOutput {
+			data, _ := json.Marshal(je)
+			if err := checkOutput(data, outputDefEvent); err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing progress: %v\n", err)
+				os.Exit(exitIO)
+			}
+		}
+		encoder.Encode(je)
+	}
+}
+
+// track сообщает о начале и окончании проверки файла и о его находках
+func (s *session) track(filename string, validate func() []fileResult) []fileResult {
+	s.progress.StartFile(filename)
+	results := validate()
+
+	var findings []validator.Finding
+	for _, result := range results {
+		// Позиции переводим в копии: в отчёте они переводятся в finish
+		mapped := result
+		mapped.findings = append([]validator.Finding(nil), result.findings...)
+		mapped.mapPositions()
+		findings = append(findings, mapped.findings...)
+	}
+	s.progress.FinishFile(filename, findings)
+	return results
+}
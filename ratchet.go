@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+)
+
+// ratchetState — зафиксированное число находок по правилам; хранится в репозитории
+type ratchetState struct {
+	Counts map[string]int `json:"counts"`
+}
+
+// checkRatchet сравнивает текущие находки с состоянием в path и сообщает о росте счётчиков в w.
+// С update состояние перезаписывается текущими счётчиками, если ни один не вырос.
+func checkRatchet(w io.Writer, results []fileResult, path string, update bool) (bool, error) {
+	current := ratchetState{Counts: countFindings(results)}
+
+	var previous ratchetState
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist) && update:
+		return true, writeRatchet(path, current)
+	case errors.Is(err, fs.ErrNotExist):
+		return false, fmt.Errorf("%s does not exist, run with --ratchet-update to create it", path)
+	case err != nil:
+		return false, err
+	}
+	if err := json.Unmarshal(data, &previous); err != nil {
+		return false, fmt.Errorf("%s: %v", path, err)
+	}
+
+	ids := make([]string, 0, len(current.Counts))
+	for id := range current.Counts {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	passed := true
+	for _, id := range ids {
+		if count, locked := current.Counts[id], previous.Counts[id]; count > locked {
+			passed = false
+			fmt.Fprintf(w, "ratchet: %s increased from %d to %d\n", id, locked, count)
+		}
+	}
+	if passed && update {
+		return true, writeRatchet(path, current)
+	}
+	return passed, nil
+}
+
+func writeRatchet(path string, state ratchetState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
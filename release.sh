@@ -0,0 +1,46 @@
+#!/bin/sh
+# Сборка выпуска под несколько платформ. Флаги делают сборку воспроизводимой:
+# одинаковый коммит и версия Go дают побайтово одинаковые бинарники.
+# Сведения о сборке печатает yamlvalid env.
+#
+# Бинарники для Linux собираются с cgo: без него модули Go (-buildmode=plugin) не загружаются.
+# Для чужой архитектуры нужен кросс-компилятор C (x86_64-linux-gnu-gcc, aarch64-linux-gnu-gcc),
+# его можно задать переменной CC_<arch>, например CC_arm64. Модули собирают той же версией Go
+# и с теми же флагами -trimpath. Остальные платформы поддерживают только исполняемые модули.
+set -eu
+
+# linux_cc печатает компилятор C для сборки под linux/<arch>
+linux_cc() {
+	eval "cc=\${CC_$1:-}"
+	if [ -n "$cc" ]; then
+		echo "$cc"
+		return
+	fi
+	if [ "$(go env GOHOSTOS)/$(go env GOHOSTARCH)" = "linux/$1" ]; then
+		echo "${CC:-cc}"
+		return
+	fi
+	case $1 in
+	amd64) echo x86_64-linux-gnu-gcc ;;
+	arm64) echo aarch64-linux-gnu-gcc ;;
+	esac
+}
+
+out=${1:-dist}
+mkdir -p "$out"
+for target in linux/amd64 linux/arm64 darwin/amd64 darwin/arm64 windows/amd64 windows/arm64; do
+	os=${target%/*}
+	arch=${target#*/}
+	ext=""
+	[ "$os" = windows ] && ext=".exe"
+	if [ "$os" = linux ]; then
+		CC=$(linux_cc "$arch") CGO_ENABLED=1 GOOS=$os GOARCH=$arch go build -trimpath -ldflags="-s -w -buildid=" \
+			-o "$out/yamlvalid-$os-$arch$ext" .
+	else
+		CGO_ENABLED=0 GOOS=$os GOARCH=$arch go build -trimpath -ldflags="-s -w -buildid=" \
+			-o "$out/yamlvalid-$os-$arch$ext" .
+	fi
+done
+# Схема JSON-вывода публикуется рядом с бинарниками для генерации кода разбора
+cp schemas/output.schema.json "$out/yamlvalid-output.schema.json"
+(cd "$out" && sha256sum yamlvalid-* > SHA256SUMS)
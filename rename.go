@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/internal/yamledit"
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+// renamedFile — файл набора до и после переименования
+type renamedFile struct {
+	name          string
+	before, after []byte
+	changes       int
+}
+
+// runRename переименовывает ресурс и ссылки на него во всех файлах набора. Результат
+// проверяется, и файлы записываются, только если переименование не добавило находок.
+func runRename(args []string) {
+	flagSet := flag.NewFlagSet("yamlvalid rename", flag.ExitOnError)
+	common := addCommonFlags(flagSet)
+	kind := flagSet.String("kind", "", "kind of the renamed resource, e.g. ConfigMap or Secret")
+	from := flagSet.String("from", "", "current metadata.name of the resource")
+	to := flagSet.String("to", "", "new metadata.name of the resource")
+	namespace := flagSet.String("namespace", "", "rename only in this namespace (default: the only namespace the name is used in)")
+	dryRun := flagSet.Bool("dry-run", false, "print the changes as a unified diff instead of writing files")
+	flagSet.Usage = func() {
+		fmt.Println("Usage: yamlvalid rename --kind <kind> --from <name> --to <name> [flags] <file|directory|glob>...")
+		flagSet.PrintDefaults()
+	}
+	paths := parseInterspersed(flagSet, args)
+
+	if len(paths) == 0 || *kind == "" || *from == "" || *to == "" {
+		flagSet.Usage()
+		os.Exit(exitUsage)
+	}
+	if *from == *to {
+		fmt.Println("Error: --from and --to are the same")
+		os.Exit(exitUsage)
+	}
+	s := common.session()
+
+	names := manifestFiles(paths, s.config.Scan)
+	contents := make([][]byte, len(names))
+	used := map[string]bool{}
+	for i, name := range names {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			fmt.Printf("Error reading file: %v\n", err)
+			os.Exit(exitIO)
+		}
+		if err := renameNamespaces(data, *kind, *from, used); err != nil {
+			fmt.Printf("Error: %s: %v\n", name, err)
+			os.Exit(exitUnparsable)
+		}
+		contents[i] = data
+	}
+	selected, err := renameNamespace(*namespace, *kind, *from, used)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+
+	var files []renamedFile
+	total := 0
+	for i, name := range names {
+		after, changes, err := renameIn(contents[i], *kind, *from, *to, selected)
+		if err != nil {
+			fmt.Printf("Error: %s: %v\n", name, err)
+			os.Exit(exitUnparsable)
+		}
+		files = append(files, renamedFile{name: name, before: contents[i], after: after, changes: changes})
+		total += changes
+	}
+	if total == 0 {
+		fmt.Printf("Error: %s %s not found\n", *kind, *from)
+		os.Exit(exitFindings)
+	}
+
+	// Набор переданных файлов считается полным, поэтому проверяются и ссылки между ними
+	introduced := newFindings(s, files)
+	if len(introduced) > 0 {
+		fmt.Println("Rename not applied: the result has new findings")
+		if err := writeReport(os.Stdout, introduced, s.report); err != nil {
+			fmt.Printf("Error writing report: %v\n", err)
+			os.Exit(exitIO)
+		}
+		os.Exit(exitFindings)
+	}
+
+	for _, file := range files {
+		if file.changes == 0 {
+			continue
+		}
+		if *dryRun {
+			name := filepath.ToSlash(file.name)
+			fmt.Print(unifiedDiff("a/"+name, "b/"+name, string(file.before), string(file.after)))
+			continue
+		}
+		if err := os.WriteFile(file.name, file.after, 0o644); err != nil {
+			fmt.Printf("Error writing file: %v\n", err)
+			os.Exit(exitIO)
+		}
+		fmt.Fprintf(os.Stderr, "%s: %d changes\n", file.name, file.changes)
+	}
+}
+
+// manifestFiles раскрывает аргументы: каталоги обходятся целиком, шаблоны раскрываются
+func manifestFiles(args []string, scan ScanConfig) []string {
+	var files []string
+	for _, arg := range args {
+		if info, err := os.Stat(arg); err == nil && info.IsDir() {
+			found, err := scanDirectory(arg, scan)
+			if err != nil {
+				fmt.Printf("Error scanning directory: %v\n", err)
+				os.Exit(exitIO)
+			}
+			files = append(files, found...)
+			continue
+		} else if err != nil && hasGlobMeta(arg) {
+			found, err := expandGlob(arg)
+			if err != nil {
+				fmt.Printf("Error expanding pattern: %v\n", err)
+				os.Exit(exitIO)
+			}
+			files = append(files, found...)
+			continue
+		}
+		files = append(files, arg)
+	}
+	return files
+}
+
+// renameIn переименовывает ресурс и ссылки на него во всех документах файла;
+// комментарии и форматирование остального текста сохраняются
+func renameIn(data []byte, kind, from, to, namespace string) ([]byte, int, error) {
+	doc, err := yamledit.Parse(data)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	changes := 0
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for i := 0; i < doc.Len(); i++ {
+		var document map[string]interface{}
+		if err := decoder.Decode(&document); err != nil {
+			return nil, 0, err
+		}
+		if namespace != "" && documentNamespace(document) != namespace {
+			continue
+		}
+		for _, path := range renamedPaths(document, kind, from) {
+			if err := doc.Set(i, path, to); err != nil {
+				return nil, 0, fmt.Errorf("%s: %v", path, err)
+			}
+			changes++
+		}
+	}
+	return doc.Bytes(), changes, nil
+}
+
+// renameNamespace выбирает пространство имён переименования. Одноимённые ресурсы разных пространств
+// имён — разные ресурсы: без --namespace переименование выполняется, только если имя используется в одном
+func renameNamespace(namespace, kind, from string, used map[string]bool) (string, error) {
+	if namespace != "" || validator.ClusterScoped(kind) {
+		return namespace, nil
+	}
+	switch namespaces := sortedKeys(used); len(namespaces) {
+	case 0:
+		return "", nil
+	case 1:
+		return namespaces[0], nil
+	default:
+		return "", fmt.Errorf("%s %s is used in namespaces %s, choose one with --namespace", kind, from, strings.Join(namespaces, ", "))
+	}
+}
+
+// renameNamespaces добавляет в used пространства имён документов, в которых ресурс определён или упомянут
+func renameNamespaces(data []byte, kind, from string, used map[string]bool) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var document map[string]interface{}
+		if err := decoder.Decode(&document); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if len(renamedPaths(document, kind, from)) > 0 {
+			used[documentNamespace(document)] = true
+		}
+	}
+}
+
+// renamedPaths возвращает поля документа, которые переименование меняет: имя самого ресурса и ссылки на него
+func renamedPaths(document map[string]interface{}, kind, from string) []validator.FieldPath {
+	var paths []validator.FieldPath
+	if document["kind"] == kind && documentName(document) == from {
+		paths = append(paths, "metadata.name")
+	}
+	for _, ref := range validator.References(document) {
+		if ref.Kind == kind && ref.Name == from {
+			paths = append(paths, ref.Path)
+		}
+	}
+	return paths
+}
+
+func documentName(document map[string]interface{}) string {
+	metadata, _ := document["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	return name
+}
+
+// documentNamespace возвращает пространство имён документа; без metadata.namespace — default
+func documentNamespace(document map[string]interface{}) string {
+	metadata, _ := document["metadata"].(map[string]interface{})
+	if namespace, _ := metadata["namespace"].(string); namespace != "" {
+		return namespace
+	}
+	return "default"
+}
+
+// newFindings проверяет набор до и после переименования и возвращает находки, которых раньше не было;
+// находки сравниваются по правилу и полю, потому что текст сообщения может содержать само имя
+func newFindings(s *session, files []renamedFile) []fileResult {
+	validateSet := func(contents func(renamedFile) []byte) map[string][]validator.Finding {
+		set := validator.DocumentSet{CheckReferences: true}
+		findings := map[string][]validator.Finding{}
+		for _, file := range files {
+			set.Add(contents(file), file.name)
+			findings[file.name] = s.validateDocument(contents(file), file.name)
+		}
+		for name, crossFile := range set.Validate() {
+			findings[name] = append(findings[name], validator.FilterFindings(crossFile, s.selection)...)
+		}
+		return findings
+	}
+	before := validateSet(func(f renamedFile) []byte { return f.before })
+	after := validateSet(func(f renamedFile) []byte { return f.after })
+
+	var results []fileResult
+	for _, file := range files {
+		known := map[string]bool{}
+		for _, finding := range before[file.name] {
+			known[finding.RuleID+"\x00"+string(finding.Path)] = true
+		}
+		var introduced []validator.Finding
+		for _, finding := range after[file.name] {
+			if !known[finding.RuleID+"\x00"+string(finding.Path)] {
+				introduced = append(introduced, finding)
+			}
+		}
+		if len(introduced) > 0 {
+			results = append(results, fileResult{file: file.name, findings: introduced, source: file.after})
+		}
+	}
+	return results
+}
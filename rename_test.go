@@ -0,0 +1,65 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const renameFixture = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: web
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: api
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: app
+  namespace: web
+spec:
+  containers:
+    - name: app
+      image: nginx:1.25
+      envFrom:
+        - configMapRef:
+            name: settings
+`
+
+func TestRenameNamespace(t *testing.T) {
+	used := map[string]bool{}
+	if err := renameNamespaces([]byte(renameFixture), "ConfigMap", "settings", used); err != nil {
+		t.Fatal(err)
+	}
+	if got := sortedKeys(used); !reflect.DeepEqual(got, []string{"api", "web"}) {
+		t.Fatalf("namespaces = %v", got)
+	}
+
+	if _, err := renameNamespace("", "ConfigMap", "settings", used); err == nil || !strings.Contains(err.Error(), "api, web") {
+		t.Errorf("ambiguous name: %v", err)
+	}
+	if got, err := renameNamespace("api", "ConfigMap", "settings", used); got != "api" || err != nil {
+		t.Errorf("explicit namespace: %q, %v", got, err)
+	}
+	if got, err := renameNamespace("", "ConfigMap", "settings", map[string]bool{"web": true}); got != "web" || err != nil {
+		t.Errorf("single namespace: %q, %v", got, err)
+	}
+	// Ресурсы без пространства имён переименовываются везде
+	if got, err := renameNamespace("", "ClusterRole", "reader", used); got != "" || err != nil {
+		t.Errorf("cluster-scoped kind: %q, %v", got, err)
+	}
+
+	after, changes, err := renameIn([]byte(renameFixture), "ConfigMap", "settings", "config", "web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes != 2 || strings.Count(string(after), "name: config") != 2 || !strings.Contains(string(after), "name: settings\n  namespace: api") {
+		t.Errorf("renamed %d fields:\n%s", changes, after)
+	}
+}
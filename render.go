@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Маркер строки шаблона, который вставляется перед каждым переводом строки в тексте шаблона
+const lineMarker = "\x00"
+
+// sourceMap сопоставляет строки результата рендеринга строкам исходного шаблона
+type sourceMap []int
+
+func (m sourceMap) line(rendered int) int {
+	if rendered <= 0 || rendered > len(m) {
+		return rendered
+	}
+	return m[rendered-1]
+}
+
+func loadValues(path string) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	if path == "" {
+		return values, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return values, nil
+}
+
+// renderGoTemplate рендерит шаблон text/template со значениями values и строит карту строк
+func renderGoTemplate(data []byte, filename string, values map[string]interface{}) ([]byte, sourceMap, error) {
+	source := string(data)
+	tmpl, err := template.New(filename).Option("missingkey=error").Parse(source)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			markLines(t.Tree.Root, source)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, values); err != nil {
+		return nil, nil, err
+	}
+
+	rendered, lines := stripLineMarkers(buf.String())
+	return []byte(rendered), lines, nil
+}
+
+// markLines дописывает к каждому переводу строки в текстовых узлах номер строки шаблона
+func markLines(node parse.Node, source string) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			markLines(child, source)
+		}
+	case *parse.TextNode:
+		line := strings.Count(source[:n.Pos], "\n") + 1
+		var marked strings.Builder
+		for _, b := range n.Text {
+			if b == '\n' {
+				marked.WriteString(lineMarker + strconv.Itoa(line) + lineMarker)
+				line++
+			}
+			marked.WriteByte(b)
+		}
+		n.Text = []byte(marked.String())
+	case *parse.IfNode:
+		markLines(n.List, source)
+		markLines(n.ElseList, source)
+	case *parse.RangeNode:
+		markLines(n.List, source)
+		markLines(n.ElseList, source)
+	case *parse.WithNode:
+		markLines(n.List, source)
+		markLines(n.ElseList, source)
+	}
+}
+
+// stripLineMarkers убирает маркеры из результата и возвращает номер строки шаблона для каждой строки результата
+func stripLineMarkers(rendered string) (string, sourceMap) {
+	var out strings.Builder
+	var lines sourceMap
+	last := 0
+	for _, line := range strings.SplitAfter(rendered, "\n") {
+		if line == "" {
+			continue
+		}
+		// Строка без маркера получена из действий шаблона — относим её к следующей строке исходника
+		source := last + 1
+		parts := strings.Split(line, lineMarker)
+		for i, part := range parts {
+			if i%2 == 0 {
+				out.WriteString(part)
+			} else if n, err := strconv.Atoi(part); err == nil {
+				source = n
+			}
+		}
+		lines = append(lines, source)
+		last = source
+	}
+	return out.String(), lines
+}
@@ -0,0 +1,356 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+type reportOptions struct {
+	format        string
+	explain       bool
+	config        *Config
+	deterministic bool
+	duration      time.Duration
+	budgets       []budgetStatus
+	// Печатать итог по файлам — при проверке нескольких файлов
+	summary bool
+	// Число проверенных файлов и документов, включая не попавшие в results
+	checked int
+	// Сведения о запуске из --metadata и конфигурации
+	metadata map[string]string
+	// Раскрашивать текстовый отчёт
+	color bool
+	// --quiet: не печатать отчёт, только вернуть код выхода
+	quiet bool
+	// --summary: печатать только число находок по файлам и уровням
+	summaryOnly bool
+	// Печатать под находкой строку файла с указателем на колонку
+	codeFrames bool
+	// Строк контекста до и после строки с нарушением
+	frameContext int
+	// --validate-output: сверять JSON-вывод со встроенной схемой перед печатью
+	validateOutput bool
+}
+
+// fileResult — нарушения, найденные в одном файле или сгенерированном манифесте
+type fileResult struct {
+	file     string
+	findings []validator.Finding
+	// Проверенный документ; нужен форматам, которые выводят сами манифесты
+	source []byte
+	// mapLine переводит строку проверенного документа в строку исходного файла;
+	// 0 означает, что позиция в исходнике неизвестна. nil — строки совпадают.
+	mapLine func(line int) int
+	// dropColumns — столбцы проверенного документа не совпадают со столбцами исходного файла
+	dropColumns bool
+	// Файл в рекомендательном каталоге: находки не влияют на код выхода
+	advisory bool
+}
+
+// mapPositions переводит позиции находок в координаты исходного файла
+func (r *fileResult) mapPositions() {
+	if r.mapLine == nil {
+		return
+	}
+	for i := range r.findings {
+		finding := &r.findings[i]
+		if finding.Line > 0 {
+			finding.Line = r.mapLine(finding.Line)
+		}
+		if finding.Line == 0 || r.dropColumns {
+			finding.Column = 0
+		}
+	}
+}
+
+// sortResults упорядочивает файлы по имени, а находки — по позиции, правилу и тексту
+func sortResults(results []fileResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].file < results[j].file
+	})
+	for _, result := range results {
+		findings := result.findings
+		sort.SliceStable(findings, func(i, j int) bool {
+			a, b := findings[i], findings[j]
+			if a.Line != b.Line {
+				return a.Line < b.Line
+			}
+			if a.Column != b.Column {
+				return a.Column < b.Column
+			}
+			if a.RuleID != b.RuleID {
+				return a.RuleID < b.RuleID
+			}
+			return a.Message < b.Message
+		})
+	}
+}
+
+// hasErrors сообщает, есть ли ошибки сверх бюджетов правил
+func hasErrors(results []fileResult, budgets []budgetStatus) bool {
+	within := tolerated(budgets)
+	for _, result := range results {
+		if result.advisory {
+			continue
+		}
+		for _, finding := range result.findings {
+			if finding.Severity == validator.SeverityError && !within[finding.RuleID] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type jsonFinding struct {
+	ID          string           `json:"id"`
+	File        string           `json:"file"`
+	Line        int              `json:"line,omitempty"`
+	Column      int              `json:"column,omitempty"`
+	Path        string           `json:"path,omitempty"`
+	RuleID      string           `json:"ruleId"`
+	Rule        string           `json:"rule"`
+	Severity    string           `json:"severity"`
+	Message     string           `json:"message"`
+	DocURL      string           `json:"docUrl,omitempty"`
+	Advisory    bool             `json:"advisory,omitempty"`
+	Remediation *jsonRemediation `json:"remediation,omitempty"`
+	// Находки, сгруппированные под этой как под первопричиной
+	Related []jsonFinding `json:"related,omitempty"`
+}
+
+// jsonRemediation — подсказка по исправлению для автоматизации GitOps
+type jsonRemediation struct {
+	Action  string      `json:"action"`
+	Path    string      `json:"path"`
+	Value   interface{} `json:"value,omitempty"`
+	Pattern string      `json:"pattern,omitempty"`
+	Allowed []string    `json:"allowed,omitempty"`
+}
+
+type jsonBudget struct {
+	RuleID   string `json:"ruleId"`
+	Rule     string `json:"rule"`
+	Count    int    `json:"count"`
+	Budget   int    `json:"budget"`
+	Exceeded bool   `json:"exceeded"`
+}
+
+type jsonReport struct {
+	RulesetVersion string            `json:"rulesetVersion"`
+	Metadata       map[string]string `json:"metadata,omitempty"`
+	Valid          bool              `json:"valid"`
+	DurationMs     int64             `json:"durationMs"`
+	Findings       []jsonFinding     `json:"findings"`
+	Budgets        []jsonBudget      `json:"budgets,omitempty"`
+}
+
+// Форматы отчёта --output
+var outputFormats = []string{"text", "json", "sarif", "codequality", "tap", "github", "argocd"}
+
+// checkOutputFormat проверяет формат отчёта до запуска: иначе о неизвестном формате
+// стало бы известно только после проверки всех файлов
+func checkOutputFormat(format string) error {
+	if format == "" || slices.Contains(outputFormats, format) {
+		return nil
+	}
+	return fmt.Errorf("unknown output format %q (known: %s)", format, strings.Join(outputFormats, ", "))
+}
+
+func writeReport(w io.Writer, results []fileResult, opts reportOptions) error {
+	if opts.quiet {
+		return nil
+	}
+	switch opts.format {
+	case "", "text":
+		if opts.summaryOnly {
+			writeSeverityCounts(w, results, opts)
+			return nil
+		}
+		writeText(w, results, opts)
+		return nil
+	case "json":
+		return writeJSON(w, results, opts)
+	case "sarif":
+		return writeSARIF(w, results, opts)
+	case "codequality":
+		return writeCodeQuality(w, results, opts)
+	case "tap":
+		return writeTAP(w, results, opts)
+	case "github":
+		writeGitHub(w, results)
+		return nil
+	case "argocd":
+		// Манифесты идут в stdout для Argo CD, находки — в stderr, который Argo показывает при ошибке
+		writeText(os.Stderr, results, opts)
+		return writeArgoCD(w, results, opts.metadata)
+	default:
+		return fmt.Errorf("unknown output format %q", opts.format)
+	}
+}
+
+func writeText(w io.Writer, results []fileResult, opts reportOptions) {
+	valid := true
+	for _, result := range results {
+		var lines []string
+		if opts.codeFrames && len(result.findings) > 0 {
+			lines = frameSource(result)
+		}
+		for _, finding := range result.findings {
+			valid = false
+			fmt.Fprintln(w, opts.paintSeverity(finding.Severity, finding.Message))
+			if opts.codeFrames {
+				writeCodeFrame(w, lines, finding, opts)
+			}
+			if len(finding.Related) > 0 {
+				fmt.Fprintf(w, "  (+%d related findings caused by this one)\n", len(finding.Related))
+			}
+			if !opts.explain {
+				continue
+			}
+			if rule, ok := validator.FindRule(finding.RuleID); ok && rule.Description != "" {
+				fmt.Fprintf(w, "  %s %s: %s\n", rule.ID, rule.Name, rule.Description)
+			} else if ok {
+				fmt.Fprintf(w, "  %s %s\n", rule.ID, rule.Name)
+			}
+			if url := opts.config.docURL(finding.RuleID); url != "" {
+				fmt.Fprintf(w, "  see %s\n", url)
+			}
+			if ruleDoc(finding.RuleID) != "" {
+				fmt.Fprintf(w, "  run 'yamlvalid explain %s' for examples\n", finding.RuleID)
+			}
+		}
+	}
+	if valid {
+		fmt.Fprintln(w, "YAML is valid!")
+	}
+	writeBudgets(w, opts)
+	if opts.summary {
+		writeSummary(w, results, opts)
+	}
+	if len(opts.metadata) > 0 {
+		fmt.Fprintf(w, "Context: %s\n", formatMetadata(opts.metadata))
+	}
+}
+
+// writeSeverityCounts печатает число находок каждого уровня по файлам и итог
+func writeSeverityCounts(w io.Writer, results []fileResult, opts reportOptions) {
+	failed := 0
+	totals := map[validator.Severity]int{}
+	for _, result := range results {
+		if len(result.findings) == 0 {
+			continue
+		}
+		counts := map[validator.Severity]int{}
+		for _, finding := range result.findings {
+			counts[finding.Severity]++
+			totals[finding.Severity]++
+		}
+		if hasErrors([]fileResult{result}, nil) {
+			failed++
+		}
+		fmt.Fprintf(w, "%s: %s\n", opts.paint(ansiBold, result.file), formatSeverityCounts(counts))
+	}
+	fmt.Fprintf(w, "Summary: %d files checked, %d with errors, %s\n", opts.checked, failed, formatSeverityCounts(totals))
+}
+
+func formatSeverityCounts(counts map[validator.Severity]int) string {
+	return fmt.Sprintf("%d errors, %d warnings, %d info",
+		counts[validator.SeverityError], counts[validator.SeverityWarning], counts[validator.SeverityInfo])
+}
+
+// writeExitSummary печатает в конце любого запуска одну строку итога постоянного вида
+// для сборщиков логов CI, например "yamlvalid: 3 errors, 5 warnings, 42 files, 1.2s"
+func writeExitSummary(w io.Writer, results []fileResult, opts reportOptions) {
+	counts := map[validator.Severity]int{}
+	for _, result := range results {
+		for _, finding := range result.findings {
+			counts[finding.Severity]++
+		}
+	}
+	fmt.Fprintf(w, "yamlvalid: %d errors, %d warnings, %d files, %.1fs\n",
+		counts[validator.SeverityError], counts[validator.SeverityWarning], opts.checked, opts.duration.Seconds())
+}
+
+// writeSummary печатает число нарушений по файлам и общий итог
+func writeSummary(w io.Writer, results []fileResult, opts reportOptions) {
+	failed, total := 0, 0
+	for _, result := range results {
+		if len(result.findings) == 0 {
+			continue
+		}
+		total += len(result.findings)
+		if hasErrors([]fileResult{result}, nil) {
+			failed++
+		}
+		fmt.Fprintf(w, "%s: %d findings\n", opts.paint(ansiBold, result.file), len(result.findings))
+	}
+	fmt.Fprintf(w, "Summary: %d files checked, %d with errors, %d findings\n", opts.checked, failed, total)
+}
+
+func newJSONFinding(file string, finding validator.Finding, opts reportOptions) jsonFinding {
+	rule, _ := validator.FindRule(finding.RuleID)
+	jf := jsonFinding{
+		ID:       finding.Fingerprint(),
+		File:     file,
+		Line:     finding.Line,
+		Column:   finding.Column,
+		Path:     finding.Path.String(),
+		RuleID:   finding.RuleID,
+		Rule:     rule.Name,
+		Severity: string(finding.Severity),
+		Message:  finding.Message,
+		DocURL:   opts.config.docURL(finding.RuleID),
+	}
+	if r := finding.Remediation; r != nil {
+		jf.Remediation = &jsonRemediation{Action: r.Action, Path: finding.Path.String(), Value: r.Value, Pattern: r.Pattern, Allowed: r.Allowed}
+	}
+	for _, related := range finding.Related {
+		jf.Related = append(jf.Related, newJSONFinding(file, related, opts))
+	}
+	return jf
+}
+
+func writeJSON(w io.Writer, results []fileResult, opts reportOptions) error {
+	report := jsonReport{
+		RulesetVersion: opts.config.RulesetVersion,
+		Metadata:       opts.metadata,
+		Valid:          !hasErrors(results, opts.budgets),
+		DurationMs:     opts.duration.Milliseconds(),
+		Findings:       []jsonFinding{},
+	}
+	for _, result := range results {
+		for _, finding := range result.findings {
+			jf := newJSONFinding(result.file, finding, opts)
+			jf.Advisory = result.advisory
+			report.Findings = append(report.Findings, jf)
+		}
+	}
+
+	for _, b := range opts.budgets {
+		report.Budgets = append(report.Budgets, jsonBudget{RuleID: b.rule.ID, Rule: b.rule.Name, Count: b.count, Budget: b.budget, Exceeded: b.exceeded()})
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	if opts.validateOutput {
+		if err := checkOutput(buf.Bytes(), outputDefReport); err != nil {
+			return err
+		}
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+func TestCheckOutputFormat(t *testing.T) {
+	tests := []struct {
+		format string
+		valid  bool
+	}{
+		{"", true},
+		{"text", true},
+		{"json", true},
+		{"sarif", true},
+		{"codequality", true},
+		{"tap", true},
+		{"github", true},
+		{"argocd", true},
+		{"xml", false},
+		{"JSON", false},
+	}
+	for _, tt := range tests {
+		if err := checkOutputFormat(tt.format); (err == nil) != tt.valid {
+			t.Errorf("checkOutputFormat(%q) = %v, want valid %v", tt.format, err, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+// Имя правила в каталоге: строчные слова через дефис, например probe-sanity
+var ruleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9]*(-[a-z0-9]+)*$`)
+
+// Константы групп правил в пакете validator
+var groupConstants = map[string]string{
+	validator.GroupStrict:        "GroupStrict",
+	validator.GroupDeadResource:  "GroupDeadResource",
+	validator.GroupPSSBaseline:   "GroupPSSBaseline",
+	validator.GroupPSSRestricted: "GroupPSSRestricted",
+	validator.GroupCIS:           "GroupCIS",
+	validator.GroupBestPractice:  "GroupBestPractice",
+	validator.GroupSupplyChain:   "GroupSupplyChain",
+}
+
+// scaffold — сведения о новом правиле, подставляемые в шаблоны заготовки
+type scaffold struct {
+	ID       string
+	Name     string
+	Const    string
+	Func     string
+	File     string
+	Severity string
+	Group    string
+	Since    string
+}
+
+// Под, который проходит все правила по умолчанию: основа примеров корпуса
+const scaffoldPod = `apiVersion: v1
+kind: Pod
+metadata:
+  name: {{.Name}}
+spec:
+  containers:
+    - name: app
+      image: registry.bigbrother.io/app:1.0.0
+      resources:
+        requests:
+          cpu: 1
+          memory: 128Mi
+        limits:
+          cpu: 2
+          memory: 256Mi
+`
+
+// Файлы заготовки: путь от корня репозитория и шаблон содержимого
+var scaffoldFiles = []struct{ path, text string }{
+	{"validator/{{.File}}.go", `package validator
+
+import "fmt"
+
+// {{.Func}} проверяет правило {{.Name}} ({{.ID}}).
+// TODO: опишите, что проверяется и почему.
+func (v *Validator) {{.Func}}(document map[string]interface{}, filename string) {
+	for _, template := range podTemplates(document) {
+		spec, ok := lookupPath(document, template.spec).(map[string]interface{})
+		if !ok {
+			continue
+		}
+		containers, _ := spec["containers"].([]interface{})
+		for i, item := range containers {
+			container, _ := item.(map[string]interface{})
+			path := template.spec.Field("containers").Index(i)
+			// TODO: замените условие проверкой правила
+			if container == nil {
+				v.addError({{.Const}}, path, fmt.Sprintf("%s: %s TODO: message", filename, path))
+			}
+		}
+	}
+}
+`},
+	{"docs/rules/{{.ID}}.md", `## Why
+
+TODO: what goes wrong in the cluster when the rule is violated.
+
+## Failing
+
+` + "```yaml" + `
+TODO
+` + "```" + `
+
+## Passing
+
+` + "```yaml" + `
+TODO
+` + "```" + `
+
+## Fix
+
+TODO: how to change the manifest.
+`},
+	{"testdata/corpus/invalid/{{.Name}}.yaml", "# expect: {{.ID}}\n" + scaffoldPod},
+	{"testdata/corpus/valid/{{.Name}}.yaml", scaffoldPod},
+}
+
+// Регистрация правила: константа идентификатора, запись каталога и вызов проверки
+const (
+	scaffoldConst = "\t{{.Const}} = \"{{.ID}}\"\n"
+	scaffoldEntry = `	{
+		ID:          {{.Const}},
+		Name:        "{{.Name}}",
+		Description: "TODO: one sentence shown by yamlvalid explain.",
+		Since:       "{{.Since}}",
+		State:       StateStable,
+{{- if .Severity}}
+		Severity:    {{.Severity}},
+{{- end}}
+		Phase:       PhaseSemantic,
+{{- if .Group}}
+		Group:       {{.Group}},
+{{- end}}
+	},
+`
+	scaffoldCall = "\t\tv.{{.Func}}(document, filename)\n"
+)
+
+// Конец блока семантических проверок в validateTopLevel
+const semanticChecksEnd = "\t}\n\tv.validateCustomRules(document, filename)"
+
+// runRule выполняет подкоманды rule; new создаёт заготовку встроенного правила
+func runRule(args []string) {
+	flagSet := flag.NewFlagSet("yamlvalid rule", flag.ExitOnError)
+	id := flagSet.String("id", "", "rule ID (default: the next free YV1xx ID)")
+	severity := flagSet.String("severity", "error", "rule severity: error, warning or info")
+	group := flagSet.String("group", "", "opt-in rule group, e.g. best-practice")
+	dir := flagSet.String("dir", ".", "root of the yamlvalid repository")
+	force := flagSet.Bool("force", false, "overwrite existing files")
+	flagSet.Usage = func() {
+		fmt.Println("Usage: yamlvalid rule new [flags] <rule-name>")
+		flagSet.PrintDefaults()
+	}
+	args = parseInterspersed(flagSet, args)
+
+	if len(args) != 2 || args[0] != "new" {
+		flagSet.Usage()
+		os.Exit(exitUsage)
+	}
+	rule, err := newScaffold(args[1], *id, validator.Severity(*severity), *group)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+
+	for _, file := range scaffoldFiles {
+		path := filepath.Join(*dir, expandScaffold(file.path, rule))
+		if _, err := os.Stat(path); err == nil && !*force {
+			fmt.Printf("Error: %s already exists (pass --force to overwrite)\n", path)
+			os.Exit(exitUsage)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			fmt.Printf("Error writing file: %v\n", err)
+			os.Exit(exitIO)
+		}
+		if err := os.WriteFile(path, []byte(expandScaffold(file.text, rule)), 0o644); err != nil {
+			fmt.Printf("Error writing file: %v\n", err)
+			os.Exit(exitIO)
+		}
+		fmt.Printf("created %s\n", path)
+	}
+	for _, problem := range registerScaffold(*dir, rule) {
+		fmt.Printf("\n%s\n", problem)
+	}
+	fmt.Println("\nFill in the TODOs, then run: go build ./... && go vet ./... && go run . selftest --corpus testdata/corpus")
+}
+
+// registerScaffold вставляет константу и запись каталога в validator/rules.go и вызов проверки
+// в validateTopLevel после правила с ближайшим меньшим идентификатором. Возвращает код,
+// который не удалось вставить и который нужно добавить вручную.
+func registerScaffold(dir string, rule scaffold) []string {
+	var manual []string
+	rulesPath := filepath.Join(dir, "validator", "rules.go")
+	data, err := os.ReadFile(rulesPath)
+	if err != nil {
+		return []string{fmt.Sprintf("Could not read %s: %v. Add to the constant block:\n%s\nand to the rule catalog:\n%s", rulesPath, err, expandScaffold(scaffoldConst, rule), expandScaffold(scaffoldEntry, rule))}
+	}
+	source := string(data)
+
+	// Константа идёт после константы предыдущего идентификатора, запись каталога — после его записи
+	previous := regexp.MustCompile(`(?m)^\t(\w+)\s*=\s*"` + regexp.QuoteMeta(previousRuleID(rule.ID)) + `"\n`)
+	match := previous.FindStringSubmatchIndex(source)
+	if match == nil {
+		manual = append(manual, fmt.Sprintf("Add to the constant block of %s:\n%s\nand to the rule catalog:\n%s", rulesPath, expandScaffold(scaffoldConst, rule), expandScaffold(scaffoldEntry, rule)))
+	} else {
+		constName := source[match[2]:match[3]]
+		source = source[:match[1]] + expandScaffold(scaffoldConst, rule) + source[match[1]:]
+		entry := strings.Index(source, "\t\tID:          "+constName+",\n")
+		closing := -1
+		if entry >= 0 {
+			closing = strings.Index(source[entry:], "\n\t},\n")
+		}
+		if closing < 0 {
+			manual = append(manual, fmt.Sprintf("Add to the rule catalog of %s:\n%s", rulesPath, expandScaffold(scaffoldEntry, rule)))
+		} else {
+			at := entry + closing + len("\n\t},\n")
+			source = source[:at] + expandScaffold(scaffoldEntry, rule) + source[at:]
+		}
+	}
+	if err := writeGoSource(rulesPath, source); err != nil {
+		manual = append(manual, fmt.Sprintf("Could not update %s: %v", rulesPath, err))
+	} else {
+		fmt.Printf("updated %s\n", rulesPath)
+	}
+
+	validatorPath := filepath.Join(dir, "validator", "validator.go")
+	data, err = os.ReadFile(validatorPath)
+	if err == nil && strings.Contains(string(data), semanticChecksEnd) {
+		source := strings.Replace(string(data), semanticChecksEnd, expandScaffold(scaffoldCall, rule)+semanticChecksEnd, 1)
+		err = writeGoSource(validatorPath, source)
+	} else if err == nil {
+		err = fmt.Errorf("validateTopLevel has an unexpected shape")
+	}
+	if err != nil {
+		manual = append(manual, fmt.Sprintf("Could not update %s: %v. Call the check in validateTopLevel:\n%s", validatorPath, err, expandScaffold(scaffoldCall, rule)))
+	} else {
+		fmt.Printf("updated %s\n", validatorPath)
+	}
+	return manual
+}
+
+// writeGoSource форматирует исходный код, как gofmt, и записывает его
+func writeGoSource(path, source string) error {
+	formatted, err := format.Source([]byte(source))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, formatted, 0o644)
+}
+
+// previousRuleID возвращает идентификатор на единицу меньше, например YV116 для YV117
+func previousRuleID(id string) string {
+	digits := strings.TrimLeft(id, "ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+	number, err := strconv.Atoi(digits)
+	if err != nil || number == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s%0*d", id[:len(id)-len(digits)], len(digits), number-1)
+}
+
+// newScaffold проверяет имя, идентификатор, уровень и группу нового правила
+func newScaffold(name, id string, severity validator.Severity, group string) (scaffold, error) {
+	if !ruleNamePattern.MatchString(name) {
+		return scaffold{}, fmt.Errorf("rule name %q must be lowercase words separated by '-', e.g. probe-sanity", name)
+	}
+	if _, exists := validator.FindRuleByKey(name); exists {
+		return scaffold{}, fmt.Errorf("rule %s already exists", name)
+	}
+	if id == "" {
+		id = nextRuleID("YV1")
+	} else if _, exists := validator.FindRule(id); exists {
+		return scaffold{}, fmt.Errorf("rule %s already exists", id)
+	}
+
+	rule := scaffold{ID: id, Name: name, File: strings.ReplaceAll(name, "-", ""), Since: validator.CurrentRulesetVersion()}
+	camel := ""
+	for _, word := range strings.Split(name, "-") {
+		camel += strings.ToUpper(word[:1]) + word[1:]
+	}
+	rule.Const, rule.Func = "rule"+camel, "validate"+camel
+
+	switch severity {
+	case validator.SeverityError:
+	case validator.SeverityWarning:
+		rule.Severity = "SeverityWarning"
+	case validator.SeverityInfo:
+		rule.Severity = "SeverityInfo"
+	default:
+		return scaffold{}, fmt.Errorf("severity must be error, warning or info")
+	}
+	if group != "" {
+		constant, known := groupConstants[group]
+		if !known {
+			return scaffold{}, fmt.Errorf("unknown group %q, must be one of %s", group, strings.Join(validator.RuleGroups(), ", "))
+		}
+		rule.Group = constant
+	}
+	return rule, nil
+}
+
+// nextRuleID возвращает следующий свободный идентификатор с префиксом, например YV117 для YV1
+func nextRuleID(prefix string) string {
+	next := 1
+	for _, rule := range validator.Rules() {
+		if number, err := strconv.Atoi(strings.TrimPrefix(rule.ID, prefix)); err == nil && strings.HasPrefix(rule.ID, prefix) && len(rule.ID) == len(prefix)+2 {
+			next = max(next, number+1)
+		}
+	}
+	return fmt.Sprintf("%s%02d", prefix, next)
+}
+
+func expandScaffold(text string, rule scaffold) string {
+	var out strings.Builder
+	template.Must(template.New("scaffold").Parse(text)).Execute(&out, rule)
+	return out.String()
+}
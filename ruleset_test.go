@@ -0,0 +1,33 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator/validatortest"
+)
+
+// Выпущенные версии набора правил не меняются: находки по корпусу под каждой из них
+// сравниваются с golden-файлом, и новое правило или тип его не затрагивают
+func TestReleasedRulesetsAreFrozen(t *testing.T) {
+	fsys := os.DirFS("testdata/corpus")
+	names, err := fs.Glob(fsys, "*/*.yaml")
+	if err != nil || len(names) == 0 {
+		t.Fatalf("no corpus examples: %v", err)
+	}
+	for _, version := range []string{"2024.1", "2026.1"} {
+		t.Run(version, func(t *testing.T) {
+			var findings []validator.Finding
+			for _, name := range names {
+				data, err := fs.ReadFile(fsys, name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				findings = append(findings, validator.FilterFindings(validator.ValidateWith(data, name, validator.Options{RulesetVersion: version}), validator.RuleSelection{RulesetVersion: version})...)
+			}
+			validatortest.AssertGolden(t, findings, "testdata/golden/ruleset-"+version+".txt")
+		})
+	}
+}
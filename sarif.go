@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+// Структуры SARIF 2.1.0 — только используемое подмножество схемы
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+	// Сведения о запуске — в наборе свойств SARIF
+	Properties *sarifProperties `json:"properties,omitempty"`
+}
+
+type sarifProperties struct {
+	Metadata map[string]string `json:"metadata"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name    string      `json:"name"`
+	Version string      `json:"version"`
+	Rules   []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	HelpURI              string             `json:"helpUri,omitempty"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	RelatedLocations    []sarifLocation   `json:"relatedLocations,omitempty"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifMessage         `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// sarifLevel переводит уровень находки в уровень SARIF
+func sarifLevel(severity validator.Severity) string {
+	switch severity {
+	case validator.SeverityWarning:
+		return "warning"
+	case validator.SeverityInfo:
+		return "note"
+	}
+	return "error"
+}
+
+func sarifLocationOf(file string, finding validator.Finding) sarifLocation {
+	// Фрагменты file#2 указывают на свой файл
+	if i := strings.IndexByte(file, '#'); i >= 0 {
+		file = file[:i]
+	}
+	location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(file)},
+	}}
+	if finding.Line > 0 {
+		location.PhysicalLocation.Region = &sarifRegion{StartLine: finding.Line, StartColumn: finding.Column}
+	}
+	return location
+}
+
+func writeSARIF(w io.Writer, results []fileResult, opts reportOptions) error {
+	driver := sarifDriver{Name: "yamlvalid", Version: opts.config.RulesetVersion}
+	ruleIndex := map[string]int{}
+	for _, rule := range validator.Rules() {
+		if rule.State == validator.StateRemoved {
+			continue
+		}
+		ruleIndex[rule.ID] = len(driver.Rules)
+		severity := rule.Severity
+		if severity == "" {
+			severity = validator.SeverityError
+		}
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:                   rule.ID,
+			Name:                 rule.Name,
+			ShortDescription:     sarifMessage{Text: rule.Description},
+			HelpURI:              opts.config.docURL(rule.ID),
+			DefaultConfiguration: sarifConfiguration{Level: sarifLevel(severity)},
+		})
+	}
+
+	run := sarifRun{Tool: sarifTool{Driver: driver}, Results: []sarifResult{}}
+	if len(opts.metadata) > 0 {
+		run.Properties = &sarifProperties{Metadata: opts.metadata}
+	}
+	for _, result := range results {
+		for _, finding := range result.findings {
+			sr := sarifResult{
+				RuleID:              finding.RuleID,
+				RuleIndex:           ruleIndex[finding.RuleID],
+				Level:               sarifLevel(finding.Severity),
+				Message:             sarifMessage{Text: finding.Message},
+				Locations:           []sarifLocation{sarifLocationOf(result.file, finding)},
+				PartialFingerprints: map[string]string{"yamlvalid/v1": finding.Fingerprint()},
+			}
+			for _, related := range finding.Related {
+				location := sarifLocationOf(result.file, related)
+				location.Message = &sarifMessage{Text: related.Message}
+				sr.RelatedLocations = append(sr.RelatedLocations, location)
+			}
+			run.Results = append(run.Results, sr)
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
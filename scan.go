@@ -0,0 +1,72 @@
+package main
+
+import (
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+var defaultScanInclude = []string{".yaml", ".yml"}
+
+// scanDirectory обходит дерево каталогов и возвращает проверяемые файлы в лексикографическом порядке.
+// Скрытые каталоги, например .git, пропускаются.
+func scanDirectory(root string, scan ScanConfig) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := entry.Name()
+		if entry.IsDir() {
+			if path != root && strings.HasPrefix(name, ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		relative, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if scanIncludes(relative, scan) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// scanIncludes сообщает, проверяется ли файл по правилам scan; relative — путь относительно
+// обходимого каталога
+func scanIncludes(relative string, scan ScanConfig) bool {
+	include := scan.Include
+	if len(include) == 0 {
+		include = defaultScanInclude
+	}
+	return scanMatches(relative, include) && !scanMatches(relative, scan.Exclude)
+}
+
+// scanMatches сопоставляет путь с элементами include и exclude: шаблон со / или символами
+// шаблона (charts/**, *-values.yaml) сравнивается с путём, иначе элемент — окончание имени файла
+func scanMatches(relative string, entries []string) bool {
+	relative = filepath.ToSlash(relative)
+	name := path.Base(relative)
+	for _, entry := range entries {
+		if !hasGlobMeta(entry) && !strings.Contains(entry, "/") {
+			if strings.HasSuffix(name, entry) {
+				return true
+			}
+			continue
+		}
+		for _, pattern := range expandBraces(entry) {
+			if strings.Contains(pattern, "/") {
+				if matchSegments(strings.Split(strings.TrimPrefix(pattern, "./"), "/"), strings.Split(relative, "/")) {
+					return true
+				}
+			} else if matched, _ := path.Match(pattern, name); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
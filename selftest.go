@@ -0,0 +1,122 @@
+package main
+
+import (
+	"embed"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+// Встроенный корпус примеров, по которому selftest проверяет сборку без внешних файлов
+//
+//go:embed testdata/corpus
+var bundledCorpus embed.FS
+
+// Строка-комментарий с ожидаемыми правилами, например "# expect: YV101 YV102"
+const expectPrefix = "# expect:"
+
+// runSelftest прогоняет корпус примеров и сверяет сработавшие правила с ожидаемыми
+func runSelftest(args []string) {
+	flagSet := flag.NewFlagSet("yamlvalid selftest", flag.ExitOnError)
+	common := addCommonFlags(flagSet)
+	corpus := flagSet.String("corpus", "", "directory with example manifests (default: corpus bundled into the binary)")
+	flagSet.Usage = func() {
+		fmt.Println("Usage: yamlvalid selftest [flags]")
+		flagSet.PrintDefaults()
+	}
+	flagSet.Parse(args)
+
+	if flagSet.NArg() != 0 {
+		flagSet.Usage()
+		os.Exit(exitUsage)
+	}
+
+	s := common.session()
+
+	var fsys fs.FS
+	if *corpus == "" {
+		fsys, _ = fs.Sub(bundledCorpus, "testdata/corpus")
+	} else {
+		fsys = os.DirFS(*corpus)
+	}
+
+	var cases []string
+	err := fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ext := path.Ext(name); !entry.IsDir() && (ext == ".yaml" || ext == ".yml") {
+			cases = append(cases, name)
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("Error reading corpus: %v\n", err)
+		os.Exit(exitIO)
+	}
+	if len(cases) == 0 {
+		fmt.Println("Error reading corpus: no .yaml files found")
+		os.Exit(exitIO)
+	}
+
+	failed := 0
+	for _, name := range cases {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			fmt.Printf("Error reading file: %v\n", err)
+			os.Exit(exitIO)
+		}
+		want := expectedRules(data)
+		findings := s.validate(data, name)
+		got := firedRules(findings)
+		if strings.Join(got, " ") != strings.Join(want, " ") {
+			failed++
+			fmt.Printf("FAIL %s: expected [%s], got [%s]\n", name, strings.Join(want, " "), strings.Join(got, " "))
+			continue
+		}
+		// С --validate-output отчёт по примеру должен соответствовать схеме вывода
+		if s.report.validateOutput {
+			if err := writeJSON(io.Discard, []fileResult{{file: name, findings: findings}}, s.report); err != nil {
+				failed++
+				fmt.Printf("FAIL %s: %v\n", name, err)
+				continue
+			}
+		}
+		fmt.Printf("ok   %s\n", name)
+	}
+
+	fmt.Printf("%d cases, %d failed\n", len(cases), failed)
+	if failed > 0 {
+		os.Exit(exitFindings)
+	}
+}
+
+// expectedRules читает ожидаемые правила из комментариев "# expect:"; без них пример должен быть чистым
+func expectedRules(data []byte) []string {
+	var rules []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), expectPrefix); ok {
+			rules = append(rules, strings.Fields(strings.ReplaceAll(rest, ",", " "))...)
+		}
+	}
+	sort.Strings(rules)
+	return rules
+}
+
+// firedRules возвращает отсортированные идентификаторы правил, включая сгруппированные находки
+func firedRules(findings []validator.Finding) []string {
+	var rules []string
+	for _, finding := range findings {
+		rules = append(rules, finding.RuleID)
+		rules = append(rules, firedRules(finding.Related)...)
+	}
+	sort.Strings(rules)
+	return rules
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Метки и аннотации, по которым cdk8s и Pulumi указывают исходную конструкцию или chart
+var constructKeys = []string{"cdk8s.io/metadata.addr", "pulumi.com/urn"}
+
+// pulumiPreview — вывод pulumi preview --json
+type pulumiPreview struct {
+	Steps []struct {
+		Op       string `json:"op"`
+		URN      string `json:"urn"`
+		NewState struct {
+			Type   string                 `json:"type"`
+			Inputs map[string]interface{} `json:"inputs"`
+		} `json:"newState"`
+	} `json:"steps"`
+}
+
+// extractSnapshot разбивает снимок на ресурсы и называет их по конструкции, из которой они синтезированы
+func extractSnapshot(data []byte, filename string) ([]snippet, error) {
+	var preview pulumiPreview
+	if json.Unmarshal(data, &preview) == nil && preview.Steps != nil {
+		var snippets []snippet
+		for _, step := range preview.Steps {
+			inputs := step.NewState.Inputs
+			if step.Op == "delete" || !strings.HasPrefix(step.NewState.Type, "kubernetes:") || inputs["apiVersion"] == nil {
+				continue
+			}
+			manifest, err := json.MarshalIndent(inputs, "", "  ")
+			if err != nil {
+				return nil, err
+			}
+			snippets = append(snippets, snippet{name: filename + "#" + pulumiName(step.URN), data: manifest, generated: true})
+		}
+		return snippets, nil
+	}
+
+	// Testing.synth в cdk8s и снимки тестов дают JSON-массив манифестов
+	var items []interface{}
+	if json.Unmarshal(data, &items) == nil {
+		var snippets []snippet
+		for i, item := range items {
+			manifest, err := json.MarshalIndent(item, "", "  ")
+			if err != nil {
+				return nil, err
+			}
+			snippets = append(snippets, snippet{name: filename + "#" + constructName(manifest, i), data: manifest, generated: true})
+		}
+		return snippets, nil
+	}
+
+	// cdk8s синтезирует поток YAML-документов, разделённых ---
+	snippets, err := extractYAML(data, filename, "documents")
+	if err != nil {
+		return nil, err
+	}
+	for i := range snippets {
+		snippets[i].name = filename + "#" + constructName(snippets[i].data, i)
+	}
+	return snippets, nil
+}
+
+// pulumiName возвращает имя ресурса из URN вида urn:pulumi:stack::project::type::name
+func pulumiName(urn string) string {
+	if i := strings.LastIndex(urn, "::"); i >= 0 {
+		return urn[i+2:]
+	}
+	return urn
+}
+
+// constructName ищет путь конструкции в метках и аннотациях, иначе возвращает kind/name
+func constructName(data []byte, index int) string {
+	var document struct {
+		Kind     string `yaml:"kind"`
+		Metadata struct {
+			Name        string            `yaml:"name"`
+			Labels      map[string]string `yaml:"labels"`
+			Annotations map[string]string `yaml:"annotations"`
+		} `yaml:"metadata"`
+	}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return fmt.Sprint(index + 1)
+	}
+	for _, key := range constructKeys {
+		if value := document.Metadata.Annotations[key]; value != "" {
+			return value
+		}
+		if value := document.Metadata.Labels[key]; value != "" {
+			return value
+		}
+	}
+	if document.Kind != "" && document.Metadata.Name != "" {
+		return document.Kind + "/" + document.Metadata.Name
+	}
+	return fmt.Sprint(index + 1)
+}
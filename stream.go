@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// streamDocuments читает поток YAML-документов, разделённых ---, и передаёт их fn по одному,
+// не загружая весь поток в память. lineOffset — число строк потока перед документом.
+func streamDocuments(r io.Reader, fn func(data []byte, lineOffset int)) error {
+	reader := bufio.NewReaderSize(r, 64*1024)
+	var document strings.Builder
+	start, line := 0, 0
+	content := false
+
+	flush := func() {
+		if content {
+			fn([]byte(document.String()), start)
+		}
+		document.Reset()
+		content = false
+	}
+
+	for {
+		text, err := reader.ReadString('\n')
+		if text != "" {
+			line++
+			switch trimmed := strings.TrimSpace(text); {
+			case isDocumentSeparator(text) || trimmed == "...":
+				flush()
+				start = line
+			default:
+				if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+					content = true
+				}
+				document.WriteString(text)
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			flush()
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// streamFile проверяет многодокументный файл по одному документу; документы называются file#N.
+// Кэш одинаковых документов не используется: он рос бы с каждым документом потока.
+func (s *session) streamFile(ctx context.Context, filename string) []fileResult {
+	file, err := os.Open(filename)
+	if err != nil {
+		s.fail(ctx, exitIO, "Error reading file: %v\n", err)
+	}
+	defer file.Close()
+
+	var results []fileResult
+	count := 0
+	err = streamDocuments(file, func(data []byte, lineOffset int) {
+		if ctx.Err() != nil {
+			return
+		}
+		count++
+		name := fmt.Sprintf("%s#%d", filename, count)
+		findings := s.record(ctx, data, name, s.validateDocument(data, name))
+		// Результаты чистых документов не храним, только учитываем в итоге
+		if len(findings) == 0 {
+			s.mu.Lock()
+			if ctx.Err() == nil {
+				s.cleanStreamed++
+			}
+			s.mu.Unlock()
+			return
+		}
+		results = append(results, fileResult{
+			file:     name,
+			findings: findings,
+			mapLine:  func(line int) int { return line + lineOffset },
+		})
+	})
+	if err != nil {
+		s.fail(ctx, exitIO, "Error reading file: %v\n", err)
+	}
+	return results
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+// Документов в testdata/stream/dump.yaml
+const dumpDocuments = 3
+
+// largeStream собирает многодокументный файл из count копий testdata/stream/dump.yaml
+func largeStream(tb testing.TB, count int) string {
+	tb.Helper()
+	fragment, err := os.ReadFile(filepath.Join("testdata", "stream", "dump.yaml"))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	var buf bytes.Buffer
+	for i := 0; i < count; i++ {
+		buf.WriteString("---\n")
+		buf.Write(bytes.ReplaceAll(fragment, []byte("N"), []byte(strconv.Itoa(i))))
+	}
+	path := filepath.Join(tb.TempDir(), "dump.yaml")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		tb.Fatal(err)
+	}
+	return path
+}
+
+func TestStreamFileValidatesEveryDocument(t *testing.T) {
+	path := largeStream(t, 50)
+	s := &session{config: &Config{}}
+	results := s.streamFile(context.Background(), path)
+	if len(results) != 0 {
+		t.Fatalf("got findings %v, want none", results[0].findings)
+	}
+	if s.cleanStreamed != 50*dumpDocuments {
+		t.Errorf("%d documents validated, want %d", s.cleanStreamed, 50*dumpDocuments)
+	}
+	// Поток не копит состояние по документам
+	if len(s.cache.entries) != 0 {
+		t.Errorf("streaming cached %d documents, want none", len(s.cache.entries))
+	}
+}
+
+// BenchmarkStream сравнивает --stream с проверкой документов файла, прочитанного целиком
+// (--extract documents); на файле в несколько десятков мегабайт поток не держит его в памяти.
+// Метрика allocs/doc у stream одинакова для потоков разной длины: память на документ не растёт.
+func BenchmarkStream(b *testing.B) {
+	for _, count := range []int{1000, 5000} {
+		path := largeStream(b, count)
+		info, err := os.Stat(path)
+		if err != nil {
+			b.Fatal(err)
+		}
+		documents := count * dumpDocuments
+
+		b.Run("stream/"+strconv.Itoa(documents), func(b *testing.B) {
+			b.SetBytes(info.Size())
+			b.ReportAllocs()
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			for i := 0; i < b.N; i++ {
+				s := &session{config: &Config{}}
+				s.streamFile(context.Background(), path)
+			}
+			runtime.ReadMemStats(&after)
+			b.ReportMetric(float64(after.Mallocs-before.Mallocs)/float64(b.N*documents), "allocs/doc")
+		})
+		b.Run("whole-file/"+strconv.Itoa(documents), func(b *testing.B) {
+			b.SetBytes(info.Size())
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				s := &session{config: &Config{}}
+				s.validateFile(context.Background(), path, fileOptions{extract: "documents"})
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// tapDiagnostic — находка в YAML-блоке диагностики TAP 13
+type tapDiagnostic struct {
+	Rule     string `yaml:"rule"`
+	Severity string `yaml:"severity"`
+	Message  string `yaml:"message"`
+	Path     string `yaml:"path,omitempty"`
+	Line     int    `yaml:"line,omitempty"`
+	Column   int    `yaml:"column,omitempty"`
+}
+
+// writeTAP печатает отчёт в формате Test Anything Protocol: каждый файл — тестовая точка,
+// которая не проходит при ошибках; находки — в YAML-блоке диагностики.
+// Рекомендательные файлы помечаются директивой TODO и на итог не влияют.
+func writeTAP(w io.Writer, results []fileResult, opts reportOptions) error {
+	fmt.Fprintln(w, "TAP version 13")
+	fmt.Fprintf(w, "1..%d\n", len(results))
+	if len(opts.metadata) > 0 {
+		fmt.Fprintf(w, "# %s\n", formatMetadata(opts.metadata))
+	}
+	for i, result := range results {
+		status := "ok"
+		strict := result
+		strict.advisory = false
+		if hasErrors([]fileResult{strict}, opts.budgets) {
+			status = "not ok"
+		}
+		directive := ""
+		if result.advisory {
+			directive = " # TODO advisory"
+		}
+		fmt.Fprintf(w, "%s %d - %s%s\n", status, i+1, result.file, directive)
+		if len(result.findings) == 0 {
+			continue
+		}
+
+		diagnostics := make([]tapDiagnostic, 0, len(result.findings))
+		for _, finding := range result.findings {
+			diagnostics = append(diagnostics, tapDiagnostic{
+				Rule:     finding.RuleID,
+				Severity: string(finding.Severity),
+				Message:  finding.Message,
+				Path:     finding.Path.String(),
+				Line:     finding.Line,
+				Column:   finding.Column,
+			})
+		}
+		var block bytes.Buffer
+		encoder := yaml.NewEncoder(&block)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(map[string]interface{}{"findings": diagnostics}); err != nil {
+			return err
+		}
+		fmt.Fprintln(w, "  ---")
+		for _, line := range strings.Split(strings.TrimSuffix(block.String(), "\n"), "\n") {
+			fmt.Fprintln(w, "  "+line)
+		}
+		fmt.Fprintln(w, "  ...")
+	}
+	return nil
+}
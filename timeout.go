@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+// timeouts ограничивают время проверки одного файла и всего запуска; ноль — без ограничения
+type timeouts struct {
+	perFile time.Duration
+	total   time.Duration
+	// Момент, после которого оставшиеся файлы не проверяются
+	deadline time.Time
+	// slots ограничивает число проверок, идущих одновременно: прерванная по таймауту проверка
+	// занимает место, пока не остановится, и зависшие проверки не копятся без предела
+	slots chan struct{}
+}
+
+func newTimeouts(perFile, total time.Duration) timeouts {
+	t := timeouts{perFile: perFile, total: total, slots: make(chan struct{}, runtime.GOMAXPROCS(0))}
+	if total > 0 {
+		t.deadline = time.Now().Add(total)
+	}
+	return t
+}
+
+// run проверяет файл в отдельной горутине и, если время вышло, возвращает находку timeout.
+// Зависшую проверку прервать нельзя: run отменяет её контекст, и проверка останавливается на
+// границе документа. Отмена выполняется под lock — тем же мьютексом, под которым проверка
+// записывает состояние сессии, поэтому после возврата run прерванная проверка его уже не меняет.
+// Если все места заняты прерванными проверками, файл ждёт освобождения в пределах своего
+// времени и иначе получает находку timeout, не запуская новую горутину.
+func (t timeouts) run(filename string, lock sync.Locker, validate func(ctx context.Context) []fileResult) []fileResult {
+	limit, reported := t.perFile, t.perFile
+	if !t.deadline.IsZero() {
+		remaining := time.Until(t.deadline)
+		if remaining <= 0 {
+			return timedOut(filename, t.total)
+		}
+		if limit == 0 || remaining < limit {
+			limit, reported = remaining, t.total
+		}
+	}
+	if limit == 0 {
+		return validate(context.Background())
+	}
+
+	timer := time.NewTimer(limit)
+	defer timer.Stop()
+	select {
+	case t.slots <- struct{}{}:
+	case <-timer.C:
+		return timedOut(filename, reported)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan []fileResult, 1)
+	go func() {
+		// defer: проверка, прерванная через fail, завершается runtime.Goexit
+		defer func() { <-t.slots }()
+		done <- validate(ctx)
+	}()
+	select {
+	case results := <-done:
+		cancel()
+		return results
+	case <-timer.C:
+		lock.Lock()
+		cancel()
+		lock.Unlock()
+		return timedOut(filename, reported)
+	}
+}
+
+func timedOut(filename string, limit time.Duration) []fileResult {
+	return []fileResult{{file: filename, findings: []validator.Finding{validator.TimeoutFinding(filename, limit)}}}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+func TestTimeoutsRunCancelsAbandonedValidation(t *testing.T) {
+	var mu sync.Mutex
+	release := make(chan struct{})
+	finished := make(chan bool)
+	state := 0
+
+	results := newTimeouts(10*time.Millisecond, 0).run("slow.yaml", &mu, func(ctx context.Context) []fileResult {
+		<-release
+		// Как validateContext: состояние меняется под мьютексом и только до отмены
+		mu.Lock()
+		defer mu.Unlock()
+		if ctx.Err() == nil {
+			state++
+		}
+		finished <- ctx.Err() != nil
+		return nil
+	})
+	if len(results) != 1 || results[0].findings[0].RuleID != validator.TimeoutFinding("slow.yaml", 0).RuleID {
+		t.Fatalf("got %+v, want a timeout finding", results)
+	}
+	close(release)
+	if cancelled := <-finished; !cancelled {
+		t.Error("validation abandoned by run still has a live context")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if state != 0 {
+		t.Error("abandoned validation changed the state after run returned")
+	}
+}
+
+func TestTimeoutsRunReturnsResults(t *testing.T) {
+	var mu sync.Mutex
+	results := newTimeouts(time.Second, 0).run("fast.yaml", &mu, func(ctx context.Context) []fileResult {
+		return []fileResult{{file: "fast.yaml"}}
+	})
+	if len(results) != 1 || len(results[0].findings) != 0 {
+		t.Fatalf("got %+v, want the validation results", results)
+	}
+}
+
+func TestTimeoutsRunLimitsAbandonedValidations(t *testing.T) {
+	var mu sync.Mutex
+	limits := newTimeouts(10*time.Millisecond, 0)
+	limits.slots = make(chan struct{}, 1)
+	release := make(chan struct{})
+	stopped := make(chan struct{})
+	started := 0
+
+	hung := limits.run("hung.yaml", &mu, func(ctx context.Context) []fileResult {
+		defer close(stopped)
+		<-release
+		return nil
+	})
+	if len(hung) != 1 || hung[0].findings[0].RuleID != validator.TimeoutFinding("hung.yaml", 0).RuleID {
+		t.Fatalf("got %+v, want a timeout finding", hung)
+	}
+
+	// Место занято зависшей проверкой: следующий файл получает timeout без новой горутины
+	count := func(ctx context.Context) []fileResult {
+		started++
+		return []fileResult{{file: "next.yaml"}}
+	}
+	if results := limits.run("next.yaml", &mu, count); len(results) != 1 || len(results[0].findings) != 1 {
+		t.Fatalf("got %+v, want a timeout finding", results)
+	}
+	if started != 0 {
+		t.Error("validation started while every slot was held by an abandoned one")
+	}
+
+	// Когда зависшая проверка остановилась, проверки снова запускаются
+	close(release)
+	<-stopped
+	if results := limits.run("next.yaml", &mu, count); len(results) != 1 || len(results[0].findings) != 0 {
+		t.Fatalf("got %+v, want the validation results", results)
+	}
+	if started != 1 {
+		t.Errorf("started %d validations, want 1", started)
+	}
+}
+
+func TestValidateContextAfterCancel(t *testing.T) {
+	s := &session{config: &Config{}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	data := []byte("apiVersion: v1\nkind: Unknown # yamlvalid:disable=YV204\n")
+	if findings := s.validateContext(ctx, data, "late.yaml"); findings != nil {
+		t.Errorf("got %v, want no findings from a cancelled validation", findings)
+	}
+	if len(s.suppressions) != 0 {
+		t.Errorf("cancelled validation recorded suppressions %v", s.suppressions)
+	}
+}
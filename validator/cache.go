@@ -0,0 +1,56 @@
+package validator
+
+import "sync"
+
+// memoKey — ключ кэша: правило и объявленный им ключ значения
+type memoKey struct {
+	ruleID string
+	key    string
+}
+
+// memoEntry вычисляется один раз, даже если его одновременно запросили несколько горутин
+type memoEntry struct {
+	once  sync.Once
+	value interface{}
+}
+
+// Наибольшее число записей Memo: при переполнении кэш очищается целиком. Записи дешевле
+// вычислить заново, чем хранить без предела в долгоживущем процессе, например в lsp.
+const memoLimit = 10000
+
+// Общий для запуска кэш результатов дорогих проверок
+var memo struct {
+	sync.Mutex
+	entries map[memoKey]*memoEntry
+}
+
+// Memo возвращает результат compute для ключа key правила ruleID, вычисляя его
+// не более одного раза за запуск. Ключ должен однозначно определять результат:
+// например, для проверки образа в реестре — сама ссылка на образ.
+func Memo(ruleID, key string, compute func() interface{}) interface{} {
+	memo.Lock()
+	if memo.entries == nil {
+		memo.entries = map[memoKey]*memoEntry{}
+	}
+	entry, exists := memo.entries[memoKey{ruleID, key}]
+	if !exists {
+		if len(memo.entries) >= memoLimit {
+			memo.entries = map[memoKey]*memoEntry{}
+		}
+		entry = &memoEntry{}
+		memo.entries[memoKey{ruleID, key}] = entry
+	}
+	memo.Unlock()
+
+	entry.once.Do(func() { entry.value = compute() })
+	return entry.value
+}
+
+// ResetCache очищает кэш Memo между запусками в одном процессе: lsp вызывает его при каждой
+// проверке рабочей области, чтобы изменившиеся модули и реестры не отдавали старый результат
+func ResetCache() {
+	memo.Lock()
+	defer memo.Unlock()
+
+	memo.entries = nil
+}
@@ -0,0 +1,45 @@
+package validator
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMemo(t *testing.T) {
+	ResetCache()
+	defer ResetCache()
+
+	calls := 0
+	compute := func() interface{} {
+		calls++
+		return calls
+	}
+	for i := 0; i < 3; i++ {
+		if got := Memo("YV900", "image:1.0", compute); got != 1 {
+			t.Fatalf("call %d: got %v, want the first result", i, got)
+		}
+	}
+	if got := Memo("YV901", "image:1.0", compute); got != 2 {
+		t.Errorf("another rule shares the entry: got %v", got)
+	}
+
+	ResetCache()
+	if got := Memo("YV900", "image:1.0", compute); got != 3 {
+		t.Errorf("ResetCache kept the entry: got %v", got)
+	}
+}
+
+func TestMemoLimit(t *testing.T) {
+	ResetCache()
+	defer ResetCache()
+
+	for i := 0; i < memoLimit*3; i++ {
+		Memo("YV900", fmt.Sprint(i), func() interface{} { return i })
+	}
+	memo.Lock()
+	size := len(memo.entries)
+	memo.Unlock()
+	if size > memoLimit {
+		t.Errorf("cache holds %d entries, limit is %d", size, memoLimit)
+	}
+}
@@ -0,0 +1,57 @@
+package validator
+
+import (
+	"fmt"
+	"path"
+)
+
+// Ресурсы уровня кластера: у них нет пространства имён
+var clusterScopedKinds = []string{
+	"Namespace", "Node", "PersistentVolume", "StorageClass", "ClusterRole", "ClusterRoleBinding",
+	"CustomResourceDefinition", "PriorityClass", "IngressClass", "ValidatingWebhookConfiguration", "MutatingWebhookConfiguration",
+}
+
+// ClusterScoped сообщает, что ресурс вида kind существует вне пространств имён
+func ClusterScoped(kind string) bool {
+	return containsString(clusterScopedKinds, kind)
+}
+
+// Сокеты среды выполнения контейнеров: смонтированный сокет даёт контейнеру управление узлом
+var runtimeSockets = []string{
+	"/var/run/docker.sock", "/run/docker.sock", "/var/run/containerd/containerd.sock",
+	"/run/containerd/containerd.sock", "/var/run/crio/crio.sock", "/run/crio/crio.sock",
+}
+
+// validateCIS проверяет рекомендации CIS Kubernetes Benchmark, относящиеся к рабочим нагрузкам:
+// 5.1.6 (токен сервисного аккаунта), 5.7.4 (пространство имён default) и монтирование сокета
+// среды выполнения. Находки выводятся только при включённой группе cis.
+func (v *Validator) validateCIS(document map[string]interface{}, filename string) {
+	kind, _ := document["kind"].(string)
+	if !ClusterScoped(kind) {
+		switch namespace, _ := lookupPath(document, "metadata.namespace").(string); namespace {
+		case "":
+			v.addError(ruleCISDefaultNamespace, "metadata", fmt.Sprintf("%s: metadata.namespace is not set, the %s would be created in the default namespace", filename, kind))
+		case "default":
+			v.addError(ruleCISDefaultNamespace, "metadata.namespace", fmt.Sprintf("%s: metadata.namespace should not be default", filename))
+		}
+	}
+
+	for _, template := range podTemplates(document) {
+		spec := template.spec
+		if _, ok := lookupPath(document, spec).(map[string]interface{}); !ok {
+			continue
+		}
+		if lookupPath(document, spec.Field("automountServiceAccountToken")) != false {
+			v.addError(ruleCISServiceAccountToken, spec.Field("automountServiceAccountToken"),
+				fmt.Sprintf("%s: %s should be false unless the pod calls the Kubernetes API", filename, spec.Field("automountServiceAccountToken")))
+		}
+		volumes, _ := lookupPath(document, spec.Field("volumes")).([]interface{})
+		for i := range volumes {
+			hostPath := spec.Field("volumes").Index(i).Field("hostPath").Field("path")
+			value, _ := lookupPath(document, hostPath).(string)
+			if value != "" && containsString(runtimeSockets, path.Clean(value)) {
+				v.addError(ruleCISRuntimeSocket, hostPath, fmt.Sprintf("%s: %s mounts the container runtime socket %s, which gives the pod control of the node", filename, hostPath, value))
+			}
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package validator
+
+import (
+	"sort"
+	"strings"
+)
+
+// Completion — вариант автодополнения для позиции курсора
+type Completion struct {
+	Label string
+	// Kind — key для ключа отображения или value для значения
+	Kind string
+	// Detail — тип значения по схеме; Documentation — описание поля
+	Detail        string
+	Documentation string
+}
+
+// Complete возвращает ключи либо значения, допустимые по схеме в позиции курсора;
+// line и column отсчитываются с единицы. Текст может быть недописанным: контекст
+// определяется по отступам, а не разбором YAML.
+func Complete(data []byte, line, column int) []Completion {
+	lines := strings.Split(string(data), "\n")
+	if line < 1 || line > len(lines) {
+		return nil
+	}
+	cursor := outlineAt(lines, line-1, column)
+	if cursor == nil {
+		return nil
+	}
+	schema := cursor.schema()
+	if schema == nil {
+		return nil
+	}
+
+	var completions []Completion
+	if cursor.value {
+		for _, value := range cursor.values(schema) {
+			if strings.HasPrefix(value, cursor.partial) {
+				completions = append(completions, Completion{Label: value, Kind: "value", Detail: schema.Type})
+			}
+		}
+		return completions
+	}
+
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if containsString(cursor.siblings, name) || !strings.HasPrefix(name, cursor.partial) {
+			continue
+		}
+		property := schema.Properties[name]
+		detail := property.Type
+		if containsString(schema.Required, name) {
+			detail += ", required"
+		}
+		completions = append(completions, Completion{Label: name, Kind: "key", Detail: detail, Documentation: property.Description})
+	}
+	return completions
+}
+
+// cursorOutline — положение курсора в структуре документа
+type cursorOutline struct {
+	// Путь к отображению, в котором стоит курсор, а в режиме значения — к полю
+	path     []PathSegment
+	value    bool
+	partial  string
+	siblings []string
+	// Тип документа из его верхнеуровневых полей
+	apiVersion, kind string
+}
+
+// schema возвращает схему отображения под курсором либо поля, значение которого дописывается
+func (c *cursorOutline) schema() *Schema {
+	schema := SchemaFor(c.apiVersion, c.kind)
+	for _, segment := range c.path {
+		if schema == nil {
+			return nil
+		}
+		if segment.IsIndex {
+			schema = schema.Items
+		} else {
+			schema = schema.property(segment.Key)
+		}
+	}
+	return schema
+}
+
+// values возвращает допустимые значения поля
+func (c *cursorOutline) values(schema *Schema) []string {
+	if len(c.path) == 1 {
+		switch c.path[0].Key {
+		case "kind":
+			kinds := registeredKinds("")
+			sort.Strings(kinds)
+			return kinds
+		case "apiVersion":
+			if c.kind != "" {
+				return apiVersionsOf(handlersFor(c.kind, ""))
+			}
+		}
+	}
+	if len(schema.Enum) > 0 {
+		return schema.Enum
+	}
+	if schema.Type == "boolean" {
+		return []string{"true", "false"}
+	}
+	return nil
+}
+
+// outlineLine — строка блочного YAML: отступ, позиции дефисов элементов и ключ
+type outlineLine struct {
+	blank  bool
+	indent int
+	dashes []int
+	keyCol int
+	key    string
+	// Текст после двоеточия либо недописанный ключ
+	rest     string
+	hasColon bool
+}
+
+func parseOutline(text string) outlineLine {
+	trimmed := strings.TrimLeft(text, " ")
+	l := outlineLine{indent: len(text) - len(trimmed)}
+	if trimmed == "" || trimmed[0] == '#' {
+		l.blank = true
+		l.keyCol = len(text)
+		return l
+	}
+	col := l.indent
+	for trimmed == "-" || strings.HasPrefix(trimmed, "- ") {
+		l.dashes = append(l.dashes, col)
+		rest := strings.TrimLeft(trimmed[1:], " ")
+		col += len(trimmed) - len(rest)
+		trimmed = rest
+	}
+	l.keyCol = col
+	end := strings.Index(trimmed, ": ")
+	if end < 0 && strings.HasSuffix(trimmed, ":") {
+		end = len(trimmed) - 1
+	}
+	if end < 0 {
+		l.rest = trimmed
+		return l
+	}
+	l.key = strings.Trim(trimmed[:end], `"'`)
+	l.rest = strings.TrimSpace(trimmed[end+1:])
+	l.hasColon = true
+	return l
+}
+
+// outlineAt восстанавливает путь к позиции курсора по отступам строк над ней
+func outlineAt(lines []string, line, column int) *cursorOutline {
+	runes := []rune(lines[line])
+	if column < 1 || column-1 > len(runes) {
+		return nil
+	}
+	start, end := line, line
+	for start > 0 && !isSeparator(lines[start-1]) {
+		start--
+	}
+	for end < len(lines) && !isSeparator(lines[end]) {
+		end++
+	}
+
+	cursor := &cursorOutline{}
+	for _, text := range lines[start:end] {
+		if l := parseOutline(text); !l.blank && l.indent == 0 && len(l.dashes) == 0 {
+			switch l.key {
+			case "apiVersion":
+				cursor.apiVersion = l.rest
+			case "kind":
+				cursor.kind = l.rest
+			}
+		}
+	}
+
+	current := parseOutline(string(runes[:column-1]))
+	var reversed []PathSegment
+	pushIndexes := func(dashes []int) {
+		for range dashes {
+			reversed = append(reversed, PathSegment{IsIndex: true})
+		}
+	}
+	if current.hasColon {
+		cursor.value = true
+		cursor.partial = current.rest
+		reversed = append(reversed, PathSegment{Key: current.key})
+	} else {
+		cursor.partial = current.rest
+	}
+	keyCol := current.keyCol
+	pos, inSequence := keyCol, len(current.dashes) > 0
+	pushIndexes(current.dashes)
+	if inSequence {
+		pos = current.dashes[0]
+	}
+
+	// Ключи того же отображения ниже курсора
+	for _, text := range lines[line+1 : end] {
+		l := parseOutline(text)
+		if l.blank {
+			continue
+		}
+		if l.indent < keyCol {
+			break
+		}
+		if l.keyCol == keyCol && len(l.dashes) == 0 && l.key != "" {
+			cursor.siblings = append(cursor.siblings, l.key)
+		}
+	}
+
+	sameLevel := !inSequence
+	for i := line - 1; i >= start; i-- {
+		l := parseOutline(lines[i])
+		if l.blank {
+			continue
+		}
+		if inSequence {
+			if l.indent > pos || (l.indent == pos && len(l.dashes) > 0) {
+				continue
+			}
+		} else {
+			if l.keyCol > pos {
+				continue
+			}
+			if l.keyCol == pos {
+				if sameLevel && l.key != "" {
+					cursor.siblings = append(cursor.siblings, l.key)
+				}
+				if len(l.dashes) > 0 {
+					pushIndexes(l.dashes)
+					pos, inSequence, sameLevel = l.dashes[0], true, false
+				}
+				continue
+			}
+		}
+		// Строка левее курсора — ключ, внутри значения которого стоит курсор
+		if l.key == "" || l.hasValue() {
+			break
+		}
+		reversed = append(reversed, PathSegment{Key: l.key})
+		sameLevel, inSequence = false, len(l.dashes) > 0
+		pos = l.keyCol
+		pushIndexes(l.dashes)
+		if inSequence {
+			pos = l.dashes[0]
+		}
+	}
+
+	for i := len(reversed) - 1; i >= 0; i-- {
+		cursor.path = append(cursor.path, reversed[i])
+	}
+	return cursor
+}
+
+// hasValue сообщает, записано ли значение на строке ключа; якорь, комментарий
+// и заголовок блочного скаляра значением не считаются
+func (l outlineLine) hasValue() bool {
+	return l.rest != "" && !strings.ContainsAny(l.rest[:1], "#&|>")
+}
+
+func isSeparator(line string) bool {
+	return strings.TrimRight(line, " \t\r") == "---"
+}
@@ -0,0 +1,62 @@
+package validator
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Ограничения раскрытия якорей: вложенные псевдонимы размножают узлы экспоненциально
+// («billion laughs»), поэтому размер документа после раскрытия считается до декодирования
+const (
+	maxAliasDepth    = 32
+	maxExpandedNodes = 1000000
+)
+
+// complexity считает размер дерева после раскрытия псевдонимов, не раскрывая их
+type complexity struct {
+	nodes map[*yaml.Node]int
+	depth map[*yaml.Node]int
+	// Узлы на текущем пути обхода — защита от якоря, содержащего сам себя
+	visiting map[*yaml.Node]bool
+}
+
+// checkComplexity возвращает ошибку, если псевдонимы документа вложены слишком глубоко
+// или раскрываются в слишком большое число узлов
+func checkComplexity(root *yaml.Node) error {
+	c := complexity{nodes: map[*yaml.Node]int{}, depth: map[*yaml.Node]int{}, visiting: map[*yaml.Node]bool{}}
+	nodes, depth := c.measure(root)
+	switch {
+	case depth > maxAliasDepth:
+		return fmt.Errorf("aliases are nested deeper than %d levels", maxAliasDepth)
+	case nodes > maxExpandedNodes:
+		return fmt.Errorf("aliases expand to more than %d nodes", maxExpandedNodes)
+	}
+	return nil
+}
+
+// measure возвращает число узлов поддерева после раскрытия и глубину вложенности псевдонимов;
+// число узлов ограничено сверху, чтобы не переполниться
+func (c *complexity) measure(node *yaml.Node) (int, int) {
+	if nodes, ok := c.nodes[node]; ok {
+		return nodes, c.depth[node]
+	}
+	if c.visiting[node] {
+		return maxExpandedNodes + 1, maxAliasDepth + 1
+	}
+	c.visiting[node] = true
+	defer delete(c.visiting, node)
+
+	nodes, depth := 1, 0
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		aliasNodes, aliasDepth := c.measure(node.Alias)
+		nodes, depth = aliasNodes, aliasDepth+1
+	}
+	for _, child := range node.Content {
+		childNodes, childDepth := c.measure(child)
+		nodes = min(nodes+childNodes, maxExpandedNodes+1)
+		depth = max(depth, childDepth)
+	}
+	c.nodes[node], c.depth[node] = nodes, depth
+	return nodes, depth
+}
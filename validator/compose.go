@@ -0,0 +1,164 @@
+package validator
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var composeFilename = regexp.MustCompile(`^(docker-)?compose([.-][\w.-]+)?\.ya?ml$`)
+
+var composePort = regexp.MustCompile(`^(\d+(\.\d+){3}:)?(\d+(-\d+)?:)?\d+(-\d+)?(/(tcp|udp))?$`)
+
+// isComposeFile распознаёт Docker Compose по имени файла либо по services без apiVersion и kind
+func isComposeFile(document map[string]interface{}, filename string) bool {
+	if composeFilename.MatchString(filepath.Base(filename)) {
+		return true
+	}
+	_, hasAPIVersion := document["apiVersion"]
+	_, hasKind := document["kind"]
+	_, hasServices := document["services"].(map[string]interface{})
+	return hasServices && !hasAPIVersion && !hasKind
+}
+
+// LooksLikeCompose сообщает, похож ли файл на Docker Compose, а не на манифест Kubernetes
+func LooksLikeCompose(data []byte, filename string) bool {
+	var document map[string]interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return composeFilename.MatchString(filepath.Base(filename))
+	}
+	return isComposeFile(document, filename)
+}
+
+// ValidateCompose выполняет минимальную проверку структуры файла Docker Compose
+func ValidateCompose(data []byte, filename string) []Finding {
+	var validator Validator
+	started := time.Now()
+	defer func() { recordValidation(validator.errors, time.Since(started)) }()
+
+	root, document, ok := validator.parse(data, filename)
+	if !ok {
+		return validator.errors
+	}
+	validator.validateCompose(document, filename)
+	validator.resolvePositions(root)
+	return validator.errors
+}
+
+func (v *Validator) validateCompose(document map[string]interface{}, filename string) {
+	// version и name (optional)
+	for _, key := range []string{"version", "name"} {
+		if value, exists := document[key]; exists {
+			if _, ok := value.(string); !ok {
+				v.addError(ruleFieldType, FieldPath(key), fmt.Sprintf("%s: %s must be string", filename, key))
+			}
+		}
+	}
+
+	// volumes, networks, configs, secrets (optional)
+	for _, key := range []string{"volumes", "networks", "configs", "secrets"} {
+		if value, exists := document[key]; exists && value != nil {
+			if _, ok := value.(map[string]interface{}); !ok {
+				v.addError(ruleFieldType, FieldPath(key), fmt.Sprintf("%s: %s must be an object", filename, key))
+			}
+		}
+	}
+
+	// services
+	services, exists := document["services"]
+	if !exists {
+		v.addError(ruleComposeSchema, "services", fmt.Sprintf("%s: services is required", filename))
+		return
+	}
+	servicesMap, ok := services.(map[string]interface{})
+	if !ok {
+		v.addError(ruleFieldType, "services", fmt.Sprintf("%s: services must be an object", filename))
+		return
+	}
+	for _, name := range sortedKeys(servicesMap) {
+		path := FieldPath("services").Field(name)
+		if service, ok := servicesMap[name].(map[string]interface{}); ok {
+			v.validateComposeService(service, path, filename)
+		} else {
+			v.addError(ruleFieldType, path, fmt.Sprintf("%s: %s must be an object", filename, path))
+		}
+	}
+}
+
+func (v *Validator) validateComposeService(service map[string]interface{}, path FieldPath, filename string) {
+	// image или build
+	_, hasImage := service["image"]
+	_, hasBuild := service["build"]
+	if !hasImage && !hasBuild {
+		v.addError(ruleComposeSchema, path, fmt.Sprintf("%s: %s must have image or build", filename, path))
+	}
+	if image, exists := service["image"]; exists {
+		if _, ok := image.(string); !ok {
+			v.addError(ruleFieldType, path.Field("image"), fmt.Sprintf("%s: %s must be string", filename, path.Field("image")))
+		}
+	}
+	if build, exists := service["build"]; exists {
+		switch build.(type) {
+		case string, map[string]interface{}:
+		default:
+			v.addError(ruleFieldType, path.Field("build"), fmt.Sprintf("%s: %s must be string or object", filename, path.Field("build")))
+		}
+	}
+
+	// ports (optional)
+	if ports, exists := service["ports"]; exists {
+		if portsList, ok := ports.([]interface{}); ok {
+			for i, port := range portsList {
+				portPath := path.Field("ports").Index(i)
+				switch val := port.(type) {
+				case int:
+					if val <= 0 || val >= 65536 {
+						v.addError(rulePortRange, portPath, fmt.Sprintf("%s: %s value out of range", filename, portPath))
+					}
+				case string:
+					if !composePort.MatchString(val) {
+						v.addError(ruleComposeSchema, portPath, fmt.Sprintf("%s: %s has invalid format '%s'", filename, portPath, val))
+					}
+				case map[string]interface{}:
+					if _, exists := val["target"]; !exists {
+						v.addError(ruleComposeSchema, portPath.Field("target"), fmt.Sprintf("%s: %s.target is required", filename, portPath))
+					}
+				default:
+					v.addError(ruleFieldType, portPath, fmt.Sprintf("%s: %s must be string, integer or object", filename, portPath))
+				}
+			}
+		} else {
+			v.addError(ruleFieldType, path.Field("ports"), fmt.Sprintf("%s: %s must be an array", filename, path.Field("ports")))
+		}
+	}
+
+	// environment (optional)
+	if environment, exists := service["environment"]; exists {
+		switch env := environment.(type) {
+		case map[string]interface{}:
+		case []interface{}:
+			for i, item := range env {
+				if _, ok := item.(string); !ok {
+					itemPath := path.Field("environment").Index(i)
+					v.addError(ruleFieldType, itemPath, fmt.Sprintf("%s: %s must be string", filename, itemPath))
+				}
+			}
+		default:
+			v.addError(ruleFieldType, path.Field("environment"), fmt.Sprintf("%s: %s must be an object or an array", filename, path.Field("environment")))
+		}
+	}
+
+	// restart (optional)
+	if restart, exists := service["restart"]; exists {
+		restartStr, ok := restart.(string)
+		if !ok {
+			v.addError(ruleFieldType, path.Field("restart"), fmt.Sprintf("%s: %s must be string", filename, path.Field("restart")))
+		} else if !containsString([]string{"no", "always", "unless-stopped", "on-failure"}, strings.SplitN(restartStr, ":", 2)[0]) {
+			v.addError(ruleComposeSchema, path.Field("restart"), fmt.Sprintf("%s: %s has unsupported value '%s'", filename, path.Field("restart"), restartStr))
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package validator
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Ключ ConfigMap и Secret: буквы, цифры, '-', '_' и '.', не длиннее 253 символов
+var configKeyPattern = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+
+// Встроенные типы Secret
+var secretTypes = []string{
+	"Opaque", "bootstrap.kubernetes.io/token", "kubernetes.io/basic-auth", "kubernetes.io/dockercfg",
+	"kubernetes.io/dockerconfigjson", "kubernetes.io/service-account-token", "kubernetes.io/ssh-auth", "kubernetes.io/tls",
+}
+
+// Ключи данных, обязательные для типа Secret
+var secretRequiredKeys = map[string][]string{
+	"kubernetes.io/dockercfg":        {".dockercfg"},
+	"kubernetes.io/dockerconfigjson": {".dockerconfigjson"},
+	"kubernetes.io/ssh-auth":         {"ssh-privatekey"},
+	"kubernetes.io/tls":              {"tls.crt", "tls.key"},
+	"bootstrap.kubernetes.io/token":  {"token-id", "token-secret"},
+}
+
+var configMapSchema = &Schema{
+	Type: "object",
+	Properties: map[string]*Schema{
+		"data":       {Type: "object", Description: "Configuration values as strings, keyed by file name or variable name."},
+		"binaryData": {Type: "object", Description: "Binary values encoded in base64; keys must not repeat keys of data."},
+		"immutable":  {Type: "boolean", Description: "Whether the data can no longer be changed."},
+	},
+}
+
+var secretSchema = &Schema{
+	Type: "object",
+	Properties: map[string]*Schema{
+		"type":       {Type: "string", Description: "Type of the secret, e.g. Opaque or kubernetes.io/tls; defines the required keys.", Enum: secretTypes, Rules: []string{ruleFieldValue}},
+		"data":       {Type: "object", Description: "Secret values encoded in base64.", Rules: []string{ruleFieldValue}},
+		"stringData": {Type: "object", Description: "Secret values as plain strings; merged into data on write."},
+		"immutable":  {Type: "boolean", Description: "Whether the data can no longer be changed."},
+	},
+}
+
+func (v *Validator) validateConfigMap(document map[string]interface{}, filename string) {
+	if immutable, exists := document["immutable"]; exists {
+		v.validateSchema(immutable, configMapSchema.Properties["immutable"], "immutable", filename)
+	}
+	keys := map[string]bool{}
+	v.validateConfigData(document, "data", keys, false, filename)
+	v.validateConfigData(document, "binaryData", keys, true, filename)
+}
+
+func (v *Validator) validateSecret(document map[string]interface{}, filename string) {
+	if immutable, exists := document["immutable"]; exists {
+		v.validateSchema(immutable, secretSchema.Properties["immutable"], "immutable", filename)
+	}
+
+	secretType := "Opaque"
+	if value, exists := document["type"]; exists {
+		if str, ok := value.(string); !ok {
+			v.addError(ruleFieldType, "type", fmt.Sprintf("%s: type must be string", filename))
+		} else if !containsString(secretTypes, str) && !customSecretType(str) {
+			v.addError(ruleFieldValue, "type", fmt.Sprintf("%s: type has unsupported value '%s'", filename, str))
+			v.suggest(Remediation{Action: ActionSet, Allowed: secretTypes})
+		} else {
+			secretType = str
+		}
+	}
+
+	keys := map[string]bool{}
+	v.validateConfigData(document, "data", keys, true, filename)
+	// Ключ stringData перекрывает тот же ключ data, поэтому повтор не ошибка
+	v.validateConfigData(document, "stringData", map[string]bool{}, false, filename)
+	if stringData, ok := document["stringData"].(map[string]interface{}); ok {
+		for key := range stringData {
+			keys[key] = true
+		}
+	}
+
+	if secretType == "kubernetes.io/basic-auth" && !keys["username"] && !keys["password"] {
+		v.addError(ruleRequiredField, "data", fmt.Sprintf("%s: a kubernetes.io/basic-auth Secret requires username or password in data or stringData", filename))
+	}
+	for _, key := range secretRequiredKeys[secretType] {
+		if !keys[key] {
+			v.addError(ruleRequiredField, FieldPath("data").Field(key), fmt.Sprintf("%s: a %s Secret requires the key '%s' in data or stringData", filename, secretType, key))
+		}
+	}
+}
+
+// customSecretType сообщает, что тип задан пользователем: в форме домен/имя с доменом
+// не из kubernetes.io
+func customSecretType(secretType string) bool {
+	domain, name, found := strings.Cut(secretType, "/")
+	return found && name != "" && strings.Contains(domain, ".") && !strings.HasSuffix(domain, "kubernetes.io")
+}
+
+// validateConfigData проверяет раздел данных: формат ключей, строковые значения и, для
+// encoded, кодировку base64. Ключи, встречающиеся в другом разделе из seen, — ошибка.
+func (v *Validator) validateConfigData(document map[string]interface{}, field string, seen map[string]bool, encoded bool, filename string) {
+	value, exists := document[field]
+	if !exists || value == nil {
+		return
+	}
+	data, ok := value.(map[string]interface{})
+	if !ok {
+		v.addError(ruleFieldType, FieldPath(field), fmt.Sprintf("%s: %s must be an object", filename, field))
+		return
+	}
+	for _, key := range sortedKeys(data) {
+		path := FieldPath(field).Field(key)
+		if len(key) > 253 || !configKeyPattern.MatchString(key) || key == "." || key == ".." {
+			v.addError(ruleFieldValue, path, fmt.Sprintf("%s: %s: key must consist of letters, digits, '-', '_' or '.' and be at most 253 characters", filename, path))
+		}
+		if seen[key] {
+			v.addError(ruleFieldValue, path, fmt.Sprintf("%s: %s: key is also set in another data section", filename, path))
+		}
+		seen[key] = true
+
+		str, ok := data[key].(string)
+		if !ok {
+			v.addError(ruleFieldType, path, fmt.Sprintf("%s: %s must be string", filename, path))
+			// Число или логическое значение достаточно взять в кавычки
+			switch data[key].(type) {
+			case int, float64, bool:
+				if !encoded {
+					v.suggest(Remediation{Action: ActionSet, Value: fmt.Sprint(data[key])})
+				}
+			}
+			continue
+		}
+		if encoded {
+			if _, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(str), "")); err != nil {
+				v.addError(ruleFieldValue, path, fmt.Sprintf("%s: %s is not valid base64; use stringData for plain values", filename, path))
+			}
+		}
+	}
+}
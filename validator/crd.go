@@ -0,0 +1,232 @@
+package validator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Тип документа, описывающего пользовательский ресурс
+const crdAPIVersion = "apiextensions.k8s.io/v1"
+
+type crdDocument struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Metadata   struct {
+		Name string `yaml:"name"`
+	} `yaml:"metadata"`
+	Spec struct {
+		Group string `yaml:"group"`
+		Names struct {
+			Kind string `yaml:"kind"`
+		} `yaml:"names"`
+		Versions []struct {
+			Name   string `yaml:"name"`
+			Served bool   `yaml:"served"`
+			Schema struct {
+				OpenAPIV3Schema *openAPISchema `yaml:"openAPIV3Schema"`
+			} `yaml:"schema"`
+		} `yaml:"versions"`
+	} `yaml:"spec"`
+}
+
+// openAPISchema — подмножество OpenAPI v3, которое переносится в Schema. Ключевые слова из
+// последней группы полей не проверяются: RegisterCRD сообщает о них предупреждением.
+type openAPISchema struct {
+	Type                 string                    `yaml:"type"`
+	Description          string                    `yaml:"description"`
+	Required             []string                  `yaml:"required"`
+	Properties           map[string]*openAPISchema `yaml:"properties"`
+	AdditionalProperties *additionalProperties     `yaml:"additionalProperties"`
+	Items                *openAPISchema            `yaml:"items"`
+	Enum                 []interface{}             `yaml:"enum"`
+	Pattern              string                    `yaml:"pattern"`
+	Minimum              *float64                  `yaml:"minimum"`
+	Maximum              *float64                  `yaml:"maximum"`
+	MinItems             *int                      `yaml:"minItems"`
+	MaxItems             *int                      `yaml:"maxItems"`
+	MinLength            *int                      `yaml:"minLength"`
+	MaxLength            *int                      `yaml:"maxLength"`
+	Format               string                    `yaml:"format"`
+	AllOf                []*openAPISchema          `yaml:"allOf"`
+	AnyOf                []*openAPISchema          `yaml:"anyOf"`
+	OneOf                []*openAPISchema          `yaml:"oneOf"`
+	Nullable             bool                      `yaml:"nullable"`
+	IntOrString          bool                      `yaml:"x-kubernetes-int-or-string"`
+	PreserveUnknown      bool                      `yaml:"x-kubernetes-preserve-unknown-fields"`
+
+	Not              interface{}   `yaml:"not"`
+	UniqueItems      bool          `yaml:"uniqueItems"`
+	MultipleOf       *float64      `yaml:"multipleOf"`
+	ExclusiveMinimum bool          `yaml:"exclusiveMinimum"`
+	ExclusiveMaximum bool          `yaml:"exclusiveMaximum"`
+	MinProperties    *int          `yaml:"minProperties"`
+	MaxProperties    *int          `yaml:"maxProperties"`
+	Validations      []interface{} `yaml:"x-kubernetes-validations"`
+}
+
+// additionalProperties — схема либо true/false
+type additionalProperties struct {
+	allowed bool
+	schema  *openAPISchema
+}
+
+func (a *additionalProperties) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!bool" {
+		return node.Decode(&a.allowed)
+	}
+	a.allowed = true
+	return node.Decode(&a.schema)
+}
+
+// RegisterCRD регистрирует типы, описанные документами CustomResourceDefinition, чтобы
+// пользовательские ресурсы проверялись по их схемам OpenAPI v3, а не считались неизвестным kind.
+// Регистрируется каждая обслуживаемая (served) версия; документы других типов пропускаются.
+// warnings — по одному на CRD, в схеме которого есть непроверяемые ключевые слова.
+func RegisterCRD(data []byte) (registered []GVK, warnings []string, err error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var node yaml.Node
+		if err := decoder.Decode(&node); errors.Is(err, io.EOF) {
+			return registered, warnings, nil
+		} else if err != nil {
+			return registered, warnings, err
+		}
+		var crd crdDocument
+		if err := node.Decode(&crd); err != nil {
+			return registered, warnings, err
+		}
+		if crd.Kind != "CustomResourceDefinition" {
+			continue
+		}
+		if crd.APIVersion != crdAPIVersion {
+			return registered, warnings, fmt.Errorf("%s: apiVersion must be '%s'", crd.Metadata.Name, crdAPIVersion)
+		}
+		if crd.Spec.Group == "" || crd.Spec.Names.Kind == "" {
+			return registered, warnings, fmt.Errorf("%s: spec.group and spec.names.kind are required", crd.Metadata.Name)
+		}
+		var skipped []string
+		for _, version := range crd.Spec.Versions {
+			if !version.Served {
+				continue
+			}
+			version.Schema.OpenAPIV3Schema.skipped(&skipped)
+			gvk := GVK{Group: crd.Spec.Group, Version: version.Name, Kind: crd.Spec.Names.Kind}
+			schema := Schema{Type: "object"}
+			if openAPI := version.Schema.OpenAPIV3Schema; openAPI != nil {
+				schema = *openAPI.schema()
+				schema.Type = "object"
+				// Общие поля проверяются как у встроенных типов; в CRD metadata обычно описана
+				// пустым объектом, и она заменила бы схему metadata
+				for name := range schema.Properties {
+					if isCommonField(name) {
+						delete(schema.Properties, name)
+					}
+				}
+			}
+			if err := RegisterKind(gvk, schema); err != nil {
+				return registered, warnings, fmt.Errorf("%s: %v", crd.Metadata.Name, err)
+			}
+			registered = append(registered, gvk)
+		}
+		if len(skipped) > 0 {
+			sort.Strings(skipped)
+			warnings = append(warnings, fmt.Sprintf("%s: schema keywords are not checked: %s", crd.Metadata.Name, strings.Join(skipped, ", ")))
+		}
+	}
+}
+
+// schema переводит схему OpenAPI в Schema
+func (o *openAPISchema) schema() *Schema {
+	if o == nil {
+		return nil
+	}
+	schema := &Schema{
+		Type:        o.Type,
+		Description: o.Description,
+		Required:    o.Required,
+		Items:       o.Items.schema(),
+		Pattern:     o.Pattern,
+		Minimum:     o.Minimum,
+		Maximum:     o.Maximum,
+		Nullable:    o.Nullable,
+		MinItems:    o.MinItems,
+		MaxItems:    o.MaxItems,
+		MinLength:   o.MinLength,
+		MaxLength:   o.MaxLength,
+		Format:      o.Format,
+		AllOf:       schemas(o.AllOf),
+		AnyOf:       schemas(o.AnyOf),
+		OneOf:       schemas(o.OneOf),
+	}
+	// Целое или строка: тип не проверяется, как у полей вида targetPort
+	if o.IntOrString {
+		schema.Type = ""
+	}
+	// Значения enum любого типа сравниваются в строковой записи, как их печатает fmt
+	for _, value := range o.Enum {
+		schema.Enum = append(schema.Enum, fmt.Sprint(value))
+	}
+	if len(o.Properties) > 0 {
+		schema.Properties = make(map[string]*Schema, len(o.Properties))
+		for name, property := range o.Properties {
+			schema.Properties[name] = property.schema()
+		}
+	}
+	switch {
+	case o.PreserveUnknown:
+		schema.AdditionalProperties = &Schema{}
+	case o.AdditionalProperties != nil && o.AdditionalProperties.schema != nil:
+		schema.AdditionalProperties = o.AdditionalProperties.schema.schema()
+	case o.AdditionalProperties != nil && o.AdditionalProperties.allowed:
+		schema.AdditionalProperties = &Schema{}
+	}
+	return schema
+}
+
+func schemas(list []*openAPISchema) []*Schema {
+	var converted []*Schema
+	for _, o := range list {
+		converted = append(converted, o.schema())
+	}
+	return converted
+}
+
+// skipped добавляет в список ключевые слова схемы и вложенных схем, которые не проверяются
+func (o *openAPISchema) skipped(keywords *[]string) {
+	if o == nil {
+		return
+	}
+	add := func(keyword string, present bool) {
+		if present && !containsString(*keywords, keyword) {
+			*keywords = append(*keywords, keyword)
+		}
+	}
+	add("not", o.Not != nil)
+	add("uniqueItems", o.UniqueItems)
+	add("multipleOf", o.MultipleOf != nil)
+	add("exclusiveMinimum", o.ExclusiveMinimum)
+	add("exclusiveMaximum", o.ExclusiveMaximum)
+	add("minProperties", o.MinProperties != nil)
+	add("maxProperties", o.MaxProperties != nil)
+	add("x-kubernetes-validations", len(o.Validations) > 0)
+	add("format: "+o.Format, !knownFormat(o.Format))
+
+	for _, property := range o.Properties {
+		property.skipped(keywords)
+	}
+	if o.AdditionalProperties != nil {
+		o.AdditionalProperties.schema.skipped(keywords)
+	}
+	o.Items.skipped(keywords)
+	for _, branches := range [][]*openAPISchema{o.AllOf, o.AnyOf, o.OneOf} {
+		for _, branch := range branches {
+			branch.skipped(keywords)
+		}
+	}
+}
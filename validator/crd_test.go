@@ -0,0 +1,134 @@
+package validator_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator/validatortest"
+)
+
+// Регистрация типов глобальна, поэтому у CRD теста собственная группа
+const widgetCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.crdtest.example.com
+spec:
+  group: crdtest.example.com
+  names:
+    kind: Widget
+  versions:
+    - name: v1
+      served: true
+      schema:
+        openAPIV3Schema:
+          type: object
+          properties:
+            spec:
+              type: object
+              properties:
+                name:
+                  type: string
+                  minLength: 3
+                  maxLength: 8
+                tags:
+                  type: array
+                  minItems: 1
+                  maxItems: 2
+                  uniqueItems: true
+                  items:
+                    type: string
+                email:
+                  type: string
+                  format: email
+                checksum:
+                  type: string
+                  format: sha1
+                replicas:
+                  type: integer
+                  enum: [1, 3, 5]
+                  multipleOf: 1
+                debug:
+                  type: boolean
+                  enum: [false]
+                port:
+                  anyOf:
+                    - type: integer
+                    - type: string
+                source:
+                  type: object
+                  oneOf:
+                    - required: [git]
+                    - required: [image]
+                  properties:
+                    git:
+                      type: string
+                    image:
+                      type: string
+                limits:
+                  type: object
+                  allOf:
+                    - required: [cpu]
+                  not:
+                    required: [gpu]
+                  properties:
+                    cpu:
+                      type: string
+                    gpu:
+                      type: string
+`
+
+func TestRegisterCRDWarnsAboutSkippedKeywords(t *testing.T) {
+	kinds, warnings, err := validator.RegisterCRD([]byte(widgetCRD))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kinds) != 1 {
+		t.Fatalf("registered %v, want one kind", kinds)
+	}
+	want := []string{"widgets.crdtest.example.com: schema keywords are not checked: format: sha1, multipleOf, not, uniqueItems"}
+	if !reflect.DeepEqual(warnings, want) {
+		t.Errorf("warnings = %q, want %q", warnings, want)
+	}
+
+	tests := []struct {
+		name    string
+		field   validator.FieldPath
+		value   interface{}
+		message string
+	}{
+		{"short name", "spec.name", "ab", "must be at least 3 characters long"},
+		{"long name", "spec.name", "abcdefghi", "must be at most 8 characters long"},
+		{"no tags", "spec.tags", []interface{}{}, "must have at least 1 items"},
+		{"too many tags", "spec.tags", []interface{}{"a", "b", "c"}, "must have at most 2 items"},
+		{"invalid email", "spec.email", "admin", "must be a valid email"},
+		{"number outside enum", "spec.replicas", 2, "unsupported value '2'"},
+		{"boolean outside enum", "spec.debug", true, "unsupported value 'true'"},
+		{"no anyOf branch", "spec.port", []interface{}{80}, "does not match any of the anyOf schemas"},
+		{"both oneOf branches", "spec.source", map[string]interface{}{"git": "repo", "image": "app"}, "matches 2"},
+		{"no oneOf branch", "spec.source", map[string]interface{}{}, "matches 0"},
+		{"allOf branch", "spec.limits", map[string]interface{}{"gpu": "1"}, "cpu"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			widget := validatortest.NewManifest("crdtest.example.com/v1", "Widget", "w").Set(tt.field, tt.value)
+			findings := validatortest.ValidateFixture(t, widget, "widget.yaml")
+			if len(findings) != 1 || !strings.Contains(findings[0].Message, tt.message) {
+				t.Errorf("findings:\n%swant one containing %q", validatortest.FormatFindings(findings), tt.message)
+			}
+		})
+	}
+
+	valid := validatortest.NewManifest("crdtest.example.com/v1", "Widget", "w").
+		Set("spec.name", "web").
+		Set("spec.tags", []interface{}{"a"}).
+		Set("spec.email", "admin@example.com").
+		Set("spec.checksum", "anything").
+		Set("spec.replicas", 3).
+		Set("spec.debug", false).
+		Set("spec.port", "http").
+		Set("spec.source.git", "repo").
+		Set("spec.limits.cpu", "1")
+	validatortest.AssertRules(t, validatortest.ValidateFixture(t, valid, "widget.yaml"))
+}
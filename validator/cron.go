@@ -0,0 +1,105 @@
+package validator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Поле расписания cron: допустимый диапазон и имена значений
+type cronField struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+// Сокращения расписания, которые понимает контроллер CronJob
+var cronDescriptors = []string{"@yearly", "@annually", "@monthly", "@weekly", "@daily", "@midnight", "@hourly"}
+
+// parseCronSchedule проверяет расписание CronJob в формате cron из пяти полей, как его разбирает
+// контроллер: списки, диапазоны, шаги, имена месяцев и дней недели, сокращения вроде @daily и @every 1h
+func parseCronSchedule(schedule string) error {
+	schedule = strings.TrimSpace(schedule)
+	if strings.HasPrefix(schedule, "TZ=") || strings.HasPrefix(schedule, "CRON_TZ=") {
+		return fmt.Errorf("time zones in the schedule are not supported, use spec.timeZone")
+	}
+	if strings.HasPrefix(schedule, "@") {
+		if every, found := strings.CutPrefix(schedule, "@every "); found {
+			if duration, err := time.ParseDuration(strings.TrimSpace(every)); err != nil || duration <= 0 {
+				return fmt.Errorf("@every needs a positive duration, e.g. @every 1h30m")
+			}
+			return nil
+		}
+		if !containsString(cronDescriptors, schedule) {
+			return fmt.Errorf("unknown descriptor %s (known: %s)", schedule, strings.Join(cronDescriptors, ", "))
+		}
+		return nil
+	}
+
+	fields := strings.Fields(schedule)
+	if len(fields) != len(cronFields) {
+		return fmt.Errorf("expected 5 fields (minute hour day-of-month month day-of-week), got %d", len(fields))
+	}
+	for i, field := range fields {
+		if err := cronFields[i].parse(field); err != nil {
+			return fmt.Errorf("%s: %v", cronFields[i].name, err)
+		}
+	}
+	return nil
+}
+
+func (f cronField) parse(field string) error {
+	for _, part := range strings.Split(field, ",") {
+		expression, step, hasStep := strings.Cut(part, "/")
+		if hasStep {
+			if n, err := strconv.Atoi(step); err != nil || n <= 0 {
+				return fmt.Errorf("invalid step '%s'", step)
+			}
+		}
+		// ? допускается в днях месяца и недели как синоним *
+		if expression == "*" || (expression == "?" && (f.name == "day of month" || f.name == "day of week")) {
+			continue
+		}
+		low, high, isRange := strings.Cut(expression, "-")
+		from, err := f.value(low)
+		if err != nil {
+			return err
+		}
+		to := from
+		if isRange {
+			if to, err = f.value(high); err != nil {
+				return err
+			}
+			if to < from {
+				return fmt.Errorf("range '%s' ends before it starts", expression)
+			}
+		}
+	}
+	return nil
+}
+
+// value разбирает одно значение поля: число из диапазона или имя
+func (f cronField) value(text string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(text, name) {
+			return i + f.min, nil
+		}
+	}
+	n, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value '%s'", text)
+	}
+	if n < f.min || n > f.max {
+		return 0, fmt.Errorf("value %d out of range %d-%d", n, f.min, f.max)
+	}
+	return n, nil
+}
@@ -0,0 +1,90 @@
+package validator
+
+import "strings"
+
+// FieldInfo описывает поле документа в позиции курсора
+type FieldInfo struct {
+	// Path — путь к полю; индексы элементов записываются как [*]
+	Path     string
+	Name     string
+	Schema   *Schema
+	Required bool
+	// Rules — правила, которые проверяют поле
+	Rules []Rule
+}
+
+// FieldAt возвращает поле, ключ или значение которого записано на строке курсора;
+// line и column отсчитываются с единицы. Для полей, не описанных схемой, возвращается nil.
+func FieldAt(data []byte, line, column int) *FieldInfo {
+	lines := strings.Split(string(data), "\n")
+	if line < 1 || line > len(lines) {
+		return nil
+	}
+	l := parseOutline(lines[line-1])
+	if l.blank || (l.key == "" && len(l.dashes) == 0) || column < 1 || column-1 > len([]rune(lines[line-1])) {
+		return nil
+	}
+	// Путь к отображению либо последовательности, которым принадлежит строка
+	cursor := outlineAt(lines, line-1, l.keyCol+1)
+	if cursor == nil {
+		return nil
+	}
+	if l.key != "" {
+		cursor.path = append(cursor.path, PathSegment{Key: l.key})
+	}
+
+	info := &FieldInfo{}
+	var parent *Schema
+	schema := SchemaFor(cursor.apiVersion, cursor.kind)
+	for _, segment := range cursor.path {
+		if schema == nil {
+			return nil
+		}
+		parent = schema
+		if segment.IsIndex {
+			info.Path += "[*]"
+			schema = schema.Items
+			continue
+		}
+		if info.Path != "" {
+			info.Path += "."
+		}
+		info.Path += segment.Key
+		info.Name = segment.Key
+		schema = schema.property(segment.Key)
+	}
+	if schema == nil || len(cursor.path) == 0 {
+		return nil
+	}
+	info.Schema = schema
+	last := cursor.path[len(cursor.path)-1]
+	info.Required = !last.IsIndex && containsString(parent.Required, last.Key)
+
+	ids := schema.Rules
+	if len(ids) == 0 {
+		ids = schemaRules(schema, info.Required)
+	}
+	for _, id := range ids {
+		if rule, ok := FindRule(id); ok && rule.State != StateRemoved {
+			info.Rules = append(info.Rules, rule)
+		}
+	}
+	return info
+}
+
+// schemaRules выводит правила, которыми validateSchema проверяет поле
+func schemaRules(schema *Schema, required bool) []string {
+	var ids []string
+	if required {
+		ids = append(ids, ruleRequiredField)
+	}
+	if schema.Type != "" {
+		ids = append(ids, ruleFieldType)
+	}
+	if len(schema.Enum) > 0 || schema.Pattern != "" || schema.Minimum != nil || schema.Maximum != nil ||
+		schema.MinItems != nil || schema.MaxItems != nil || schema.MinLength != nil || schema.MaxLength != nil ||
+		schema.Format != "" || len(schema.AllOf) > 0 || len(schema.AnyOf) > 0 || len(schema.OneOf) > 0 {
+		ids = append(ids, ruleFieldValue)
+	}
+	return ids
+}
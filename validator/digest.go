@@ -0,0 +1,29 @@
+package validator
+
+import "fmt"
+
+// DigestResolver возвращает текущий дайджест образа (sha256:...) по его тегу. Ошибку
+// резолвер сообщает сам: находка от неё не зависит, чтобы её отпечаток оставался стабильным.
+type DigestResolver func(image string) (string, error)
+
+// validateImageDigest требует закреплять образ дайджестом: тег можно перезаписать, дайджест — нет.
+// Если задан DigestResolver, подсказка содержит ссылку вида repo:tag@sha256:...
+func (v *Validator) validateImageDigest(image string, index int, filename string) {
+	tag, digest := imageReference(image)
+	if digest != "" {
+		return
+	}
+	path := v.containerPath(index).Field("image")
+	message := fmt.Sprintf("%s: container[%d].image is not pinned by digest", filename, index)
+	remediation := Remediation{Action: ActionSet, Pattern: `@sha256:[0-9a-f]{64}$`}
+	if resolve := v.options.DigestResolver; resolve != nil {
+		if tag == "" {
+			image += ":latest"
+		}
+		if resolved, err := resolve(image); err == nil {
+			remediation.Value = image + "@" + resolved
+		}
+	}
+	v.addError(ruleImageDigest, path, message)
+	v.suggest(remediation)
+}
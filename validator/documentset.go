@@ -0,0 +1,106 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DocumentSet накапливает документы для правил этапа PhaseCrossFile, которым нужен весь набор
+type DocumentSet struct {
+	// CheckReferences включает правила связей между документами (YV4xx); имеет смысл,
+	// только когда в наборе все манифесты приложения, иначе ссылки окажутся «висячими»
+	CheckReferences bool
+
+	mu        sync.Mutex
+	documents []setDocument
+}
+
+type setDocument struct {
+	filename string
+	root     *yaml.Node
+	document map[string]interface{}
+}
+
+// KeepsDocuments сообщает, что набор хранит добавленные документы: их требуют правила
+// уникальности и проверки связей
+func (s *DocumentSet) KeepsDocuments() bool {
+	return hasUniqueRules() || s.CheckReferences
+}
+
+// Add добавляет документ в набор; документы, которые не разбираются, пропускаются.
+// Без правил уникальности и проверки связей документы не сохраняются, чтобы не расходовать память.
+func (s *DocumentSet) Add(data []byte, filename string) {
+	if !s.KeepsDocuments() {
+		return
+	}
+	var v Validator
+	root, document, ok := v.parse(data, filename)
+	if !ok || document == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.documents = append(s.documents, setDocument{filename: filename, root: root, document: document})
+}
+
+// Validate выполняет правила уникальности DSL и проверки связей над набором и возвращает находки по именам файлов
+func (s *DocumentSet) Validate() map[string][]Finding {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	findings := map[string][]Finding{}
+	for _, rule := range registeredCustomRules() {
+		if rule.Unique == nil {
+			continue
+		}
+		// Первое вхождение значения в группе
+		seen := map[string]Reference{}
+		for _, doc := range s.documents {
+			var v Validator
+			kind, _ := doc.document["kind"].(string)
+			for _, target := range rule.targets(doc.document, kind) {
+				key := rule.scopeKey(doc.document) + "\x00" + fmt.Sprint(target.value)
+				first, duplicate := seen[key]
+				if !duplicate {
+					seen[key] = doc.reference(target.path, fmt.Sprintf("first occurrence of '%v'", target.value))
+					continue
+				}
+				problem := fmt.Sprintf("'%v' duplicates %s %s", target.value, first.File, first.Path)
+				v.addError(rule.ID, target.path, fmt.Sprintf("%s: %s", doc.filename, rule.message(target.path, target.value, problem)))
+				v.errors[len(v.errors)-1].References = []Reference{first}
+			}
+			v.resolvePositions(doc.root)
+			findings[doc.filename] = append(findings[doc.filename], v.errors...)
+		}
+	}
+	if s.CheckReferences {
+		s.validateReferences(findings)
+		s.validateUnused(findings)
+		s.validateServiceAccounts(findings)
+	}
+	return findings
+}
+
+// scopeKey строит ключ группы из полей unique.scope
+func (r *compiledRule) scopeKey(document map[string]interface{}) string {
+	parts := make([]string, len(r.Unique.Scope))
+	for i, field := range r.Unique.Scope {
+		if value, exists := lookup(document, field); exists {
+			parts[i] = fmt.Sprint(value)
+		}
+	}
+	return strings.Join(parts, "\x00")
+}
+
+func hasUniqueRules() bool {
+	for _, rule := range registeredCustomRules() {
+		if rule.Unique != nil {
+			return true
+		}
+	}
+	return false
+}
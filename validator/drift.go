@@ -0,0 +1,138 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// LiveObject возвращает объект из кластера; nil без ошибки, если объекта нет
+type LiveObject func(apiVersion, kind, namespace, name string) (map[string]interface{}, error)
+
+// Поля, которые заполняет сервер; они не считаются расхождением
+var serverManagedFields = []FieldPath{
+	"status",
+	"metadata.uid",
+	"metadata.resourceVersion",
+	"metadata.generation",
+	"metadata.creationTimestamp",
+	"metadata.managedFields",
+	"metadata.selfLink",
+	`metadata.annotations["kubectl.kubernetes.io/last-applied-configuration"]`,
+	`metadata.annotations["deployment.kubernetes.io/revision"]`,
+}
+
+// Drift сравнивает документы файла с объектами кластера. Сравниваются только поля, заданные
+// в манифесте: значения по умолчанию, которые добавил сервер, расхождением не считаются.
+// namespace — пространство имён для документов без metadata.namespace.
+func Drift(data []byte, filename, namespace string, live LiveObject) ([]Finding, error) {
+	documents, err := revisionDocuments(data)
+	if err != nil {
+		return nil, err
+	}
+	var findings []Finding
+	for _, doc := range documents {
+		manifest := setDocument{document: doc.document}
+		apiVersion, _ := doc.document["apiVersion"].(string)
+		if manifest.kind() == "" || manifest.name() == "" {
+			continue
+		}
+		objectNamespace := namespace
+		if ns, _ := lookupPath(doc.document, "metadata.namespace").(string); ns != "" {
+			objectNamespace = ns
+		}
+		resource := fmt.Sprintf("%s %s", manifest.kind(), manifest.name())
+
+		object, err := live(apiVersion, manifest.kind(), objectNamespace, manifest.name())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", resource, err)
+		}
+		var v Validator
+		if object == nil {
+			v.addError(ruleClusterDrift, "metadata.name", fmt.Sprintf("%s: %s does not exist in the cluster", filename, resource))
+		} else {
+			v.driftFindings(doc.document, object, filename, resource)
+		}
+		v.resolvePositions(doc.root)
+		findings = append(findings, v.errors...)
+	}
+	return findings, nil
+}
+
+// driftFindings добавляет находку на каждое поле манифеста, которое в кластере отличается или не задано
+func (v *Validator) driftFindings(manifest, object map[string]interface{}, filename, resource string) {
+	for _, path := range driftPaths(manifest, object, "") {
+		liveValue, exists := lookup(object, string(path))
+		message := fmt.Sprintf("%s: %s: %s is %s in the manifest but %s in the cluster", filename, resource, path, formatValue(lookupPath(manifest, path)), formatValue(liveValue))
+		if !exists {
+			message = fmt.Sprintf("%s: %s: %s is %s in the manifest but not set in the cluster", filename, resource, path, formatValue(lookupPath(manifest, path)))
+		}
+		v.addError(ruleClusterDrift, path, message)
+	}
+}
+
+// driftPaths возвращает пути к листьям манифеста, значения которых отличаются в живом объекте
+func driftPaths(manifest, object interface{}, path FieldPath) []FieldPath {
+	if object == nil {
+		return nil
+	}
+	for _, managed := range serverManagedFields {
+		if path != "" && path.HasPrefix(managed) {
+			return nil
+		}
+	}
+	switch manifest := manifest.(type) {
+	case map[string]interface{}:
+		liveMap, ok := object.(map[string]interface{})
+		if !ok {
+			return []FieldPath{path}
+		}
+		var paths []FieldPath
+		for _, key := range sortedKeys(manifest) {
+			liveValue, exists := liveMap[key]
+			if !exists {
+				if manifest[key] != nil {
+					paths = append(paths, path.Field(key))
+				}
+				continue
+			}
+			paths = append(paths, driftPaths(manifest[key], liveValue, path.Field(key))...)
+		}
+		return paths
+	case []interface{}:
+		liveList, ok := object.([]interface{})
+		if !ok || len(liveList) != len(manifest) {
+			return []FieldPath{path}
+		}
+		var paths []FieldPath
+		for i := range manifest {
+			paths = append(paths, driftPaths(manifest[i], liveList[i], path.Index(i))...)
+		}
+		return paths
+	}
+	if !sameScalar(manifest, object) {
+		return []FieldPath{path}
+	}
+	return nil
+}
+
+// sameScalar сравнивает скаляры так, как их понимает API: 1 и "1", 1Gi и 1073741824 равны
+func sameScalar(manifest, live interface{}) bool {
+	if reflect.DeepEqual(manifest, live) || fmt.Sprint(manifest) == fmt.Sprint(live) {
+		return true
+	}
+	a, errA := ParseQuantity(manifest)
+	b, errB := ParseQuantity(live)
+	return errA == nil && errB == nil && a.Cmp(b) == 0
+}
+
+func formatValue(value interface{}) string {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return fmt.Sprintf("a list of %d items", len(value))
+	case string:
+		return "'" + value + "'"
+	}
+	return fmt.Sprint(value)
+}
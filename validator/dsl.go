@@ -0,0 +1,650 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/internal/starlark"
+)
+
+// CustomRule — правило декларативного DSL в стиле Spectral: given выбирает значения
+// в документе, then проверяет их функциями
+type CustomRule struct {
+	ID          string   `yaml:"id"`
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description"`
+	Severity    Severity `yaml:"severity"`
+	// Environments задаёт уровень для окружений, выбранных через --env, например dev: warning, prod: error
+	Environments map[string]Severity `yaml:"environments"`
+	// Message — текст нарушения; подстановки {path}, {value} и {error}
+	Message string `yaml:"message"`
+	// Kinds ограничивает правило типами ресурсов; пустой список — все типы
+	Kinds []string `yaml:"kinds"`
+	// Given — путь с подстановками [*] (все элементы) и * (все ключи), например spec.containers[*]
+	Given string `yaml:"given"`
+	// When и Unless — условия применения к найденному значению: все условия When должны
+	// выполняться и ни одно из Unless. Поле условия с префиксом $. отсчитывается от корня документа.
+	When   []Check `yaml:"when"`
+	Unless []Check `yaml:"unless"`
+	Then   []Check `yaml:"then"`
+	// Unique требует, чтобы найденные значения не повторялись во всём проверяемом наборе документов
+	Unique *UniqueConstraint `yaml:"unique"`
+	// Parallel разрешает выполнять правило в отдельной горутине одновременно с другими такими правилами
+	Parallel bool `yaml:"parallel"`
+	// CacheKey — поля найденного значения, которые целиком определяют результат проверок then;
+	// для одинаковых ключей проверки выполняются один раз за запуск
+	CacheKey []string `yaml:"cacheKey"`
+	// Script — файл Starlark с функцией check(doc), которая сообщает о нарушениях через
+	// report(message, path); задаётся вместо given, then и unique. Поддерживается подмножество
+	// Starlark без **, побитовых операторов и множеств (см. пакет internal/starlark).
+	Script string `yaml:"script"`
+	// ScriptSource — текст скрипта; загрузчик конфигурации читает его из файла Script
+	ScriptSource string `yaml:"-"`
+	// Limits ограничивают шаги и время скрипта на одном документе
+	Limits ScriptLimits `yaml:"limits"`
+}
+
+// UniqueConstraint — ограничение уникальности значений given между документами
+type UniqueConstraint struct {
+	// Scope — поля от корня документа, задающие группу, внутри которой значения уникальны,
+	// например kind и metadata.namespace; пустой список — весь набор
+	Scope []string `yaml:"scope"`
+}
+
+// Check — проверка значения функцией; Field задаёт путь относительно найденного given значения
+type Check struct {
+	Field    string          `yaml:"field"`
+	Function string          `yaml:"function"`
+	Options  FunctionOptions `yaml:"functionOptions"`
+}
+
+// FunctionOptions — параметры функций проверки
+type FunctionOptions struct {
+	// length и range
+	Min *float64 `yaml:"min"`
+	Max *float64 `yaml:"max"`
+	// pattern
+	Match    string `yaml:"match"`
+	NotMatch string `yaml:"notMatch"`
+	// enumeration
+	Values []string `yaml:"values"`
+	// date: дата RFC 3339, YYYY-MM-DD или now
+	Before string `yaml:"before"`
+	After  string `yaml:"after"`
+	// casing: flat, camel, pascal, kebab, cobol, snake или macro
+	Type string `yaml:"type"`
+	// quantity: границы в единицах Kubernetes, например 500m или 2Gi
+	LessThan           string `yaml:"lessThan"`
+	LessThanOrEqual    string `yaml:"lessThanOrEqual"`
+	GreaterThan        string `yaml:"greaterThan"`
+	GreaterThanOrEqual string `yaml:"greaterThanOrEqual"`
+}
+
+// quantityBound — оператор сравнения количества и его граница
+type quantityBound struct {
+	operator string
+	bound    string
+	// holds сообщает, удовлетворяет ли результат big.Rat.Cmp(значение, граница) оператору
+	holds func(cmp int) bool
+}
+
+func (o FunctionOptions) quantityBounds() []quantityBound {
+	bounds := []quantityBound{
+		{"less than", o.LessThan, func(cmp int) bool { return cmp < 0 }},
+		{"less than or equal to", o.LessThanOrEqual, func(cmp int) bool { return cmp <= 0 }},
+		{"greater than", o.GreaterThan, func(cmp int) bool { return cmp > 0 }},
+		{"greater than or equal to", o.GreaterThanOrEqual, func(cmp int) bool { return cmp >= 0 }},
+	}
+	var set []quantityBound
+	for _, b := range bounds {
+		if b.bound != "" {
+			set = append(set, b)
+		}
+	}
+	return set
+}
+
+var casings = map[string]*regexp.Regexp{
+	"flat":   regexp.MustCompile(`^[a-z][a-z0-9]*$`),
+	"camel":  regexp.MustCompile(`^[a-z][a-z0-9]*(?:[A-Z0-9][a-z0-9]*)*$`),
+	"pascal": regexp.MustCompile(`^[A-Z][a-z0-9]*(?:[A-Z0-9][a-z0-9]*)*$`),
+	"kebab":  regexp.MustCompile(`^[a-z][a-z0-9]*(?:-[a-z0-9]+)*$`),
+	"cobol":  regexp.MustCompile(`^[A-Z][A-Z0-9]*(?:-[A-Z0-9]+)*$`),
+	"snake":  regexp.MustCompile(`^[a-z][a-z0-9]*(?:_[a-z0-9]+)*$`),
+	"macro":  regexp.MustCompile(`^[A-Z][A-Z0-9]*(?:_[A-Z0-9]+)*$`),
+}
+
+// compiledRule — правило DSL с разобранным путём и скомпилированными выражениями
+type compiledRule struct {
+	CustomRule
+	given    []PathSegment
+	patterns map[string]*regexp.Regexp
+	// script — функция check скрипта Starlark
+	script *starlark.Function
+}
+
+var customRules struct {
+	sync.RWMutex
+	rules []*compiledRule
+}
+
+// RegisterRule добавляет правило DSL; оно выполняется при каждой последующей проверке
+func RegisterRule(rule CustomRule) error {
+	if rule.ID == "" {
+		return fmt.Errorf("rule id is required")
+	}
+	if _, exists := FindRule(rule.ID); exists {
+		return fmt.Errorf("rule %s is already registered", rule.ID)
+	}
+	if rule.Severity == "" {
+		rule.Severity = SeverityError
+	}
+	if !validSeverity(rule.Severity) {
+		return fmt.Errorf("rule %s: severity must be 'error', 'warning' or 'info'", rule.ID)
+	}
+	for env, severity := range rule.Environments {
+		if !validSeverity(severity) {
+			return fmt.Errorf("rule %s: environments.%s: severity must be 'error', 'warning' or 'info'", rule.ID, env)
+		}
+	}
+	if rule.Script != "" {
+		if rule.Given != "" || len(rule.When) > 0 || len(rule.Unless) > 0 || len(rule.Then) > 0 || rule.Unique != nil {
+			return fmt.Errorf("rule %s: script cannot be combined with given, when, unless, then or unique", rule.ID)
+		}
+		check, err := compileScript(rule.Script, rule.ScriptSource, rule.Limits)
+		if err != nil {
+			return fmt.Errorf("rule %s: %v", rule.ID, err)
+		}
+		addCustomRule(&compiledRule{CustomRule: rule, script: check})
+		return nil
+	}
+	if len(rule.Then) == 0 && rule.Unique == nil {
+		return fmt.Errorf("rule %s: then, unique or script is required", rule.ID)
+	}
+	if rule.Unique != nil {
+		for i, field := range rule.Unique.Scope {
+			if _, err := FieldPath(field).Segments(); err != nil {
+				return fmt.Errorf("rule %s: unique.scope[%d]: %v", rule.ID, i, err)
+			}
+		}
+	}
+
+	for i, field := range rule.CacheKey {
+		if _, err := FieldPath(field).Segments(); err != nil {
+			return fmt.Errorf("rule %s: cacheKey[%d]: %v", rule.ID, i, err)
+		}
+	}
+
+	compiled := &compiledRule{CustomRule: rule, patterns: map[string]*regexp.Regexp{}}
+	given, err := parseGiven(rule.Given)
+	if err != nil {
+		return fmt.Errorf("rule %s: given: %v", rule.ID, err)
+	}
+	compiled.given = given
+	clauses := []struct {
+		name   string
+		checks []Check
+	}{{"when", rule.When}, {"unless", rule.Unless}, {"then", rule.Then}}
+	for _, clause := range clauses {
+		for i, check := range clause.checks {
+			if err := compiled.compileCheck(check); err != nil {
+				return fmt.Errorf("rule %s: %s[%d]: %v", rule.ID, clause.name, i, err)
+			}
+		}
+	}
+
+	addCustomRule(compiled)
+	return nil
+}
+
+func addCustomRule(rule *compiledRule) {
+	customRules.Lock()
+	defer customRules.Unlock()
+
+	customRules.rules = append(customRules.rules, rule)
+}
+
+// ResetRules удаляет правила DSL и подключаемых модулей, например чтобы проверить
+// несколько наборов правил в одном процессе
+func ResetRules() {
+	customRules.Lock()
+	customRules.rules = nil
+	customRules.Unlock()
+
+	externalRules.Lock()
+	externalRules.rules = nil
+	externalRules.Unlock()
+}
+
+func (r *compiledRule) compileCheck(check Check) error {
+	if _, err := FieldPath(strings.TrimPrefix(check.Field, "$.")).Segments(); err != nil {
+		return err
+	}
+	options := check.Options
+	switch check.Function {
+	case "truthy", "defined", "undefined":
+	case "length", "range":
+		if options.Min == nil && options.Max == nil {
+			return fmt.Errorf("%s requires min or max", check.Function)
+		}
+	case "pattern":
+		if options.Match == "" && options.NotMatch == "" {
+			return fmt.Errorf("pattern requires match or notMatch")
+		}
+		for _, pattern := range []string{options.Match, options.NotMatch} {
+			if pattern == "" {
+				continue
+			}
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return err
+			}
+			r.patterns[pattern] = re
+		}
+	case "enumeration":
+		if len(options.Values) == 0 {
+			return fmt.Errorf("enumeration requires values")
+		}
+	case "date":
+		if options.Before == "" && options.After == "" {
+			return fmt.Errorf("date requires before or after")
+		}
+		for _, bound := range []string{options.Before, options.After} {
+			if _, err := parseDateBound(bound); bound != "" && err != nil {
+				return err
+			}
+		}
+	case "quantity":
+		bounds := options.quantityBounds()
+		if len(bounds) == 0 {
+			return fmt.Errorf("quantity requires lessThan, lessThanOrEqual, greaterThan or greaterThanOrEqual")
+		}
+		for _, b := range bounds {
+			if _, err := ParseQuantity(b.bound); err != nil {
+				return err
+			}
+		}
+	case "casing":
+		if casings[options.Type] == nil {
+			return fmt.Errorf("casing type must be one of flat, camel, pascal, kebab, cobol, snake, macro")
+		}
+	default:
+		return fmt.Errorf("unknown function %q", check.Function)
+	}
+	return nil
+}
+
+func validSeverity(severity Severity) bool {
+	return severity == SeverityError || severity == SeverityWarning || severity == SeverityInfo
+}
+
+// parseGiven разбирает путь given; индекс -1 означает любой элемент, ключ * — любой ключ
+func parseGiven(given string) ([]PathSegment, error) {
+	given = strings.TrimPrefix(strings.TrimPrefix(given, "$"), ".")
+	return FieldPath(strings.ReplaceAll(given, "[*]", "[-1]")).Segments()
+}
+
+// customRuleList возвращает зарегистрированные правила DSL в виде записей каталога
+func customRuleList() []Rule {
+	customRules.RLock()
+	defer customRules.RUnlock()
+
+	list := make([]Rule, 0, len(customRules.rules))
+	for _, r := range customRules.rules {
+		phase := PhaseSemantic
+		if r.Unique != nil {
+			phase = PhaseCrossFile
+		}
+		list = append(list, Rule{
+			ID:           r.ID,
+			Name:         r.Name,
+			Description:  r.Description,
+			State:        StateStable,
+			Severity:     r.Severity,
+			Environments: r.Environments,
+			Phase:        phase,
+			DependsOn:    []string{ruleFieldType},
+		})
+	}
+	return list
+}
+
+func registeredCustomRules() []*compiledRule {
+	customRules.RLock()
+	defer customRules.RUnlock()
+
+	return append([]*compiledRule(nil), customRules.rules...)
+}
+
+// selected — значение, найденное по пути given
+type selected struct {
+	path  FieldPath
+	value interface{}
+}
+
+func selectValues(value interface{}, segments []PathSegment, path FieldPath) []selected {
+	if len(segments) == 0 {
+		return []selected{{path: path, value: value}}
+	}
+	segment, rest := segments[0], segments[1:]
+
+	var found []selected
+	switch val := value.(type) {
+	case map[string]interface{}:
+		if segment.IsIndex {
+			return nil
+		}
+		if segment.Key == "*" {
+			for _, key := range sortedKeys(val) {
+				found = append(found, selectValues(val[key], rest, path.Field(key))...)
+			}
+		} else if child, exists := val[segment.Key]; exists {
+			found = selectValues(child, rest, path.Field(segment.Key))
+		}
+	case []interface{}:
+		if !segment.IsIndex {
+			return nil
+		}
+		for i, item := range val {
+			if segment.Index < 0 || segment.Index == i {
+				found = append(found, selectValues(item, rest, path.Index(i))...)
+			}
+		}
+	}
+	return found
+}
+
+// lookup возвращает значение по относительному пути без подстановок
+func lookup(value interface{}, field string) (interface{}, bool) {
+	segments, _ := FieldPath(field).Segments()
+	for _, segment := range segments {
+		switch val := value.(type) {
+		case map[string]interface{}:
+			child, exists := val[segment.Key]
+			if segment.IsIndex || !exists {
+				return nil, false
+			}
+			value = child
+		case []interface{}:
+			if !segment.IsIndex || segment.Index < 0 || segment.Index >= len(val) {
+				return nil, false
+			}
+			value = val[segment.Index]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// validateCustomRules выполняет правила DSL; правила с parallel запускаются одновременно,
+// а их находки добавляются в порядке регистрации, чтобы отчёт не зависел от планирования
+func (v *Validator) validateCustomRules(document map[string]interface{}, filename string) {
+	kind, _ := document["kind"].(string)
+	rules := registeredCustomRules()
+	results := make([][]Finding, len(rules))
+	var wg sync.WaitGroup
+	for i, rule := range rules {
+		if !rule.Parallel {
+			results[i] = rule.check(document, kind, filename)
+			continue
+		}
+		wg.Add(1)
+		go func(i int, rule *compiledRule) {
+			defer wg.Done()
+			results[i] = rule.check(document, kind, filename)
+		}(i, rule)
+	}
+	wg.Wait()
+	for _, findings := range results {
+		v.errors = append(v.errors, findings...)
+	}
+}
+
+func (r *compiledRule) check(document map[string]interface{}, kind, filename string) []Finding {
+	if r.script != nil {
+		return r.checkScript(document, kind, filename)
+	}
+	var v Validator
+	for _, target := range r.targets(document, kind) {
+		problems := r.problems(target.value)
+		for i, check := range r.Then {
+			if problems[i] == "" {
+				continue
+			}
+			value, _ := lookup(target.value, check.Field)
+			path := target.path
+			switch {
+			case check.Field == "":
+			case path == "" || strings.HasPrefix(check.Field, "["):
+				path += FieldPath(check.Field)
+			default:
+				path += FieldPath("." + check.Field)
+			}
+			v.addError(r.ID, path, fmt.Sprintf("%s: %s", filename, r.message(path, value, problems[i])))
+		}
+	}
+	return v.errors
+}
+
+// problems возвращает нарушения проверок then для найденного значения, по одному на проверку;
+// при заданном cacheKey результат берётся из общего кэша
+func (r *compiledRule) problems(target interface{}) []string {
+	evaluate := func() interface{} {
+		problems := make([]string, len(r.Then))
+		for i, check := range r.Then {
+			value, exists := lookup(target, check.Field)
+			problems[i] = r.evaluate(check, value, exists)
+		}
+		return problems
+	}
+	if len(r.CacheKey) == 0 {
+		return evaluate().([]string)
+	}
+	return Memo(r.ID, r.cacheKey(target), evaluate).([]string)
+}
+
+func (r *compiledRule) cacheKey(target interface{}) string {
+	parts := make([]string, len(r.CacheKey))
+	for i, field := range r.CacheKey {
+		// Отсутствующее поле отличаем от любого значения, в том числе от пустой строки
+		if value, exists := lookup(target, field); exists {
+			parts[i] = fmt.Sprintf("%#v", value)
+		} else {
+			parts[i] = "\x01"
+		}
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// targets возвращает значения given, к которым применяется правило
+func (r *compiledRule) targets(document map[string]interface{}, kind string) []selected {
+	if len(r.Kinds) > 0 && !containsString(r.Kinds, kind) {
+		return nil
+	}
+	var targets []selected
+	for _, target := range selectValues(document, r.given, "") {
+		if r.applies(document, target.value) {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// applies проверяет условия when и unless для найденного значения
+func (r *compiledRule) applies(document map[string]interface{}, target interface{}) bool {
+	for _, condition := range r.When {
+		if !r.holds(condition, document, target) {
+			return false
+		}
+	}
+	for _, condition := range r.Unless {
+		if r.holds(condition, document, target) {
+			return false
+		}
+	}
+	return true
+}
+
+// holds сообщает, выполняется ли условие. В отличие от проверок then, отсутствующее поле
+// условию не удовлетворяет, кроме функции undefined.
+func (r *compiledRule) holds(condition Check, document map[string]interface{}, target interface{}) bool {
+	var value interface{}
+	var exists bool
+	if field, fromRoot := strings.CutPrefix(condition.Field, "$."); fromRoot {
+		value, exists = lookup(document, field)
+	} else {
+		value, exists = lookup(target, condition.Field)
+	}
+	if !exists && condition.Function != "undefined" {
+		return false
+	}
+	return r.evaluate(condition, value, exists) == ""
+}
+
+func (r *compiledRule) message(path FieldPath, value interface{}, problem string) string {
+	if r.Message == "" {
+		return fmt.Sprintf("%s %s", displayPath(path), problem)
+	}
+	return strings.NewReplacer("{path}", displayPath(path), "{value}", fmt.Sprint(value), "{error}", problem).Replace(r.Message)
+}
+
+// evaluate применяет функцию проверки и возвращает описание нарушения либо пустую строку.
+// Кроме truthy и defined, функции пропускают отсутствующие поля, как в Spectral.
+func (r *compiledRule) evaluate(check Check, value interface{}, exists bool) string {
+	options := check.Options
+	switch check.Function {
+	case "truthy":
+		if !exists || isEmptyValue(value) {
+			return "must be set"
+		}
+		return ""
+	case "defined":
+		if !exists {
+			return "is required"
+		}
+		return ""
+	case "undefined":
+		if exists {
+			return "must not be set"
+		}
+		return ""
+	}
+	if !exists {
+		return ""
+	}
+
+	switch check.Function {
+	case "length":
+		length, ok := lengthOf(value)
+		if !ok {
+			return "must be a string, an array or an object"
+		}
+		return checkBounds("length", float64(length), options.Min, options.Max)
+	case "range":
+		number, _ := toNumber(value)
+		if number == nil {
+			return "must be a number"
+		}
+		return checkBounds("value", *number, options.Min, options.Max)
+	case "pattern":
+		str := fmt.Sprint(value)
+		if options.Match != "" && !r.patterns[options.Match].MatchString(str) {
+			return fmt.Sprintf("must match '%s'", options.Match)
+		}
+		if options.NotMatch != "" && r.patterns[options.NotMatch].MatchString(str) {
+			return fmt.Sprintf("must not match '%s'", options.NotMatch)
+		}
+	case "enumeration":
+		if !containsString(options.Values, fmt.Sprint(value)) {
+			return "must be " + quoteList(options.Values)
+		}
+	case "date":
+		date, err := parseDate(fmt.Sprint(value))
+		if err != nil {
+			return "must be a date"
+		}
+		if options.Before != "" {
+			if bound, _ := parseDateBound(options.Before); !date.Before(bound) {
+				return fmt.Sprintf("must be before %s", options.Before)
+			}
+		}
+		if options.After != "" {
+			if bound, _ := parseDateBound(options.After); !date.After(bound) {
+				return fmt.Sprintf("must be after %s", options.After)
+			}
+		}
+	case "quantity":
+		quantity, err := ParseQuantity(value)
+		if err != nil {
+			return "must be a quantity"
+		}
+		for _, b := range options.quantityBounds() {
+			bound, _ := ParseQuantity(b.bound)
+			if !b.holds(quantity.Cmp(bound)) {
+				return fmt.Sprintf("must be %s %s", b.operator, b.bound)
+			}
+		}
+	case "casing":
+		if str, ok := value.(string); !ok || !casings[options.Type].MatchString(str) {
+			return fmt.Sprintf("must be %s case", options.Type)
+		}
+	}
+	return ""
+}
+
+func checkBounds(what string, n float64, min, max *float64) string {
+	if min != nil && n < *min {
+		return fmt.Sprintf("%s must be at least %v", what, *min)
+	}
+	if max != nil && n > *max {
+		return fmt.Sprintf("%s must be at most %v", what, *max)
+	}
+	return ""
+}
+
+func lengthOf(value interface{}) (int, bool) {
+	switch val := value.(type) {
+	case string:
+		return len([]rune(val)), true
+	case []interface{}:
+		return len(val), true
+	case map[string]interface{}:
+		return len(val), true
+	}
+	return 0, false
+}
+
+func isEmptyValue(value interface{}) bool {
+	switch val := value.(type) {
+	case nil:
+		return true
+	case bool:
+		return !val
+	case string, []interface{}, map[string]interface{}:
+		n, _ := lengthOf(val)
+		return n == 0
+	}
+	return false
+}
+
+func parseDate(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+func parseDateBound(bound string) (time.Time, error) {
+	if bound == "now" {
+		return time.Now(), nil
+	}
+	t, err := parseDate(bound)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: use RFC 3339, YYYY-MM-DD or now", bound)
+	}
+	return t, nil
+}
@@ -0,0 +1,57 @@
+package validator
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// validateDuplicateKeys ищет повторяющиеся ключи отображений. Парсеры Kubernetes молча берут
+// последнее значение, поэтому предыдущие вхождения удаляются из дерева, и остальные правила
+// проверяют то же, что применит кластер.
+func (v *Validator) validateDuplicateKeys(node *yaml.Node, path FieldPath, filename string) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			v.validateDuplicateKeys(child, path, filename)
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			v.validateDuplicateKeys(child, path.Index(i), filename)
+		}
+	case yaml.MappingNode:
+		// Последнее вхождение каждого ключа; ключи слияния << повторять можно
+		last := map[string]int{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if key := node.Content[i]; key.Kind == yaml.ScalarNode && key.Tag != "!!merge" {
+				last[key.Value] = i
+			}
+		}
+		first := map[string]*yaml.Node{}
+		content := node.Content[:0]
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if index, ok := last[key.Value]; ok && key.Kind == yaml.ScalarNode && key.Tag != "!!merge" {
+				if previous, seen := first[key.Value]; seen {
+					v.addDuplicateKey(path.Field(key.Value), key, previous, filename)
+				} else {
+					first[key.Value] = key
+				}
+				if index != i {
+					continue
+				}
+			}
+			v.validateDuplicateKeys(value, path.Field(key.Value), filename)
+			content = append(content, key, value)
+		}
+		node.Content = content
+	}
+}
+
+// addDuplicateKey добавляет находку на позиции повторного ключа со ссылкой на первое вхождение
+func (v *Validator) addDuplicateKey(path FieldPath, key, first *yaml.Node, filename string) {
+	v.addError(ruleDuplicateKey, path, fmt.Sprintf("%s: %s is already defined at line %d; only the last value is used", filename, path, first.Line))
+	finding := &v.errors[len(v.errors)-1]
+	finding.Line, finding.Column = key.Line, key.Column
+	finding.References = []Reference{{File: filename, Path: path, Line: first.Line, Column: first.Column, Message: fmt.Sprintf("first definition of %s", key.Value)}}
+}
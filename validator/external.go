@@ -0,0 +1,105 @@
+package validator
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ExternalCheck — проверка правила, реализованного вне пакета, например в подключаемом модуле.
+// Возвращает находки документа; RuleID и Severity находок заполняются по правилу.
+type ExternalCheck func(document map[string]interface{}, filename string) []Finding
+
+// ExternalRule — правило подключаемого модуля: описание для каталога и проверка
+type ExternalRule struct {
+	Rule  Rule
+	Check ExternalCheck
+}
+
+var externalRules struct {
+	sync.RWMutex
+	rules []ExternalRule
+}
+
+// RegisterExternalRule добавляет правило подключаемого модуля; оно выполняется при каждой
+// последующей проверке, как правила DSL
+func RegisterExternalRule(rule ExternalRule) error {
+	if rule.Rule.ID == "" {
+		return fmt.Errorf("rule id is required")
+	}
+	if _, exists := FindRule(rule.Rule.ID); exists {
+		return fmt.Errorf("rule %s is already registered", rule.Rule.ID)
+	}
+	if rule.Check == nil {
+		return fmt.Errorf("rule %s: check is required", rule.Rule.ID)
+	}
+	if rule.Rule.Severity == "" {
+		rule.Rule.Severity = SeverityError
+	}
+	if !validSeverity(rule.Rule.Severity) {
+		return fmt.Errorf("rule %s: severity must be 'error', 'warning' or 'info'", rule.Rule.ID)
+	}
+	if rule.Rule.Group != "" && !containsString(RuleGroups(), rule.Rule.Group) {
+		return fmt.Errorf("rule %s: unknown group %q", rule.Rule.ID, rule.Rule.Group)
+	}
+	if rule.Rule.State == "" {
+		rule.Rule.State = StateStable
+	}
+	if replacement := rule.Rule.ReplacedBy; replacement != "" {
+		if _, exists := FindRule(replacement); !exists {
+			return fmt.Errorf("rule %s: replacement %s is not a known rule", rule.Rule.ID, replacement)
+		}
+	}
+	// Версии набора правил относятся только к встроенным правилам
+	rule.Rule.Since = ""
+	rule.Rule.Phase = PhaseSemantic
+
+	externalRules.Lock()
+	defer externalRules.Unlock()
+
+	externalRules.rules = append(externalRules.rules, rule)
+	return nil
+}
+
+// HasExternalRules сообщает, что зарегистрировано хотя бы одно правило подключаемого модуля.
+// Модуль получает имя файла, поэтому его находки могут зависеть не только от содержимого.
+func HasExternalRules() bool {
+	externalRules.RLock()
+	defer externalRules.RUnlock()
+	return len(externalRules.rules) > 0
+}
+
+func externalRuleList() []Rule {
+	externalRules.RLock()
+	defer externalRules.RUnlock()
+
+	list := make([]Rule, 0, len(externalRules.rules))
+	for _, rule := range externalRules.rules {
+		list = append(list, rule.Rule)
+	}
+	return list
+}
+
+// validateExternalRules выполняет правила подключаемых модулей; паника модуля становится
+// находкой его правила, а не завершает проверку
+func (v *Validator) validateExternalRules(document map[string]interface{}, filename string) {
+	externalRules.RLock()
+	rules := append([]ExternalRule(nil), externalRules.rules...)
+	externalRules.RUnlock()
+
+	for _, rule := range rules {
+		for _, finding := range runExternalCheck(rule, document, filename) {
+			finding.RuleID = rule.Rule.ID
+			finding.Severity = rule.Rule.Severity
+			v.errors = append(v.errors, finding)
+		}
+	}
+}
+
+func runExternalCheck(rule ExternalRule, document map[string]interface{}, filename string) (findings []Finding) {
+	defer func() {
+		if r := recover(); r != nil {
+			findings = []Finding{{Message: fmt.Sprintf("%s: rule %s failed: %v", filename, rule.Rule.ID, r)}}
+		}
+	}()
+	return rule.Check(document, filename)
+}
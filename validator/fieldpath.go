@@ -0,0 +1,217 @@
+package validator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FieldPath — путь к полю документа вида spec.containers[2].ports[0].containerPort.
+// Ключи с точками и скобками записываются в кавычках: metadata.labels["app.kubernetes.io/name"].
+type FieldPath string
+
+// PathSegment — элемент пути: ключ отображения либо индекс последовательности
+type PathSegment struct {
+	Key   string
+	Index int
+	// IsIndex отличает индекс [0] от ключа
+	IsIndex bool
+}
+
+// Field добавляет к пути ключ отображения
+func (p FieldPath) Field(name string) FieldPath {
+	if strings.ContainsAny(name, ".[]\"") || name == "" {
+		return FieldPath(string(p) + "[" + strconv.Quote(name) + "]")
+	}
+	if p == "" {
+		return FieldPath(name)
+	}
+	return FieldPath(string(p) + "." + name)
+}
+
+// Index добавляет к пути индекс элемента последовательности
+func (p FieldPath) Index(i int) FieldPath {
+	return FieldPath(fmt.Sprintf("%s[%d]", p, i))
+}
+
+// Join добавляет к пути относительный путь, например путь находки внутри элемента списка
+func (p FieldPath) Join(rel FieldPath) FieldPath {
+	switch {
+	case rel == "":
+		return p
+	case p == "" || strings.HasPrefix(string(rel), "["):
+		return p + rel
+	}
+	return p + "." + rel
+}
+
+func (p FieldPath) String() string {
+	return string(p)
+}
+
+// Parent возвращает путь к содержащему полю; для поля верхнего уровня — пустой путь
+func (p FieldPath) Parent() FieldPath {
+	segments, err := p.Segments()
+	if err != nil || len(segments) == 0 {
+		return ""
+	}
+	var parent FieldPath
+	for _, segment := range segments[:len(segments)-1] {
+		if segment.IsIndex {
+			parent = parent.Index(segment.Index)
+		} else {
+			parent = parent.Field(segment.Key)
+		}
+	}
+	return parent
+}
+
+// HasPrefix сообщает, совпадает ли путь с ancestor или лежит внутри него
+func (p FieldPath) HasPrefix(ancestor FieldPath) bool {
+	if ancestor == "" || p == ancestor {
+		return true
+	}
+	rest := strings.TrimPrefix(string(p), string(ancestor))
+	return len(rest) < len(p) && (rest[0] == '.' || rest[0] == '[')
+}
+
+// Segments разбирает путь на элементы
+func (p FieldPath) Segments() ([]PathSegment, error) {
+	var segments []PathSegment
+	s := string(p)
+	for len(s) > 0 {
+		switch {
+		case s[0] == '.':
+			s = s[1:]
+		case strings.HasPrefix(s, "[\""):
+			end := 2
+			for end < len(s) && (s[end] != '"' || s[end-1] == '\\') {
+				end++
+			}
+			if end >= len(s) || !strings.HasPrefix(s[end:], "\"]") {
+				return nil, fmt.Errorf("invalid field path %q: unterminated key", p)
+			}
+			key, err := strconv.Unquote(s[1 : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid field path %q: %v", p, err)
+			}
+			segments = append(segments, PathSegment{Key: key})
+			s = s[end+2:]
+		case s[0] == '[':
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid field path %q: unterminated index", p)
+			}
+			index, err := strconv.Atoi(s[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("invalid field path %q: %v", p, err)
+			}
+			segments = append(segments, PathSegment{Index: index, IsIndex: true})
+			s = s[end+1:]
+		default:
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+			segments = append(segments, PathSegment{Key: s[:end]})
+			s = s[end:]
+		}
+	}
+	return segments, nil
+}
+
+// Resolve находит узел по пути; nil, если такого поля в документе нет
+func (p FieldPath) Resolve(root *yaml.Node) *yaml.Node {
+	node, exact := p.resolve(root)
+	if !exact {
+		return nil
+	}
+	return node
+}
+
+// ResolveNearest находит узел по пути либо ближайшего существующего предка,
+// например отображение, в котором отсутствует обязательное поле
+func (p FieldPath) ResolveNearest(root *yaml.Node) *yaml.Node {
+	node, _ := p.resolve(root)
+	return node
+}
+
+func (p FieldPath) resolve(root *yaml.Node) (*yaml.Node, bool) {
+	segments, err := p.Segments()
+	if err != nil {
+		return nil, false
+	}
+	return resolveSegments(root, segments)
+}
+
+// KeyNode возвращает узел ключа, если путь указывает на поле отображения
+func (p FieldPath) KeyNode(root *yaml.Node) *yaml.Node {
+	segments, err := p.Segments()
+	if err != nil || len(segments) == 0 || segments[len(segments)-1].IsIndex {
+		return nil
+	}
+	parent, exact := resolveSegments(root, segments[:len(segments)-1])
+	if !exact {
+		return nil
+	}
+	parent = dealias(parent)
+	if parent.Kind != yaml.MappingNode {
+		return nil
+	}
+	key := segments[len(segments)-1].Key
+	var found *yaml.Node
+	for i := 0; i+1 < len(parent.Content); i += 2 {
+		if parent.Content[i].Value == key {
+			found = parent.Content[i]
+		}
+	}
+	return found
+}
+
+func resolveSegments(root *yaml.Node, segments []PathSegment) (*yaml.Node, bool) {
+	if root == nil {
+		return nil, false
+	}
+	node := root
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	for _, segment := range segments {
+		next := childNode(node, segment)
+		if next == nil {
+			return node, false
+		}
+		node = next
+	}
+	return node, true
+}
+
+func childNode(node *yaml.Node, segment PathSegment) *yaml.Node {
+	node = dealias(node)
+	if segment.IsIndex {
+		if node.Kind != yaml.SequenceNode || segment.Index < 0 || segment.Index >= len(node.Content) {
+			return nil
+		}
+		return node.Content[segment.Index]
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	var found *yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		// При повторяющихся ключах yaml.v3 берёт последнее значение
+		if node.Content[i].Value == segment.Key {
+			found = node.Content[i+1]
+		}
+	}
+	return found
+}
+
+func dealias(node *yaml.Node) *yaml.Node {
+	for node != nil && node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
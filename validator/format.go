@@ -0,0 +1,44 @@
+package validator
+
+import (
+	"encoding/base64"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+var (
+	hostnamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+	uuidPattern     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// stringFormats — проверки значений format схем OpenAPI, которые понимает Kubernetes.
+// Форматы чисел (int32, int64, float, double) и password значение строки не ограничивают.
+var stringFormats = map[string]func(string) bool{
+	"date-time": func(s string) bool { _, err := time.Parse(time.RFC3339, s); return err == nil },
+	"date":      func(s string) bool { _, err := time.Parse("2006-01-02", s); return err == nil },
+	"duration":  func(s string) bool { _, err := time.ParseDuration(s); return err == nil },
+	"email":     func(s string) bool { _, err := mail.ParseAddress(s); return err == nil },
+	"hostname":  func(s string) bool { return len(s) <= 253 && hostnamePattern.MatchString(s) },
+	"ipv4":      func(s string) bool { ip := net.ParseIP(s); return ip != nil && ip.To4() != nil },
+	"ipv6":      func(s string) bool { ip := net.ParseIP(s); return ip != nil && ip.To4() == nil },
+	"cidr":      func(s string) bool { _, _, err := net.ParseCIDR(s); return err == nil },
+	"mac":       func(s string) bool { _, err := net.ParseMAC(s); return err == nil },
+	"uuid":      func(s string) bool { return uuidPattern.MatchString(s) },
+	"byte":      func(s string) bool { _, err := base64.StdEncoding.DecodeString(s); return err == nil },
+	"uri": func(s string) bool {
+		u, err := url.Parse(s)
+		return err == nil && u.Scheme != ""
+	},
+}
+
+// knownFormat сообщает, что формат проверяется или намеренно не ограничивает строку
+func knownFormat(format string) bool {
+	switch format {
+	case "", "int32", "int64", "float", "double", "password":
+		return true
+	}
+	return stringFormats[format] != nil
+}
@@ -0,0 +1,131 @@
+package validator
+
+import (
+	"sort"
+	"strings"
+)
+
+// GraphNode — ресурс графа ссылок; ресурсы, на которые ссылаются, но которых нет в наборе, помечены Missing
+type GraphNode struct {
+	Kind      string
+	Namespace string
+	Name      string
+	File      string
+	Line      int
+	Missing   bool
+}
+
+// ID — уникальный идентификатор ресурса в графе: Kind/namespace/name
+func (n GraphNode) ID() string {
+	return n.Kind + "/" + n.Namespace + "/" + n.Name
+}
+
+// GraphEdge — ссылка рабочей нагрузки на ресурс; Label — вид ссылки: envFrom, env, volume, selector
+type GraphEdge struct {
+	From, To string
+	Label    string
+	Optional bool
+}
+
+// Graph — ресурсы набора и ссылки рабочих нагрузок на ConfigMap, Secret, PersistentVolumeClaim и Service
+type Graph struct {
+	Nodes []GraphNode
+	Edges []GraphEdge
+}
+
+// Graph строит граф ссылок набора теми же правилами, что и проверки связей (YV4xx);
+// документы сохраняются, только если включён CheckReferences
+func (s *DocumentSet) Graph() Graph {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.graph()
+}
+
+func (s *DocumentSet) graph() Graph {
+	nodes := map[string]GraphNode{}
+	edges := map[GraphEdge]bool{}
+	for _, doc := range s.documents {
+		if doc.kind() == "" || doc.name() == "" {
+			continue
+		}
+		node := GraphNode{Kind: doc.kind(), Namespace: doc.namespace(), Name: doc.name(), File: doc.filename}
+		if key := FieldPath("metadata.name").ResolveNearest(doc.root); key != nil {
+			node.Line = key.Line
+		}
+		nodes[node.ID()] = node
+	}
+
+	for _, doc := range s.documents {
+		from := GraphNode{Kind: doc.kind(), Namespace: doc.namespace(), Name: doc.name()}.ID()
+		if _, ok := nodes[from]; !ok {
+			continue
+		}
+		for _, ref := range References(doc.document) {
+			target := GraphNode{Kind: ref.Kind, Namespace: doc.namespace(), Name: ref.Name, Missing: true}
+			if _, ok := nodes[target.ID()]; !ok {
+				nodes[target.ID()] = target
+			}
+			edges[GraphEdge{From: from, To: target.ID(), Label: referenceLabel(ref.Path), Optional: ref.Optional}] = true
+		}
+	}
+
+	// Сервис связан с рабочими нагрузками, чьи поды попадают под его селектор
+	for _, service := range s.documents {
+		selector, _ := lookupPath(service.document, "spec.selector").(map[string]interface{})
+		if service.kind() != "Service" || len(selector) == 0 || service.name() == "" {
+			continue
+		}
+		to := GraphNode{Kind: "Service", Namespace: service.namespace(), Name: service.name()}.ID()
+		for _, doc := range s.documents {
+			from := GraphNode{Kind: doc.kind(), Namespace: doc.namespace(), Name: doc.name()}.ID()
+			if _, ok := nodes[from]; !ok || doc.namespace() != service.namespace() {
+				continue
+			}
+			for _, template := range podTemplates(doc.document) {
+				labels, _ := lookupPath(doc.document, template.labelsPath).(map[string]interface{})
+				if mapSelector(selector).Matches(labels) {
+					edges[GraphEdge{From: from, To: to, Label: "selector"}] = true
+				}
+			}
+		}
+	}
+
+	var graph Graph
+	for _, node := range nodes {
+		graph.Nodes = append(graph.Nodes, node)
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool { return graph.Nodes[i].ID() < graph.Nodes[j].ID() })
+	for edge := range edges {
+		graph.Edges = append(graph.Edges, edge)
+	}
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		a, b := graph.Edges[i], graph.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.Label < b.Label
+	})
+	return graph
+}
+
+// referenceLabel называет вид ссылки по пути к полю с именем ресурса
+func referenceLabel(path FieldPath) string {
+	switch p := string(path); {
+	case strings.Contains(p, ".envFrom["):
+		return "envFrom"
+	case strings.Contains(p, ".env["):
+		return "env"
+	case strings.Contains(p, ".projected."):
+		return "projected volume"
+	case strings.Contains(p, ".imagePullSecrets["):
+		return "imagePullSecrets"
+	case strings.HasSuffix(p, ".serviceAccountName"):
+		return "serviceAccountName"
+	default:
+		return "volume"
+	}
+}
@@ -0,0 +1,79 @@
+package validator
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// HostPathPolicy — ограничения томов hostPath из конфигурации (правило host-path)
+type HostPathPolicy struct {
+	// Allowed — разрешённые пути узла; путь разрешает и всё под ним, допускаются шаблоны
+	// вида /var/log/pods/*. Пустой список запрещает hostPath совсем.
+	Allowed []string `yaml:"allowed"`
+	// ReadOnly — разрешённые тома можно монтировать только с readOnly: true
+	ReadOnly bool `yaml:"readOnly"`
+}
+
+// allows сообщает, входит ли путь узла в разрешённые
+func (p *HostPathPolicy) allows(hostPath string) bool {
+	hostPath = path.Clean(hostPath)
+	for _, allowed := range p.Allowed {
+		allowed = path.Clean(allowed)
+		if hostPath == allowed || strings.HasPrefix(hostPath, strings.TrimSuffix(allowed, "/")+"/") {
+			return true
+		}
+		if matched, _ := path.Match(allowed, hostPath); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// validateHostPath проверяет тома hostPath шаблонов пода по политике из конфигурации:
+// путь должен быть разрешён, а монтирования таких томов — только для чтения
+func (v *Validator) validateHostPath(document map[string]interface{}, filename string) {
+	policy := v.options.HostPath
+	if policy == nil {
+		return
+	}
+	for _, template := range podTemplates(document) {
+		spec := template.spec
+		volumes, _ := lookupPath(document, spec.Field("volumes")).([]interface{})
+		hostVolumes := map[string]bool{}
+		for i := range volumes {
+			volume := spec.Field("volumes").Index(i)
+			if _, ok := lookupPath(document, volume.Field("hostPath")).(map[string]interface{}); !ok {
+				continue
+			}
+			name, _ := lookupPath(document, volume.Field("name")).(string)
+			hostPath, _ := lookupPath(document, volume.Field("hostPath").Field("path")).(string)
+			switch {
+			case len(policy.Allowed) == 0:
+				v.addError(ruleHostPath, volume.Field("hostPath"), fmt.Sprintf("%s: %s: hostPath volumes are not allowed", filename, volume.Field("hostPath")))
+			case !policy.allows(hostPath):
+				v.addError(ruleHostPath, volume.Field("hostPath").Field("path"), fmt.Sprintf("%s: %s '%s' is not an allowed host path (allowed: %s)", filename, volume.Field("hostPath").Field("path"), hostPath, strings.Join(policy.Allowed, ", ")))
+			default:
+				hostVolumes[name] = true
+			}
+		}
+		if !policy.ReadOnly || len(hostVolumes) == 0 {
+			continue
+		}
+		for _, list := range []string{"initContainers", "containers", "ephemeralContainers"} {
+			containers, _ := lookupPath(document, spec.Field(list)).([]interface{})
+			for i := range containers {
+				mounts := spec.Field(list).Index(i).Field("volumeMounts")
+				items, _ := lookupPath(document, mounts).([]interface{})
+				for j := range items {
+					mount := mounts.Index(j)
+					if name, _ := lookupPath(document, mount.Field("name")).(string); !hostVolumes[name] || lookupPath(document, mount.Field("readOnly")) == true {
+						continue
+					}
+					v.addError(ruleHostPath, mount.Field("readOnly"), fmt.Sprintf("%s: %s mounts a hostPath volume and must set readOnly: true", filename, mount))
+					v.suggest(Remediation{Action: ActionSet, Value: true})
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Типы метрик HPA и поле, в котором описывается метрика каждого типа
+var hpaMetricSources = map[string]string{
+	"Resource":          "resource",
+	"ContainerResource": "containerResource",
+	"Pods":              "pods",
+	"Object":            "object",
+	"External":          "external",
+}
+
+var hpaMetricTypes = []string{"Resource", "ContainerResource", "Pods", "Object", "External"}
+
+// Допустимые типы цели для типа метрики
+var hpaTargetTypes = map[string][]string{
+	"Resource":          {"Utilization", "AverageValue"},
+	"ContainerResource": {"Utilization", "AverageValue"},
+	"Pods":              {"AverageValue"},
+	"Object":            {"Value", "AverageValue"},
+	"External":          {"Value", "AverageValue"},
+}
+
+// Поле цели, обязательное для её типа
+var hpaTargetFields = map[string]string{
+	"Utilization":  "averageUtilization",
+	"Value":        "value",
+	"AverageValue": "averageValue",
+}
+
+var hpaTargetSchema = &Schema{
+	Type:        "object",
+	Description: "Value of the metric the autoscaler keeps.",
+	Required:    []string{"type"},
+	Properties: map[string]*Schema{
+		"type":               {Type: "string", Description: "Kind of the target value.", Enum: []string{"Utilization", "Value", "AverageValue"}, Rules: []string{ruleRequiredField, ruleFieldValue}},
+		"averageUtilization": {Type: "integer", Description: "Average usage in percent of the requests of the pods.", Minimum: float(1)},
+		"averageValue":       {Description: "Average value per pod, as a quantity."},
+		"value":              {Description: "Total value, as a quantity."},
+	},
+}
+
+var hpaMetricIdentifierSchema = &Schema{
+	Type:        "object",
+	Description: "Metric of the metrics API.",
+	Required:    []string{"name"},
+	Properties: map[string]*Schema{
+		"name":     {Type: "string", Description: "Name of the metric."},
+		"selector": {Type: "object", Description: "Label selector of the metric series."},
+	},
+}
+
+var crossVersionObjectReferenceSchema = &Schema{
+	Type:     "object",
+	Required: []string{"kind", "name"},
+	Properties: map[string]*Schema{
+		"apiVersion": {Type: "string", Description: "API version of the object, e.g. apps/v1."},
+		"kind":       {Type: "string", Description: "Kind of the object, e.g. Deployment.", Rules: []string{ruleRequiredField}},
+		"name":       {Type: "string", Description: "Name of the object.", Rules: []string{ruleRequiredField}},
+	},
+}
+
+var hpaScalingRulesSchema = &Schema{
+	Type:        "object",
+	Description: "How fast the replica count may change in this direction.",
+	Properties: map[string]*Schema{
+		"stabilizationWindowSeconds": {Type: "integer", Description: "Past recommendations considered before scaling.", Minimum: float(0), Maximum: float(3600)},
+		"selectPolicy":               {Type: "string", Description: "Which policy wins when several apply.", Enum: []string{"Max", "Min", "Disabled"}},
+		"policies": {
+			Type:        "array",
+			Description: "Allowed changes per period.",
+			Items: &Schema{
+				Type:     "object",
+				Required: []string{"type", "value", "periodSeconds"},
+				Properties: map[string]*Schema{
+					"type":          {Type: "string", Description: "Whether value is a number of pods or a percentage.", Enum: []string{"Pods", "Percent"}},
+					"value":         {Type: "integer", Description: "Allowed change.", Minimum: float(1)},
+					"periodSeconds": {Type: "integer", Description: "Window the change is measured in.", Minimum: float(1), Maximum: float(1800)},
+				},
+			},
+		},
+	},
+}
+
+// horizontalPodAutoscalerSchema описывает поля HorizontalPodAutoscaler autoscaling/v2
+var horizontalPodAutoscalerSchema = &Schema{
+	Type:     "object",
+	Required: []string{"spec"},
+	Properties: map[string]*Schema{
+		"spec": {
+			Type:        "object",
+			Description: "Target of the autoscaler and the metrics it scales on.",
+			Required:    []string{"scaleTargetRef", "maxReplicas"},
+			Properties: map[string]*Schema{
+				"scaleTargetRef": crossVersionObjectReferenceSchema,
+				"minReplicas":    {Type: "integer", Description: "Lowest replica count.", Minimum: float(1)},
+				"maxReplicas":    {Type: "integer", Description: "Highest replica count; not lower than minReplicas.", Minimum: float(1), Rules: []string{ruleRequiredField, ruleFieldValue}},
+				"metrics": {
+					Type:        "array",
+					Description: "Metrics the replica count is computed from; the highest recommendation wins.",
+					Items: &Schema{
+						Type:     "object",
+						Required: []string{"type"},
+						Properties: map[string]*Schema{
+							"type": {Type: "string", Description: "Source of the metric; the field of the same name describes it.", Enum: hpaMetricTypes, Rules: []string{ruleRequiredField, ruleFieldValue}},
+							"resource": {
+								Type:     "object",
+								Required: []string{"name", "target"},
+								Properties: map[string]*Schema{
+									"name":   {Type: "string", Description: "Resource of the pods, e.g. cpu or memory."},
+									"target": hpaTargetSchema,
+								},
+							},
+							"containerResource": {
+								Type:     "object",
+								Required: []string{"name", "container", "target"},
+								Properties: map[string]*Schema{
+									"name":      {Type: "string", Description: "Resource of the container, e.g. cpu or memory."},
+									"container": {Type: "string", Description: "Name of the container."},
+									"target":    hpaTargetSchema,
+								},
+							},
+							"pods": {
+								Type:     "object",
+								Required: []string{"metric", "target"},
+								Properties: map[string]*Schema{
+									"metric": hpaMetricIdentifierSchema,
+									"target": hpaTargetSchema,
+								},
+							},
+							"object": {
+								Type:     "object",
+								Required: []string{"describedObject", "metric", "target"},
+								Properties: map[string]*Schema{
+									"describedObject": crossVersionObjectReferenceSchema,
+									"metric":          hpaMetricIdentifierSchema,
+									"target":          hpaTargetSchema,
+								},
+							},
+							"external": {
+								Type:     "object",
+								Required: []string{"metric", "target"},
+								Properties: map[string]*Schema{
+									"metric": hpaMetricIdentifierSchema,
+									"target": hpaTargetSchema,
+								},
+							},
+						},
+					},
+				},
+				"behavior": {
+					Type:        "object",
+					Description: "Scaling speed limits.",
+					Properties: map[string]*Schema{
+						"scaleUp":   hpaScalingRulesSchema,
+						"scaleDown": hpaScalingRulesSchema,
+					},
+				},
+			},
+		},
+	},
+}
+
+func (v *Validator) validateHorizontalPodAutoscaler(document map[string]interface{}, filename string) {
+	spec, exists := document["spec"]
+	if !exists {
+		v.addError(ruleRequiredField, "spec", fmt.Sprintf("%s: spec is required", filename))
+		return
+	}
+	specMap, ok := spec.(map[string]interface{})
+	if !ok {
+		v.addError(ruleFieldType, "spec", fmt.Sprintf("%s: spec must be an object", filename))
+		return
+	}
+	v.validateSchema(specMap, horizontalPodAutoscalerSchema.Properties["spec"], "spec", filename)
+
+	minReplicas, _ := toNumber(specMap["minReplicas"])
+	maxReplicas, _ := toNumber(specMap["maxReplicas"])
+	if minReplicas != nil && maxReplicas != nil && *minReplicas > *maxReplicas {
+		v.addError(ruleFieldValue, "spec.maxReplicas", fmt.Sprintf("%s: spec.maxReplicas %v must not be lower than spec.minReplicas %v", filename, *maxReplicas, *minReplicas))
+		v.suggest(Remediation{Action: ActionSet, Value: int(*minReplicas)})
+	}
+
+	metrics, _ := specMap["metrics"].([]interface{})
+	for i, item := range metrics {
+		metric, _ := item.(map[string]interface{})
+		metricType, _ := metric["type"].(string)
+		field, known := hpaMetricSources[metricType]
+		if !known {
+			continue
+		}
+		path := FieldPath("spec.metrics").Index(i)
+		// Описание метрики задаётся только в поле её типа
+		for _, otherType := range hpaMetricTypes {
+			if other := hpaMetricSources[otherType]; other != field {
+				if _, exists := metric[other]; exists {
+					v.addError(ruleFieldValue, path.Field(other), fmt.Sprintf("%s: %s must not be set for a metric of type %s", filename, path.Field(other), metricType))
+					v.suggest(Remediation{Action: ActionRemove})
+				}
+			}
+		}
+		source, exists := metric[field]
+		if !exists {
+			v.addError(ruleRequiredField, path.Field(field), fmt.Sprintf("%s: %s is required for a metric of type %s", filename, path.Field(field), metricType))
+			continue
+		}
+		sourceMap, _ := source.(map[string]interface{})
+		if target, ok := sourceMap["target"].(map[string]interface{}); ok {
+			v.validateHPATarget(target, metricType, path.Field(field).Field("target"), filename)
+		}
+	}
+}
+
+// validateHPATarget проверяет, что тип цели подходит метрике и задано поле этого типа
+func (v *Validator) validateHPATarget(target map[string]interface{}, metricType string, path FieldPath, filename string) {
+	targetType, _ := target["type"].(string)
+	field, known := hpaTargetFields[targetType]
+	if !known {
+		return
+	}
+	if allowed := hpaTargetTypes[metricType]; !containsString(allowed, targetType) {
+		v.addError(ruleFieldValue, path.Field("type"), fmt.Sprintf("%s: %s must be %s for a metric of type %s, got '%s'", filename, path.Field("type"), strings.Join(allowed, " or "), metricType, targetType))
+		v.suggest(Remediation{Action: ActionSet, Allowed: allowed})
+		return
+	}
+	value, exists := target[field]
+	if !exists {
+		v.addError(ruleRequiredField, path.Field(field), fmt.Sprintf("%s: %s is required for target type %s", filename, path.Field(field), targetType))
+		return
+	}
+	if field != "averageUtilization" {
+		if quantity, err := ParseQuantity(value); err != nil || quantity.Sign() < 0 {
+			v.addError(ruleFieldValue, path.Field(field), fmt.Sprintf("%s: %s must be a non-negative quantity, e.g. 500m or 100", filename, path.Field(field)))
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Формат тега SemVer: 1.2.3, v1.2.3, 1.2.3-rc.1; метаданные сборки (+...) в тегах образов недопустимы
+const semverTag = `^v?(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)(-[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*)?$`
+
+var semverPattern = regexp.MustCompile(semverTag)
+
+// ImageTagPolicy — требования к тегам образов (правило image-tag)
+type ImageTagPolicy struct {
+	// SemVer — тег должен быть версией SemVer
+	SemVer bool
+	// Pattern — регулярное выражение, которому должен соответствовать тег, вместо SemVer
+	Pattern *regexp.Regexp
+	// ForbidPrerelease — теги pre-release (1.2.3-rc.1) запрещены; имеет смысл вместе с SemVer
+	ForbidPrerelease bool
+	// Environment — окружение, для которого действует политика; только для сообщений
+	Environment string
+}
+
+// imageReference разбирает образ на тег и дайджест. Двоеточие в адресе реестра
+// (registry:5000/app) тегом не считается.
+func imageReference(image string) (tag, digest string) {
+	if at := strings.LastIndex(image, "@"); at >= 0 {
+		image, digest = image[:at], image[at+1:]
+	}
+	name := image
+	if slash := strings.LastIndex(image, "/"); slash >= 0 {
+		name = image[slash+1:]
+	}
+	if colon := strings.LastIndex(name, ":"); colon >= 0 {
+		tag = name[colon+1:]
+	}
+	return tag, digest
+}
+
+// Версия набора правил, с которой порт реестра не считается тегом, а образ с дайджестом тега не требует
+const imageReferenceSince = "2026.2"
+
+// checkImageTag возвращает, чем тег образа нарушает политику; пусто, если нарушений нет.
+// Образ, закреплённый дайджестом, версию уже не меняет и тега не требует.
+func (v *Validator) checkImageTag(image string) string {
+	policy := v.options.ImageTags
+	tag, digest := imageReference(image)
+	switch {
+	case !v.rulesetAtLeast(imageReferenceSince):
+		// Прежний разбор: тегом считается любое двоеточие в ссылке
+		if !strings.Contains(image, ":") {
+			return "must have a version tag"
+		}
+	case tag == "" && digest != "":
+		return ""
+	case tag == "":
+		return "must have a version tag"
+	}
+	if policy == nil || tag == "" {
+		return ""
+	}
+	switch {
+	case policy.Pattern != nil && !policy.Pattern.MatchString(tag):
+		return fmt.Sprintf("tag '%s' must match %s", tag, policy.Pattern)
+	case policy.Pattern == nil && policy.SemVer && !semverPattern.MatchString(tag):
+		return fmt.Sprintf("tag '%s' must be a SemVer version, e.g. 1.2.3", tag)
+	}
+	if match := semverPattern.FindStringSubmatch(tag); policy.ForbidPrerelease && match != nil && match[4] != "" {
+		if policy.Environment != "" {
+			return fmt.Sprintf("tag '%s' is a pre-release version, which is not allowed in %s", tag, policy.Environment)
+		}
+		return fmt.Sprintf("tag '%s' is a pre-release version, which is not allowed", tag)
+	}
+	return ""
+}
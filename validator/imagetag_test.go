@@ -0,0 +1,41 @@
+package validator_test
+
+import (
+	"testing"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator/validatortest"
+)
+
+func TestImageTagParsingFollowsRulesetVersion(t *testing.T) {
+	const digest = "@sha256:0000000000000000000000000000000000000000000000000000000000000000"
+	tests := []struct {
+		image  string
+		legacy []string
+		rules  []string
+	}{
+		{"nginx:1.25", nil, nil},
+		{"nginx", []string{"YV102"}, []string{"YV102"}},
+		// Порт реестра считался тегом до 2026.2
+		{"registry.example.com:5000/app", nil, []string{"YV102"}},
+		// Образ только с дайджестом проходил и прежде: двоеточие есть в самом дайджесте
+		{"nginx" + digest, nil, nil},
+		{"registry.example.com:5000/app" + digest, nil, nil},
+	}
+	for _, version := range []string{"2024.1", validator.CurrentRulesetVersion()} {
+		for _, tt := range tests {
+			want := tt.rules
+			if version == "2024.1" {
+				want = tt.legacy
+			}
+			pod := validatortest.Pod("web").Set("spec.containers[0].image", tt.image)
+			findings := validatortest.ValidateFixtureWith(t, pod, "pod.yaml", validator.RuleSelection{RulesetVersion: version, Enable: []string{"YV102"}, Disable: []string{"YV101", "YV116"}})
+			t.Run(version+" "+tt.image, func(t *testing.T) {
+				validatortest.AssertRules(t, findings, want...)
+			})
+		}
+	}
+	if err := (validator.Options{RulesetVersion: "1999.1"}).Check(); err == nil {
+		t.Error("unknown ruleset version accepted")
+	}
+}
@@ -0,0 +1,234 @@
+package validator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// immutableField — поле, которое API Kubernetes не даёт изменить у существующего объекта
+type immutableField struct {
+	kinds []string
+	// Путь к полю; [*] — любой элемент последовательности
+	path string
+	// except — изменяемые поля внутри path
+	except []string
+	// when — условие на прежнюю ревизию; nil — поле неизменяемо всегда
+	when func(before map[string]interface{}) bool
+}
+
+// markedImmutable — ConfigMap и Secret с immutable: true
+func markedImmutable(before map[string]interface{}) bool {
+	immutable, _ := before["immutable"].(bool)
+	return immutable
+}
+
+var (
+	persistentVolumeClaim = []string{"PersistentVolumeClaim"}
+	configData            = []string{"ConfigMap", "Secret"}
+)
+
+var immutableFields = []immutableField{
+	{kinds: selectorWorkloads, path: "spec.selector"},
+	{kinds: []string{"StatefulSet"}, path: "spec.serviceName"},
+	{kinds: []string{"StatefulSet"}, path: "spec.volumeClaimTemplates"},
+	{kinds: []string{"StatefulSet"}, path: "spec.podManagementPolicy"},
+	{kinds: []string{"Job"}, path: "spec.selector"},
+	{kinds: []string{"Job"}, path: "spec.template"},
+	{kinds: []string{"Job"}, path: "spec.completionMode"},
+	{kinds: []string{"Pod"}, path: "spec", except: []string{
+		"spec.containers[*].image", "spec.initContainers[*].image", "spec.activeDeadlineSeconds", "spec.tolerations", "spec.terminationGracePeriodSeconds",
+	}},
+	{kinds: persistentVolumeClaim, path: "spec.storageClassName"},
+	{kinds: persistentVolumeClaim, path: "spec.accessModes"},
+	{kinds: persistentVolumeClaim, path: "spec.volumeMode"},
+	{kinds: persistentVolumeClaim, path: "spec.volumeName"},
+	{kinds: persistentVolumeClaim, path: "spec.selector"},
+	{kinds: persistentVolumeClaim, path: "spec.dataSource"},
+	{kinds: []string{"Service"}, path: "spec.clusterIP"},
+	{kinds: configData, path: "data", when: markedImmutable},
+	{kinds: configData, path: "binaryData", when: markedImmutable},
+	{kinds: configData, path: "stringData", when: markedImmutable},
+	{kinds: configData, path: "immutable", when: markedImmutable},
+}
+
+// revisionDocument — документ ревизии с деревом узлов для позиций
+type revisionDocument struct {
+	root     *yaml.Node
+	document map[string]interface{}
+}
+
+// ImmutableChanges сравнивает две ревизии манифестов и возвращает находки для объектов, у которых
+// изменились неизменяемые поля: такое изменение API отклонит при применении. Объекты сопоставляются
+// по kind, пространству имён и имени; позиции находок указывают в новую ревизию after.
+func ImmutableChanges(before, after []byte, filename string) ([]Finding, error) {
+	previous, err := revisionDocuments(before)
+	if err != nil {
+		return nil, err
+	}
+	current, err := revisionDocuments(after)
+	if err != nil {
+		return nil, err
+	}
+	old := map[string]revisionDocument{}
+	for _, doc := range previous {
+		old[revisionKey(doc.document)] = doc
+	}
+
+	var findings []Finding
+	for _, doc := range current {
+		was, exists := old[revisionKey(doc.document)]
+		if !exists {
+			continue
+		}
+		var v Validator
+		kind, _ := doc.document["kind"].(string)
+		resource := fmt.Sprintf("%s %s", kind, lookupPath(doc.document, "metadata.name"))
+		changed := diffPaths(was.document, doc.document, "")
+
+		for _, field := range immutableFields {
+			if !containsString(field.kinds, kind) || (field.when != nil && !field.when(was.document)) {
+				continue
+			}
+			var fields []string
+			var first FieldPath
+			for _, path := range changed {
+				if !matchesPattern(path, field.path) || matchesAny(path, field.except) {
+					continue
+				}
+				if first == "" {
+					first = path
+				}
+				fields = append(fields, string(path))
+			}
+			if len(fields) == 0 {
+				continue
+			}
+			message := fmt.Sprintf("%s: %s: %s is immutable", filename, resource, field.path)
+			if len(field.except) > 0 {
+				message = fmt.Sprintf("%s: %s: %s fields are immutable except %s", filename, resource, field.path, strings.Join(field.except, ", "))
+			}
+			if len(fields) > 1 || fields[0] != field.path {
+				message += fmt.Sprintf(" (changed: %s)", strings.Join(fields, ", "))
+			}
+			v.addError(ruleImmutableField, FieldPath(field.path), message+"; the object must be deleted and recreated")
+			if node := first.ResolveNearest(doc.root); node != nil {
+				v.errors[len(v.errors)-1].Line, v.errors[len(v.errors)-1].Column = node.Line, node.Column
+			}
+		}
+
+		// Запрос места у PVC можно только увеличить
+		if kind == "PersistentVolumeClaim" {
+			const storage = FieldPath("spec.resources.requests.storage")
+			oldSize, oldErr := ParseQuantity(lookupPath(was.document, storage))
+			newSize, newErr := ParseQuantity(lookupPath(doc.document, storage))
+			if oldErr == nil && newErr == nil && newSize.Cmp(oldSize) < 0 {
+				v.addError(ruleImmutableField, storage, fmt.Sprintf("%s: %s: %s cannot be decreased (was %v)", filename, resource, storage, lookupPath(was.document, storage)))
+			}
+		}
+
+		v.resolvePositions(doc.root)
+		findings = append(findings, v.errors...)
+	}
+	return findings, nil
+}
+
+// revisionDocuments разбирает все документы файла; пустые документы пропускаются
+func revisionDocuments(data []byte) ([]revisionDocument, error) {
+	var documents []revisionDocument
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var root yaml.Node
+		if err := decoder.Decode(&root); err != nil {
+			if errors.Is(err, io.EOF) {
+				return documents, nil
+			}
+			return nil, err
+		}
+		var document map[string]interface{}
+		if err := root.Decode(&document); err != nil {
+			return nil, err
+		}
+		if document != nil {
+			documents = append(documents, revisionDocument{root: &root, document: document})
+		}
+	}
+}
+
+// revisionKey идентифицирует объект в обеих ревизиях
+func revisionKey(document map[string]interface{}) string {
+	doc := setDocument{document: document}
+	return doc.kind() + "/" + doc.namespace() + "/" + doc.name()
+}
+
+// diffPaths возвращает пути к различающимся значениям: листьям, добавленным и удалённым полям
+func diffPaths(a, b interface{}, path FieldPath) []FieldPath {
+	aMap, aIsMap := a.(map[string]interface{})
+	bMap, bIsMap := b.(map[string]interface{})
+	if aIsMap && bIsMap {
+		keys := map[string]bool{}
+		for key := range aMap {
+			keys[key] = true
+		}
+		for key := range bMap {
+			keys[key] = true
+		}
+		names := make([]string, 0, len(keys))
+		for key := range keys {
+			names = append(names, key)
+		}
+		sort.Strings(names)
+		var paths []FieldPath
+		for _, key := range names {
+			paths = append(paths, diffPaths(aMap[key], bMap[key], path.Field(key))...)
+		}
+		return paths
+	}
+	aList, aIsList := a.([]interface{})
+	bList, bIsList := b.([]interface{})
+	if aIsList && bIsList && len(aList) == len(bList) {
+		var paths []FieldPath
+		for i := range aList {
+			paths = append(paths, diffPaths(aList[i], bList[i], path.Index(i))...)
+		}
+		return paths
+	}
+	if reflect.DeepEqual(a, b) {
+		return nil
+	}
+	return []FieldPath{path}
+}
+
+// matchesPattern сообщает, лежит ли путь внутри шаблона вида spec.containers[*].image
+func matchesPattern(path FieldPath, pattern string) bool {
+	segments, err := path.Segments()
+	if err != nil {
+		return false
+	}
+	wildcard, err := FieldPath(strings.ReplaceAll(pattern, "[*]", "[-1]")).Segments()
+	if err != nil || len(segments) < len(wildcard) {
+		return false
+	}
+	for i, want := range wildcard {
+		got := segments[i]
+		if want.IsIndex != got.IsIndex || (!want.IsIndex && want.Key != got.Key) || (want.IsIndex && want.Index >= 0 && want.Index != got.Index) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesAny(path FieldPath, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matchesPattern(path, pattern) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+package validator
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// Имя узла: поддомен DNS-1123 из строчных букв, цифр и дефисов; допускается маска *. в начале
+var ingressHostPattern = regexp.MustCompile(`^(\*\.)?[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+
+var ingressPathTypes = []string{"Exact", "Prefix", "ImplementationSpecific"}
+
+var ingressBackendSchema = &Schema{
+	Type:        "object",
+	Description: "Where matching requests are sent: a Service or another resource.",
+	Properties: map[string]*Schema{
+		"service": {
+			Type:        "object",
+			Description: "Service that receives the traffic.",
+			Required:    []string{"name", "port"},
+			Properties: map[string]*Schema{
+				"name": {Type: "string", Description: "Name of the Service in the namespace of the Ingress."},
+				"port": {
+					Type:        "object",
+					Description: "Port of the Service, by number or by name.",
+					Properties: map[string]*Schema{
+						"number": {Type: "integer", Description: "Port number of the Service.", Rules: []string{rulePortRange}},
+						"name":   {Type: "string", Description: "Port name of the Service."},
+					},
+				},
+			},
+		},
+		"resource": {Type: "object", Description: "Another resource in the namespace, e.g. a storage bucket."},
+	},
+}
+
+// ingressSchema описывает поля Ingress networking.k8s.io/v1; правила и TLS проверяются кодом validateIngress
+var ingressSchema = &Schema{
+	Type:     "object",
+	Required: []string{"spec"},
+	Properties: map[string]*Schema{
+		"spec": {
+			Type:        "object",
+			Description: "Desired state of the ingress.",
+			Properties: map[string]*Schema{
+				"ingressClassName": {Type: "string", Description: "IngressClass of the controller that serves the ingress."},
+				"defaultBackend":   ingressBackendSchema,
+				"rules": {
+					Type:        "array",
+					Description: "Host and path rules; requests that match no rule go to defaultBackend.",
+					Items: &Schema{
+						Type: "object",
+						Properties: map[string]*Schema{
+							"host": {Type: "string", Description: "Host name the rule applies to; may start with *. for one level of subdomains.", Rules: []string{ruleFieldValue}},
+							"http": {
+								Type:        "object",
+								Description: "HTTP paths of the rule.",
+								Required:    []string{"paths"},
+								Properties: map[string]*Schema{
+									"paths": {
+										Type:        "array",
+										Description: "Paths and the backends they are sent to.",
+										Items: &Schema{
+											Type:     "object",
+											Required: []string{"pathType", "backend"},
+											Properties: map[string]*Schema{
+												"path":     {Type: "string", Description: "Path of the request; must start with / for Exact and Prefix.", Rules: []string{ruleFieldValue}},
+												"pathType": {Type: "string", Description: "How the path is matched.", Enum: ingressPathTypes, Rules: []string{ruleRequiredField, ruleFieldValue}},
+												"backend":  ingressBackendSchema,
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				"tls": {
+					Type:        "array",
+					Description: "TLS configuration: hosts and the Secret with their certificate.",
+					Items: &Schema{
+						Type: "object",
+						Properties: map[string]*Schema{
+							"hosts":      {Type: "array", Description: "Hosts covered by the certificate.", Items: &Schema{Type: "string"}},
+							"secretName": {Type: "string", Description: "kubernetes.io/tls Secret with the certificate and key."},
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+func (v *Validator) validateIngress(document map[string]interface{}, filename string) {
+	spec, exists := document["spec"]
+	if !exists {
+		v.addError(ruleRequiredField, "spec", fmt.Sprintf("%s: spec is required", filename))
+		return
+	}
+	specMap, ok := spec.(map[string]interface{})
+	if !ok {
+		v.addError(ruleFieldType, "spec", fmt.Sprintf("%s: spec must be an object", filename))
+		return
+	}
+	v.validateSchema(specMap, ingressSchema.Properties["spec"], "spec", filename)
+	if _, hasRules := specMap["rules"]; !hasRules && specMap["defaultBackend"] == nil {
+		v.addError(ruleRequiredField, "spec.rules", fmt.Sprintf("%s: spec.rules or spec.defaultBackend is required", filename))
+	}
+	if backend, ok := specMap["defaultBackend"].(map[string]interface{}); ok {
+		v.validateIngressBackend(backend, "spec.defaultBackend", filename)
+	}
+
+	rules, _ := specMap["rules"].([]interface{})
+	for i, item := range rules {
+		rule, _ := item.(map[string]interface{})
+		path := FieldPath("spec.rules").Index(i)
+		if host, ok := rule["host"].(string); ok {
+			v.validateIngressHost(host, path.Field("host"), filename)
+		}
+		paths, _ := lookupPath(rule, "http.paths").([]interface{})
+		for j, item := range paths {
+			httpPath, _ := item.(map[string]interface{})
+			itemPath := path.Field("http").Field("paths").Index(j)
+			pathType, _ := httpPath["pathType"].(string)
+			if value, ok := httpPath["path"].(string); ok && (pathType == "Exact" || pathType == "Prefix") && !strings.HasPrefix(value, "/") {
+				v.addError(ruleFieldValue, itemPath.Field("path"), fmt.Sprintf("%s: %s must start with / for pathType %s", filename, itemPath.Field("path"), pathType))
+				v.suggest(Remediation{Action: ActionSet, Value: "/" + value})
+			}
+			if backend, ok := httpPath["backend"].(map[string]interface{}); ok {
+				v.validateIngressBackend(backend, itemPath.Field("backend"), filename)
+			}
+		}
+	}
+
+	tls, _ := specMap["tls"].([]interface{})
+	for i, item := range tls {
+		block, _ := item.(map[string]interface{})
+		path := FieldPath("spec.tls").Index(i)
+		hosts, _ := block["hosts"].([]interface{})
+		for j, host := range hosts {
+			if hostStr, ok := host.(string); ok {
+				v.validateIngressHost(hostStr, path.Field("hosts").Index(j), filename)
+			}
+		}
+		if _, exists := block["secretName"]; !exists && len(hosts) > 0 {
+			v.addError(ruleRequiredField, path.Field("secretName"), fmt.Sprintf("%s: %s is required: without it the controller serves its default certificate", filename, path.Field("secretName")))
+		}
+	}
+}
+
+// validateIngressHost проверяет имя узла: не IP-адрес, без порта, поддомен DNS-1123
+func (v *Validator) validateIngressHost(host string, path FieldPath, filename string) {
+	switch {
+	case net.ParseIP(host) != nil:
+		v.addError(ruleFieldValue, path, fmt.Sprintf("%s: %s must be a host name, not an IP address", filename, path))
+	case strings.Contains(host, ":"):
+		v.addError(ruleFieldValue, path, fmt.Sprintf("%s: %s must not contain a port, got '%s'", filename, path, host))
+	case len(host) > 253 || !ingressHostPattern.MatchString(host):
+		v.addError(ruleFieldValue, path, fmt.Sprintf("%s: %s '%s' is not a valid host name: lowercase letters, digits, '-' and '.', optionally starting with *.", filename, path, host))
+		if lower := strings.ToLower(host); lower != host && ingressHostPattern.MatchString(lower) {
+			v.suggest(Remediation{Action: ActionSet, Value: lower})
+		}
+	}
+}
+
+// validateIngressBackend проверяет, что у бэкенда ровно один получатель, а порт сервиса
+// задан ровно одним способом: номером или именем
+func (v *Validator) validateIngressBackend(backend map[string]interface{}, path FieldPath, filename string) {
+	service, hasService := backend["service"]
+	_, hasResource := backend["resource"]
+	if hasService == hasResource {
+		v.addError(ruleFieldValue, path, fmt.Sprintf("%s: %s must set exactly one of service and resource", filename, path))
+		return
+	}
+	serviceMap, _ := service.(map[string]interface{})
+	port, ok := serviceMap["port"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	portPath := path.Field("service").Field("port")
+	number, hasNumber := port["number"]
+	name, hasName := port["name"]
+	switch {
+	case hasNumber == hasName:
+		v.addError(ruleFieldValue, portPath, fmt.Sprintf("%s: %s must set exactly one of number and name", filename, portPath))
+	case hasNumber:
+		if value, ok := portNumber(number); ok && !portInRange(value) {
+			v.addError(rulePortRange, portPath.Field("number"), fmt.Sprintf("%s: %s value out of range", filename, portPath.Field("number")))
+		}
+	case hasName:
+		if nameStr, ok := name.(string); ok && (!portNamePattern.MatchString(nameStr) || !strings.ContainsAny(nameStr, "abcdefghijklmnopqrstuvwxyz")) {
+			v.addError(ruleFieldValue, portPath.Field("name"), fmt.Sprintf("%s: %s must be a port name of up to 15 lowercase letters, digits and hyphens, got '%s'", filename, portPath.Field("name"), nameStr))
+		}
+	}
+}
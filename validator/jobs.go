@@ -0,0 +1,130 @@
+package validator
+
+import "fmt"
+
+// jobSpecSchema описывает spec задания: у Job и в jobTemplate у CronJob
+var jobSpecSchema = &Schema{
+	Type:        "object",
+	Description: "Desired state of the job.",
+	Required:    []string{"template"},
+	Properties: map[string]*Schema{
+		"template":                podTemplateSchema,
+		"backoffLimit":            {Type: "integer", Description: "Retries before the job is marked as failed; 6 by default.", Minimum: float(0)},
+		"backoffLimitPerIndex":    {Type: "integer", Description: "Retries of each index of an Indexed job.", Minimum: float(0)},
+		"completions":             {Type: "integer", Description: "Number of pods that must succeed.", Minimum: float(0)},
+		"parallelism":             {Type: "integer", Description: "Maximum number of pods running at once.", Minimum: float(0)},
+		"activeDeadlineSeconds":   {Type: "integer", Description: "Seconds the job may run before it is terminated.", Minimum: float(1)},
+		"ttlSecondsAfterFinished": {Type: "integer", Description: "Seconds after which a finished job is deleted.", Minimum: float(0)},
+		"completionMode":          {Type: "string", Description: "Whether pods get a completion index.", Enum: []string{"NonIndexed", "Indexed"}},
+		"suspend":                 {Type: "boolean", Description: "Whether the job is suspended."},
+		"manualSelector":          {Type: "boolean", Description: "Whether spec.selector is set manually instead of generated."},
+		"selector":                {Type: "object", Description: "Label selector of the pods; generated unless manualSelector is set."},
+		"podFailurePolicy":        {Type: "object", Description: "Rules that decide how pod failures count towards backoffLimit."},
+		"podReplacementPolicy":    {Type: "string", Description: "When replacement pods are created.", Enum: []string{"TerminatingOrFailed", "Failed"}},
+	},
+}
+
+// jobSchema описывает поля Job batch/v1; шаблон пода проверяется кодом validatePodTemplate
+var jobSchema = &Schema{
+	Type:       "object",
+	Required:   []string{"spec"},
+	Properties: map[string]*Schema{"spec": jobSpecSchema},
+}
+
+// cronJobSchema описывает поля CronJob batch/v1
+var cronJobSchema = &Schema{
+	Type:     "object",
+	Required: []string{"spec"},
+	Properties: map[string]*Schema{
+		"spec": {
+			Type:        "object",
+			Description: "Desired state of the cron job.",
+			Required:    []string{"schedule", "jobTemplate"},
+			Properties: map[string]*Schema{
+				"schedule":                   {Type: "string", Description: "Cron schedule, e.g. */15 * * * * or @daily.", Rules: []string{ruleRequiredField, ruleFieldValue}},
+				"timeZone":                   {Type: "string", Description: "Time zone of the schedule, e.g. Europe/Moscow; the controller time zone by default."},
+				"concurrencyPolicy":          {Type: "string", Description: "What happens when the previous job is still running.", Enum: []string{"Allow", "Forbid", "Replace"}},
+				"startingDeadlineSeconds":    {Type: "integer", Description: "Seconds after the scheduled time a missed job may still start.", Minimum: float(0)},
+				"successfulJobsHistoryLimit": {Type: "integer", Description: "Number of finished jobs kept.", Minimum: float(0)},
+				"failedJobsHistoryLimit":     {Type: "integer", Description: "Number of failed jobs kept.", Minimum: float(0)},
+				"suspend":                    {Type: "boolean", Description: "Whether new jobs are not started."},
+				"jobTemplate": {
+					Type:        "object",
+					Description: "Template of the jobs the cron job creates.",
+					Required:    []string{"spec"},
+					Properties: map[string]*Schema{
+						"metadata": {
+							Type:        "object",
+							Description: "Metadata of the created jobs.",
+							Properties: map[string]*Schema{
+								"labels":      {Type: "object", Description: "Labels of the created jobs."},
+								"annotations": {Type: "object", Description: "Annotations of the created jobs."},
+							},
+						},
+						"spec": jobSpecSchema,
+					},
+				},
+			},
+		},
+	},
+}
+
+func (v *Validator) validateJob(document map[string]interface{}, filename string) {
+	v.validateWorkload(document, jobSchema, filename)
+	if spec, ok := document["spec"].(map[string]interface{}); ok {
+		v.validateJobSpec(spec, "spec", filename)
+	}
+}
+
+func (v *Validator) validateCronJob(document map[string]interface{}, filename string) {
+	v.validateWorkload(document, cronJobSchema, filename)
+	if schedule, ok := lookupPath(document, "spec.schedule").(string); ok {
+		if err := parseCronSchedule(schedule); err != nil {
+			v.addError(ruleFieldValue, "spec.schedule", fmt.Sprintf("%s: spec.schedule '%s' is not a valid cron expression: %v", filename, schedule, err))
+		}
+	}
+}
+
+// validateJobTemplate проверяет jobTemplate у CronJob: spec задания и его шаблон пода
+func (v *Validator) validateJobTemplate(template interface{}, path FieldPath, filename string) {
+	templateMap, ok := template.(map[string]interface{})
+	if !ok {
+		v.addError(ruleFieldType, path, fmt.Sprintf("%s: %s must be an object", filename, path))
+		return
+	}
+	templateSchema := cronJobSchema.Properties["spec"].Properties["jobTemplate"]
+	if metadata, exists := templateMap["metadata"]; exists {
+		v.validateSchema(metadata, templateSchema.Properties["metadata"], path.Field("metadata"), filename)
+	}
+	spec, exists := templateMap["spec"]
+	if !exists {
+		v.addError(ruleRequiredField, path.Field("spec"), fmt.Sprintf("%s: %s is required", filename, path.Field("spec")))
+		return
+	}
+	v.validateWorkloadSpec(spec, path.Field("spec"), jobSpecSchema, filename)
+	if specMap, ok := spec.(map[string]interface{}); ok {
+		v.validateJobSpec(specMap, path.Field("spec"), filename)
+	}
+}
+
+// validateJobSpec проверяет ограничения задания, которые не выражаются схемой: поды задания
+// не перезапускаются политикой Always, а Indexed-заданию нужно число завершений
+func (v *Validator) validateJobSpec(spec map[string]interface{}, path FieldPath, filename string) {
+	if podSpec, ok := lookupPath(spec, "template.spec").(map[string]interface{}); ok {
+		restartPath := path.Field("template").Field("spec").Field("restartPolicy")
+		switch policy := podSpec["restartPolicy"]; policy {
+		case "Never", "OnFailure":
+		case nil:
+			v.addError(ruleRequiredField, restartPath, fmt.Sprintf("%s: %s is required for jobs: Never or OnFailure", filename, restartPath))
+			v.suggest(Remediation{Action: ActionSet, Allowed: []string{"Never", "OnFailure"}})
+		default:
+			v.addError(ruleFieldValue, restartPath, fmt.Sprintf("%s: %s must be Never or OnFailure for jobs, got '%v'", filename, restartPath, policy))
+			v.suggest(Remediation{Action: ActionSet, Allowed: []string{"Never", "OnFailure"}})
+		}
+	}
+	if spec["completionMode"] == "Indexed" {
+		if _, exists := spec["completions"]; !exists {
+			v.addError(ruleRequiredField, path.Field("completions"), fmt.Sprintf("%s: %s is required when completionMode is Indexed", filename, path.Field("completions")))
+		}
+	}
+}
@@ -0,0 +1,462 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// GVK идентифицирует тип ресурса: группу, версию и kind
+type GVK struct {
+	Group   string
+	Version string
+	Kind    string
+}
+
+// APIVersion возвращает значение поля apiVersion для типа, например apps/v1 или v1
+func (g GVK) APIVersion() string {
+	if g.Group == "" {
+		return g.Version
+	}
+	return g.Group + "/" + g.Version
+}
+
+// Schema описывает допустимую структуру документа в духе OpenAPI v3.
+// Type принимает значения object, array, string, integer, number и boolean.
+type Schema struct {
+	Type        string
+	Description string
+	Required    []string
+	Properties  map[string]*Schema
+	Items       *Schema
+	Enum        []string
+	Pattern     string
+	Minimum     *float64
+	Maximum     *float64
+	// Rules — правила, проверяющие поле; если не заданы, выводятся из ограничений схемы
+	Rules []string
+	// AdditionalProperties — схема значений под ключами, которых нет в Properties, например
+	// у отображений вида labels; пустая схема допускает любые значения, nil — только известные ключи
+	AdditionalProperties *Schema
+	// Nullable допускает null вместо значения
+	Nullable bool
+	// Ограничения числа элементов массива и длины строки в символах
+	MinItems, MaxItems   *int
+	MinLength, MaxLength *int
+	// Format — формат строки из поддерживаемых stringFormats, например date-time или ipv4
+	Format string
+	// AllOf, AnyOf и OneOf — схемы, которым значение должно соответствовать все, хотя бы одна
+	// и ровно одна; тип ветви без своего type берётся из родительской схемы
+	AllOf, AnyOf, OneOf []*Schema
+}
+
+// property возвращает схему поля отображения: описанного в Properties либо произвольного ключа
+func (s *Schema) property(name string) *Schema {
+	if property, ok := s.Properties[name]; ok {
+		return property
+	}
+	return s.AdditionalProperties
+}
+
+type kindHandler struct {
+	gvk GVK
+	// Версия набора правил, в которой встроенный тип появился; у зарегистрированных типов пусто
+	since    string
+	schema   *Schema
+	validate func(v *Validator, document map[string]interface{}, filename string)
+}
+
+var registry struct {
+	sync.RWMutex
+	handlers []*kindHandler
+	patterns map[string]*regexp.Regexp
+}
+
+func init() {
+	registerBuiltin("2024.1", GVK{Version: "v1", Kind: "Pod"}, podSchema, (*Validator).validatePod)
+	registerBuiltin("2026.2", GVK{Group: "apps", Version: "v1", Kind: "Deployment"}, deploymentSchema, (*Validator).validateDeployment)
+	registerBuiltin("2026.2", GVK{Group: "apps", Version: "v1", Kind: "StatefulSet"}, statefulSetSchema, (*Validator).validateStatefulSet)
+	registerBuiltin("2026.2", GVK{Group: "apps", Version: "v1", Kind: "DaemonSet"}, daemonSetSchema, (*Validator).validateDaemonSet)
+	registerBuiltin("2026.2", GVK{Group: "batch", Version: "v1", Kind: "Job"}, jobSchema, (*Validator).validateJob)
+	registerBuiltin("2026.2", GVK{Group: "batch", Version: "v1", Kind: "CronJob"}, cronJobSchema, (*Validator).validateCronJob)
+	registerBuiltin("2026.2", GVK{Group: "autoscaling", Version: "v2", Kind: "HorizontalPodAutoscaler"}, horizontalPodAutoscalerSchema, (*Validator).validateHorizontalPodAutoscaler)
+	registerBuiltin("2026.2", GVK{Group: "policy", Version: "v1", Kind: "PodDisruptionBudget"}, podDisruptionBudgetSchema, (*Validator).validatePodDisruptionBudget)
+	registerBuiltin("2026.2", GVK{Version: "v1", Kind: "Service"}, serviceSchema, (*Validator).validateService)
+	registerBuiltin("2026.2", GVK{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"}, ingressSchema, (*Validator).validateIngress)
+	registerBuiltin("2026.2", GVK{Group: "networking.k8s.io", Version: "v1", Kind: "NetworkPolicy"}, networkPolicySchema, (*Validator).validateNetworkPolicy)
+	registerBuiltin("2026.2", GVK{Version: "v1", Kind: "ConfigMap"}, configMapSchema, (*Validator).validateConfigMap)
+	registerBuiltin("2026.2", GVK{Version: "v1", Kind: "Secret"}, secretSchema, (*Validator).validateSecret)
+	registerBuiltin("2026.2", GVK{Version: "v1", Kind: "PersistentVolumeClaim"}, persistentVolumeClaimSchema, (*Validator).validatePersistentVolumeClaim)
+	registerBuiltin("2026.2", GVK{Version: "v1", Kind: "Namespace"}, namespaceSchema, (*Validator).validateNamespace)
+	registerBuiltin("2026.2", GVK{Version: "v1", Kind: "ServiceAccount"}, serviceAccountSchema, (*Validator).validateServiceAccount)
+	registerBuiltin("2026.2", GVK{Version: "v1", Kind: "ResourceQuota"}, resourceQuotaSchema, (*Validator).validateResourceQuota)
+	registerBuiltin("2026.2", GVK{Group: rbacGroup, Version: "v1", Kind: "Role"}, roleSchema, (*Validator).validateRole)
+	registerBuiltin("2026.2", GVK{Group: rbacGroup, Version: "v1", Kind: "ClusterRole"}, clusterRoleSchema, (*Validator).validateClusterRole)
+	registerBuiltin("2026.2", GVK{Group: rbacGroup, Version: "v1", Kind: "RoleBinding"}, roleBindingSchema, (*Validator).validateRoleBinding)
+	registerBuiltin("2026.2", GVK{Group: rbacGroup, Version: "v1", Kind: "ClusterRoleBinding"}, roleBindingSchema, (*Validator).validateClusterRoleBinding)
+}
+
+// registerBuiltin добавляет встроенный тип; под более старой версией набора правил, чем since,
+// тип неизвестен, как и до его появления
+func registerBuiltin(since string, gvk GVK, schema *Schema, validate func(v *Validator, document map[string]interface{}, filename string)) {
+	registry.handlers = append(registry.handlers, &kindHandler{gvk: gvk, since: since, schema: schema, validate: validate})
+}
+
+// inRuleset сообщает, что тип входит в версию набора правил ruleset; пусто — текущая
+func (h *kindHandler) inRuleset(ruleset string) bool {
+	return h.since == "" || rulesetAtLeast(ruleset, h.since)
+}
+
+// RegisterKind добавляет проверку документов указанного типа по схеме.
+// Поля apiVersion, kind и metadata проверяются так же, как у встроенных типов.
+func RegisterKind(gvk GVK, schema Schema) error {
+	if gvk.Kind == "" || gvk.Version == "" {
+		return fmt.Errorf("kind and version are required")
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	for _, h := range registry.handlers {
+		if h.gvk == gvk {
+			return fmt.Errorf("%s %s is already registered", gvk.APIVersion(), gvk.Kind)
+		}
+	}
+	patterns := map[string]*regexp.Regexp{}
+	if err := compileSchema(&schema, "", patterns); err != nil {
+		return fmt.Errorf("%s %s: %v", gvk.APIVersion(), gvk.Kind, err)
+	}
+	if registry.patterns == nil {
+		registry.patterns = map[string]*regexp.Regexp{}
+	}
+	for pattern, re := range patterns {
+		registry.patterns[pattern] = re
+	}
+
+	handler := &kindHandler{gvk: gvk, schema: &schema}
+	handler.validate = func(v *Validator, document map[string]interface{}, filename string) {
+		v.validateSchema(document, handler.schema, "", filename)
+	}
+	registry.handlers = append(registry.handlers, handler)
+	return nil
+}
+
+func compileSchema(schema *Schema, path FieldPath, patterns map[string]*regexp.Regexp) error {
+	if schema == nil {
+		return nil
+	}
+	if schema.Pattern != "" {
+		re, err := regexp.Compile(schema.Pattern)
+		if err != nil {
+			return fmt.Errorf("%s: invalid pattern: %v", displayPath(path), err)
+		}
+		patterns[schema.Pattern] = re
+	}
+	for name, property := range schema.Properties {
+		if err := compileSchema(property, path.Field(name), patterns); err != nil {
+			return err
+		}
+	}
+	if err := compileSchema(schema.AdditionalProperties, path.Field("*"), patterns); err != nil {
+		return err
+	}
+	for _, branches := range [][]*Schema{schema.AllOf, schema.AnyOf, schema.OneOf} {
+		for _, branch := range branches {
+			if err := compileSchema(branch, path, patterns); err != nil {
+				return err
+			}
+		}
+	}
+	return compileSchema(schema.Items, path.Index(0), patterns)
+}
+
+// SchemaFor возвращает схему документа указанного типа вместе с общими полями
+// apiVersion, kind и metadata; для неизвестного типа — только общие поля
+func SchemaFor(apiVersion, kind string) *Schema {
+	schema := metadataSchema
+	schema.Properties = map[string]*Schema{}
+	for name, property := range metadataSchema.Properties {
+		schema.Properties[name] = property
+	}
+	for _, h := range handlersFor(kind, "") {
+		if h.schema == nil || (apiVersion != "" && h.gvk.APIVersion() != apiVersion) {
+			continue
+		}
+		for name, property := range h.schema.Properties {
+			schema.Properties[name] = property
+		}
+		schema.Required = append(append([]string(nil), h.schema.Required...), "apiVersion", "kind", "metadata")
+		break
+	}
+	return &schema
+}
+
+func handlersFor(kind, ruleset string) []*kindHandler {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	var handlers []*kindHandler
+	for _, h := range registry.handlers {
+		if h.gvk.Kind == kind && h.inRuleset(ruleset) {
+			handlers = append(handlers, h)
+		}
+	}
+	return handlers
+}
+
+func registeredKinds(ruleset string) []string {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	var kinds []string
+	for _, h := range registry.handlers {
+		if h.inRuleset(ruleset) && !containsString(kinds, h.gvk.Kind) {
+			kinds = append(kinds, h.gvk.Kind)
+		}
+	}
+	return kinds
+}
+
+func apiVersionsOf(handlers []*kindHandler) []string {
+	var versions []string
+	for _, h := range handlers {
+		if !containsString(versions, h.gvk.APIVersion()) {
+			versions = append(versions, h.gvk.APIVersion())
+		}
+	}
+	return versions
+}
+
+// validateSchema проверяет значение по схеме; на верхнем уровне apiVersion, kind и metadata пропускаются
+func (v *Validator) validateSchema(value interface{}, schema *Schema, path FieldPath, filename string) {
+	if schema == nil || (value == nil && schema.Nullable) {
+		return
+	}
+	// Ветви комбинаторов проверяются, только если тип подходит: иначе ошибку типа сообщает switch ниже
+	if hasSchemaType(value, schema.Type) {
+		v.validateCombinators(value, schema, path, filename)
+	}
+
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			v.addError(ruleFieldType, path, fmt.Sprintf("%s: %s must be an object", filename, displayPath(path)))
+			return
+		}
+		for _, name := range schema.Required {
+			if path == "" && isCommonField(name) {
+				continue
+			}
+			if _, exists := object[name]; !exists {
+				v.addError(ruleRequiredField, path.Field(name), fmt.Sprintf("%s: %s is required", filename, path.Field(name)))
+			}
+		}
+		names := make([]string, 0, len(schema.Properties))
+		for name := range schema.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if path == "" && isCommonField(name) {
+				continue
+			}
+			if child, exists := object[name]; exists {
+				v.validateSchema(child, schema.Properties[name], path.Field(name), filename)
+			}
+		}
+		if schema.AdditionalProperties != nil {
+			for _, name := range sortedKeys(object) {
+				if _, known := schema.Properties[name]; !known && !(path == "" && isCommonField(name)) {
+					v.validateSchema(object[name], schema.AdditionalProperties, path.Field(name), filename)
+				}
+			}
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			v.addError(ruleFieldType, path, fmt.Sprintf("%s: %s must be an array", filename, displayPath(path)))
+			return
+		}
+		if schema.MinItems != nil && len(items) < *schema.MinItems {
+			v.addError(ruleFieldValue, path, fmt.Sprintf("%s: %s must have at least %d items", filename, displayPath(path), *schema.MinItems))
+		}
+		if schema.MaxItems != nil && len(items) > *schema.MaxItems {
+			v.addError(ruleFieldValue, path, fmt.Sprintf("%s: %s must have at most %d items", filename, displayPath(path), *schema.MaxItems))
+		}
+		for i, item := range items {
+			v.validateSchema(item, schema.Items, path.Index(i), filename)
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			v.addError(ruleFieldType, path, fmt.Sprintf("%s: %s must be string", filename, displayPath(path)))
+			return
+		}
+		if len(schema.Enum) > 0 && !containsString(schema.Enum, str) {
+			v.addError(ruleFieldValue, path, fmt.Sprintf("%s: %s has unsupported value '%s'", filename, displayPath(path), str))
+			v.suggest(Remediation{Action: ActionSet, Allowed: schema.Enum})
+		}
+		if schema.Pattern != "" && !patternFor(schema.Pattern).MatchString(str) {
+			v.addError(ruleFieldValue, path, fmt.Sprintf("%s: %s has invalid format '%s'", filename, displayPath(path), str))
+			v.suggest(Remediation{Action: ActionSet, Pattern: schema.Pattern})
+		}
+		length := utf8.RuneCountInString(str)
+		if schema.MinLength != nil && length < *schema.MinLength {
+			v.addError(ruleFieldValue, path, fmt.Sprintf("%s: %s must be at least %d characters long", filename, displayPath(path), *schema.MinLength))
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			v.addError(ruleFieldValue, path, fmt.Sprintf("%s: %s must be at most %d characters long", filename, displayPath(path), *schema.MaxLength))
+		}
+		if check := stringFormats[schema.Format]; check != nil && !check(str) {
+			v.addError(ruleFieldValue, path, fmt.Sprintf("%s: %s must be a valid %s, got '%s'", filename, displayPath(path), schema.Format, str))
+		}
+	case "integer", "number":
+		number, isInt := toNumber(value)
+		if !isInt && (schema.Type == "integer" || number == nil) {
+			v.addError(ruleFieldType, path, fmt.Sprintf("%s: %s must be %s", filename, displayPath(path), schema.Type))
+			return
+		}
+		if (schema.Minimum != nil && *number < *schema.Minimum) || (schema.Maximum != nil && *number > *schema.Maximum) {
+			v.addError(ruleFieldValue, path, fmt.Sprintf("%s: %s value out of range", filename, displayPath(path)))
+		}
+		v.validateEnum(value, schema, path, filename)
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			v.addError(ruleFieldType, path, fmt.Sprintf("%s: %s must be boolean", filename, displayPath(path)))
+			return
+		}
+		v.validateEnum(value, schema, path, filename)
+	}
+}
+
+// validateEnum сверяет число или логическое значение с Enum, записанным строками
+func (v *Validator) validateEnum(value interface{}, schema *Schema, path FieldPath, filename string) {
+	if len(schema.Enum) > 0 && !containsString(schema.Enum, fmt.Sprint(value)) {
+		v.addError(ruleFieldValue, path, fmt.Sprintf("%s: %s has unsupported value '%v'", filename, displayPath(path), value))
+		v.suggest(Remediation{Action: ActionSet, Allowed: schema.Enum})
+	}
+}
+
+// validateCombinators проверяет allOf, anyOf и oneOf. Находки allOf сообщаются как есть;
+// для anyOf и oneOf ветви проверяются отдельно и сообщается одна находка о несоответствии.
+func (v *Validator) validateCombinators(value interface{}, schema *Schema, path FieldPath, filename string) {
+	for _, branch := range schema.AllOf {
+		v.validateSchema(value, branchSchema(schema, branch), path, filename)
+	}
+	matches := func(branches []*Schema) int {
+		count := 0
+		for _, branch := range branches {
+			var branchValidator Validator
+			branchValidator.validateSchema(value, branchSchema(schema, branch), path, filename)
+			if len(branchValidator.errors) == 0 {
+				count++
+			}
+		}
+		return count
+	}
+	if len(schema.AnyOf) > 0 && matches(schema.AnyOf) == 0 {
+		v.addError(ruleFieldValue, path, fmt.Sprintf("%s: %s does not match any of the anyOf schemas", filename, displayPath(path)))
+	}
+	if len(schema.OneOf) > 0 {
+		if count := matches(schema.OneOf); count != 1 {
+			v.addError(ruleFieldValue, path, fmt.Sprintf("%s: %s must match exactly one of the oneOf schemas, matches %d", filename, displayPath(path), count))
+		}
+	}
+}
+
+// branchSchema возвращает ветвь комбинатора с типом родительской схемы, если у ветви нет своего:
+// в CRD ветви обычно задают только required или ограничения значения
+func branchSchema(parent, branch *Schema) *Schema {
+	if branch == nil || branch.Type != "" {
+		return branch
+	}
+	inherited := *branch
+	inherited.Type = parent.Type
+	return &inherited
+}
+
+// hasSchemaType сообщает, что значение имеет тип схемы; пустой тип подходит любому значению
+func hasSchemaType(value interface{}, typ string) bool {
+	switch typ {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "integer":
+		_, isInt := toNumber(value)
+		return isInt
+	case "number":
+		number, _ := toNumber(value)
+		return number != nil
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	}
+	return true
+}
+
+func patternFor(pattern string) *regexp.Regexp {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	return registry.patterns[pattern]
+}
+
+// toNumber приводит целые и дробные значения YAML к float64; isInt сообщает, было ли значение целым
+func toNumber(value interface{}) (number *float64, isInt bool) {
+	var f float64
+	switch val := value.(type) {
+	case int:
+		f, isInt = float64(val), true
+	case int64:
+		f, isInt = float64(val), true
+	case uint64:
+		f, isInt = float64(val), true
+	case float64:
+		f = val
+	default:
+		return nil, false
+	}
+	return &f, isInt
+}
+
+func isCommonField(name string) bool {
+	return name == "apiVersion" || name == "kind" || name == "metadata"
+}
+
+func displayPath(path FieldPath) string {
+	if path == "" {
+		return "document"
+	}
+	return string(path)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// quoteList форматирует допустимые значения: 'v1' или one of 'v1', 'apps/v1'
+func quoteList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = "'" + value + "'"
+	}
+	if len(quoted) == 1 {
+		return quoted[0]
+	}
+	return "one of " + strings.Join(quoted, ", ")
+}
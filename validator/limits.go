@@ -0,0 +1,107 @@
+package validator
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// LimitRatios — во сколько раз лимиты превышают запросы; по ним правило resource-defaults
+// предлагает недостающие requests или limits
+type LimitRatios struct {
+	CPU    float64 `yaml:"cpu"`
+	Memory float64 `yaml:"memory"`
+}
+
+// DefaultLimitRatios — соотношения без конфигурации: лимиты вдвое больше запросов
+var DefaultLimitRatios = LimitRatios{CPU: 2, Memory: 2}
+
+// suggestResourceDefaults предлагает лимиты для ресурсов, у которых задан только запрос,
+// и запросы для ресурсов, у которых задан только лимит. Подсказка содержит значение,
+// поэтому --fix-dry-run может её применить.
+func (v *Validator) suggestResourceDefaults(resources map[string]interface{}, containerIndex int, filename string) {
+	requests, _ := resources["requests"].(map[string]interface{})
+	limits, _ := resources["limits"].(map[string]interface{})
+	ratios := v.limitRatios()
+	v.suggestMissing(resources, containerIndex, "limits", requests, limits, ratios, filename)
+	v.suggestMissing(resources, containerIndex, "requests", limits, requests, ratios, filename)
+}
+
+// suggestMissing дополняет раздел target (limits или requests) по значениям раздела source
+func (v *Validator) suggestMissing(resources map[string]interface{}, containerIndex int, target string, source, existing map[string]interface{}, ratios LimitRatios, filename string) {
+	suggested := map[string]interface{}{}
+	for _, name := range []string{"cpu", "memory"} {
+		if _, exists := existing[name]; exists || source[name] == nil {
+			continue
+		}
+		if value := scaledQuantity(name, source[name], target, ratios); value != nil {
+			suggested[name] = value
+		}
+	}
+	if len(suggested) == 0 {
+		return
+	}
+
+	path := v.containerPath(containerIndex).Field("resources").Field(target)
+	sourceName := "limits"
+	if target == "limits" {
+		sourceName = "requests"
+	}
+	// Отсутствующий раздел добавляется целиком: правка умеет добавлять только поля существующего отображения
+	if _, exists := resources[target]; !exists {
+		var values []string
+		for _, name := range sortedKeys(suggested) {
+			values = append(values, fmt.Sprintf("%s: %v", name, suggested[name]))
+		}
+		v.addError(ruleResourceDefaults, path, fmt.Sprintf("%s: container[%d].resources has %s but no %s, suggested %s",
+			filename, containerIndex, sourceName, target, strings.Join(values, ", ")))
+		v.suggest(Remediation{Action: ActionSet, Value: suggested})
+		return
+	}
+	if existing == nil {
+		return
+	}
+	for _, name := range sortedKeys(suggested) {
+		v.addError(ruleResourceDefaults, path.Field(name), fmt.Sprintf("%s: container[%d].resources.%s.%s is not set, suggested %v",
+			filename, containerIndex, target, name, suggested[name]))
+		v.suggest(Remediation{Action: ActionSet, Value: suggested[name]})
+	}
+}
+
+// scaledQuantity вычисляет лимит по запросу (умножая на соотношение) или запрос по лимиту
+// (деля на него). CPU округляется до целого ядра, память — до Mi, чтобы значение проходило
+// правила resource-cpu и resource-memory; nil, если исходное значение не разобрать.
+func scaledQuantity(name string, value interface{}, target string, ratios LimitRatios) interface{} {
+	quantity, err := ParseQuantity(value)
+	if err != nil || quantity.Sign() <= 0 {
+		return nil
+	}
+	ratio := ratios.CPU
+	unit := big.NewRat(1, 1)
+	if name == "memory" {
+		ratio, unit = ratios.Memory, quantitySuffixes["Mi"]
+	}
+	scaled := new(big.Rat).SetFloat64(ratio)
+	if target == "limits" {
+		scaled.Mul(quantity, scaled)
+	} else {
+		scaled.Quo(quantity, scaled)
+	}
+
+	// Лимит округляется вверх, запрос — вниз, чтобы запрос не превысил лимит
+	units := new(big.Rat).Quo(scaled, unit)
+	count := new(big.Int).Quo(units.Num(), units.Denom())
+	if target == "limits" && !units.IsInt() {
+		count.Add(count, big.NewInt(1))
+	}
+	if count.Sign() == 0 {
+		count.SetInt64(1)
+	}
+	if name == "cpu" {
+		return int(count.Int64())
+	}
+	if new(big.Int).Rem(count, big.NewInt(1024)).Sign() == 0 {
+		return new(big.Int).Quo(count, big.NewInt(1024)).String() + "Gi"
+	}
+	return count.String() + "Mi"
+}
@@ -0,0 +1,85 @@
+package validator
+
+import "fmt"
+
+// listKind — список ресурсов, например вывод kubectl get -o yaml
+const listKind = "List"
+
+// Версия набора правил, с которой проверяются элементы списков; раньше kind List был неизвестен
+const listSince = "2026.2"
+
+// isList сообщает, что документ — список ресурсов; apiVersion проверяет validateList
+func (v *Validator) isList(document map[string]interface{}) bool {
+	kind, _ := document["kind"].(string)
+	return kind == listKind && v.rulesetAtLeast(listSince)
+}
+
+// validateResource проверяет документ как список ресурсов либо как отдельный ресурс
+func (v *Validator) validateResource(document map[string]interface{}, filename string) {
+	if v.isList(document) {
+		v.validateList(document, filename)
+		return
+	}
+	v.validateTopLevel(document, filename)
+}
+
+// validateList проверяет каждый элемент items как отдельный манифест. Находки элемента
+// получают путь с префиксом items[i], так что позиции и исправления указывают на поле
+// внутри списка, а сообщение называет элемент.
+func (v *Validator) validateList(document map[string]interface{}, filename string) {
+	if apiVersion, exists := document["apiVersion"]; !exists {
+		v.addError(ruleRequiredField, "apiVersion", fmt.Sprintf("%s: apiVersion is required", filename))
+	} else if apiVersionStr, ok := apiVersion.(string); !ok {
+		v.addError(ruleFieldType, "apiVersion", fmt.Sprintf("%s: apiVersion must be string", filename))
+	} else if apiVersionStr != "v1" {
+		v.addError(ruleAPIVersion, "apiVersion", fmt.Sprintf("%s: apiVersion must be 'v1'", filename))
+		v.suggest(Remediation{Action: ActionSet, Value: "v1", Allowed: []string{"v1"}})
+	}
+	// metadata у списка необязательна: kubectl выводит в ней только resourceVersion
+	if metadata, exists := document["metadata"]; exists && metadata != nil {
+		if _, ok := metadata.(map[string]interface{}); !ok {
+			v.addError(ruleFieldType, "metadata", fmt.Sprintf("%s: metadata must be an object", filename))
+		}
+	}
+
+	items, exists := document["items"]
+	if !exists {
+		v.addError(ruleRequiredField, "items", fmt.Sprintf("%s: items is required", filename))
+		return
+	}
+	// items: без значения — пустой список
+	if items == nil {
+		return
+	}
+	itemList, ok := items.([]interface{})
+	if !ok {
+		v.addError(ruleFieldType, "items", fmt.Sprintf("%s: items must be an array", filename))
+		return
+	}
+	for i, item := range itemList {
+		path := FieldPath("items").Index(i)
+		itemMap, ok := item.(map[string]interface{})
+		if !ok {
+			v.addError(ruleFieldType, path, fmt.Sprintf("%s: %s must be an object", filename, path))
+			continue
+		}
+		var itemValidator Validator
+		itemValidator.validateResource(itemMap, fmt.Sprintf("%s %s", filename, path))
+		for _, finding := range itemValidator.errors {
+			v.errors = append(v.errors, finding.under(path))
+		}
+	}
+}
+
+// under переносит находку, найденную в отдельном документе, внутрь поля path
+func (f Finding) under(path FieldPath) Finding {
+	f.Path = path.Join(f.Path)
+	if f.Related != nil {
+		related := make([]Finding, len(f.Related))
+		for i, r := range f.Related {
+			related[i] = r.under(path)
+		}
+		f.Related = related
+	}
+	return f
+}
@@ -0,0 +1,80 @@
+package validator
+
+import (
+	"sync"
+	"time"
+)
+
+// Metrics — снимок счётчиков проверки с начала процесса или с последнего ResetMetrics,
+// чтобы встраивающий код мог передать их в свою телеметрию, не разбирая отчёты.
+// Находки считаются такими, какими их вернули Validate и ValidateCompose, до FilterFindings.
+type Metrics struct {
+	// Проверенные файлы и документы
+	Files int
+	// Все находки и находки по идентификатору правила
+	Findings       int
+	FindingsByRule map[string]int
+	// Суммарное и наибольшее время проверки одного файла
+	Duration    time.Duration
+	MaxDuration time.Duration
+}
+
+// Общие для процесса счётчики; обновляются из нескольких горутин
+var metrics struct {
+	sync.Mutex
+	files       int
+	findings    int
+	byRule      map[string]int
+	duration    time.Duration
+	maxDuration time.Duration
+}
+
+// MetricsSnapshot возвращает копию текущих счётчиков
+func MetricsSnapshot() Metrics {
+	metrics.Lock()
+	defer metrics.Unlock()
+
+	snapshot := Metrics{
+		Files:          metrics.files,
+		Findings:       metrics.findings,
+		FindingsByRule: make(map[string]int, len(metrics.byRule)),
+		Duration:       metrics.duration,
+		MaxDuration:    metrics.maxDuration,
+	}
+	for rule, count := range metrics.byRule {
+		snapshot.FindingsByRule[rule] = count
+	}
+	return snapshot
+}
+
+// ResetMetrics обнуляет счётчики, например между запусками в одном процессе
+func ResetMetrics() {
+	metrics.Lock()
+	defer metrics.Unlock()
+
+	metrics.files, metrics.findings = 0, 0
+	metrics.byRule = nil
+	metrics.duration, metrics.maxDuration = 0, 0
+}
+
+// recordValidation учитывает проверку одного файла; сгруппированные находки считаются отдельно
+func recordValidation(findings []Finding, duration time.Duration) {
+	metrics.Lock()
+	defer metrics.Unlock()
+
+	if metrics.byRule == nil {
+		metrics.byRule = map[string]int{}
+	}
+	metrics.files++
+	metrics.duration += duration
+	metrics.maxDuration = max(metrics.maxDuration, duration)
+	var count func([]Finding)
+	count = func(findings []Finding) {
+		for _, finding := range findings {
+			metrics.findings++
+			metrics.byRule[finding.RuleID]++
+			count(finding.Related)
+		}
+	}
+	count(findings)
+}
@@ -0,0 +1,224 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// Метка DNS-1123: имя пространства имён, не длиннее 63 символов
+	dnsLabelPattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	// Поддомен DNS-1123: имя большинства ресурсов, не длиннее 253 символов
+	dnsSubdomainPattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+)
+
+// Области квоты и операторы scopeSelector
+var (
+	quotaScopes          = []string{"Terminating", "NotTerminating", "BestEffort", "NotBestEffort", "PriorityClass", "CrossNamespacePodAffinity"}
+	quotaScopeOperators  = []string{"In", "NotIn", "Exists", "DoesNotExist"}
+	quotaExclusiveScopes = [][2]string{{"Terminating", "NotTerminating"}, {"BestEffort", "NotBestEffort"}}
+)
+
+var namespaceSchema = &Schema{
+	Type: "object",
+	Properties: map[string]*Schema{
+		"spec": {
+			Type:        "object",
+			Description: "Desired state of the namespace.",
+			Properties: map[string]*Schema{
+				"finalizers": {Type: "array", Description: "Finalizers that must finish before the namespace is deleted.", Items: &Schema{Type: "string"}},
+			},
+		},
+	},
+}
+
+var serviceAccountSchema = &Schema{
+	Type: "object",
+	Properties: map[string]*Schema{
+		"automountServiceAccountToken": {Type: "boolean", Description: "Whether pods of the service account get its API token mounted by default."},
+		"imagePullSecrets": {
+			Type:        "array",
+			Description: "Secrets used to pull images of the pods that use the service account.",
+			Items: &Schema{
+				Type:       "object",
+				Required:   []string{"name"},
+				Properties: map[string]*Schema{"name": {Type: "string", Description: "Name of the Secret."}},
+			},
+		},
+		"secrets": {
+			Type:        "array",
+			Description: "Secrets the pods of the service account may use.",
+			Items: &Schema{
+				Type: "object",
+				Properties: map[string]*Schema{
+					"name":      {Type: "string", Description: "Name of the Secret."},
+					"namespace": {Type: "string", Description: "Namespace of the Secret."},
+				},
+			},
+		},
+	},
+}
+
+var resourceQuotaSchema = &Schema{
+	Type:     "object",
+	Required: []string{"spec"},
+	Properties: map[string]*Schema{
+		"spec": {
+			Type:        "object",
+			Description: "Limits of the quota.",
+			Properties: map[string]*Schema{
+				"hard": {Type: "object", Description: "Maximum total of each resource in the namespace, e.g. requests.cpu or count/deployments.apps."},
+				"scopes": {
+					Type:        "array",
+					Description: "Only objects in all of the scopes are counted.",
+					Items:       &Schema{Type: "string", Enum: quotaScopes, Rules: []string{ruleFieldValue}},
+				},
+				"scopeSelector": {
+					Type:        "object",
+					Description: "Scopes selected by expressions, e.g. by priority class.",
+					Properties: map[string]*Schema{
+						"matchExpressions": {
+							Type:        "array",
+							Description: "Expressions that must all match.",
+							Items: &Schema{
+								Type:     "object",
+								Required: []string{"scopeName", "operator"},
+								Properties: map[string]*Schema{
+									"scopeName": {Type: "string", Description: "Scope the expression applies to.", Enum: quotaScopes, Rules: []string{ruleRequiredField, ruleFieldValue}},
+									"operator":  {Type: "string", Description: "Relation of the scope to the values.", Enum: quotaScopeOperators, Rules: []string{ruleRequiredField, ruleFieldValue}},
+									"values":    {Type: "array", Description: "Values for In and NotIn.", Items: &Schema{Type: "string"}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+func (v *Validator) validateNamespace(document map[string]interface{}, filename string) {
+	v.validateResourceName(document, dnsLabelPattern, 63, filename)
+	v.validateClusterScoped(document, filename)
+	if spec, exists := document["spec"]; exists {
+		v.validateSchema(spec, namespaceSchema.Properties["spec"], "spec", filename)
+	}
+}
+
+func (v *Validator) validateServiceAccount(document map[string]interface{}, filename string) {
+	v.validateResourceName(document, dnsSubdomainPattern, 253, filename)
+	for _, key := range []string{"automountServiceAccountToken", "imagePullSecrets", "secrets"} {
+		if value, exists := document[key]; exists {
+			v.validateSchema(value, serviceAccountSchema.Properties[key], FieldPath(key), filename)
+		}
+	}
+}
+
+func (v *Validator) validateResourceQuota(document map[string]interface{}, filename string) {
+	v.validateResourceName(document, dnsSubdomainPattern, 253, filename)
+	spec, exists := document["spec"]
+	if !exists {
+		v.addError(ruleRequiredField, "spec", fmt.Sprintf("%s: spec is required", filename))
+		return
+	}
+	specMap, ok := spec.(map[string]interface{})
+	if !ok {
+		v.addError(ruleFieldType, "spec", fmt.Sprintf("%s: spec must be an object", filename))
+		return
+	}
+	v.validateSchema(specMap, resourceQuotaSchema.Properties["spec"], "spec", filename)
+
+	hard, _ := specMap["hard"].(map[string]interface{})
+	for _, name := range sortedKeys(hard) {
+		path := FieldPath("spec.hard").Field(name)
+		quantity, err := ParseQuantity(hard[name])
+		switch {
+		case err != nil || quantity.Sign() < 0:
+			v.addError(ruleFieldValue, path, fmt.Sprintf("%s: %s must be a non-negative quantity, e.g. 10 or 4Gi", filename, path))
+		case quotaObjectCount(name) && !quantity.IsInt():
+			v.addError(ruleFieldValue, path, fmt.Sprintf("%s: %s counts objects and must be a whole number", filename, path))
+		}
+	}
+
+	scopes := map[string]bool{}
+	if list, ok := specMap["scopes"].([]interface{}); ok {
+		for _, scope := range list {
+			if name, ok := scope.(string); ok {
+				scopes[name] = true
+			}
+		}
+	}
+	for _, pair := range quotaExclusiveScopes {
+		if scopes[pair[0]] && scopes[pair[1]] {
+			v.addError(ruleFieldValue, "spec.scopes", fmt.Sprintf("%s: spec.scopes: %s and %s exclude each other", filename, pair[0], pair[1]))
+		}
+	}
+	// Квота BestEffort считает только поды: у них нет запросов и пределов ресурсов
+	if scopes["BestEffort"] {
+		for _, name := range sortedKeys(hard) {
+			if name != "pods" && name != "count/pods" {
+				path := FieldPath("spec.hard").Field(name)
+				v.addError(ruleFieldValue, path, fmt.Sprintf("%s: %s cannot be limited in the BestEffort scope, only pods", filename, path))
+			}
+		}
+	}
+
+	expressions, _ := lookupPath(specMap, "scopeSelector.matchExpressions").([]interface{})
+	for i, item := range expressions {
+		expression, _ := item.(map[string]interface{})
+		path := FieldPath("spec.scopeSelector.matchExpressions").Index(i)
+		values, _ := expression["values"].([]interface{})
+		switch operator, _ := expression["operator"].(string); operator {
+		case "In", "NotIn":
+			if len(values) == 0 {
+				v.addError(ruleRequiredField, path.Field("values"), fmt.Sprintf("%s: %s must not be empty for operator %s", filename, path.Field("values"), operator))
+			}
+		case "Exists", "DoesNotExist":
+			if len(values) > 0 {
+				v.addError(ruleFieldValue, path.Field("values"), fmt.Sprintf("%s: %s must be empty for operator %s", filename, path.Field("values"), operator))
+				v.suggest(Remediation{Action: ActionRemove})
+			}
+		}
+	}
+}
+
+// quotaObjectCount сообщает, что ресурс квоты — число объектов, например pods или count/secrets
+func quotaObjectCount(name string) bool {
+	if strings.HasPrefix(name, "count/") {
+		return true
+	}
+	switch name {
+	case "pods", "services", "services.loadbalancers", "services.nodeports", "secrets", "configmaps",
+		"persistentvolumeclaims", "replicationcontrollers", "resourcequotas":
+		return true
+	}
+	return strings.HasSuffix(name, ".storageclass.storage.k8s.io/persistentvolumeclaims")
+}
+
+// validateClusterScoped проверяет, что у ресурса уровня кластера не задано пространство имён
+func (v *Validator) validateClusterScoped(document map[string]interface{}, filename string) {
+	if _, ok := lookupPath(document, "metadata.namespace").(string); ok {
+		kind, _ := document["kind"].(string)
+		v.addError(ruleFieldValue, "metadata.namespace", fmt.Sprintf("%s: metadata.namespace must not be set: %s is a cluster-scoped resource", filename, kind))
+		v.suggest(Remediation{Action: ActionRemove})
+	}
+}
+
+// validateResourceName проверяет формат metadata.name; отсутствие имени проверяет validateMetadata
+func (v *Validator) validateResourceName(document map[string]interface{}, pattern *regexp.Regexp, maxLength int, filename string) {
+	characters := "lowercase letters, digits, '-' and '.'"
+	if pattern == dnsLabelPattern {
+		characters = "lowercase letters, digits and '-'"
+	}
+	name, ok := lookupPath(document, "metadata.name").(string)
+	if !ok || name == "" {
+		return
+	}
+	if len(name) > maxLength || !pattern.MatchString(name) {
+		v.addError(ruleFieldValue, "metadata.name", fmt.Sprintf("%s: metadata.name '%s' must consist of %s, at most %d characters", filename, name, characters, maxLength))
+		if lower := strings.ToLower(name); lower != name && len(lower) <= maxLength && pattern.MatchString(lower) {
+			v.suggest(Remediation{Action: ActionSet, Value: lower})
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package validator
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+var networkPolicyTypes = []string{"Ingress", "Egress"}
+
+var networkPolicyPeerSchema = &Schema{
+	Type:        "object",
+	Description: "Pods, namespaces or IP ranges traffic is allowed from or to.",
+	Properties: map[string]*Schema{
+		"podSelector":       {Type: "object", Description: "Pods selected by labels; with namespaceSelector in the selected namespaces, otherwise in the namespace of the policy.", Rules: []string{ruleLabelSelector}},
+		"namespaceSelector": {Type: "object", Description: "Namespaces selected by labels; {} selects all namespaces.", Rules: []string{ruleLabelSelector}},
+		"ipBlock": {
+			Type:        "object",
+			Description: "IP range; cannot be combined with the selectors.",
+			Required:    []string{"cidr"},
+			Properties: map[string]*Schema{
+				"cidr":   {Type: "string", Description: "Allowed range, e.g. 10.0.0.0/16.", Rules: []string{ruleRequiredField, ruleFieldValue}},
+				"except": {Type: "array", Description: "Ranges inside cidr that are not allowed.", Items: &Schema{Type: "string"}},
+			},
+		},
+	},
+}
+
+var networkPolicyPortSchema = &Schema{
+	Type: "object",
+	Properties: map[string]*Schema{
+		// Значение проверяет validateProtocol: правило port-protocol, а не field-value
+		"protocol": {Type: "string", Description: "Protocol of the port: TCP, UDP or SCTP.", Rules: []string{rulePortProtocol}},
+		"port":     {Description: "Port number or a named port of the pods.", Rules: []string{rulePortRange}},
+		"endPort":  {Type: "integer", Description: "Last port of a range that starts at port.", Rules: []string{rulePortRange}},
+	},
+}
+
+// networkPolicySchema описывает поля NetworkPolicy networking.k8s.io/v1
+var networkPolicySchema = &Schema{
+	Type:     "object",
+	Required: []string{"spec"},
+	Properties: map[string]*Schema{
+		"spec": {
+			Type:        "object",
+			Description: "Pods the policy applies to and the traffic allowed for them.",
+			Required:    []string{"podSelector"},
+			Properties: map[string]*Schema{
+				"podSelector": {Type: "object", Description: "Pods the policy applies to; {} selects all pods in the namespace.", Rules: []string{ruleRequiredField, ruleLabelSelector}},
+				"policyTypes": {Type: "array", Description: "Directions the policy isolates.", Items: &Schema{Type: "string", Enum: networkPolicyTypes, Rules: []string{ruleFieldValue}}},
+				"ingress": {
+					Type:        "array",
+					Description: "Allowed incoming traffic; an empty list denies all.",
+					Items: &Schema{
+						Type: "object",
+						Properties: map[string]*Schema{
+							"from":  {Type: "array", Description: "Sources of the traffic; absent or empty allows all sources.", Items: networkPolicyPeerSchema},
+							"ports": {Type: "array", Description: "Destination ports; absent or empty allows all ports.", Items: networkPolicyPortSchema},
+						},
+					},
+				},
+				"egress": {
+					Type:        "array",
+					Description: "Allowed outgoing traffic; an empty list denies all.",
+					Items: &Schema{
+						Type: "object",
+						Properties: map[string]*Schema{
+							"to":    {Type: "array", Description: "Destinations of the traffic; absent or empty allows all destinations.", Items: networkPolicyPeerSchema},
+							"ports": {Type: "array", Description: "Destination ports; absent or empty allows all ports.", Items: networkPolicyPortSchema},
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+func (v *Validator) validateNetworkPolicy(document map[string]interface{}, filename string) {
+	spec, exists := document["spec"]
+	if !exists {
+		v.addError(ruleRequiredField, "spec", fmt.Sprintf("%s: spec is required", filename))
+		return
+	}
+	specMap, ok := spec.(map[string]interface{})
+	if !ok {
+		v.addError(ruleFieldType, "spec", fmt.Sprintf("%s: spec must be an object", filename))
+		return
+	}
+	v.validateSchema(specMap, networkPolicySchema.Properties["spec"], "spec", filename)
+	// Пустой селектор здесь допустим: он выбирает все поды пространства имён
+	if selector, ok := specMap["podSelector"].(map[string]interface{}); ok && len(selector) > 0 {
+		v.validateLabelSelector(selector, "spec.podSelector", filename)
+	}
+
+	// Правила направления, которого нет в policyTypes, не действуют
+	if types, ok := specMap["policyTypes"].([]interface{}); ok {
+		listed := map[string]bool{}
+		for _, item := range types {
+			if name, ok := item.(string); ok {
+				listed[name] = true
+			}
+		}
+		for _, direction := range networkPolicyTypes {
+			key := strings.ToLower(direction)
+			if _, exists := specMap[key]; exists && !listed[direction] {
+				v.addError(ruleFieldValue, FieldPath("spec").Field(key), fmt.Sprintf("%s: spec.%s has no effect: spec.policyTypes does not list %s", filename, key, direction))
+			}
+		}
+	}
+
+	for _, direction := range []struct{ key, peers string }{{"ingress", "from"}, {"egress", "to"}} {
+		rules, _ := specMap[direction.key].([]interface{})
+		for i, item := range rules {
+			rule, _ := item.(map[string]interface{})
+			path := FieldPath("spec").Field(direction.key).Index(i)
+			peers, _ := rule[direction.peers].([]interface{})
+			for j, peer := range peers {
+				if peerMap, ok := peer.(map[string]interface{}); ok {
+					v.validateNetworkPolicyPeer(peerMap, path.Field(direction.peers).Index(j), filename)
+				}
+			}
+			ports, _ := rule["ports"].([]interface{})
+			for j, port := range ports {
+				if portMap, ok := port.(map[string]interface{}); ok {
+					v.validateNetworkPolicyPort(portMap, path.Field("ports").Index(j), filename)
+				}
+			}
+		}
+	}
+}
+
+// validateNetworkPolicyPeer проверяет источник или получателя трафика: селекторы либо ipBlock
+func (v *Validator) validateNetworkPolicyPeer(peer map[string]interface{}, path FieldPath, filename string) {
+	_, hasPods := peer["podSelector"]
+	_, hasNamespaces := peer["namespaceSelector"]
+	block, hasBlock := peer["ipBlock"]
+	switch {
+	case !hasPods && !hasNamespaces && !hasBlock:
+		v.addError(ruleRequiredField, path, fmt.Sprintf("%s: %s must set podSelector, namespaceSelector or ipBlock", filename, path))
+	case hasBlock && (hasPods || hasNamespaces):
+		v.addError(ruleFieldValue, path, fmt.Sprintf("%s: %s: ipBlock cannot be combined with podSelector or namespaceSelector", filename, path))
+	}
+	for _, key := range []string{"podSelector", "namespaceSelector"} {
+		if selector, ok := peer[key].(map[string]interface{}); ok && len(selector) > 0 {
+			v.validateLabelSelector(selector, path.Field(key), filename)
+		}
+	}
+
+	blockMap, _ := block.(map[string]interface{})
+	cidr, ok := blockMap["cidr"].(string)
+	if !ok {
+		return
+	}
+	blockPath := path.Field("ipBlock")
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		v.addError(ruleFieldValue, blockPath.Field("cidr"), fmt.Sprintf("%s: %s '%s' is not a CIDR range, e.g. 10.0.0.0/16", filename, blockPath.Field("cidr"), cidr))
+		return
+	}
+	size, _ := network.Mask.Size()
+	except, _ := blockMap["except"].([]interface{})
+	for i, item := range except {
+		exceptPath := blockPath.Field("except").Index(i)
+		str, _ := item.(string)
+		_, excluded, err := net.ParseCIDR(str)
+		if err != nil {
+			v.addError(ruleFieldValue, exceptPath, fmt.Sprintf("%s: %s '%s' is not a CIDR range, e.g. 10.0.1.0/24", filename, exceptPath, str))
+			continue
+		}
+		// Исключение должно быть строго внутри cidr
+		if excludedSize, _ := excluded.Mask.Size(); !network.Contains(excluded.IP) || excludedSize <= size || len(excluded.IP) != len(network.IP) {
+			v.addError(ruleFieldValue, exceptPath, fmt.Sprintf("%s: %s '%s' must be a range inside %s", filename, exceptPath, str, cidr))
+		}
+	}
+}
+
+// validateNetworkPolicyPort проверяет протокол, номер или имя порта и диапазон endPort
+func (v *Validator) validateNetworkPolicyPort(port map[string]interface{}, path FieldPath, filename string) {
+	v.validateProtocol(port, path, filename)
+
+	value, hasPort := port["port"]
+	number, numeric := portNumber(value)
+	if name, ok := value.(string); ok {
+		if !portNamePattern.MatchString(name) || !strings.ContainsAny(name, "abcdefghijklmnopqrstuvwxyz") {
+			v.addError(ruleFieldValue, path.Field("port"), fmt.Sprintf("%s: %s must be a port number or a port name of up to 15 lowercase letters, digits and hyphens, got '%s'", filename, path.Field("port"), name))
+		}
+	} else if hasPort {
+		v.validateServicePortNumber(value, path.Field("port"), filename)
+	}
+
+	endPort, exists := port["endPort"]
+	if !exists {
+		return
+	}
+	end, ok := portNumber(endPort)
+	switch {
+	case !ok:
+		v.addError(ruleFieldType, path.Field("endPort"), fmt.Sprintf("%s: %s must be integer", filename, path.Field("endPort")))
+	case !portInRange(end):
+		v.addError(rulePortRange, path.Field("endPort"), fmt.Sprintf("%s: %s value out of range", filename, path.Field("endPort")))
+	case !numeric:
+		v.addError(ruleFieldValue, path.Field("endPort"), fmt.Sprintf("%s: %s requires a numeric port", filename, path.Field("endPort")))
+	case end < number:
+		v.addError(rulePortRange, path.Field("endPort"), fmt.Sprintf("%s: %s must not be less than port %v", filename, path.Field("endPort"), number))
+	}
+}
@@ -0,0 +1,74 @@
+package validator
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// detectNonKubernetes возвращает описание файла, если он явно не является манифестом Kubernetes
+func detectNonKubernetes(document map[string]interface{}, filename string) string {
+	if document == nil {
+		return ""
+	}
+	if isComposeFile(document, filename) {
+		return "a Docker Compose file"
+	}
+
+	_, hasAPIVersion := document["apiVersion"]
+	_, hasKind := document["kind"]
+	if hasAPIVersion || hasKind {
+		return ""
+	}
+
+	_, hasJobs := document["jobs"].(map[string]interface{})
+	_, hasOn := document["on"]
+	if isWorkflowPath(filename) || (hasJobs && hasOn) {
+		return "a GitHub Actions workflow"
+	}
+	if _, hasRuns := document["runs"].(map[string]interface{}); hasRuns && isActionFilename(filename) {
+		return "a GitHub Actions action definition"
+	}
+	if _, hasStages := document["stages"]; hasStages || isGitLabCIFilename(filename) {
+		return "a GitLab CI configuration"
+	}
+
+	_, hasMetadata := document["metadata"]
+	_, hasSpec := document["spec"]
+	if !hasMetadata && !hasSpec {
+		return "application configuration"
+	}
+	return ""
+}
+
+func isWorkflowPath(filename string) bool {
+	return strings.Contains(filepath.ToSlash(filename), ".github/workflows/")
+}
+
+func isActionFilename(filename string) bool {
+	return strings.HasPrefix(filepath.Base(filename), "action.")
+}
+
+func isGitLabCIFilename(filename string) bool {
+	return filepath.Base(filename) == ".gitlab-ci.yml"
+}
+
+// FilenameTraits перечисляет, что проверка выводит из имени файла, а не из содержимого:
+// Compose, workflow и action GitHub Actions, GitLab CI. Документы с одинаковым содержимым
+// и признаками получают одни и те же находки с точностью до имени файла в сообщениях.
+func FilenameTraits(filename string) string {
+	var traits []string
+	for _, trait := range []struct {
+		name    string
+		matches bool
+	}{
+		{"compose", composeFilename.MatchString(filepath.Base(filename))},
+		{"workflow", isWorkflowPath(filename)},
+		{"action", isActionFilename(filename)},
+		{"gitlab-ci", isGitLabCIFilename(filename)},
+	} {
+		if trait.matches {
+			traits = append(traits, trait.name)
+		}
+	}
+	return strings.Join(traits, ",")
+}
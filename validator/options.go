@@ -0,0 +1,111 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Options — настройки одного запуска проверки из конфигурации и флагов. Нулевое значение —
+// проверка без конфигурации: текущая версия набора правил и реестр по умолчанию.
+type Options struct {
+	// RulesetVersion — версия набора правил, поведение проверок которой сохраняется:
+	// изменения разбора, вошедшие в более поздние версии, не применяются. Пусто — текущая версия.
+	RulesetVersion string
+	// MinimumQoS — минимальный класс QoS подов (правило qos-class); пусто — проверка выключена
+	MinimumQoS string
+	// AllowedRegistries — реестры, из которых можно брать образы (правило image-registry);
+	// первый из них подставляется в исправления. Пусто — реестр по умолчанию.
+	AllowedRegistries []string
+	// HostPath — политика томов hostPath (правило host-path); nil — проверка выключена
+	HostPath *HostPathPolicy
+	// LimitRatios — соотношения лимитов и запросов для подсказок resource-defaults;
+	// nil — DefaultLimitRatios
+	LimitRatios *LimitRatios
+	// ImageTags — требования к тегам образов; nil оставляет только требование наличия тега
+	ImageTags *ImageTagPolicy
+	// DigestResolver — как узнать дайджест образа для подсказки правила image-digest;
+	// nil — дайджесты не запрашиваются и подсказка без значения
+	DigestResolver DigestResolver
+}
+
+// Check проверяет значения, которые приходят из конфигурации как строки
+func (o Options) Check() error {
+	if o.RulesetVersion != "" {
+		if err := CheckRulesetVersion(o.RulesetVersion); err != nil {
+			return err
+		}
+	}
+	if o.MinimumQoS != "" && !containsString(QoSClasses(), o.MinimumQoS) {
+		return fmt.Errorf("unknown QoS class %q (known: %s)", o.MinimumQoS, strings.Join(QoSClasses(), ", "))
+	}
+	return nil
+}
+
+// Validate проверяет YAML-манифест без конфигурации и возвращает найденные нарушения
+func Validate(data []byte, filename string) []Finding {
+	return ValidateWith(data, filename, Options{})
+}
+
+// ValidateWith проверяет YAML-манифест с настройками запуска и возвращает найденные нарушения
+func ValidateWith(data []byte, filename string, options Options) []Finding {
+	validator := Validator{options: options}
+	started := time.Now()
+	defer func() { recordValidation(validator.errors, time.Since(started)) }()
+
+	root, document, ok := validator.parse(data, filename)
+	if !ok {
+		return validator.errors
+	}
+
+	// До появления правила not-kubernetes такие файлы проверялись как манифесты
+	if looksLike := detectNonKubernetes(document, filename); looksLike != "" && validator.ruleInRuleset(ruleNotKubernetes) {
+		validator.addError(ruleNotKubernetes, "", fmt.Sprintf("%s: not a Kubernetes manifest (looks like %s), skipped", filename, looksLike))
+		return validator.errors
+	}
+
+	// Валидируем верхнеуровневые поля
+	validator.validateResource(document, filename)
+	validator.validateScalars(root, "", filename)
+
+	validator.resolvePositions(root)
+	return validator.errors
+}
+
+// rulesetAtLeast сообщает, что выбранная версия набора правил не старше version
+func (v *Validator) rulesetAtLeast(version string) bool {
+	return rulesetAtLeast(v.options.RulesetVersion, version)
+}
+
+// ruleInRuleset сообщает, что встроенное правило входит в выбранную версию набора правил
+func (v *Validator) ruleInRuleset(id string) bool {
+	rule, _ := FindRule(id)
+	return v.rulesetAtLeast(rule.Since)
+}
+
+// allowedRegistries возвращает разрешённые реестры без завершающей косой черты
+func (v *Validator) allowedRegistries() []string {
+	if len(v.options.AllowedRegistries) == 0 {
+		return []string{defaultRegistry}
+	}
+	allowed := make([]string, len(v.options.AllowedRegistries))
+	for i, registry := range v.options.AllowedRegistries {
+		allowed[i] = strings.TrimSuffix(registry, "/")
+	}
+	return allowed
+}
+
+// limitRatios возвращает соотношения лимитов и запросов; незаданные берутся по умолчанию
+func (v *Validator) limitRatios() LimitRatios {
+	if v.options.LimitRatios == nil {
+		return DefaultLimitRatios
+	}
+	ratios := *v.options.LimitRatios
+	if ratios.CPU == 0 {
+		ratios.CPU = DefaultLimitRatios.CPU
+	}
+	if ratios.Memory == 0 {
+		ratios.Memory = DefaultLimitRatios.Memory
+	}
+	return ratios
+}
@@ -0,0 +1,83 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Процент в полях «число или процент», например 25%
+var percentPattern = regexp.MustCompile(`^[0-9]+%$`)
+
+// podDisruptionBudgetSchema описывает поля PodDisruptionBudget policy/v1
+var podDisruptionBudgetSchema = &Schema{
+	Type:     "object",
+	Required: []string{"spec"},
+	Properties: map[string]*Schema{
+		"spec": {
+			Type:        "object",
+			Description: "Pods the budget protects and how many of them may be disrupted at once.",
+			Properties: map[string]*Schema{
+				"selector":                   {Type: "object", Description: "Label selector of the protected pods; {} selects every pod of the namespace."},
+				"minAvailable":               {Description: "Pods that must stay available, as a number or a percentage; set this or maxUnavailable."},
+				"maxUnavailable":             {Description: "Pods that may be unavailable, as a number or a percentage; set this or minAvailable."},
+				"unhealthyPodEvictionPolicy": {Type: "string", Description: "When pods that are not ready may be evicted.", Enum: []string{"IfHealthyBudget", "AlwaysAllow"}},
+			},
+		},
+	},
+}
+
+func (v *Validator) validatePodDisruptionBudget(document map[string]interface{}, filename string) {
+	spec, exists := document["spec"]
+	if !exists {
+		v.addError(ruleRequiredField, "spec", fmt.Sprintf("%s: spec is required", filename))
+		return
+	}
+	specMap, ok := spec.(map[string]interface{})
+	if !ok {
+		v.addError(ruleFieldType, "spec", fmt.Sprintf("%s: spec must be an object", filename))
+		return
+	}
+	v.validateSchema(specMap, podDisruptionBudgetSchema.Properties["spec"], "spec", filename)
+	// Пустой селектор допустим: он выбирает все поды пространства имён
+	if selector, ok := specMap["selector"].(map[string]interface{}); ok && len(selector) > 0 {
+		v.validateLabelSelector(selector, "spec.selector", filename)
+	}
+
+	minAvailable, hasMin := specMap["minAvailable"]
+	maxUnavailable, hasMax := specMap["maxUnavailable"]
+	switch {
+	case hasMin && hasMax:
+		v.addError(ruleFieldValue, "spec.maxUnavailable", fmt.Sprintf("%s: spec.minAvailable and spec.maxUnavailable are mutually exclusive", filename))
+		v.suggest(Remediation{Action: ActionRemove})
+	case !hasMin && !hasMax:
+		v.addError(ruleRequiredField, "spec.minAvailable", fmt.Sprintf("%s: one of spec.minAvailable or spec.maxUnavailable is required", filename))
+	}
+	if hasMin {
+		v.validateIntOrPercent(minAvailable, "spec.minAvailable", filename)
+	}
+	if hasMax {
+		v.validateIntOrPercent(maxUnavailable, "spec.maxUnavailable", filename)
+	}
+}
+
+// validateIntOrPercent проверяет значение вида «неотрицательное целое или процент до 100%»
+func (v *Validator) validateIntOrPercent(value interface{}, path FieldPath, filename string) {
+	switch typed := value.(type) {
+	case int:
+		if typed < 0 {
+			v.addError(ruleFieldValue, path, fmt.Sprintf("%s: %s must not be negative", filename, path))
+		}
+		return
+	case string:
+		if percentPattern.MatchString(typed) {
+			if percent, _ := strconv.Atoi(strings.TrimSuffix(typed, "%")); percent > 100 {
+				v.addError(ruleFieldValue, path, fmt.Sprintf("%s: %s must not exceed 100%%", filename, path))
+				v.suggest(Remediation{Action: ActionSet, Value: "100%"})
+			}
+			return
+		}
+	}
+	v.addError(ruleFieldType, path, fmt.Sprintf("%s: %s must be an integer or a percentage, e.g. 2 or 50%%", filename, path))
+}
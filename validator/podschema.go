@@ -0,0 +1,185 @@
+package validator
+
+func float(f float64) *float64 {
+	return &f
+}
+
+// metadataSchema — поля apiVersion, kind и metadata, общие для всех типов
+var metadataSchema = Schema{
+	Type: "object",
+	Properties: map[string]*Schema{
+		"apiVersion": {Type: "string", Description: "Versioned schema of this object, e.g. v1 or apps/v1.", Rules: []string{ruleRequiredField, ruleAPIVersion}},
+		"kind":       {Type: "string", Description: "Type of the resource, e.g. Pod.", Rules: []string{ruleRequiredField, ruleKind}},
+		"metadata": {
+			Type:        "object",
+			Description: "Standard object metadata.",
+			Required:    []string{"name"},
+			Properties: map[string]*Schema{
+				"name":         {Type: "string", Description: "Name of the object, unique within its namespace.", Rules: []string{ruleRequiredField, ruleFieldType}},
+				"generateName": {Type: "string", Description: "Prefix for a name generated by the server."},
+				"namespace":    {Type: "string", Description: "Namespace the object belongs to."},
+				"labels":       {Type: "object", Description: "Key-value pairs used to select and group objects."},
+				"annotations":  {Type: "object", Description: "Arbitrary non-identifying metadata."},
+			},
+		},
+	},
+}
+
+// podSchema описывает поля пода; проверка значений выполняется кодом validatePod, а схема нужна
+// автодополнению, подсказкам редактора и поиску неизвестных полей в режиме --strict
+var podSchema = &Schema{
+	Type:     "object",
+	Required: []string{"spec"},
+	Properties: map[string]*Schema{
+		"spec": {
+			Type:        "object",
+			Description: "Desired state of the pod.",
+			Required:    []string{"containers"},
+			Properties: map[string]*Schema{
+				"os": {
+					Type:        "object",
+					Description: "Operating system of the containers in the pod.",
+					Rules:       []string{ruleOSName},
+					Required:    []string{"name"},
+					Properties: map[string]*Schema{
+						"name": {Type: "string", Description: "Operating system name.", Enum: []string{"linux", "windows"}, Rules: []string{ruleOSName}},
+					},
+				},
+				"containers": {
+					Type:        "array",
+					Description: "Containers of the pod; at least one is required.",
+					Rules:       []string{ruleRequiredField, ruleMinContainers},
+					Items:       containerSchema,
+				},
+				"initContainers":                {Type: "array", Description: "Containers that run to completion before the app containers start.", Items: containerSchema},
+				"volumes":                       {Type: "array", Description: "Volumes that containers of the pod can mount.", Items: &Schema{Type: "object"}},
+				"restartPolicy":                 {Type: "string", Description: "Restart policy of the containers.", Enum: []string{"Always", "OnFailure", "Never"}},
+				"serviceAccountName":            {Type: "string", Description: "Service account the pod runs as."},
+				"automountServiceAccountToken":  {Type: "boolean", Description: "Whether the service account token is mounted into the pod."},
+				"nodeSelector":                  {Type: "object", Description: "Node labels the pod must be scheduled on."},
+				"nodeName":                      {Type: "string", Description: "Node the pod is bound to, bypassing the scheduler."},
+				"affinity":                      {Type: "object", Description: "Scheduling constraints of the pod."},
+				"tolerations":                   {Type: "array", Description: "Taints the pod tolerates.", Items: &Schema{Type: "object"}},
+				"topologySpreadConstraints":     {Type: "array", Description: "How pods are spread across topology domains.", Items: &Schema{Type: "object"}},
+				"securityContext":               {Type: "object", Description: "Security attributes of the pod."},
+				"imagePullSecrets":              {Type: "array", Description: "Secrets used to pull the images.", Items: &Schema{Type: "object"}},
+				"hostNetwork":                   {Type: "boolean", Description: "Whether the pod uses the network namespace of the node."},
+				"hostPID":                       {Type: "boolean", Description: "Whether the pod uses the process namespace of the node."},
+				"hostIPC":                       {Type: "boolean", Description: "Whether the pod uses the IPC namespace of the node."},
+				"dnsPolicy":                     {Type: "string", Description: "DNS policy of the pod."},
+				"priorityClassName":             {Type: "string", Description: "Priority class of the pod."},
+				"terminationGracePeriodSeconds": {Type: "integer", Description: "Seconds the pod is given to terminate gracefully."},
+			},
+		},
+	},
+}
+
+var containerSchema = &Schema{
+	Type:     "object",
+	Required: []string{"name", "image", "resources"},
+	Properties: map[string]*Schema{
+		"name":  {Type: "string", Description: "Container name in snake_case.", Pattern: snakeCase, Rules: []string{ruleRequiredField, ruleContainerName}},
+		"image": {Type: "string", Description: "Image from registry.bigbrother.io with an explicit version tag.", Rules: []string{ruleRequiredField, ruleImageRegistry, ruleImageTag}},
+		"ports": {
+			Type:        "array",
+			Description: "Ports exposed by the container.",
+			Items: &Schema{
+				Type:     "object",
+				Required: []string{"containerPort"},
+				Properties: map[string]*Schema{
+					"containerPort": {Type: "integer", Description: "Port number.", Minimum: float(1), Maximum: float(65535), Rules: []string{ruleRequiredField, rulePortRange}},
+					"protocol":      {Type: "string", Description: "Port protocol.", Enum: []string{"TCP", "UDP"}, Rules: []string{rulePortProtocol}},
+					"name":          {Type: "string", Description: "Port name referenced by Services."},
+					"hostPort":      {Type: "integer", Description: "Port on the node.", Minimum: float(1), Maximum: float(65535)},
+				},
+			},
+		},
+		"readinessProbe":  probeSchema("Probe that decides when the container is ready to serve traffic."),
+		"livenessProbe":   probeSchema("Probe that decides when the container must be restarted."),
+		"startupProbe":    probeSchema("Probe that holds off the other probes until the container has started."),
+		"command":         {Type: "array", Description: "Entrypoint of the container, overriding the image.", Items: &Schema{Type: "string"}},
+		"args":            {Type: "array", Description: "Arguments of the entrypoint.", Items: &Schema{Type: "string"}},
+		"workingDir":      {Type: "string", Description: "Working directory of the container."},
+		"imagePullPolicy": {Type: "string", Description: "When the image is pulled.", Enum: []string{"Always", "IfNotPresent", "Never"}},
+		"env": {
+			Type:        "array",
+			Description: "Environment variables of the container.",
+			Items: &Schema{
+				Type:     "object",
+				Required: []string{"name"},
+				Properties: map[string]*Schema{
+					"name":      {Type: "string", Description: "Variable name."},
+					"value":     {Type: "string", Description: "Variable value."},
+					"valueFrom": {Type: "object", Description: "Source of the value: configMapKeyRef, secretKeyRef, fieldRef or resourceFieldRef."},
+				},
+			},
+		},
+		"envFrom": {Type: "array", Description: "ConfigMaps and Secrets whose keys become environment variables.", Items: &Schema{Type: "object"}},
+		"volumeMounts": {
+			Type:        "array",
+			Description: "Pod volumes mounted into the container.",
+			Items: &Schema{
+				Type:     "object",
+				Required: []string{"name", "mountPath"},
+				Properties: map[string]*Schema{
+					"name":             {Type: "string", Description: "Name of the pod volume."},
+					"mountPath":        {Type: "string", Description: "Path inside the container."},
+					"subPath":          {Type: "string", Description: "Path within the volume to mount."},
+					"readOnly":         {Type: "boolean", Description: "Mount the volume read-only."},
+					"mountPropagation": {Type: "string", Description: "How mounts are propagated between the host and the container."},
+				},
+			},
+		},
+		"securityContext": {Type: "object", Description: "Security attributes of the container."},
+		"lifecycle":       {Type: "object", Description: "Actions run after the container starts and before it stops."},
+		"stdin":           {Type: "boolean", Description: "Keep stdin open for the container."},
+		"tty":             {Type: "boolean", Description: "Allocate a TTY for the container."},
+		"resources": {
+			Type:        "object",
+			Description: "Compute resources of the container.",
+			Rules:       []string{ruleRequiredField, ruleResourceName},
+			Properties: map[string]*Schema{
+				"requests": resourceListSchema("Minimum resources the container needs."),
+				"limits":   resourceListSchema("Maximum resources the container may use."),
+			},
+		},
+	},
+}
+
+func probeSchema(description string) *Schema {
+	return &Schema{
+		Type:        "object",
+		Description: description,
+		Required:    []string{"httpGet"},
+		Properties: map[string]*Schema{
+			"initialDelaySeconds": {Type: "integer", Description: "Seconds after the start before the first probe."},
+			"periodSeconds":       {Type: "integer", Description: "How often the probe runs, in seconds."},
+			"timeoutSeconds":      {Type: "integer", Description: "Seconds after which the probe times out."},
+			"successThreshold":    {Type: "integer", Description: "Consecutive successes to be considered successful."},
+			"failureThreshold":    {Type: "integer", Description: "Consecutive failures to be considered failed."},
+			"httpGet": {
+				Type:        "object",
+				Description: "HTTP GET request used as the probe.",
+				Required:    []string{"path", "port"},
+				Properties: map[string]*Schema{
+					"path":        {Type: "string", Description: "Absolute path of the request.", Pattern: "^/", Rules: []string{ruleRequiredField, ruleProbePath}},
+					"port":        {Type: "integer", Description: "Port of the request.", Minimum: float(1), Maximum: float(65535)},
+					"host":        {Type: "string", Description: "Host name to connect to, the pod IP by default."},
+					"scheme":      {Type: "string", Description: "Scheme of the request.", Enum: []string{"HTTP", "HTTPS"}},
+					"httpHeaders": {Type: "array", Description: "Custom headers of the request.", Items: &Schema{Type: "object"}},
+				},
+			},
+		},
+	}
+}
+
+func resourceListSchema(description string) *Schema {
+	return &Schema{
+		Type:        "object",
+		Description: description,
+		Properties: map[string]*Schema{
+			"cpu":    {Type: "integer", Description: "Number of CPU cores.", Rules: []string{ruleResourceCPU}},
+			"memory": {Type: "string", Description: "Memory with the Gi, Mi or Ki suffix.", Pattern: `^[0-9]+(Ki|Mi|Gi)$`, Rules: []string{ruleResourceMemory}},
+		},
+	}
+}
@@ -0,0 +1,92 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Обработчики проверки живости: у пробы задан ровно один из них
+var probeHandlers = []string{"httpGet", "tcpSocket", "grpc", "exec"}
+
+// Значения таймингов пробы по умолчанию, как у kubelet
+var probeTimingDefaults = map[string]float64{
+	"initialDelaySeconds": 0,
+	"periodSeconds":       10,
+	"timeoutSeconds":      1,
+	"failureThreshold":    3,
+	"successThreshold":    1,
+}
+
+// Время на корректное завершение пода по умолчанию
+const defaultTerminationGracePeriod = 30
+
+// probeTiming возвращает тайминг пробы с учётом значения по умолчанию
+func probeTiming(probe map[string]interface{}, field string) float64 {
+	if number, _ := toNumber(probe[field]); number != nil {
+		return *number
+	}
+	return probeTimingDefaults[field]
+}
+
+// validateProbeSanity ищет пробы, которые формально верны, но ведут себя не так, как ожидает автор:
+// одинаковые liveness и readiness, таймаут не короче периода, перезапуск медленнее завершения пода
+func (v *Validator) validateProbeSanity(document map[string]interface{}, filename string) {
+	for _, template := range podTemplates(document) {
+		spec, ok := lookupPath(document, template.spec).(map[string]interface{})
+		if !ok {
+			continue
+		}
+		gracePeriod := float64(defaultTerminationGracePeriod)
+		if number, _ := toNumber(spec["terminationGracePeriodSeconds"]); number != nil {
+			gracePeriod = *number
+		}
+
+		containers, _ := spec["containers"].([]interface{})
+		for i, item := range containers {
+			container, _ := item.(map[string]interface{})
+			path := template.spec.Field("containers").Index(i)
+			liveness, _ := container["livenessProbe"].(map[string]interface{})
+			readiness, _ := container["readinessProbe"].(map[string]interface{})
+
+			if liveness != nil && readiness != nil && sameProbe(liveness, readiness) {
+				v.addError(ruleProbeSanity, path.Field("livenessProbe"), fmt.Sprintf("%s: %s and readinessProbe check the same endpoint with identical timings, so a slow response restarts the container instead of only taking it out of rotation", filename, path.Field("livenessProbe")))
+			}
+
+			for _, probeType := range []string{"livenessProbe", "readinessProbe", "startupProbe"} {
+				probe, _ := container[probeType].(map[string]interface{})
+				if probe == nil {
+					continue
+				}
+				probePath := path.Field(probeType)
+				period, timeout := probeTiming(probe, "periodSeconds"), probeTiming(probe, "timeoutSeconds")
+				if timeout >= period {
+					v.addError(ruleProbeSanity, probePath.Field("timeoutSeconds"), fmt.Sprintf("%s: %s (%g) is not shorter than periodSeconds (%g), so the next check starts before the previous one times out", filename, probePath.Field("timeoutSeconds"), timeout, period))
+				}
+				// Kubernetes перезапускает контейнер только по liveness-пробе
+				if probeType != "livenessProbe" {
+					continue
+				}
+				if detection := probeTiming(probe, "failureThreshold") * period; detection > gracePeriod {
+					v.addError(ruleProbeSanity, probePath.Field("failureThreshold"), fmt.Sprintf("%s: %s x periodSeconds (%gs) exceeds terminationGracePeriodSeconds (%gs), so a hung container is restarted slower than it is allowed to shut down", filename, probePath.Field("failureThreshold"), detection, gracePeriod))
+				}
+			}
+		}
+	}
+}
+
+// sameProbe сообщает, что пробы обращаются к одной точке с одинаковыми таймингами
+func sameProbe(a, b map[string]interface{}) bool {
+	handler := false
+	for _, name := range probeHandlers {
+		if !reflect.DeepEqual(a[name], b[name]) {
+			return false
+		}
+		handler = handler || a[name] != nil
+	}
+	for field := range probeTimingDefaults {
+		if probeTiming(a, field) != probeTiming(b, field) {
+			return false
+		}
+	}
+	return handler
+}
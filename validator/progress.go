@@ -0,0 +1,103 @@
+package validator
+
+import (
+	"sync"
+	"time"
+)
+
+// EventType — вид события о ходе проверки
+type EventType string
+
+const (
+	EventFileStarted  EventType = "file_started"
+	EventFileFinished EventType = "file_finished"
+	EventFinding      EventType = "finding"
+	EventRunFinished  EventType = "run_finished"
+)
+
+// Event — событие о ходе проверки. File заполнен у событий файла и находки,
+// Finding — у события находки, Files и Findings — итоги у file_finished (по файлу)
+// и run_finished (по запуску).
+type Event struct {
+	Type     EventType
+	File     string
+	Finding  *Finding
+	Files    int
+	Findings int
+	Duration time.Duration
+}
+
+// Progress сообщает о ходе проверки нескольких файлов, например для индикатора в GUI
+// или обёртки CI; нулевое значение без OnEvent событий не отправляет.
+// Методы можно вызывать из нескольких горутин: события доставляются по одному.
+type Progress struct {
+	OnEvent func(Event)
+
+	mu       sync.Mutex
+	started  time.Time
+	files    int
+	findings int
+}
+
+func (p *Progress) emit(event Event) {
+	if p.OnEvent != nil {
+		p.OnEvent(event)
+	}
+}
+
+// StartFile сообщает о начале проверки файла
+func (p *Progress) StartFile(filename string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.started.IsZero() {
+		p.started = time.Now()
+	}
+	p.emit(Event{Type: EventFileStarted, File: filename})
+}
+
+// FinishFile сообщает о находках файла и об окончании его проверки
+func (p *Progress) FinishFile(filename string, findings []Finding) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.files++
+	p.report(filename, findings)
+	p.emit(Event{Type: EventFileFinished, File: filename, Files: 1, Findings: len(findings)})
+}
+
+// AddFindings сообщает о находках, найденных после окончания проверки файла,
+// например о нарушениях уникальности между файлами
+func (p *Progress) AddFindings(filename string, findings []Finding) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.report(filename, findings)
+}
+
+func (p *Progress) report(filename string, findings []Finding) {
+	for i := range findings {
+		p.findings++
+		p.emit(Event{Type: EventFinding, File: filename, Finding: &findings[i]})
+	}
+}
+
+// Finish сообщает об окончании запуска с итогами
+func (p *Progress) Finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var duration time.Duration
+	if !p.started.IsZero() {
+		duration = time.Since(p.started)
+	}
+	p.emit(Event{Type: EventRunFinished, Files: p.files, Findings: p.findings, Duration: duration})
+}
+
+// Validate проверяет файл, как Validate, и сообщает о начале, находках и окончании
+func (p *Progress) Validate(data []byte, filename string) []Finding {
+	p.StartFile(filename)
+	findings := Validate(data, filename)
+	p.FinishFile(filename, findings)
+	return findings
+}
@@ -0,0 +1,218 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Уровни Pod Security Standards; каждый следующий строже предыдущего
+const (
+	PSSPrivileged = "privileged"
+	PSSBaseline   = "baseline"
+	PSSRestricted = "restricted"
+)
+
+// PSSLevels возвращает уровни Pod Security Standards от самого мягкого к самому строгому
+func PSSLevels() []string {
+	return []string{PSSPrivileged, PSSBaseline, PSSRestricted}
+}
+
+// PSSGroups возвращает группы правил, которые проверяют уровень: restricted включает baseline,
+// а privileged ничего не ограничивает
+func PSSGroups(level string) ([]string, error) {
+	switch level {
+	case PSSPrivileged:
+		return nil, nil
+	case PSSBaseline:
+		return []string{GroupPSSBaseline}, nil
+	case PSSRestricted:
+		return []string{GroupPSSBaseline, GroupPSSRestricted}, nil
+	}
+	return nil, fmt.Errorf("unknown Pod Security Standards level %q (known: %s)", level, strings.Join(PSSLevels(), ", "))
+}
+
+// Добавляемые capabilities, которые допускает baseline
+var pssBaselineCapabilities = []string{
+	"AUDIT_WRITE", "CHOWN", "DAC_OVERRIDE", "FOWNER", "FSETID", "KILL", "MKNOD", "NET_BIND_SERVICE",
+	"SETFCAP", "SETGID", "SETPCAP", "SETUID", "SYS_CHROOT",
+}
+
+// Sysctl, безопасные для baseline: они изолированы пространствами имён пода
+var pssSafeSysctls = []string{
+	"kernel.shm_rmid_forced", "net.ipv4.ip_local_port_range", "net.ipv4.ip_unprivileged_port_start",
+	"net.ipv4.tcp_syncookies", "net.ipv4.ping_group_range", "net.ipv4.ip_local_reserved_ports",
+	"net.ipv4.tcp_keepalive_time", "net.ipv4.tcp_fin_timeout", "net.ipv4.tcp_keepalive_intvl",
+	"net.ipv4.tcp_keepalive_probes",
+}
+
+// Типы SELinux, которые допускает baseline; пустой тип тоже допустим
+var pssSELinuxTypes = []string{"container_t", "container_init_t", "container_kvm_t", "container_engine_t"}
+
+// Типы томов, которые допускает restricted
+var pssRestrictedVolumes = []string{
+	"configMap", "csi", "downwardAPI", "emptyDir", "ephemeral", "persistentVolumeClaim", "projected", "secret",
+}
+
+// Префикс аннотаций AppArmor до появления securityContext.appArmorProfile
+const appArmorAnnotationPrefix = "container.apparmor.security.beta.kubernetes.io/"
+
+// validatePodSecurity проверяет шаблоны пода документа по критериям Pod Security Standards,
+// которые применяет Pod Security Admission. Находки уровней baseline и restricted выводятся
+// только при включённых группах pss-baseline и pss-restricted.
+func (v *Validator) validatePodSecurity(document map[string]interface{}, filename string) {
+	for _, template := range podTemplates(document) {
+		spec := template.spec
+		if _, ok := lookupPath(document, spec).(map[string]interface{}); !ok {
+			continue
+		}
+		violation := func(ruleID string, path FieldPath, format string, args ...interface{}) {
+			v.addError(ruleID, path, fmt.Sprintf("%s: %s %s", filename, path, fmt.Sprintf(format, args...)))
+		}
+		// Часть ограничений restricted к подам Windows не применяется
+		windows := lookupPath(document, spec.Field("os").Field("name")) == "windows"
+
+		for _, field := range []string{"hostNetwork", "hostPID", "hostIPC"} {
+			if lookupPath(document, spec.Field(field)) == true {
+				violation(rulePSSHostNamespaces, spec.Field(field), "must not be true: host namespaces are not allowed")
+			}
+		}
+
+		podContext := spec.Field("securityContext")
+		checkSecurityContext(document, podContext, violation)
+		if sysctls, ok := lookupPath(document, podContext.Field("sysctls")).([]interface{}); ok {
+			for i := range sysctls {
+				path := podContext.Field("sysctls").Index(i).Field("name")
+				if name, _ := lookupPath(document, path).(string); !containsString(pssSafeSysctls, name) {
+					violation(rulePSSSysctls, path, "'%s' is not a safe sysctl", name)
+				}
+			}
+		}
+
+		annotations, _ := lookupPath(document, template.labelsPath.Parent().Field("annotations")).(map[string]interface{})
+		for _, key := range sortedKeys(annotations) {
+			if !strings.HasPrefix(key, appArmorAnnotationPrefix) {
+				continue
+			}
+			if profile, _ := annotations[key].(string); profile != "runtime/default" && !strings.HasPrefix(profile, "localhost/") {
+				violation(rulePSSAppArmor, template.labelsPath.Parent().Field("annotations").Field(key), "must be runtime/default or localhost/<profile>, got '%s'", profile)
+			}
+		}
+
+		volumes, _ := lookupPath(document, spec.Field("volumes")).([]interface{})
+		for i, volume := range volumes {
+			volumeMap, _ := volume.(map[string]interface{})
+			for _, source := range sortedKeys(volumeMap) {
+				path := spec.Field("volumes").Index(i).Field(source)
+				switch {
+				case source == "name":
+				case source == "hostPath":
+					violation(rulePSSHostPath, path, "is not allowed: hostPath volumes expose the node filesystem")
+				case !containsString(pssRestrictedVolumes, source):
+					violation(rulePSSVolumeTypes, path, "is not an allowed volume type (allowed: %s)", strings.Join(pssRestrictedVolumes, ", "))
+				}
+			}
+		}
+
+		// runAsNonRoot и seccompProfile можно задать для пода целиком или для каждого контейнера
+		podNonRoot := lookupPath(document, podContext.Field("runAsNonRoot")) == true
+		podSeccomp := lookupPath(document, podContext.Field("seccompProfile").Field("type")) != nil
+
+		for _, list := range []string{"initContainers", "containers", "ephemeralContainers"} {
+			containers, _ := lookupPath(document, spec.Field(list)).([]interface{})
+			for i := range containers {
+				container := spec.Field(list).Index(i)
+				context := container.Field("securityContext")
+				checkSecurityContext(document, context, violation)
+
+				if lookupPath(document, context.Field("privileged")) == true {
+					violation(rulePSSPrivileged, context.Field("privileged"), "must not be true: privileged containers are not allowed")
+				}
+				ports, _ := lookupPath(document, container.Field("ports")).([]interface{})
+				for j := range ports {
+					path := container.Field("ports").Index(j).Field("hostPort")
+					if hostPort := lookupPath(document, path); hostPort != nil && hostPort != 0 {
+						violation(rulePSSHostPorts, path, "must not be set: host ports are not allowed")
+					}
+				}
+
+				capabilities := context.Field("capabilities")
+				added, _ := lookupPath(document, capabilities.Field("add")).([]interface{})
+				for j, capability := range added {
+					name := fmt.Sprint(capability)
+					switch {
+					case !containsString(pssBaselineCapabilities, name):
+						violation(rulePSSCapabilities, capabilities.Field("add").Index(j), "'%s' is not allowed by the baseline level", name)
+					case name != "NET_BIND_SERVICE" && !windows:
+						violation(rulePSSRestrictedCapabilities, capabilities.Field("add").Index(j), "'%s' is not allowed: only NET_BIND_SERVICE may be added", name)
+					}
+				}
+				if windows {
+					continue
+				}
+				dropped, _ := lookupPath(document, capabilities.Field("drop")).([]interface{})
+				if !containsString(stringValues(dropped), "ALL") {
+					violation(rulePSSRestrictedCapabilities, capabilities.Field("drop"), "must include ALL")
+				}
+				if lookupPath(document, context.Field("allowPrivilegeEscalation")) != false {
+					violation(rulePSSPrivilegeEscalation, context.Field("allowPrivilegeEscalation"), "must be false")
+				}
+				if nonRoot := lookupPath(document, context.Field("runAsNonRoot")); nonRoot == nil && !podNonRoot {
+					violation(rulePSSRunAsNonRoot, context.Field("runAsNonRoot"), "must be true, or %s must be true", podContext.Field("runAsNonRoot"))
+				}
+				if lookupPath(document, context.Field("seccompProfile").Field("type")) == nil && !podSeccomp {
+					violation(rulePSSSeccompRequired, context.Field("seccompProfile").Field("type"), "must be RuntimeDefault or Localhost, or %s must be set", podContext.Field("seccompProfile").Field("type"))
+				}
+			}
+		}
+	}
+}
+
+// checkSecurityContext проверяет поля, общие для securityContext пода и контейнера
+func checkSecurityContext(document map[string]interface{}, context FieldPath, violation func(string, FieldPath, string, ...interface{})) {
+	if _, ok := lookupPath(document, context).(map[string]interface{}); !ok {
+		return
+	}
+	if lookupPath(document, context.Field("windowsOptions").Field("hostProcess")) == true {
+		violation(rulePSSHostProcess, context.Field("windowsOptions").Field("hostProcess"), "must not be true: Windows HostProcess containers are not allowed")
+	}
+	if profile := lookupPath(document, context.Field("appArmorProfile").Field("type")); profile == "Unconfined" {
+		violation(rulePSSAppArmor, context.Field("appArmorProfile").Field("type"), "must not be Unconfined")
+	}
+	seLinux := context.Field("seLinuxOptions")
+	if seLinuxType, _ := lookupPath(document, seLinux.Field("type")).(string); seLinuxType != "" && !containsString(pssSELinuxTypes, seLinuxType) {
+		violation(rulePSSSELinux, seLinux.Field("type"), "'%s' is not allowed (allowed: %s)", seLinuxType, strings.Join(pssSELinuxTypes, ", "))
+	}
+	for _, field := range []string{"user", "role"} {
+		if value, _ := lookupPath(document, seLinux.Field(field)).(string); value != "" {
+			violation(rulePSSSELinux, seLinux.Field(field), "must not be set")
+		}
+	}
+	if procMount, _ := lookupPath(document, context.Field("procMount")).(string); procMount != "" && procMount != "Default" {
+		violation(rulePSSProcMount, context.Field("procMount"), "must be Default, got '%s'", procMount)
+	}
+
+	switch profile := lookupPath(document, context.Field("seccompProfile").Field("type")); profile {
+	case nil, "RuntimeDefault", "Localhost":
+	case "Unconfined":
+		violation(rulePSSSeccomp, context.Field("seccompProfile").Field("type"), "must not be Unconfined")
+	default:
+		violation(rulePSSSeccompRequired, context.Field("seccompProfile").Field("type"), "must be RuntimeDefault or Localhost, got '%v'", profile)
+	}
+	if lookupPath(document, context.Field("runAsNonRoot")) == false {
+		violation(rulePSSRunAsNonRoot, context.Field("runAsNonRoot"), "must not be false")
+	}
+	if runAsUser := lookupPath(document, context.Field("runAsUser")); runAsUser == 0 {
+		violation(rulePSSRunAsUser, context.Field("runAsUser"), "must not be 0: containers must not run as root")
+	}
+}
+
+// stringValues возвращает строковые элементы списка по порядку
+func stringValues(items []interface{}) []string {
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		if value, ok := item.(string); ok {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,147 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Классы QoS пода от худшего к лучшему: при нехватке памяти на узле первыми вытесняются BestEffort
+const (
+	QoSBestEffort = "BestEffort"
+	QoSBurstable  = "Burstable"
+	QoSGuaranteed = "Guaranteed"
+)
+
+// QoSClasses возвращает классы QoS от худшего к лучшему
+func QoSClasses() []string {
+	return []string{QoSBestEffort, QoSBurstable, QoSGuaranteed}
+}
+
+// Ресурсы, по которым Kubernetes определяет класс QoS
+var qosResources = []string{"cpu", "memory"}
+
+// QoSClass вычисляет класс QoS пода по ресурсам его контейнеров и init-контейнеров так же,
+// как kubelet: Guaranteed — у всех контейнеров лимиты cpu и memory равны запросам,
+// BestEffort — ни у одного контейнера нет запросов и лимитов, иначе Burstable
+func QoSClass(spec map[string]interface{}) string {
+	containers := qosContainers(spec)
+	guaranteed, burstable := len(containers) > 0, false
+	for _, container := range containers {
+		if len(guaranteedProblems(container)) > 0 {
+			guaranteed = false
+		}
+		if hasResources(container) {
+			burstable = true
+		}
+	}
+	switch {
+	case guaranteed:
+		return QoSGuaranteed
+	case burstable:
+		return QoSBurstable
+	}
+	return QoSBestEffort
+}
+
+func qosContainers(spec map[string]interface{}) []map[string]interface{} {
+	var containers []map[string]interface{}
+	for _, list := range []string{"initContainers", "containers"} {
+		items, _ := spec[list].([]interface{})
+		for _, item := range items {
+			container, _ := item.(map[string]interface{})
+			containers = append(containers, container)
+		}
+	}
+	return containers
+}
+
+// hasResources сообщает, задан ли у контейнера запрос или лимит cpu или memory
+func hasResources(container map[string]interface{}) bool {
+	for _, section := range []string{"requests", "limits"} {
+		values, _ := lookupPath(container, FieldPath("resources").Field(section)).(map[string]interface{})
+		for _, name := range qosResources {
+			if values[name] != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// guaranteedProblems перечисляет, почему контейнер не даёт поду класс Guaranteed;
+// запрос без значения считается равным лимиту
+func guaranteedProblems(container map[string]interface{}) []string {
+	requests, _ := lookupPath(container, "resources.requests").(map[string]interface{})
+	limits, _ := lookupPath(container, "resources.limits").(map[string]interface{})
+	var problems []string
+	for _, name := range qosResources {
+		limit, hasLimit := limits[name]
+		if !hasLimit {
+			problems = append(problems, fmt.Sprintf("limits.%s is not set", name))
+			continue
+		}
+		request, hasRequest := requests[name]
+		if !hasRequest {
+			continue
+		}
+		requestQuantity, err := ParseQuantity(request)
+		if err != nil {
+			continue
+		}
+		limitQuantity, err := ParseQuantity(limit)
+		if err != nil {
+			continue
+		}
+		if requestQuantity.Cmp(limitQuantity) != 0 {
+			problems = append(problems, fmt.Sprintf("requests.%s (%v) differs from limits.%s (%v)", name, request, name, limit))
+		}
+	}
+	return problems
+}
+
+func qosRank(class string) int {
+	for i, known := range QoSClasses() {
+		if known == class {
+			return i
+		}
+	}
+	return -1
+}
+
+// validateQoS проверяет, что шаблоны пода получают не худший класс QoS, чем требует
+// конфигурация, и называет контейнеры, которые мешают
+func (v *Validator) validateQoS(document map[string]interface{}, filename string) {
+	required := v.options.MinimumQoS
+	if required == "" {
+		return
+	}
+	for _, template := range podTemplates(document) {
+		spec, ok := lookupPath(document, template.spec).(map[string]interface{})
+		if !ok {
+			continue
+		}
+		class := QoSClass(spec)
+		if qosRank(class) >= qosRank(required) {
+			continue
+		}
+		for _, list := range []string{"initContainers", "containers"} {
+			items, _ := spec[list].([]interface{})
+			for i, item := range items {
+				container, _ := item.(map[string]interface{})
+				var problems []string
+				switch required {
+				case QoSGuaranteed:
+					problems = guaranteedProblems(container)
+				case QoSBurstable:
+					problems = []string{"no cpu or memory requests or limits are set"}
+				}
+				if len(problems) == 0 {
+					continue
+				}
+				path := template.spec.Field(list).Index(i)
+				v.addError(ruleQoSClass, path.Field("resources"), fmt.Sprintf("%s: %s keeps the pod at QoS class %s, %s is required: %s",
+					filename, path, class, required, strings.Join(problems, ", ")))
+			}
+		}
+	}
+}
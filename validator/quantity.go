@@ -0,0 +1,63 @@
+package validator
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+var quantityFormat = regexp.MustCompile(`^([+-]?[0-9.]+)([eE][+-]?[0-9]+|[numkMGTPE]|[KMGTPE]i)?$`)
+
+// Множители суффиксов количества Kubernetes
+var quantitySuffixes = map[string]*big.Rat{
+	"n":  big.NewRat(1, 1000000000),
+	"u":  big.NewRat(1, 1000000),
+	"m":  big.NewRat(1, 1000),
+	"":   big.NewRat(1, 1),
+	"k":  big.NewRat(1000, 1),
+	"M":  big.NewRat(1000000, 1),
+	"G":  new(big.Rat).SetInt64(1e9),
+	"T":  new(big.Rat).SetInt64(1e12),
+	"P":  new(big.Rat).SetInt64(1e15),
+	"E":  new(big.Rat).SetInt64(1e18),
+	"Ki": new(big.Rat).SetInt64(1 << 10),
+	"Mi": new(big.Rat).SetInt64(1 << 20),
+	"Gi": new(big.Rat).SetInt64(1 << 30),
+	"Ti": new(big.Rat).SetInt64(1 << 40),
+	"Pi": new(big.Rat).SetInt64(1 << 50),
+	"Ei": new(big.Rat).SetInt64(1 << 60),
+}
+
+// ParseQuantity разбирает количество ресурса Kubernetes (500m, 2Gi, 1.5, 1e3) в точное число;
+// принимает строки и числа YAML
+func ParseQuantity(value interface{}) (*big.Rat, error) {
+	var str string
+	switch val := value.(type) {
+	case string:
+		str = strings.TrimSpace(val)
+	case int, int64, uint64, float64:
+		str = fmt.Sprint(val)
+	default:
+		return nil, fmt.Errorf("quantity must be a string or a number")
+	}
+
+	match := quantityFormat.FindStringSubmatch(str)
+	if match == nil {
+		return nil, fmt.Errorf("invalid quantity %q", str)
+	}
+	number, ok := new(big.Rat).SetString(match[1])
+	if !ok {
+		return nil, fmt.Errorf("invalid quantity %q", str)
+	}
+	suffix := match[2]
+	if multiplier, known := quantitySuffixes[suffix]; known {
+		return number.Mul(number, multiplier), nil
+	}
+	// Десятичная экспонента e3 / E-2
+	exponent, ok := new(big.Rat).SetString("1" + suffix)
+	if !ok {
+		return nil, fmt.Errorf("invalid quantity %q", str)
+	}
+	return number.Mul(number, exponent), nil
+}
@@ -0,0 +1,189 @@
+package validator
+
+import "fmt"
+
+const rbacGroup = "rbac.authorization.k8s.io"
+
+var policyRuleSchema = &Schema{
+	Type:     "object",
+	Required: []string{"verbs"},
+	Properties: map[string]*Schema{
+		"verbs":           {Type: "array", Description: "Allowed actions, e.g. get, list or watch; * allows every action.", Items: &Schema{Type: "string"}, Rules: []string{ruleRequiredField}},
+		"apiGroups":       {Type: "array", Description: "API groups of the resources; \"\" is the core group.", Items: &Schema{Type: "string"}},
+		"resources":       {Type: "array", Description: "Resources the rule applies to, e.g. pods or deployments/scale.", Items: &Schema{Type: "string"}},
+		"resourceNames":   {Type: "array", Description: "Names of the objects the rule is limited to.", Items: &Schema{Type: "string"}},
+		"nonResourceURLs": {Type: "array", Description: "URL paths such as /healthz; only in a ClusterRole.", Items: &Schema{Type: "string"}},
+	},
+}
+
+var roleSchema = &Schema{
+	Type: "object",
+	Properties: map[string]*Schema{
+		"rules": {Type: "array", Description: "Permissions granted by the role.", Items: policyRuleSchema},
+	},
+}
+
+var clusterRoleSchema = &Schema{
+	Type: "object",
+	Properties: map[string]*Schema{
+		"rules": {Type: "array", Description: "Permissions granted by the role.", Items: policyRuleSchema},
+		"aggregationRule": {
+			Type:        "object",
+			Description: "Rules of the role are collected from the cluster roles matching the selectors.",
+			Properties: map[string]*Schema{
+				"clusterRoleSelectors": {Type: "array", Description: "Label selectors of the aggregated cluster roles.", Items: &Schema{Type: "object", Rules: []string{ruleLabelSelector}}},
+			},
+		},
+	},
+}
+
+var subjectSchema = &Schema{
+	Type:     "object",
+	Required: []string{"kind", "name"},
+	Properties: map[string]*Schema{
+		"kind":      {Type: "string", Description: "Kind of the subject.", Enum: []string{"User", "Group", "ServiceAccount"}, Rules: []string{ruleRequiredField, ruleFieldValue}},
+		"name":      {Type: "string", Description: "Name of the subject.", Rules: []string{ruleRequiredField}},
+		"namespace": {Type: "string", Description: "Namespace of a ServiceAccount subject."},
+		"apiGroup":  {Type: "string", Description: "\"\" for ServiceAccount, rbac.authorization.k8s.io for User and Group."},
+	},
+}
+
+var roleRefSchema = &Schema{
+	Type:        "object",
+	Description: "Role the subjects are bound to; cannot be changed after creation.",
+	Required:    []string{"apiGroup", "kind", "name"},
+	Properties: map[string]*Schema{
+		"apiGroup": {Type: "string", Description: "API group of the role.", Enum: []string{rbacGroup}, Rules: []string{ruleRequiredField, ruleFieldValue}},
+		"kind":     {Type: "string", Description: "Kind of the role.", Enum: []string{"Role", "ClusterRole"}, Rules: []string{ruleRequiredField, ruleFieldValue}},
+		"name":     {Type: "string", Description: "Name of the role.", Rules: []string{ruleRequiredField}},
+	},
+}
+
+var roleBindingSchema = &Schema{
+	Type:     "object",
+	Required: []string{"roleRef"},
+	Properties: map[string]*Schema{
+		"subjects": {Type: "array", Description: "Users, groups and service accounts the role is granted to.", Items: subjectSchema},
+		"roleRef":  roleRefSchema,
+	},
+}
+
+func (v *Validator) validateRole(document map[string]interface{}, filename string) {
+	v.validateRoleRules(document, roleSchema, false, filename)
+}
+
+func (v *Validator) validateClusterRole(document map[string]interface{}, filename string) {
+	v.validateClusterScoped(document, filename)
+	if aggregation, exists := document["aggregationRule"]; exists {
+		v.validateSchema(aggregation, clusterRoleSchema.Properties["aggregationRule"], "aggregationRule", filename)
+	}
+	v.validateRoleRules(document, clusterRoleSchema, true, filename)
+}
+
+// validateRoleRules проверяет правила роли: ресурсы или адреса, группы API ресурсов
+// и права с подстановкой *
+func (v *Validator) validateRoleRules(document map[string]interface{}, schema *Schema, clusterRole bool, filename string) {
+	value, exists := document["rules"]
+	if !exists {
+		return
+	}
+	v.validateSchema(value, schema.Properties["rules"], "rules", filename)
+
+	rules, _ := value.([]interface{})
+	for i, item := range rules {
+		rule, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		path := FieldPath("rules").Index(i)
+		_, hasResources := rule["resources"]
+		_, hasURLs := rule["nonResourceURLs"]
+		switch {
+		case hasURLs && !clusterRole:
+			v.addError(ruleFieldValue, path.Field("nonResourceURLs"), fmt.Sprintf("%s: %s: nonResourceURLs are only allowed in a ClusterRole", filename, path.Field("nonResourceURLs")))
+		case hasURLs && hasResources:
+			v.addError(ruleFieldValue, path, fmt.Sprintf("%s: %s must set either resources or nonResourceURLs, not both", filename, path))
+		case !hasURLs && !hasResources:
+			v.addError(ruleRequiredField, path.Field("resources"), fmt.Sprintf("%s: %s is required", filename, path.Field("resources")))
+		case hasResources:
+			if _, exists := rule["apiGroups"]; !exists {
+				v.addError(ruleRequiredField, path.Field("apiGroups"), fmt.Sprintf("%s: %s is required with resources; use \"\" for the core group", filename, path.Field("apiGroups")))
+			}
+		}
+		if verbs, ok := rule["verbs"].([]interface{}); ok && len(verbs) == 0 {
+			v.addError(ruleRequiredField, path.Field("verbs"), fmt.Sprintf("%s: %s must list at least one verb", filename, path.Field("verbs")))
+		}
+
+		for _, key := range []string{"apiGroups", "resources", "verbs"} {
+			list, _ := rule[key].([]interface{})
+			for j, entry := range list {
+				if entry == "*" {
+					v.addError(ruleRBACWildcard, path.Field(key).Index(j), fmt.Sprintf("%s: %s grants every %s with '*'; list the %s the role needs", filename, path.Field(key), wildcardSubject(key), key))
+				}
+			}
+		}
+	}
+}
+
+// wildcardSubject называет то, что разрешает * в поле правила роли
+func wildcardSubject(key string) string {
+	switch key {
+	case "apiGroups":
+		return "API group"
+	case "resources":
+		return "resource"
+	}
+	return "verb"
+}
+
+func (v *Validator) validateRoleBinding(document map[string]interface{}, filename string) {
+	v.validateBinding(document, false, filename)
+}
+
+func (v *Validator) validateClusterRoleBinding(document map[string]interface{}, filename string) {
+	v.validateClusterScoped(document, filename)
+	v.validateBinding(document, true, filename)
+}
+
+// validateBinding проверяет roleRef и субъекты привязки роли
+func (v *Validator) validateBinding(document map[string]interface{}, clusterBinding bool, filename string) {
+	for _, key := range []string{"subjects", "roleRef"} {
+		if value, exists := document[key]; exists {
+			v.validateSchema(value, roleBindingSchema.Properties[key], FieldPath(key), filename)
+		} else if key == "roleRef" {
+			v.addError(ruleRequiredField, "roleRef", fmt.Sprintf("%s: roleRef is required", filename))
+		}
+	}
+	// Привязка уровня кластера не может ссылаться на Role: у неё нет пространства имён
+	if kind, _ := lookupPath(document, "roleRef.kind").(string); clusterBinding && kind == "Role" {
+		v.addError(ruleFieldValue, "roleRef.kind", fmt.Sprintf("%s: roleRef.kind must be ClusterRole in a ClusterRoleBinding", filename))
+		v.suggest(Remediation{Action: ActionSet, Value: "ClusterRole"})
+	}
+
+	subjects, _ := document["subjects"].([]interface{})
+	for i, item := range subjects {
+		subject, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		path := FieldPath("subjects").Index(i)
+		kind, _ := subject["kind"].(string)
+		apiGroup, hasAPIGroup := subject["apiGroup"].(string)
+		switch kind {
+		case "ServiceAccount":
+			if hasAPIGroup && apiGroup != "" {
+				v.addError(ruleFieldValue, path.Field("apiGroup"), fmt.Sprintf("%s: %s must be empty for a ServiceAccount subject", filename, path.Field("apiGroup")))
+				v.suggest(Remediation{Action: ActionRemove})
+			}
+			// В RoleBinding пространство имён по умолчанию — пространство имён привязки
+			if _, exists := subject["namespace"]; !exists && clusterBinding {
+				v.addError(ruleRequiredField, path.Field("namespace"), fmt.Sprintf("%s: %s is required for a ServiceAccount subject of a ClusterRoleBinding", filename, path.Field("namespace")))
+			}
+		case "User", "Group":
+			if hasAPIGroup && apiGroup != rbacGroup {
+				v.addError(ruleFieldValue, path.Field("apiGroup"), fmt.Sprintf("%s: %s must be %s for a %s subject", filename, path.Field("apiGroup"), rbacGroup, kind))
+				v.suggest(Remediation{Action: ActionSet, Value: rbacGroup})
+			}
+		}
+	}
+}
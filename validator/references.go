@@ -0,0 +1,194 @@
+package validator
+
+import "fmt"
+
+// Reference — место в другом документе набора, связанное с находкой
+type Reference struct {
+	File         string
+	Path         FieldPath
+	Line, Column int
+	Message      string
+}
+
+// ResourceReference — ссылка шаблона пода на другой ресурс по имени
+type ResourceReference struct {
+	Kind string
+	Name string
+	Path FieldPath
+	// Optional — ссылка с optional: true, отсутствие ресурса допустимо
+	Optional bool
+}
+
+// podTemplate — спецификация пода в документе и метки, которые получат его поды
+type podTemplate struct {
+	spec       FieldPath
+	labelsPath FieldPath
+}
+
+// podTemplates находит спецификации подов: у пода — spec, у рабочих нагрузок — шаблон
+func podTemplates(document map[string]interface{}) []podTemplate {
+	if kind, _ := document["kind"].(string); kind == "Pod" {
+		return []podTemplate{{spec: "spec", labelsPath: "metadata.labels"}}
+	}
+	var templates []podTemplate
+	for _, path := range []FieldPath{"spec.template", "spec.jobTemplate.spec.template"} {
+		if _, ok := lookupPath(document, path.Field("spec")).(map[string]interface{}); ok {
+			templates = append(templates, podTemplate{spec: path.Field("spec"), labelsPath: path.Field("metadata").Field("labels")})
+		}
+	}
+	return templates
+}
+
+// References возвращает ссылки шаблонов пода документа на ConfigMap и Secret (envFrom,
+// env.valueFrom, тома, projected-источники и imagePullSecrets), на PersistentVolumeClaim томов
+// и на ServiceAccount пода
+func References(document map[string]interface{}) []ResourceReference {
+	var refs []ResourceReference
+	add := func(kind string, path FieldPath, nameField string) {
+		ref, ok := lookupPath(document, path).(map[string]interface{})
+		if !ok {
+			return
+		}
+		name, _ := ref[nameField].(string)
+		if name == "" {
+			return
+		}
+		optional, _ := ref["optional"].(bool)
+		refs = append(refs, ResourceReference{Kind: kind, Name: name, Path: path.Field(nameField), Optional: optional})
+	}
+
+	for _, template := range podTemplates(document) {
+		add("ServiceAccount", template.spec, "serviceAccountName")
+		for _, list := range []string{"initContainers", "containers"} {
+			containers, _ := lookupPath(document, template.spec.Field(list)).([]interface{})
+			for i := range containers {
+				container := template.spec.Field(list).Index(i)
+				envFrom, _ := lookupPath(document, container.Field("envFrom")).([]interface{})
+				for j := range envFrom {
+					add("ConfigMap", container.Field("envFrom").Index(j).Field("configMapRef"), "name")
+					add("Secret", container.Field("envFrom").Index(j).Field("secretRef"), "name")
+				}
+				env, _ := lookupPath(document, container.Field("env")).([]interface{})
+				for j := range env {
+					valueFrom := container.Field("env").Index(j).Field("valueFrom")
+					add("ConfigMap", valueFrom.Field("configMapKeyRef"), "name")
+					add("Secret", valueFrom.Field("secretKeyRef"), "name")
+				}
+			}
+		}
+		pullSecrets, _ := lookupPath(document, template.spec.Field("imagePullSecrets")).([]interface{})
+		for i := range pullSecrets {
+			add("Secret", template.spec.Field("imagePullSecrets").Index(i), "name")
+		}
+		volumes, _ := lookupPath(document, template.spec.Field("volumes")).([]interface{})
+		for i := range volumes {
+			volume := template.spec.Field("volumes").Index(i)
+			add("ConfigMap", volume.Field("configMap"), "name")
+			add("Secret", volume.Field("secret"), "secretName")
+			add("PersistentVolumeClaim", volume.Field("persistentVolumeClaim"), "claimName")
+			sources, _ := lookupPath(document, volume.Field("projected").Field("sources")).([]interface{})
+			for j := range sources {
+				source := volume.Field("projected").Field("sources").Index(j)
+				add("ConfigMap", source.Field("configMap"), "name")
+				add("Secret", source.Field("secret"), "name")
+			}
+		}
+	}
+	return refs
+}
+
+func lookupPath(document map[string]interface{}, path FieldPath) interface{} {
+	value, _ := lookup(document, string(path))
+	return value
+}
+
+func (d setDocument) kind() string {
+	kind, _ := d.document["kind"].(string)
+	return kind
+}
+
+func (d setDocument) name() string {
+	name, _ := lookupPath(d.document, "metadata.name").(string)
+	return name
+}
+
+// namespace возвращает пространство имён документа; без metadata.namespace — default
+func (d setDocument) namespace() string {
+	if namespace, _ := lookupPath(d.document, "metadata.namespace").(string); namespace != "" {
+		return namespace
+	}
+	return "default"
+}
+
+// reference строит ссылку на поле документа набора
+func (d setDocument) reference(path FieldPath, message string) Reference {
+	ref := Reference{File: d.filename, Path: path, Message: message}
+	if node := path.ResolveNearest(d.root); node != nil {
+		ref.Line, ref.Column = node.Line, node.Column
+	}
+	return ref
+}
+
+// validateReferences проверяет связи между документами: селекторы сервисов и ссылки на ConfigMap.
+// Находка ссылается на документы в других пространствах имён, которые подошли бы по имени или меткам.
+func (s *DocumentSet) validateReferences(findings map[string][]Finding) {
+	configMaps := map[string][]setDocument{}
+	for _, doc := range s.documents {
+		if doc.kind() == "ConfigMap" {
+			configMaps[doc.name()] = append(configMaps[doc.name()], doc)
+		}
+	}
+
+	for _, doc := range s.documents {
+		var v Validator
+		namespace := doc.namespace()
+
+		if selector, _ := lookupPath(doc.document, "spec.selector").(map[string]interface{}); doc.kind() == "Service" && len(selector) > 0 {
+			matched := false
+			var elsewhere []Reference
+			for _, other := range s.documents {
+				for _, template := range podTemplates(other.document) {
+					labels, _ := lookupPath(other.document, template.labelsPath).(map[string]interface{})
+					if !mapSelector(selector).Matches(labels) {
+						continue
+					}
+					if other.namespace() == namespace {
+						matched = true
+					} else {
+						elsewhere = append(elsewhere, other.reference(template.labelsPath,
+							fmt.Sprintf("%s %s in namespace %s has matching labels", other.kind(), other.name(), other.namespace())))
+					}
+				}
+			}
+			if !matched {
+				v.addError(ruleServiceSelector, "spec.selector", fmt.Sprintf("%s: spec.selector %s matches no pods in namespace %s", doc.filename, mapSelector(selector), namespace))
+				v.errors[len(v.errors)-1].References = elsewhere
+			}
+		}
+
+		for _, ref := range References(doc.document) {
+			if ref.Kind != "ConfigMap" || ref.Optional {
+				continue
+			}
+			var elsewhere []Reference
+			found := false
+			for _, configMap := range configMaps[ref.Name] {
+				if configMap.namespace() == namespace {
+					found = true
+					break
+				}
+				elsewhere = append(elsewhere, configMap.reference("metadata.name",
+					fmt.Sprintf("ConfigMap %s exists in namespace %s", ref.Name, configMap.namespace())))
+			}
+			if !found {
+				v.addError(ruleConfigMapReference, ref.Path, fmt.Sprintf("%s: %s references ConfigMap '%s' that is not defined in namespace %s", doc.filename, ref.Path, ref.Name, namespace))
+				v.errors[len(v.errors)-1].References = elsewhere
+			}
+		}
+
+		if len(v.errors) > 0 {
+			v.resolvePositions(doc.root)
+			findings[doc.filename] = append(findings[doc.filename], v.errors...)
+		}
+	}
+}
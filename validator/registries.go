@@ -0,0 +1,31 @@
+package validator
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Реестр образов по умолчанию
+const defaultRegistry = "registry.bigbrother.io"
+
+// fromRegistry сообщает, берётся ли образ из одного из реестров
+func fromRegistry(image string, allowed []string) bool {
+	for _, registry := range allowed {
+		if strings.HasPrefix(image, registry+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// registryPattern — регулярное выражение для подсказки: образ начинается с одного из реестров
+func registryPattern(allowed []string) string {
+	quoted := make([]string, len(allowed))
+	for i, registry := range allowed {
+		quoted[i] = regexp.QuoteMeta(registry + "/")
+	}
+	if len(quoted) == 1 {
+		return "^" + quoted[0]
+	}
+	return "^(?:" + strings.Join(quoted, "|") + ")"
+}
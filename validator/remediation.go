@@ -0,0 +1,77 @@
+package validator
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Действия подсказки по исправлению
+const (
+	ActionSet    = "set"
+	ActionRemove = "remove"
+)
+
+// Remediation — машиночитаемая подсказка, как исправить находку в поле Finding.Path,
+// чтобы боты GitOps могли открыть PR с исправлением
+type Remediation struct {
+	Action string
+	// Value — значение, которое можно подставить как есть; nil, если однозначного значения нет
+	Value interface{}
+	// Pattern — регулярное выражение, которому должно соответствовать новое значение
+	Pattern string
+	// Allowed — допустимые значения поля
+	Allowed []string
+}
+
+const snakeCase = `^[a-z]+(_[a-z]+)*$`
+
+var (
+	snakeCaseRegex = regexp.MustCompile(snakeCase)
+	wordBoundary   = regexp.MustCompile(`([a-z])([A-Z])`)
+	nonLetters     = regexp.MustCompile(`[^a-z]+`)
+	// 512M, 1G, 1gb, 256mi — суффикс без i или в другом регистре
+	decimalMemory = regexp.MustCompile(`^([0-9]+)\s*([KkMmGg])[iI]?[bB]?$`)
+)
+
+// suggest прикрепляет подсказку к последней добавленной находке
+func (v *Validator) suggest(remediation Remediation) {
+	v.errors[len(v.errors)-1].Remediation = &remediation
+}
+
+// imageInRegistry переносит образ в первый из разрешённых реестров, отбрасывая исходный хост
+func imageInRegistry(image string, allowed []string) string {
+	if host, rest, found := strings.Cut(image, "/"); found && (strings.ContainsAny(host, ".:") || host == "localhost") {
+		image = rest
+	}
+	return allowed[0] + "/" + image
+}
+
+// toSnakeCase приводит имя к snake_case; пустая строка, если привести не удалось
+func toSnakeCase(name string) string {
+	name = strings.ToLower(wordBoundary.ReplaceAllString(name, "${1}_${2}"))
+	name = strings.Trim(nonLetters.ReplaceAllString(name, "_"), "_")
+	if !snakeCaseRegex.MatchString(name) {
+		return ""
+	}
+	return name
+}
+
+// integerValue возвращает целое из строки вида "2", если оно записано без дробной части
+func integerValue(value interface{}) interface{} {
+	if str, ok := value.(string); ok {
+		if n, err := strconv.Atoi(strings.TrimSpace(str)); err == nil {
+			return n
+		}
+	}
+	return nil
+}
+
+// memorySuffix приводит суффикс памяти к Ki, Mi или Gi: 1G → 1Gi; пустая строка, если суффикс не распознан
+func memorySuffix(memory string) string {
+	match := decimalMemory.FindStringSubmatch(memory)
+	if match == nil {
+		return ""
+	}
+	return match[1] + strings.ToUpper(match[2]) + "i"
+}
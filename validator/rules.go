@@ -0,0 +1,856 @@
+package validator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Версии набора правил в порядке выпуска; последняя — текущая. Выпущенная версия не меняется:
+// правила, типы ресурсов и изменения разбора, появившиеся после её выпуска, входят только
+// в следующую версию (Rule.Since, kindHandler.since, rulesetAtLeast). Исключение — ограничения
+// сложности документа: они защищают саму проверку и действуют во всех версиях.
+var rulesetVersions = []string{"2024.1", "2026.1", "2026.2"}
+
+// CurrentRulesetVersion возвращает версию набора правил, используемую по умолчанию
+func CurrentRulesetVersion() string {
+	return rulesetVersions[len(rulesetVersions)-1]
+}
+
+// RuleYAMLSyntax — правило, которым помечаются файлы, не разбирающиеся как YAML
+const RuleYAMLSyntax = ruleYAMLSyntax
+
+// RuleAmbiguousScalar — правило, которым помечаются незакавыченные yes, no, on и off
+const RuleAmbiguousScalar = ruleAmbiguousScalar
+
+// RuleNotKubernetes — правило, которым помечаются пропущенные файлы, не являющиеся манифестами Kubernetes
+const RuleNotKubernetes = ruleNotKubernetes
+
+// RuleTimeout — правило, которым помечаются файлы, не проверенные за отведённое время
+const RuleTimeout = ruleTimeout
+
+// Идентификаторы правил: YV0xx — разбор, YV1xx — проверки пода и контейнеров, YV2xx — структура документа,
+// YV3xx — Docker Compose, YV4xx — связи между документами, YV5xx — сравнение ревизий,
+// YV6xx — Pod Security Standards, YV7xx — CIS Kubernetes Benchmark
+const (
+	ruleYAMLSyntax      = "YV001"
+	ruleNotKubernetes   = "YV002"
+	ruleTimeout         = "YV003"
+	ruleAmbiguousScalar = "YV004"
+	ruleDuplicateKey    = "YV005"
+	// Комментарий yamlvalid:disable, который ничего не подавил
+	ruleUnusedSuppression = "YV006"
+	ruleImageRegistry     = "YV101"
+	ruleImageTag          = "YV102"
+	ruleContainerName     = "YV103"
+	rulePortRange         = "YV104"
+	rulePortProtocol      = "YV105"
+	ruleResourceCPU       = "YV106"
+	ruleResourceMemory    = "YV107"
+	ruleResourceName      = "YV108"
+	ruleProbePath         = "YV109"
+	ruleOSName            = "YV110"
+	ruleHostPath          = "YV111"
+	// Проверяется и в наборе документов, если под ссылается на собственный ServiceAccount
+	ruleServiceAccountToken = "YV112"
+	ruleResourceDefaults    = "YV113"
+	ruleQoSClass            = "YV114"
+	ruleProbeSanity         = "YV115"
+	ruleImageDigest         = "YV116"
+	ruleRBACWildcard        = "YV117"
+	ruleRequiredField       = "YV201"
+	ruleFieldType           = "YV202"
+	ruleAPIVersion          = "YV203"
+	ruleKind                = "YV204"
+	ruleMinContainers       = "YV205"
+	ruleFieldValue          = "YV206"
+	ruleUnknownField        = "YV207"
+	ruleLabelSelector       = "YV208"
+	ruleComposeSchema       = "YV301"
+	// Выполняются, только если набор документов полный (DocumentSet.CheckReferences)
+	ruleServiceSelector    = "YV401"
+	ruleConfigMapReference = "YV402"
+	ruleUnusedResource     = "YV403"
+	ruleUnusedService      = "YV404"
+	// Выполняются командами compare и drift над двумя ревизиями
+	ruleImmutableField = "YV501"
+	ruleClusterDrift   = "YV502"
+	// Pod Security Standards: YV601–YV611 — уровень baseline, YV612–YV617 — restricted
+	rulePSSHostNamespaces         = "YV601"
+	rulePSSPrivileged             = "YV602"
+	rulePSSCapabilities           = "YV603"
+	rulePSSHostPath               = "YV604"
+	rulePSSHostPorts              = "YV605"
+	rulePSSAppArmor               = "YV606"
+	rulePSSSELinux                = "YV607"
+	rulePSSProcMount              = "YV608"
+	rulePSSSeccomp                = "YV609"
+	rulePSSSysctls                = "YV610"
+	rulePSSHostProcess            = "YV611"
+	rulePSSVolumeTypes            = "YV612"
+	rulePSSPrivilegeEscalation    = "YV613"
+	rulePSSRunAsNonRoot           = "YV614"
+	rulePSSRunAsUser              = "YV615"
+	rulePSSSeccompRequired        = "YV616"
+	rulePSSRestrictedCapabilities = "YV617"
+	// CIS Kubernetes Benchmark
+	ruleCISServiceAccountToken = "YV701"
+	ruleCISDefaultNamespace    = "YV702"
+	ruleCISRuntimeSocket       = "YV703"
+)
+
+// Severity — уровень серьёзности нарушения
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Phase — этап проверки, на котором выполняется правило
+type Phase int
+
+const (
+	PhaseParse Phase = iota
+	PhaseStructural
+	PhaseSemantic
+	// Проверки, которым нужен весь набор документов
+	PhaseCrossFile
+)
+
+// RuleState — стадия жизненного цикла правила
+type RuleState string
+
+const (
+	StateExperimental RuleState = "experimental"
+	StateStable       RuleState = "stable"
+	StateDeprecated   RuleState = "deprecated"
+	StateRemoved      RuleState = "removed"
+)
+
+// Rule описывает правило каталога
+type Rule struct {
+	ID          string
+	Name        string
+	Description string
+	// Версия набора правил, в которой правило появилось
+	Since string
+	State RuleState
+	// ReplacedBy — правило, которое заменяет устаревшее или удалённое; его называют
+	// предупреждения конфигурации и explain
+	ReplacedBy string
+	// Уровень по умолчанию; пустое значение означает error
+	Severity Severity
+	// Уровни для отдельных окружений (RuleSelection.Environment), перекрывают Severity
+	Environments map[string]Severity
+	Phase        Phase
+	// Правила-предпосылки: если одно из них сработало на том же поле или его предке,
+	// находки этого правила отбрасываются как следствие той же причины
+	DependsOn []string
+	// Group — группа правил, которые выполняются только по запросу (RuleSelection.Groups),
+	// например strict; пусто — правило выполняется всегда
+	Group string
+	// Mandatory — правило нельзя выключить ни выбором, ни комментарием yamlvalid:disable:
+	// без его находки неразобранный или непроверенный файл выглядел бы корректным
+	Mandatory bool
+}
+
+// Группы правил, включаемых по запросу
+const (
+	GroupStrict = "strict"
+	// Ресурсы, которые ничем не используются; нужен полный набор документов
+	GroupDeadResource = "dead-resource"
+	// Уровни Pod Security Standards; --pss-level=restricted включает обе группы
+	GroupPSSBaseline   = "pss-baseline"
+	GroupPSSRestricted = "pss-restricted"
+	// Рекомендации CIS Kubernetes Benchmark для рабочих нагрузок
+	GroupCIS = "cis"
+	// Рекомендации, которые не делают манифест неверным, но обычно стоят исправления
+	GroupBestPractice = "best-practice"
+	// Происхождение и неизменность образов
+	GroupSupplyChain = "supply-chain"
+)
+
+// RuleGroups возвращает группы правил, включаемых по запросу
+func RuleGroups() []string {
+	return []string{GroupStrict, GroupDeadResource, GroupPSSBaseline, GroupPSSRestricted, GroupCIS, GroupBestPractice, GroupSupplyChain}
+}
+
+var rules = []Rule{
+	{
+		ID:          ruleYAMLSyntax,
+		Name:        "yaml-syntax",
+		Description: "The file must be well-formed YAML, and its aliases must stay within the nesting and expansion limits.",
+		Since:       "2024.1",
+		State:       StateStable,
+		Phase:       PhaseParse,
+		Mandatory:   true,
+	},
+	{
+		ID:          ruleNotKubernetes,
+		Name:        "not-kubernetes",
+		Description: "Files that are clearly not Kubernetes manifests (Compose files, CI workflows, application config) are skipped.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Severity:    SeverityInfo,
+		Phase:       PhaseParse,
+	},
+	{
+		ID:          ruleTimeout,
+		Name:        "timeout",
+		Description: "The file could not be validated within the time limit set by --timeout-per-file or --timeout.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Phase:       PhaseParse,
+		Mandatory:   true,
+	},
+	{
+		ID:          ruleAmbiguousScalar,
+		Name:        "ambiguous-scalar",
+		Description: "Unquoted yes, no, on, off, y and n are booleans for YAML 1.1 parsers; such strings must be quoted.",
+		Since:       "2026.2",
+		State:       StateExperimental,
+		Severity:    SeverityWarning,
+		Phase:       PhaseParse,
+	},
+	{
+		ID:          ruleDuplicateKey,
+		Name:        "duplicate-key",
+		Description: "A mapping must not repeat a key; Kubernetes silently keeps the last value.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Phase:       PhaseParse,
+	},
+	{
+		ID:          ruleUnusedSuppression,
+		Name:        "unused-suppression",
+		Description: "A # yamlvalid:disable=<rule> comment must name a known rule that reports a finding on the line or block it covers.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Severity:    SeverityWarning,
+		Phase:       PhaseParse,
+	},
+	{
+		ID:          ruleImageRegistry,
+		Name:        "image-registry",
+		Description: "Container images must be pulled from registry.bigbrother.io.",
+		Since:       "2024.1",
+		State:       StateStable,
+		Phase:       PhaseSemantic,
+		DependsOn:   []string{ruleFieldType},
+	},
+	{
+		ID:          ruleImageTag,
+		Name:        "image-tag",
+		Description: "Container images must reference an explicit version tag or a digest. The imageTags section of the config can require SemVer tags or a custom pattern and forbid pre-release tags in some environments.",
+		Since:       "2024.1",
+		State:       StateStable,
+		Phase:       PhaseSemantic,
+		DependsOn:   []string{ruleFieldType},
+	},
+	{
+		ID:          ruleContainerName,
+		Name:        "container-name",
+		Description: "Container names must be in snake_case.",
+		Since:       "2024.1",
+		State:       StateStable,
+		Phase:       PhaseSemantic,
+		DependsOn:   []string{ruleFieldType},
+	},
+	{
+		ID:          rulePortRange,
+		Name:        "port-range",
+		Description: "Port numbers must be within 1-65535.",
+		Since:       "2024.1",
+		State:       StateStable,
+		Phase:       PhaseSemantic,
+		DependsOn:   []string{ruleFieldType},
+	},
+	{
+		ID:          rulePortProtocol,
+		Name:        "port-protocol",
+		Description: "Port protocol must be TCP or UDP; Service ports also accept SCTP.",
+		Since:       "2024.1",
+		State:       StateStable,
+		Phase:       PhaseSemantic,
+		DependsOn:   []string{ruleFieldType},
+	},
+	{
+		ID:          ruleResourceCPU,
+		Name:        "resource-cpu",
+		Description: "CPU requests and limits must be integers.",
+		Since:       "2024.1",
+		State:       StateStable,
+		Phase:       PhaseSemantic,
+		DependsOn:   []string{ruleFieldType},
+	},
+	{
+		ID:          ruleResourceMemory,
+		Name:        "resource-memory",
+		Description: "Memory requests and limits must use the Gi, Mi or Ki suffix.",
+		Since:       "2024.1",
+		State:       StateStable,
+		Phase:       PhaseSemantic,
+		DependsOn:   []string{ruleFieldType},
+	},
+	{
+		ID:          ruleResourceName,
+		Name:        "resource-name",
+		Description: "Only cpu and memory resources are supported.",
+		Since:       "2024.1",
+		State:       StateStable,
+		Phase:       PhaseSemantic,
+		DependsOn:   []string{ruleFieldType},
+	},
+	{
+		ID:          ruleProbePath,
+		Name:        "probe-path",
+		Description: "Probe httpGet.path must be an absolute path.",
+		Since:       "2024.1",
+		State:       StateStable,
+		Phase:       PhaseSemantic,
+		DependsOn:   []string{ruleFieldType},
+	},
+	{
+		ID:          ruleOSName,
+		Name:        "os-name",
+		Description: "spec.os.name must be linux or windows.",
+		Since:       "2024.1",
+		State:       StateStable,
+		Phase:       PhaseSemantic,
+		DependsOn:   []string{ruleFieldType},
+	},
+	{
+		ID:          ruleHostPath,
+		Name:        "host-path",
+		Description: "hostPath volumes must be absent, or use a path from the hostPath.allowed list of the config and be mounted readOnly when hostPath.readOnly is set. Runs only when the config has a hostPath section.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Phase:       PhaseSemantic,
+	},
+	{
+		ID:          ruleServiceAccountToken,
+		Name:        "service-account-token",
+		Description: "Pods should set automountServiceAccountToken: false, or use a ServiceAccount that does, unless they call the Kubernetes API. A warning, or info with --env dev.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Severity:    SeverityWarning,
+		Environments: map[string]Severity{
+			"dev": SeverityInfo,
+		},
+		Phase: PhaseSemantic,
+		Group: GroupBestPractice,
+	},
+	{
+		ID:          ruleResourceDefaults,
+		Name:        "resource-defaults",
+		Description: "Containers that set only requests or only limits get a suggestion for the missing values, computed with the limitRatios of the config (limits = 2x requests by default); --fix-dry-run can apply it.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Severity:    SeverityInfo,
+		Phase:       PhaseSemantic,
+		Group:       GroupBestPractice,
+	},
+	{
+		ID:          ruleQoSClass,
+		Name:        "qos-class",
+		Description: "Pods must get at least the QoS class that the qos section of the config requires for the environment (BestEffort, Burstable or Guaranteed); the finding names the containers that lower the class. Runs only when a class is required.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Phase:       PhaseSemantic,
+	},
+	{
+		ID:          ruleProbeSanity,
+		Name:        "probe-sanity",
+		Description: "Probes should not repeat each other or contradict the pod: liveness and readiness on the same endpoint with identical timings, timeoutSeconds not shorter than periodSeconds, liveness failureThreshold x periodSeconds above terminationGracePeriodSeconds.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Severity:    SeverityWarning,
+		Phase:       PhaseSemantic,
+	},
+	{
+		ID:          ruleImageDigest,
+		Name:        "image-digest",
+		Description: "Container images must be pinned by digest (repo:tag@sha256:...). With --resolve-digests the current digest of the tag is looked up in the registry, so that --fix-dry-run can pin it.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Phase:       PhaseSemantic,
+		Group:       GroupSupplyChain,
+	},
+	{
+		ID:          ruleRBACWildcard,
+		Name:        "rbac-wildcard",
+		Description: "Roles and ClusterRoles should not grant permissions with '*' in apiGroups, resources or verbs: the role also covers resources and verbs added later (CIS 5.1.3).",
+		Since:       "2026.2",
+		State:       StateStable,
+		Severity:    SeverityWarning,
+		Phase:       PhaseSemantic,
+	},
+	{
+		ID:          ruleRequiredField,
+		Name:        "required-field",
+		Description: "Required fields must be present and non-empty.",
+		Since:       "2024.1",
+		State:       StateStable,
+		Phase:       PhaseStructural,
+	},
+	{
+		ID:          ruleFieldType,
+		Name:        "field-type",
+		Description: "Fields must have the expected YAML type.",
+		Since:       "2024.1",
+		State:       StateStable,
+		Phase:       PhaseStructural,
+	},
+	{
+		ID:          ruleAPIVersion,
+		Name:        "api-version",
+		Description: "apiVersion must be supported for the kind.",
+		Since:       "2024.1",
+		State:       StateStable,
+		Phase:       PhaseStructural,
+	},
+	{
+		ID:          ruleKind,
+		Name:        "kind",
+		Description: "kind must be a supported resource kind.",
+		Since:       "2024.1",
+		State:       StateStable,
+		Phase:       PhaseStructural,
+	},
+	{
+		ID:          ruleMinContainers,
+		Name:        "min-containers",
+		Description: "A pod must declare at least one container.",
+		Since:       "2024.1",
+		State:       StateStable,
+		Phase:       PhaseStructural,
+	},
+	{
+		ID:          ruleFieldValue,
+		Name:        "field-value",
+		Description: "Field values must satisfy the enum, pattern and range constraints of the kind schema.",
+		Since:       "2026.1",
+		State:       StateStable,
+		Phase:       PhaseSemantic,
+		DependsOn:   []string{ruleFieldType},
+	},
+	{
+		ID:          ruleUnknownField,
+		Name:        "unknown-field",
+		Description: "Fields must be defined in the kind schema; misspelled keys such as contianers are otherwise silently ignored. Runs with --strict.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Phase:       PhaseStructural,
+		// Лишние ресурсы в requests и limits уже отмечает resource-name
+		DependsOn: []string{ruleResourceName},
+		Group:     GroupStrict,
+	},
+	{
+		ID:          ruleLabelSelector,
+		Name:        "label-selector",
+		Description: "A workload spec.selector must be non-empty, use valid matchExpressions operators and match the labels of its own pod template.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Phase:       PhaseSemantic,
+	},
+	{
+		ID:          ruleComposeSchema,
+		Name:        "compose-schema",
+		Description: "Docker Compose files checked with --compose must follow the basic Compose structure.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Phase:       PhaseStructural,
+	},
+	{
+		ID:          ruleServiceSelector,
+		Name:        "service-selector",
+		Description: "A Service selector must match the labels of at least one pod or pod template in its namespace.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Severity:    SeverityWarning,
+		Phase:       PhaseCrossFile,
+	},
+	{
+		ID:          ruleConfigMapReference,
+		Name:        "configmap-reference",
+		Description: "ConfigMaps referenced by envFrom, env, volumes and projected sources must be defined in the same namespace, unless the reference is optional.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Severity:    SeverityWarning,
+		Phase:       PhaseCrossFile,
+	},
+	{
+		ID:          ruleUnusedResource,
+		Name:        "unused-resource",
+		Description: "ConfigMaps, Opaque Secrets and PersistentVolumeClaims should be referenced by at least one workload in their namespace.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Severity:    SeverityWarning,
+		Phase:       PhaseCrossFile,
+		Group:       GroupDeadResource,
+	},
+	{
+		ID:          ruleUnusedService,
+		Name:        "unused-service",
+		Description: "A Service whose selector matches no workload routes traffic nowhere and can likely be removed.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Severity:    SeverityWarning,
+		Phase:       PhaseCrossFile,
+		Group:       GroupDeadResource,
+	},
+	{
+		ID:          ruleImmutableField,
+		Name:        "immutable-field",
+		Description: "Fields that Kubernetes treats as immutable (workload selectors, Pod spec, PVC storage class and access modes, data of immutable ConfigMaps) must not change between revisions.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Phase:       PhaseCrossFile,
+	},
+	{
+		ID:          ruleClusterDrift,
+		Name:        "cluster-drift",
+		Description: "Fields set in the manifest should have the same values in the live cluster object; reported by yamlvalid drift.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Severity:    SeverityWarning,
+		Phase:       PhaseCrossFile,
+	},
+	{
+		ID:          rulePSSHostNamespaces,
+		Name:        "pss-host-namespaces",
+		Description: "Pods must not share the host network, PID or IPC namespace (hostNetwork, hostPID, hostIPC).",
+		Since:       "2026.2",
+		State:       StateStable,
+		Phase:       PhaseSemantic,
+		Group:       GroupPSSBaseline,
+	},
+	{
+		ID:          rulePSSPrivileged,
+		Name:        "pss-privileged",
+		Description: "Containers must not run in privileged mode.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Phase:       PhaseSemantic,
+		Group:       GroupPSSBaseline,
+	},
+	{
+		ID:          rulePSSCapabilities,
+		Name:        "pss-capabilities",
+		Description: "Containers may add only the capabilities of the default container runtime set, e.g. CHOWN or NET_BIND_SERVICE.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Phase:       PhaseSemantic,
+		Group:       GroupPSSBaseline,
+	},
+	{
+		ID:          rulePSSHostPath,
+		Name:        "pss-host-path",
+		Description: "Pods must not mount hostPath volumes.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Phase:       PhaseSemantic,
+		Group:       GroupPSSBaseline,
+	},
+	{
+		ID:          rulePSSHostPorts,
+		Name:        "pss-host-ports",
+		Description: "Container ports must not set hostPort.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Phase:       PhaseSemantic,
+		Group:       GroupPSSBaseline,
+	},
+	{
+		ID:          rulePSSAppArmor,
+		Name:        "pss-apparmor",
+		Description: "The AppArmor profile must not be Unconfined; annotations must be runtime/default or localhost/<profile>.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Phase:       PhaseSemantic,
+		Group:       GroupPSSBaseline,
+	},
+	{
+		ID:          rulePSSSELinux,
+		Name:        "pss-selinux",
+		Description: "seLinuxOptions must not set user or role, and type must be one of the container_* types.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Phase:       PhaseSemantic,
+		Group:       GroupPSSBaseline,
+	},
+	{
+		ID:          rulePSSProcMount,
+		Name:        "pss-proc-mount",
+		Description: "procMount must be Default.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Phase:       PhaseSemantic,
+		Group:       GroupPSSBaseline,
+	},
+	{
+		ID:          rulePSSSeccomp,
+		Name:        "pss-seccomp",
+		Description: "The seccomp profile must not be Unconfined.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Phase:       PhaseSemantic,
+		Group:       GroupPSSBaseline,
+	},
+	{
+		ID:          rulePSSSysctls,
+		Name:        "pss-sysctls",
+		Description: "Pods may set only the sysctls that are isolated per pod, e.g. net.ipv4.ip_local_port_range.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Phase:       PhaseSemantic,
+		Group:       GroupPSSBaseline,
+	},
+	{
+		ID:          rulePSSHostProcess,
+		Name:        "pss-host-process",
+		Description: "Windows pods must not run HostProcess containers.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Phase:       PhaseSemantic,
+		Group:       GroupPSSBaseline,
+	},
+	{
+		ID:          rulePSSVolumeTypes,
+		Name:        "pss-volume-types",
+		Description: "Pods may use only configMap, csi, downwardAPI, emptyDir, ephemeral, persistentVolumeClaim, projected and secret volumes.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Phase:       PhaseSemantic,
+		Group:       GroupPSSRestricted,
+	},
+	{
+		ID:          rulePSSPrivilegeEscalation,
+		Name:        "pss-privilege-escalation",
+		Description: "Containers must set securityContext.allowPrivilegeEscalation to false.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Phase:       PhaseSemantic,
+		Group:       GroupPSSRestricted,
+	},
+	{
+		ID:          rulePSSRunAsNonRoot,
+		Name:        "pss-run-as-non-root",
+		Description: "runAsNonRoot must be true for the pod or for every container.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Phase:       PhaseSemantic,
+		Group:       GroupPSSRestricted,
+	},
+	{
+		ID:          rulePSSRunAsUser,
+		Name:        "pss-run-as-user",
+		Description: "runAsUser must not be 0.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Phase:       PhaseSemantic,
+		Group:       GroupPSSRestricted,
+	},
+	{
+		ID:          rulePSSSeccompRequired,
+		Name:        "pss-seccomp-required",
+		Description: "The seccomp profile must be RuntimeDefault or Localhost for the pod or for every container.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Phase:       PhaseSemantic,
+		Group:       GroupPSSRestricted,
+	},
+	{
+		ID:          rulePSSRestrictedCapabilities,
+		Name:        "pss-restricted-capabilities",
+		Description: "Containers must drop ALL capabilities and may add back only NET_BIND_SERVICE.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Phase:       PhaseSemantic,
+		Group:       GroupPSSRestricted,
+	},
+	{
+		ID:          ruleCISServiceAccountToken,
+		Name:        "cis-service-account-token",
+		Description: "CIS 5.1.6: pods should set automountServiceAccountToken: false unless they call the Kubernetes API. Deprecated: service-account-token checks the same field and also accepts a ServiceAccount that disables the token.",
+		Since:       "2026.2",
+		State:       StateDeprecated,
+		ReplacedBy:  ruleServiceAccountToken,
+		Severity:    SeverityWarning,
+		Phase:       PhaseSemantic,
+		Group:       GroupCIS,
+	},
+	{
+		ID:          ruleCISDefaultNamespace,
+		Name:        "cis-default-namespace",
+		Description: "CIS 5.7.4: namespaced resources should set metadata.namespace to a namespace other than default.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Severity:    SeverityWarning,
+		Phase:       PhaseSemantic,
+		Group:       GroupCIS,
+	},
+	{
+		ID:          ruleCISRuntimeSocket,
+		Name:        "cis-runtime-socket",
+		Description: "Pods must not mount the Docker, containerd or CRI-O socket through a hostPath volume.",
+		Since:       "2026.2",
+		State:       StateStable,
+		Phase:       PhaseSemantic,
+		Group:       GroupCIS,
+	},
+}
+
+// Rules возвращает каталог всех правил, включая удалённые
+func Rules() []Rule {
+	return append(append(append([]Rule(nil), rules...), customRuleList()...), externalRuleList()...)
+}
+
+// FindRule ищет правило по идентификатору. Встроенные правила просматриваются без копирования
+// списка: поиск выполняется для каждой находки каждого документа.
+func FindRule(id string) (Rule, bool) {
+	for _, rule := range rules {
+		if rule.ID == id {
+			return rule, true
+		}
+	}
+	for _, rule := range append(customRuleList(), externalRuleList()...) {
+		if rule.ID == id {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// FindRuleByKey ищет правило по идентификатору или имени
+func FindRuleByKey(key string) (Rule, bool) {
+	for _, rule := range Rules() {
+		if rule.ID == key || rule.Name == key {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+func severityOf(id string) Severity {
+	if rule, ok := FindRule(id); ok && rule.Severity != "" {
+		return rule.Severity
+	}
+	return SeverityError
+}
+
+// DocURL подставляет {id} и {name} правила в шаблон ссылки на документацию
+func DocURL(template string, rule Rule) string {
+	if template == "" {
+		return ""
+	}
+	return strings.NewReplacer("{id}", rule.ID, "{name}", rule.Name).Replace(template)
+}
+
+// rulesetAtLeast сообщает, что версия набора правил ruleset не старше version; пусто — текущая
+func rulesetAtLeast(ruleset, version string) bool {
+	if ruleset == "" {
+		return true
+	}
+	return compareRulesetVersions(ruleset, version) >= 0
+}
+
+// CheckRulesetVersion проверяет, что версия набора правил была выпущена
+func CheckRulesetVersion(version string) error {
+	for _, known := range rulesetVersions {
+		if known == version {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown ruleset version %q (known: %s)", version, strings.Join(rulesetVersions, ", "))
+}
+
+// compareRulesetVersions сравнивает версии вида 2024.1 покомпонентно
+func compareRulesetVersions(a, b string) int {
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var x, y int
+		if i < len(aParts) {
+			x, _ = strconv.Atoi(aParts[i])
+		}
+		if i < len(bParts) {
+			y, _ = strconv.Atoi(bParts[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// RuleSelection определяет, какие правила из каталога выполняются
+type RuleSelection struct {
+	RulesetVersion     string
+	EnableExperimental bool
+	// Environment — целевое окружение, например dev или prod; выбирает уровни из Rule.Environments
+	Environment string
+	// Groups — включённые группы правил (Rule.Group)
+	Groups []string
+	// Severities — уровни по идентификатору правила из конфигурации; перекрывают уровни окружения
+	Severities map[string]Severity
+	// Enable и Disable — идентификаторы правил, включённых или выключенных явно; Disable важнее,
+	// а явно включённое правило выполняется независимо от стадии, группы и версии набора
+	Enable  []string
+	Disable []string
+	// StrictWarnings — считать предупреждения ошибками, чтобы они тоже влияли на код выхода
+	StrictWarnings bool
+}
+
+// Enabled сообщает, включено ли правило при данном выборе
+func (s RuleSelection) Enabled(rule Rule) bool {
+	switch {
+	case rule.Mandatory:
+		return true
+	case rule.State == StateRemoved || containsString(s.Disable, rule.ID):
+		return false
+	case containsString(s.Enable, rule.ID):
+		return true
+	}
+	switch rule.State {
+	case StateExperimental:
+		if !s.EnableExperimental {
+			return false
+		}
+	}
+	if rule.Group != "" && !containsString(s.Groups, rule.Group) {
+		return false
+	}
+	return compareRulesetVersions(rule.Since, s.RulesetVersion) <= 0
+}
+
+// FilterFindings оставляет находки только включённых правил
+func FilterFindings(findings []Finding, selection RuleSelection) []Finding {
+	var filtered []Finding
+	for _, finding := range findings {
+		rule, ok := FindRule(finding.RuleID)
+		if ok && !selection.Enabled(rule) {
+			continue
+		}
+		if severity := rule.Environments[selection.Environment]; severity != "" {
+			finding.Severity = severity
+		}
+		if severity := selection.Severities[finding.RuleID]; severity != "" {
+			finding.Severity = severity
+		}
+		if selection.StrictWarnings && finding.Severity == SeverityWarning {
+			finding.Severity = SeverityError
+		}
+		// Сгруппированные находки выключенных правил тоже не показываются
+		if len(finding.Related) > 0 {
+			finding.Related = FilterFindings(finding.Related, selection)
+		}
+		filtered = append(filtered, finding)
+	}
+	return filtered
+}
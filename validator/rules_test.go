@@ -0,0 +1,108 @@
+package validator_test
+
+import (
+	"testing"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator/validatortest"
+)
+
+func TestBuiltInRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest *validatortest.Manifest
+		rules    []string
+	}{
+		{"valid pod", validatortest.Pod("web"), nil},
+		{"valid config map", validatortest.NewManifest("v1", "ConfigMap", "settings").Set("data.LOG_LEVEL", "info"), nil},
+		{"missing image", validatortest.Pod("web").Delete("spec.containers[0].image"), []string{"YV201"}},
+		{"cpu as string", validatortest.Pod("web").Set("spec.containers[0].resources.requests.cpu", "one"), []string{"YV106"}},
+		{"unknown kind", validatortest.NewManifest("v1", "Gadget", "g"), []string{"YV204"}},
+		{"missing name", validatortest.Pod("web").Delete("metadata.name"), []string{"YV201"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validatortest.AssertRules(t, validatortest.ValidateFixture(t, tt.manifest, "fixture.yaml"), tt.rules...)
+		})
+	}
+}
+
+func TestOptInGroups(t *testing.T) {
+	privileged := validatortest.Pod("web").Set("spec.containers[0].securityContext.privileged", true)
+
+	// Без группы pss-baseline привилегированный контейнер не считается нарушением
+	validatortest.AssertRules(t, validatortest.ValidateFixture(t, privileged, "pod.yaml"))
+	findings := validatortest.ValidateFixtureWith(t, privileged, "pod.yaml", validator.RuleSelection{RulesetVersion: validator.CurrentRulesetVersion(), Groups: []string{"pss-baseline"}})
+	validatortest.AssertGolden(t, findings, "testdata/golden/pss-baseline-privileged.txt")
+}
+
+func TestListItemFindings(t *testing.T) {
+	list := validatortest.NewManifest("v1", "List", "").
+		Delete("metadata").
+		Set("items[0]", map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"name": "a"}}).
+		Set("items[1]", map[string]interface{}{"apiVersion": "v1", "kind": "Pod", "metadata": map[string]interface{}{"name": "b"}})
+	validatortest.AssertGolden(t, validatortest.ValidateFixture(t, list, "list.yaml"), "testdata/golden/list-items.txt")
+}
+
+func TestMandatoryRules(t *testing.T) {
+	data := []byte("apiVersion: v1\nkind: Pod\nmetadata: [web # yamlvalid:disable=YV001\n")
+	for _, selection := range []validator.RuleSelection{
+		{RulesetVersion: validator.CurrentRulesetVersion(), Disable: []string{"YV001"}},
+		// Правило timeout появилось позже 2024.1, но и под старой версией не выключается
+		{RulesetVersion: "2024.1", Disable: []string{"YV001", "YV003"}},
+	} {
+		findings := validator.FilterFindings(validator.Validate(data, "broken.yaml"), selection)
+		findings = validator.Suppress(findings, validator.ParseSuppressions(data))
+		validatortest.AssertRules(t, findings, "YV001")
+
+		timeout, _ := validator.FindRule("YV003")
+		if !selection.Enabled(timeout) {
+			t.Errorf("selection %+v disables the timeout rule", selection)
+		}
+	}
+}
+
+func TestRuleLifecycle(t *testing.T) {
+	for _, rule := range validator.Rules() {
+		if rule.ReplacedBy == "" {
+			continue
+		}
+		replacement, ok := validator.FindRule(rule.ReplacedBy)
+		if !ok || replacement.State == validator.StateRemoved {
+			t.Errorf("%s is replaced by %q, which is not a running rule", rule.ID, rule.ReplacedBy)
+		}
+		if rule.State != validator.StateDeprecated && rule.State != validator.StateRemoved {
+			t.Errorf("%s has a replacement but is %s", rule.ID, rule.State)
+		}
+	}
+
+	// Устаревшее правило выполняется, пока не удалено
+	current := validator.CurrentRulesetVersion()
+	token := validatortest.Pod("web").Set("metadata.namespace", "shop")
+	findings := validatortest.ValidateFixtureWith(t, token, "pod.yaml", validator.RuleSelection{RulesetVersion: current, Groups: []string{"cis"}})
+	validatortest.AssertRules(t, findings, "YV701")
+	deprecated, _ := validator.FindRule("YV701")
+	if deprecated.State != validator.StateDeprecated || deprecated.ReplacedBy != "YV112" {
+		t.Errorf("YV701 = %s replaced by %q, want deprecated in favor of YV112", deprecated.State, deprecated.ReplacedBy)
+	}
+
+	// Удалённое правило не выполняется, даже если включено явно
+	removed := validator.Rule{ID: "TEST801", Name: "removed-rule", State: validator.StateRemoved, ReplacedBy: "YV112"}
+	check := func(map[string]interface{}, string) []validator.Finding {
+		return []validator.Finding{{Message: "removed rule ran"}}
+	}
+	if err := validator.RegisterExternalRule(validator.ExternalRule{Rule: removed, Check: check}); err != nil {
+		t.Fatal(err)
+	}
+	selection := validator.RuleSelection{RulesetVersion: current, Enable: []string{"TEST801"}}
+	if selection.Enabled(removed) {
+		t.Error("explicitly enabled removed rule is enabled")
+	}
+	findings = validatortest.ValidateFixtureWith(t, validatortest.Pod("web"), "pod.yaml", selection)
+	validatortest.AssertRules(t, findings)
+
+	unknown := validator.Rule{ID: "TEST802", State: validator.StateDeprecated, ReplacedBy: "YV999"}
+	if err := validator.RegisterExternalRule(validator.ExternalRule{Rule: unknown, Check: check}); err == nil {
+		t.Error("rule with an unknown replacement registered")
+	}
+}
@@ -0,0 +1,35 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Значения, которые парсеры YAML 1.1 (в том числе kubectl) читают как булевы,
+// хотя yaml.v3 оставляет их строками
+var yaml11Booleans = []string{"y", "yes", "n", "no", "on", "off"}
+
+// validateScalars ищет незакавыченные значения, которые разные парсеры прочитают по-разному
+func (v *Validator) validateScalars(node *yaml.Node, path FieldPath, filename string) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			v.validateScalars(child, path, filename)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			v.validateScalars(node.Content[i+1], path.Field(node.Content[i].Value), filename)
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			v.validateScalars(child, path.Index(i), filename)
+		}
+	case yaml.ScalarNode:
+		if node.Style == 0 && node.Tag == "!!str" && containsString(yaml11Booleans, strings.ToLower(node.Value)) {
+			v.addError(ruleAmbiguousScalar, path, fmt.Sprintf("%s: %s: '%s' is a boolean in YAML 1.1 and must be quoted", filename, displayPath(path), node.Value))
+			v.suggest(Remediation{Action: ActionSet, Value: node.Value})
+		}
+	}
+}
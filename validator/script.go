@@ -0,0 +1,112 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/internal/starlark"
+)
+
+// ScriptLimits ограничивают выполнение скрипта правила на одном документе; ноль — значение по умолчанию
+type ScriptLimits struct {
+	// MaxSteps — предел шагов интерпретатора: операторов, итераций и вызовов
+	MaxSteps int64 `yaml:"maxSteps"`
+	// Timeout — предел времени проверки одного документа, например 500ms
+	Timeout time.Duration `yaml:"timeout"`
+	// MaxAllocs — предел элементов коллекций и байтов строк, которые скрипт создаёт на одном документе
+	MaxAllocs int64 `yaml:"maxAllocs"`
+}
+
+// Пределы по умолчанию с запасом покрывают обход любого разумного манифеста,
+// но не дают зациклившемуся скрипту занять долгоживущий процесс, например lsp
+var defaultScriptLimits = ScriptLimits{MaxSteps: 1000000, Timeout: time.Second, MaxAllocs: 1 << 20}
+
+func (l ScriptLimits) withDefaults() starlark.Limits {
+	limits := starlark.Limits{MaxSteps: l.MaxSteps, Timeout: l.Timeout, MaxAllocs: l.MaxAllocs}
+	if limits.MaxSteps <= 0 {
+		limits.MaxSteps = defaultScriptLimits.MaxSteps
+	}
+	if limits.Timeout <= 0 {
+		limits.Timeout = defaultScriptLimits.Timeout
+	}
+	if limits.MaxAllocs <= 0 {
+		limits.MaxAllocs = defaultScriptLimits.MaxAllocs
+	}
+	return limits
+}
+
+// scriptReport — нарушение, о котором скрипт сообщил через report()
+type scriptReport struct {
+	path    FieldPath
+	message string
+}
+
+// scriptBuiltins — имена, которые правило получает сверх встроенных функций Starlark
+var scriptBuiltins = map[string]starlark.Value{
+	// report(message, path=None) добавляет нарушение; path — путь к полю, например spec.replicas
+	"report": starlark.NewBuiltin("report", func(thread *starlark.Thread, args starlark.Tuple, kwargs map[string]starlark.Value) (starlark.Value, error) {
+		values, err := starlark.UnpackArgs("report", args, kwargs, "message", "path?")
+		if err != nil {
+			return nil, err
+		}
+		reports, ok := thread.Local.(*[]scriptReport)
+		if !ok {
+			return nil, fmt.Errorf("report: findings can only be reported from check()")
+		}
+		report := scriptReport{message: starlark.AsString(values[0])}
+		if path := values[1]; path != nil && path != starlark.None {
+			report.path = FieldPath(starlark.AsString(path))
+			if _, err := report.path.Segments(); err != nil {
+				return nil, fmt.Errorf("report: path: %v", err)
+			}
+		}
+		*reports = append(*reports, report)
+		return starlark.None, nil
+	}),
+}
+
+// compileScript разбирает скрипт правила и выполняет его верхний уровень; скрипт должен
+// объявить функцию check(doc)
+func compileScript(filename, source string, limits ScriptLimits) (*starlark.Function, error) {
+	program, err := starlark.Compile(filename, source)
+	if err != nil {
+		return nil, err
+	}
+	// Верхний уровень выполняется без Local: report() вне check() — ошибка
+	thread := &starlark.Thread{Limits: limits.withDefaults()}
+	globals, err := program.Exec(thread, scriptBuiltins)
+	if err != nil {
+		return nil, err
+	}
+	check, ok := globals["check"].(*starlark.Function)
+	if !ok {
+		return nil, fmt.Errorf("%s: script must define a function check(doc)", filename)
+	}
+	return check, nil
+}
+
+// checkScript вызывает check(doc) скрипта правила. Документ передаётся замороженным, так что
+// скрипт не может повлиять на другие правила; ошибка или превышение пределов становятся
+// находкой правила: непроверенный документ не должен выглядеть корректным.
+func (r *compiledRule) checkScript(document map[string]interface{}, kind, filename string) []Finding {
+	if len(r.Kinds) > 0 && !containsString(r.Kinds, kind) {
+		return nil
+	}
+	var reports []scriptReport
+	thread := &starlark.Thread{Limits: r.Limits.withDefaults(), Local: &reports}
+	_, err := starlark.Call(thread, r.script, starlark.FromGo(document))
+
+	var v Validator
+	for _, report := range reports {
+		value, _ := lookup(document, string(report.path))
+		v.addError(r.ID, report.path, fmt.Sprintf("%s: %s", filename, r.message(report.path, value, report.message)))
+	}
+	switch {
+	case errors.Is(err, starlark.ErrLimit):
+		v.addError(r.ID, "", fmt.Sprintf("%s: rule %s stopped: %v", filename, r.ID, err))
+	case err != nil:
+		v.addError(r.ID, "", fmt.Sprintf("%s: rule %s failed: %v", filename, r.ID, err))
+	}
+	return v.errors
+}
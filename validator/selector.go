@@ -0,0 +1,193 @@
+package validator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Операторы matchExpressions
+const (
+	selectorIn           = "In"
+	selectorNotIn        = "NotIn"
+	selectorExists       = "Exists"
+	selectorDoesNotExist = "DoesNotExist"
+)
+
+// LabelSelector — селектор меток Kubernetes: matchLabels и matchExpressions, объединённые по И
+type LabelSelector struct {
+	MatchLabels      map[string]string
+	MatchExpressions []SelectorRequirement
+}
+
+// SelectorRequirement — условие matchExpressions: ключ, оператор In, NotIn, Exists или DoesNotExist и значения
+type SelectorRequirement struct {
+	Key      string
+	Operator string
+	Values   []string
+}
+
+// selectorProblem — ошибка в селекторе с путём к полю относительно селектора
+type selectorProblem struct {
+	path    FieldPath
+	message string
+}
+
+// mapSelector строит селектор из отображения меток, как у spec.selector сервиса
+func mapSelector(labels map[string]interface{}) LabelSelector {
+	selector := LabelSelector{MatchLabels: map[string]string{}}
+	for key, value := range labels {
+		selector.MatchLabels[key] = fmt.Sprint(value)
+	}
+	return selector
+}
+
+// parseLabelSelector разбирает селектор рабочей нагрузки и возвращает найденные в нём ошибки
+func parseLabelSelector(value map[string]interface{}) (LabelSelector, []selectorProblem) {
+	var problems []selectorProblem
+	selector := LabelSelector{MatchLabels: map[string]string{}}
+	if labels, exists := value["matchLabels"]; exists {
+		labelMap, ok := labels.(map[string]interface{})
+		if !ok {
+			problems = append(problems, selectorProblem{"matchLabels", "must be an object"})
+		}
+		for key, label := range labelMap {
+			selector.MatchLabels[key] = fmt.Sprint(label)
+		}
+	}
+
+	expressions, _ := value["matchExpressions"].([]interface{})
+	if _, exists := value["matchExpressions"]; exists && expressions == nil {
+		problems = append(problems, selectorProblem{"matchExpressions", "must be an array"})
+	}
+	for i, item := range expressions {
+		path := FieldPath("matchExpressions").Index(i)
+		expression, _ := item.(map[string]interface{})
+		key, _ := expression["key"].(string)
+		operator, _ := expression["operator"].(string)
+		if key == "" {
+			problems = append(problems, selectorProblem{path.Field("key"), "is required"})
+			continue
+		}
+		requirement := SelectorRequirement{Key: key, Operator: operator}
+		values, _ := expression["values"].([]interface{})
+		for _, v := range values {
+			requirement.Values = append(requirement.Values, fmt.Sprint(v))
+		}
+		switch operator {
+		case selectorIn, selectorNotIn:
+			if len(requirement.Values) == 0 {
+				problems = append(problems, selectorProblem{path.Field("values"), fmt.Sprintf("must not be empty for operator %s", operator)})
+				continue
+			}
+		case selectorExists, selectorDoesNotExist:
+			if len(requirement.Values) > 0 {
+				problems = append(problems, selectorProblem{path.Field("values"), fmt.Sprintf("must be empty for operator %s", operator)})
+				continue
+			}
+		default:
+			problems = append(problems, selectorProblem{path.Field("operator"), fmt.Sprintf("has unsupported value '%s', expected In, NotIn, Exists or DoesNotExist", operator)})
+			continue
+		}
+		selector.MatchExpressions = append(selector.MatchExpressions, requirement)
+	}
+
+	if len(problems) == 0 && selector.Empty() {
+		problems = append(problems, selectorProblem{"", "must not be empty; an empty selector would match every pod"})
+	}
+	return selector, problems
+}
+
+// Empty сообщает, что селектор без условий
+func (s LabelSelector) Empty() bool {
+	return len(s.MatchLabels) == 0 && len(s.MatchExpressions) == 0
+}
+
+// Matches сообщает, подходят ли метки под все условия селектора
+func (s LabelSelector) Matches(labels map[string]interface{}) bool {
+	for key, value := range s.MatchLabels {
+		if label, exists := labels[key]; !exists || fmt.Sprint(label) != value {
+			return false
+		}
+	}
+	for _, requirement := range s.MatchExpressions {
+		label, exists := labels[requirement.Key]
+		switch requirement.Operator {
+		case selectorIn:
+			if !exists || !containsString(requirement.Values, fmt.Sprint(label)) {
+				return false
+			}
+		case selectorNotIn:
+			// Как в Kubernetes: метка без ключа подходит под NotIn
+			if exists && containsString(requirement.Values, fmt.Sprint(label)) {
+				return false
+			}
+		case selectorExists:
+			if !exists {
+				return false
+			}
+		case selectorDoesNotExist:
+			if exists {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// String печатает селектор в синтаксисе kubectl: app=web,tier in (api,web),!legacy
+func (s LabelSelector) String() string {
+	var parts []string
+	for key, value := range s.MatchLabels {
+		parts = append(parts, key+"="+value)
+	}
+	sort.Strings(parts)
+	for _, requirement := range s.MatchExpressions {
+		switch requirement.Operator {
+		case selectorIn, selectorNotIn:
+			parts = append(parts, fmt.Sprintf("%s %s (%s)", requirement.Key, strings.ToLower(requirement.Operator), strings.Join(requirement.Values, ",")))
+		case selectorExists:
+			parts = append(parts, requirement.Key)
+		case selectorDoesNotExist:
+			parts = append(parts, "!"+requirement.Key)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// Типы с селектором меток в spec.selector и шаблоном пода в spec.template
+var selectorWorkloads = []string{"Deployment", "StatefulSet", "DaemonSet", "ReplicaSet"}
+
+// validateWorkloadSelector проверяет, что spec.selector рабочей нагрузки корректен и выбирает
+// поды её собственного шаблона; иначе API отклонит объект или контроллер не увидит свои поды
+func (v *Validator) validateWorkloadSelector(document map[string]interface{}, filename string) {
+	if kind, _ := document["kind"].(string); !containsString(selectorWorkloads, kind) {
+		return
+	}
+	value, ok := lookupPath(document, "spec.selector").(map[string]interface{})
+	if !ok {
+		return
+	}
+	selector, ok := v.validateLabelSelector(value, "spec.selector", filename)
+	if !ok {
+		return
+	}
+	labels, _ := lookupPath(document, "spec.template.metadata.labels").(map[string]interface{})
+	if !selector.Matches(labels) {
+		v.addError(ruleLabelSelector, "spec.selector", fmt.Sprintf("%s: spec.selector %s does not match spec.template.metadata.labels", filename, selector))
+	}
+}
+
+// validateLabelSelector разбирает селектор меток по пути path и сообщает о его ошибках;
+// ok ложно, если селектор некорректен
+func (v *Validator) validateLabelSelector(value map[string]interface{}, path FieldPath, filename string) (LabelSelector, bool) {
+	selector, problems := parseLabelSelector(value)
+	for _, problem := range problems {
+		problemPath := path
+		if problem.path != "" {
+			problemPath = FieldPath(string(path) + "." + string(problem.path))
+		}
+		v.addError(ruleLabelSelector, problemPath, fmt.Sprintf("%s: %s %s", filename, problemPath, problem.message))
+	}
+	return selector, len(problems) == 0
+}
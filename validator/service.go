@@ -0,0 +1,197 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Типы сервисов
+var serviceTypes = []string{"ClusterIP", "NodePort", "LoadBalancer", "ExternalName"}
+
+// Протоколы портов сервиса; в отличие от портов контейнера допускается и SCTP
+var serviceProtocols = []string{"TCP", "UDP", "SCTP"}
+
+// Имя порта (IANA_SVC_NAME): до 15 символов, строчные буквы, цифры и дефисы, хотя бы одна буква
+var portNamePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,13}[a-z0-9])?$`)
+
+var servicePortSchema = &Schema{
+	Type:     "object",
+	Required: []string{"port"},
+	Properties: map[string]*Schema{
+		"name":        {Type: "string", Description: "Name of the port; required when the service has more than one port."},
+		"port":        {Type: "integer", Description: "Port exposed by the service.", Rules: []string{ruleRequiredField, rulePortRange}},
+		"targetPort":  {Description: "Number or name of the container port traffic is sent to; defaults to port.", Rules: []string{rulePortRange, ruleFieldValue}},
+		"nodePort":    {Type: "integer", Description: "Port on every node for NodePort and LoadBalancer services.", Rules: []string{rulePortRange, ruleFieldValue}},
+		"protocol":    {Type: "string", Description: "Protocol of the port.", Enum: serviceProtocols, Rules: []string{rulePortProtocol}},
+		"appProtocol": {Type: "string", Description: "Application protocol of the port, e.g. http or kubernetes.io/h2c."},
+	},
+}
+
+// serviceSchema описывает поля Service; порты и селектор проверяются кодом validateService
+var serviceSchema = &Schema{
+	Type:     "object",
+	Required: []string{"spec"},
+	Properties: map[string]*Schema{
+		"spec": {
+			Type:        "object",
+			Description: "Desired state of the service.",
+			Properties: map[string]*Schema{
+				"type":                     {Type: "string", Description: "How the service is exposed.", Enum: serviceTypes},
+				"selector":                 {Type: "object", Description: "Labels of the pods that receive the traffic.", Rules: []string{ruleLabelSelector}},
+				"ports":                    {Type: "array", Description: "Ports exposed by the service.", Items: servicePortSchema},
+				"clusterIP":                {Type: "string", Description: "Virtual IP of the service; None makes the service headless."},
+				"clusterIPs":               {Type: "array", Description: "Virtual IPs of the service, one per IP family.", Items: &Schema{Type: "string"}},
+				"externalName":             {Type: "string", Description: "DNS name returned for ExternalName services.", Rules: []string{ruleRequiredField}},
+				"externalIPs":              {Type: "array", Description: "External IPs that route to the service.", Items: &Schema{Type: "string"}},
+				"sessionAffinity":          {Type: "string", Description: "Whether requests of one client go to one pod.", Enum: []string{"None", "ClientIP"}},
+				"externalTrafficPolicy":    {Type: "string", Description: "Whether external traffic is routed to node-local endpoints only.", Enum: []string{"Cluster", "Local"}},
+				"internalTrafficPolicy":    {Type: "string", Description: "Whether internal traffic is routed to node-local endpoints only.", Enum: []string{"Cluster", "Local"}},
+				"loadBalancerIP":           {Type: "string", Description: "Requested IP of the load balancer (deprecated)."},
+				"loadBalancerSourceRanges": {Type: "array", Description: "Client CIDRs allowed to reach the load balancer.", Items: &Schema{Type: "string"}},
+				"loadBalancerClass":        {Type: "string", Description: "Load balancer implementation of the service."},
+				"ipFamilies":               {Type: "array", Description: "IP families of the service.", Items: &Schema{Type: "string", Enum: []string{"IPv4", "IPv6"}}},
+				"ipFamilyPolicy":           {Type: "string", Description: "Dual-stack policy of the service.", Enum: []string{"SingleStack", "PreferDualStack", "RequireDualStack"}},
+				"publishNotReadyAddresses": {Type: "boolean", Description: "Whether endpoints of pods that are not ready are published."},
+				"healthCheckNodePort":      {Type: "integer", Description: "Node port of the health check for externalTrafficPolicy: Local."},
+			},
+		},
+	},
+}
+
+func (v *Validator) validateService(document map[string]interface{}, filename string) {
+	spec, exists := document["spec"]
+	if !exists {
+		v.addError(ruleRequiredField, "spec", fmt.Sprintf("%s: spec is required", filename))
+		return
+	}
+	specMap, ok := spec.(map[string]interface{})
+	if !ok {
+		v.addError(ruleFieldType, "spec", fmt.Sprintf("%s: spec must be an object", filename))
+		return
+	}
+
+	specSchema := serviceSchema.Properties["spec"]
+	for _, name := range sortedKeys(specMap) {
+		if property, ok := specSchema.Properties[name]; ok && name != "ports" && name != "selector" {
+			v.validateSchema(specMap[name], property, FieldPath("spec").Field(name), filename)
+		}
+	}
+
+	serviceType, _ := specMap["type"].(string)
+	if serviceType == "" {
+		serviceType = "ClusterIP"
+	}
+	if serviceType == "ExternalName" {
+		if _, exists := specMap["externalName"]; !exists {
+			v.addError(ruleRequiredField, "spec.externalName", fmt.Sprintf("%s: spec.externalName is required for ExternalName services", filename))
+		}
+	} else if _, exists := specMap["ports"]; !exists && specMap["clusterIP"] != "None" {
+		v.addError(ruleRequiredField, "spec.ports", fmt.Sprintf("%s: spec.ports is required", filename))
+	}
+
+	if ports, exists := specMap["ports"]; exists {
+		if portsList, ok := ports.([]interface{}); ok {
+			for i, port := range portsList {
+				if portMap, ok := port.(map[string]interface{}); ok {
+					v.validateServicePort(portMap, FieldPath("spec.ports").Index(i), serviceType, len(portsList) > 1, filename)
+				} else {
+					v.addError(ruleFieldType, FieldPath("spec.ports").Index(i), fmt.Sprintf("%s: spec.ports[%d] must be an object", filename, i))
+				}
+			}
+		} else {
+			v.addError(ruleFieldType, "spec.ports", fmt.Sprintf("%s: spec.ports must be an array", filename))
+		}
+	}
+
+	if selector, exists := specMap["selector"]; exists {
+		v.validateServiceSelector(selector, filename)
+	}
+}
+
+func (v *Validator) validateServicePort(port map[string]interface{}, path FieldPath, serviceType string, named bool, filename string) {
+	// name
+	if name, exists := port["name"]; !exists && named {
+		v.addError(ruleRequiredField, path.Field("name"), fmt.Sprintf("%s: %s is required when the service has more than one port", filename, path.Field("name")))
+	} else if exists {
+		v.validateSchema(name, servicePortSchema.Properties["name"], path.Field("name"), filename)
+	}
+
+	// port
+	if value, exists := port["port"]; !exists {
+		v.addError(ruleRequiredField, path.Field("port"), fmt.Sprintf("%s: %s is required", filename, path.Field("port")))
+	} else {
+		v.validateServicePortNumber(value, path.Field("port"), filename)
+	}
+
+	// targetPort (optional): номер порта контейнера или имя его порта
+	if value, exists := port["targetPort"]; exists {
+		if name, ok := value.(string); ok {
+			if !portNamePattern.MatchString(name) || !strings.ContainsAny(name, "abcdefghijklmnopqrstuvwxyz") {
+				v.addError(ruleFieldValue, path.Field("targetPort"), fmt.Sprintf("%s: %s must be a port number or a port name of up to 15 lowercase letters, digits and hyphens, got '%s'", filename, path.Field("targetPort"), name))
+			}
+		} else {
+			v.validateServicePortNumber(value, path.Field("targetPort"), filename)
+		}
+	}
+
+	// nodePort (optional)
+	if value, exists := port["nodePort"]; exists {
+		if serviceType != "NodePort" && serviceType != "LoadBalancer" {
+			v.addError(ruleFieldValue, path.Field("nodePort"), fmt.Sprintf("%s: %s is only allowed for NodePort and LoadBalancer services", filename, path.Field("nodePort")))
+		} else {
+			v.validateServicePortNumber(value, path.Field("nodePort"), filename)
+		}
+	}
+
+	// protocol (optional)
+	v.validateProtocol(port, path, filename)
+}
+
+// validateProtocol проверяет необязательное поле protocol порта: TCP, UDP или SCTP
+func (v *Validator) validateProtocol(port map[string]interface{}, path FieldPath, filename string) {
+	if protocol, exists := port["protocol"]; exists {
+		if protocolStr, ok := protocol.(string); !ok {
+			v.addError(ruleFieldType, path.Field("protocol"), fmt.Sprintf("%s: %s must be string", filename, path.Field("protocol")))
+		} else if !containsString(serviceProtocols, protocolStr) {
+			v.addError(rulePortProtocol, path.Field("protocol"), fmt.Sprintf("%s: %s must be 'TCP', 'UDP' or 'SCTP'", filename, path.Field("protocol")))
+			remediation := Remediation{Action: ActionSet, Allowed: serviceProtocols}
+			if upper := strings.ToUpper(protocolStr); containsString(serviceProtocols, upper) {
+				remediation.Value = upper
+			}
+			v.suggest(remediation)
+		}
+	}
+}
+
+func (v *Validator) validateServicePortNumber(value interface{}, path FieldPath, filename string) {
+	if number, ok := portNumber(value); !ok {
+		v.addError(ruleFieldType, path, fmt.Sprintf("%s: %s must be integer", filename, path))
+	} else if !portInRange(number) {
+		v.addError(rulePortRange, path, fmt.Sprintf("%s: %s value out of range", filename, path))
+	}
+}
+
+// validateServiceSelector проверяет селектор сервиса: это простое отображение меток, а не
+// селектор рабочей нагрузки с matchLabels и matchExpressions
+func (v *Validator) validateServiceSelector(selector interface{}, filename string) {
+	selectorMap, ok := selector.(map[string]interface{})
+	if !ok {
+		v.addError(ruleFieldType, "spec.selector", fmt.Sprintf("%s: spec.selector must be an object", filename))
+		return
+	}
+	_, hasMatchLabels := selectorMap["matchLabels"]
+	_, hasMatchExpressions := selectorMap["matchExpressions"]
+	if hasMatchLabels || hasMatchExpressions {
+		v.addError(ruleLabelSelector, "spec.selector", fmt.Sprintf("%s: spec.selector of a Service is a plain map of labels, matchLabels and matchExpressions are not supported", filename))
+		if labels, ok := selectorMap["matchLabels"].(map[string]interface{}); ok && !hasMatchExpressions && len(selectorMap) == 1 {
+			v.suggest(Remediation{Action: ActionSet, Value: labels})
+		}
+		return
+	}
+	for _, key := range sortedKeys(selectorMap) {
+		if _, ok := selectorMap[key].(string); !ok {
+			v.addError(ruleFieldType, FieldPath("spec.selector").Field(key), fmt.Sprintf("%s: spec.selector.%s must be string", filename, key))
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package validator
+
+import "fmt"
+
+// validateServiceAccountToken предупреждает о подах, в контейнеры которых монтируется токен
+// ServiceAccount по умолчанию: большинству нагрузок доступ к API не нужен. Поды с собственным
+// ServiceAccount проверяются в наборе документов, где виден сам ServiceAccount.
+func (v *Validator) validateServiceAccountToken(document map[string]interface{}, filename string) {
+	for _, template := range podTemplates(document) {
+		spec, ok := lookupPath(document, template.spec).(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if _, set := spec["automountServiceAccountToken"]; set {
+			continue
+		}
+		if name, _ := spec["serviceAccountName"].(string); name != "" && name != "default" {
+			continue
+		}
+		path := template.spec.Field("automountServiceAccountToken")
+		v.addError(ruleServiceAccountToken, path, fmt.Sprintf("%s: %s is not set and the pod uses the default ServiceAccount, so an API token is mounted into every container", filename, path))
+		v.suggest(Remediation{Action: ActionSet, Value: false})
+	}
+}
+
+// validateServiceAccounts проверяет поды с собственным ServiceAccount: если ни под, ни ServiceAccount
+// в том же пространстве имён не отключают automountServiceAccountToken, токен монтируется
+func (s *DocumentSet) validateServiceAccounts(findings map[string][]Finding) {
+	accounts := map[string]setDocument{}
+	for _, doc := range s.documents {
+		if doc.kind() == "ServiceAccount" {
+			accounts[doc.namespace()+"/"+doc.name()] = doc
+		}
+	}
+
+	for _, doc := range s.documents {
+		var v Validator
+		for _, ref := range References(doc.document) {
+			if ref.Kind != "ServiceAccount" || ref.Name == "default" {
+				continue
+			}
+			spec := ref.Path.Parent()
+			if lookupPath(doc.document, spec.Field("automountServiceAccountToken")) != nil {
+				continue
+			}
+			account, found := accounts[doc.namespace()+"/"+ref.Name]
+			if !found || lookupPath(account.document, "automountServiceAccountToken") == false {
+				continue
+			}
+			path := spec.Field("automountServiceAccountToken")
+			v.addError(ruleServiceAccountToken, path, fmt.Sprintf("%s: %s is not set and ServiceAccount %s does not set automountServiceAccountToken: false, so an API token is mounted into every container", doc.filename, path, ref.Name))
+			v.errors[len(v.errors)-1].References = []Reference{account.reference("metadata.name", fmt.Sprintf("ServiceAccount %s is defined here", ref.Name))}
+			v.suggest(Remediation{Action: ActionSet, Value: false})
+		}
+		if len(v.errors) > 0 {
+			v.resolvePositions(doc.root)
+			findings[doc.filename] = append(findings[doc.filename], v.errors...)
+		}
+	}
+}
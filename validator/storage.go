@@ -0,0 +1,64 @@
+package validator
+
+import "fmt"
+
+// persistentVolumeClaimSchema описывает поля PersistentVolumeClaim v1
+var persistentVolumeClaimSchema = &Schema{
+	Type:     "object",
+	Required: []string{"spec"},
+	Properties: map[string]*Schema{
+		"spec": persistentVolumeClaimSpecSchema,
+	},
+}
+
+func (v *Validator) validatePersistentVolumeClaim(document map[string]interface{}, filename string) {
+	spec, exists := document["spec"]
+	if !exists {
+		v.addError(ruleRequiredField, "spec", fmt.Sprintf("%s: spec is required", filename))
+		return
+	}
+	specMap, ok := spec.(map[string]interface{})
+	if !ok {
+		v.addError(ruleFieldType, "spec", fmt.Sprintf("%s: spec must be an object", filename))
+		return
+	}
+	v.validateSchema(specMap, persistentVolumeClaimSpecSchema, "spec", filename)
+	v.validateStorageRequest(document, "spec", filename)
+	v.validateClaimSpec(specMap, "spec", filename)
+}
+
+// validateClaimSpec проверяет сочетание режимов доступа, предел размера тома и имя класса хранения
+func (v *Validator) validateClaimSpec(spec map[string]interface{}, path FieldPath, filename string) {
+	if modes, ok := spec["accessModes"].([]interface{}); ok {
+		modesPath := path.Field("accessModes")
+		seen := map[string]bool{}
+		for i, mode := range modes {
+			name, _ := mode.(string)
+			if seen[name] {
+				v.addError(ruleFieldValue, modesPath.Index(i), fmt.Sprintf("%s: %s: access mode '%s' is listed more than once", filename, modesPath.Index(i), name))
+			}
+			seen[name] = true
+		}
+		switch {
+		case len(modes) == 0:
+			v.addError(ruleRequiredField, modesPath, fmt.Sprintf("%s: %s must list at least one access mode", filename, modesPath))
+		case seen["ReadWriteOncePod"] && len(seen) > 1:
+			v.addError(ruleFieldValue, modesPath, fmt.Sprintf("%s: %s: ReadWriteOncePod cannot be combined with other access modes", filename, modesPath))
+		}
+	}
+
+	limitPath := path.Field("resources").Field("limits").Field("storage")
+	if limit := lookupPath(spec, "resources.limits.storage"); limit != nil {
+		limitQuantity, err := ParseQuantity(limit)
+		if err != nil || limitQuantity.Sign() <= 0 {
+			v.addError(ruleFieldValue, limitPath, fmt.Sprintf("%s: %s must be a positive quantity, e.g. 10Gi", filename, limitPath))
+		} else if request, err := ParseQuantity(lookupPath(spec, "resources.requests.storage")); err == nil && request.Cmp(limitQuantity) > 0 {
+			v.addError(ruleFieldValue, limitPath, fmt.Sprintf("%s: %s must not be less than resources.requests.storage", filename, limitPath))
+		}
+	}
+
+	// Пустая строка — явный запрос тома без класса
+	if class, ok := spec["storageClassName"].(string); ok && class != "" && (len(class) > 253 || !dnsSubdomainPattern.MatchString(class)) {
+		v.addError(ruleFieldValue, path.Field("storageClassName"), fmt.Sprintf("%s: %s '%s' is not a valid storage class name: lowercase letters, digits, '-' and '.'", filename, path.Field("storageClassName"), class))
+	}
+}
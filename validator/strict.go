@@ -0,0 +1,84 @@
+package validator
+
+import (
+	"fmt"
+	"sort"
+)
+
+// validateUnknownFields ищет поля, которых нет в схеме типа. Отображения без описанных
+// свойств, например metadata.labels, могут содержать любые ключи.
+func (v *Validator) validateUnknownFields(value interface{}, schema *Schema, path FieldPath, filename string) {
+	if schema == nil {
+		return
+	}
+	switch value := value.(type) {
+	case map[string]interface{}:
+		if len(schema.Properties) == 0 && schema.AdditionalProperties == nil {
+			return
+		}
+		known := make([]string, 0, len(schema.Properties))
+		for name := range schema.Properties {
+			known = append(known, name)
+		}
+		sort.Strings(known)
+		for _, name := range sortedKeys(value) {
+			property, ok := schema.Properties[name]
+			if ok {
+				v.validateUnknownFields(value[name], property, path.Field(name), filename)
+				continue
+			}
+			if schema.AdditionalProperties != nil {
+				v.validateUnknownFields(value[name], schema.AdditionalProperties, path.Field(name), filename)
+				continue
+			}
+			message := fmt.Sprintf("%s: %s is not a known field of %s", filename, path.Field(name), displayPath(path))
+			if suggestion := closestName(name, known); suggestion != "" {
+				message += fmt.Sprintf(", did you mean '%s'?", suggestion)
+			}
+			v.addError(ruleUnknownField, path.Field(name), message)
+		}
+	case []interface{}:
+		for i, item := range value {
+			v.validateUnknownFields(item, schema.Items, path.Index(i), filename)
+		}
+	}
+}
+
+// closestName возвращает известное имя, отличающееся от name опечаткой: не более чем
+// на две правки и меньше чем на треть длины; пусто, если такого нет
+func closestName(name string, known []string) string {
+	best, bestDistance := "", 3
+	for _, candidate := range known {
+		distance := editDistance(name, candidate)
+		if distance < bestDistance && distance*3 < len(candidate) {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+// editDistance — расстояние Дамерау — Левенштейна: вставки, удаления, замены и перестановки соседних букв
+func editDistance(a, b string) int {
+	x, y := []rune(a), []rune(b)
+	d := make([][]int, len(x)+1)
+	for i := range d {
+		d[i] = make([]int, len(y)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(x); i++ {
+		for j := 1; j <= len(y); j++ {
+			cost := 1
+			if x[i-1] == y[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && x[i-1] == y[j-2] && x[i-2] == y[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(x)][len(y)]
+}
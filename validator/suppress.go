@@ -0,0 +1,147 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Suppression — комментарий # yamlvalid:disable=YV102,image-tag, отключающий правила на строке
+// с комментарием или, если комментарий стоит на отдельной строке, на следующей; если строка
+// открывает блок, правила отключаются во всём блоке
+type Suppression struct {
+	// Line — строка комментария (с единицы); From и To — строки, на которые он действует
+	Line     int
+	From, To int
+	// Rules — правила из комментария, как они записаны: идентификаторы или имена
+	Rules []string
+	// used — правила, подавившие хотя бы одну находку
+	used map[string]bool
+}
+
+var suppressionPattern = regexp.MustCompile(`(^|\s)#\s*yamlvalid:disable=([\w.,-]+)`)
+
+// ParseSuppressions находит комментарии yamlvalid:disable в документе
+func ParseSuppressions(data []byte) []*Suppression {
+	lines := strings.Split(string(data), "\n")
+	var suppressions []*Suppression
+	for i, line := range lines {
+		match := suppressionPattern.FindStringSubmatchIndex(line)
+		if match == nil {
+			continue
+		}
+		suppression := &Suppression{Line: i + 1, used: map[string]bool{}}
+		for _, rule := range strings.Split(line[match[4]:match[5]], ",") {
+			if rule != "" {
+				suppression.Rules = append(suppression.Rules, rule)
+			}
+		}
+		// Комментарий на отдельной строке относится к следующей строке с содержимым
+		target := i
+		if strings.TrimSpace(line[:match[0]]) == "" {
+			for target = i + 1; target < len(lines) && blankOrComment(lines[target]); target++ {
+			}
+			if target == len(lines) {
+				continue
+			}
+		}
+		suppression.From, suppression.To = target+1, blockEnd(lines, target)+1
+		suppressions = append(suppressions, suppression)
+	}
+	return suppressions
+}
+
+func blankOrComment(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" || strings.HasPrefix(trimmed, "#")
+}
+
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// blockEnd возвращает последнюю строку блока, который открывает строка start: строки с большим
+// отступом, а для ключа — и элементы последовательности на том же отступе
+func blockEnd(lines []string, start int) int {
+	indent := indentation(lines[start])
+	isKey := !strings.HasPrefix(strings.TrimSpace(lines[start]), "-")
+	end := start
+	for i := start + 1; i < len(lines); i++ {
+		line := lines[i]
+		if blankOrComment(line) {
+			continue
+		}
+		nested := indentation(line) > indent ||
+			(isKey && indentation(line) == indent && strings.HasPrefix(strings.TrimSpace(line), "-"))
+		if !nested || strings.HasPrefix(line, "---") {
+			break
+		}
+		end = i
+	}
+	return end
+}
+
+// covers сообщает, отключает ли комментарий правило на строке; совпавшее правило отмечается
+// как использованное. Обязательные правила комментарием не отключаются.
+func (s *Suppression) covers(ruleID string, line int) bool {
+	if line < s.From || line > s.To {
+		return false
+	}
+	rule, known := FindRule(ruleID)
+	if known && rule.Mandatory {
+		return false
+	}
+	for _, key := range s.Rules {
+		if key == ruleID || (known && key == rule.Name) {
+			s.used[key] = true
+			return true
+		}
+	}
+	return false
+}
+
+// Suppress убирает находки, отключённые комментариями; находки без позиции не подавляются
+func Suppress(findings []Finding, suppressions []*Suppression) []Finding {
+	if len(suppressions) == 0 {
+		return findings
+	}
+	var kept []Finding
+	for _, finding := range findings {
+		suppressed := false
+		for _, suppression := range suppressions {
+			if finding.Line != 0 && suppression.covers(finding.RuleID, finding.Line) {
+				suppressed = true
+			}
+		}
+		if !suppressed {
+			kept = append(kept, finding)
+		}
+	}
+	return kept
+}
+
+// UnusedSuppressions возвращает находки о комментариях, которые ничего не подавили: правило
+// неизвестно или не нашло нарушений. Правила, выключенные выбором, не считаются неиспользованными.
+func UnusedSuppressions(suppressions []*Suppression, filename string, selection RuleSelection) []Finding {
+	var findings []Finding
+	for _, suppression := range suppressions {
+		for _, key := range suppression.Rules {
+			if suppression.used[key] {
+				continue
+			}
+			message := fmt.Sprintf("%s:%d: yamlvalid:disable=%s suppresses no findings", filename, suppression.Line, key)
+			if rule, ok := FindRuleByKey(key); !ok {
+				message = fmt.Sprintf("%s:%d: yamlvalid:disable refers to unknown rule '%s'", filename, suppression.Line, key)
+			} else if !selection.Enabled(rule) {
+				continue
+			}
+			findings = append(findings, Finding{
+				RuleID:   ruleUnusedSuppression,
+				Severity: severityOf(ruleUnusedSuppression),
+				Message:  message,
+				Line:     suppression.Line,
+			})
+		}
+	}
+	return findings
+}
@@ -0,0 +1,129 @@
+package validator
+
+import (
+	"bytes"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Виды символов структуры файла
+const (
+	SymbolResource  = "resource"
+	SymbolContainer = "container"
+	SymbolVolume    = "volume"
+)
+
+// Symbol — элемент структуры манифеста: ресурс, контейнер или том
+type Symbol struct {
+	Name   string
+	Detail string
+	Kind   string
+	// Позиции с единицы; End — конец последнего значения внутри символа
+	Line, Column       int
+	EndLine, EndColumn int
+	Children           []Symbol
+}
+
+// Пути к спецификации пода у встроенного пода и у ресурсов с шаблоном пода
+var podSpecPaths = []FieldPath{"spec", "spec.template.spec", "spec.jobTemplate.spec.template.spec"}
+
+// Symbols строит структуру файла: ресурс на каждый документ, внутри — его контейнеры и тома.
+// Документы после синтаксической ошибки не попадают в результат.
+func Symbols(data []byte) []Symbol {
+	var symbols []Symbol
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var root yaml.Node
+		if decoder.Decode(&root) != nil {
+			return symbols
+		}
+		if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+			continue
+		}
+		symbols = append(symbols, resourceSymbol(&root))
+	}
+}
+
+func resourceSymbol(root *yaml.Node) Symbol {
+	scalar := func(path FieldPath) string {
+		if node := dealias(path.Resolve(root)); node != nil && node.Kind == yaml.ScalarNode {
+			return node.Value
+		}
+		return ""
+	}
+	mapping := root.Content[0]
+	resource := nodeSymbol(mapping, SymbolResource, scalar("kind"), scalar("apiVersion"))
+	if name := scalar("metadata.name"); name != "" {
+		resource.Name = fmt.Sprintf("%s %s", resource.Name, name)
+	}
+	if resource.Name == "" {
+		resource.Name = "document"
+	}
+	if namespace := scalar("metadata.namespace"); namespace != "" {
+		resource.Detail += " in " + namespace
+	}
+
+	for _, spec := range podSpecPaths {
+		for _, list := range []string{"initContainers", "containers"} {
+			for _, item := range sequence(spec.Field(list).Resolve(root)) {
+				image := ""
+				if node := dealias(FieldPath("image").Resolve(item)); node != nil {
+					image = node.Value
+				}
+				resource.Children = append(resource.Children, nodeSymbol(item, SymbolContainer, itemName(item), image))
+			}
+		}
+		for _, item := range sequence(spec.Field("volumes").Resolve(root)) {
+			// Тип тома — первый ключ кроме name: configMap, secret, emptyDir...
+			source := ""
+			for i := 0; i+1 < len(item.Content); i += 2 {
+				if item.Content[i].Value != "name" {
+					source = item.Content[i].Value
+					break
+				}
+			}
+			resource.Children = append(resource.Children, nodeSymbol(item, SymbolVolume, itemName(item), source))
+		}
+	}
+	return resource
+}
+
+func nodeSymbol(node *yaml.Node, kind, name, detail string) Symbol {
+	endLine, endColumn := nodeEnd(node)
+	return Symbol{Name: name, Detail: detail, Kind: kind, Line: node.Line, Column: node.Column, EndLine: endLine, EndColumn: endColumn}
+}
+
+// sequence возвращает элементы-отображения последовательности
+func sequence(node *yaml.Node) []*yaml.Node {
+	node = dealias(node)
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return nil
+	}
+	var items []*yaml.Node
+	for _, item := range node.Content {
+		if item = dealias(item); item.Kind == yaml.MappingNode {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func itemName(item *yaml.Node) string {
+	if node := dealias(FieldPath("name").Resolve(item)); node != nil && node.Kind == yaml.ScalarNode {
+		return node.Value
+	}
+	return "(unnamed)"
+}
+
+// nodeEnd возвращает позицию сразу за последним скаляром узла; для многострочных
+// скаляров — приблизительно, по началу значения
+func nodeEnd(node *yaml.Node) (int, int) {
+	for len(node.Content) > 0 {
+		node = node.Content[len(node.Content)-1]
+	}
+	if node.Alias != nil || node.Kind != yaml.ScalarNode {
+		return node.Line, node.Column + 1
+	}
+	return node.Line, node.Column + len([]rune(node.Value))
+}
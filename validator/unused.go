@@ -0,0 +1,43 @@
+package validator
+
+import "fmt"
+
+// validateUnused ищет ресурсы, на которые не ссылается ни одна рабочая нагрузка набора:
+// ConfigMap, Secret типа Opaque и PersistentVolumeClaim, а также сервисы, не выбирающие ни одной нагрузки
+func (s *DocumentSet) validateUnused(findings map[string][]Finding) {
+	used := map[string]bool{}
+	for _, edge := range s.graph().Edges {
+		used[edge.To] = true
+	}
+
+	for _, doc := range s.documents {
+		if doc.name() == "" {
+			continue
+		}
+		id := GraphNode{Kind: doc.kind(), Namespace: doc.namespace(), Name: doc.name()}.ID()
+		if used[id] {
+			continue
+		}
+		var v Validator
+		switch doc.kind() {
+		case "Secret":
+			// Прочие типы — сертификаты, токены и учётные данные реестров — используются не подами
+			if secretType, _ := doc.document["type"].(string); secretType != "" && secretType != "Opaque" {
+				continue
+			}
+			fallthrough
+		case "ConfigMap", "PersistentVolumeClaim":
+			v.addError(ruleUnusedResource, "metadata.name", fmt.Sprintf("%s: %s %s is not referenced by any workload in namespace %s", doc.filename, doc.kind(), doc.name(), doc.namespace()))
+		case "Service":
+			// Сервис без селектора направляет трафик на вручную заданные Endpoints
+			if selector, _ := lookupPath(doc.document, "spec.selector").(map[string]interface{}); len(selector) == 0 {
+				continue
+			}
+			v.addError(ruleUnusedService, "metadata.name", fmt.Sprintf("%s: Service %s selects no workload in namespace %s", doc.filename, doc.name(), doc.namespace()))
+		default:
+			continue
+		}
+		v.resolvePositions(doc.root)
+		findings[doc.filename] = append(findings[doc.filename], v.errors...)
+	}
+}
@@ -0,0 +1,574 @@
+package validator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Finding struct {
+	RuleID   string
+	Severity Severity
+	Message  string
+	// Path указывает на поле, к которому относится нарушение
+	Path FieldPath
+	// Line и Column — позиция поля в файле (с единицы); 0, если позиция неизвестна
+	Line   int
+	Column int
+	// Related — находки, сгруппированные под этой как под первопричиной
+	Related []Finding
+	// Remediation — подсказка по исправлению; есть не у всех правил
+	Remediation *Remediation
+	// References — связанные места в других файлах набора, например первое вхождение дубликата
+	References []Reference
+}
+
+// Fingerprint — стабильный идентификатор находки, не зависящий от позиции в файле
+func (f Finding) Fingerprint() string {
+	sum := sha256.Sum256([]byte(f.RuleID + "\x00" + string(f.Path) + "\x00" + f.Message))
+	return f.RuleID + "-" + hex.EncodeToString(sum[:6])
+}
+
+type Validator struct {
+	errors []Finding
+	// podSpec — путь к проверяемой спецификации пода: spec у Pod, spec.template.spec у рабочих нагрузок
+	podSpec FieldPath
+	// options — настройки запуска; у проверок наборов документов нулевые
+	options Options
+}
+
+func (v *Validator) addError(ruleID string, path FieldPath, message string) {
+	v.errors = append(v.errors, Finding{RuleID: ruleID, Severity: severityOf(ruleID), Message: message, Path: path})
+}
+
+// TimeoutFinding — находка для файла, проверка которого не уложилась в limit
+func TimeoutFinding(filename string, limit time.Duration) Finding {
+	return Finding{
+		RuleID:   ruleTimeout,
+		Severity: severityOf(ruleTimeout),
+		Message:  fmt.Sprintf("%s: validation timed out after %s", filename, limit),
+	}
+}
+
+// specPath возвращает путь к проверяемой спецификации пода
+func (v *Validator) specPath() FieldPath {
+	if v.podSpec == "" {
+		return "spec"
+	}
+	return v.podSpec
+}
+
+func (v *Validator) containerPath(index int) FieldPath {
+	return v.specPath().Field("containers").Index(index)
+}
+
+// parse разбирает документ как generic YAML, сохраняя дерево узлов для позиций;
+// повторяющиеся ключи становятся находками, и в документе остаётся последнее значение
+func (v *Validator) parse(data []byte, filename string) (*yaml.Node, map[string]interface{}, bool) {
+	var root yaml.Node
+	var document map[string]interface{}
+	err := yaml.Unmarshal(data, &root)
+	if err == nil {
+		if complexityErr := checkComplexity(&root); complexityErr != nil {
+			v.addError(ruleYAMLSyntax, "", fmt.Sprintf("%s: document too complex: %v", filename, complexityErr))
+			return nil, nil, false
+		}
+		v.validateDuplicateKeys(&root, "", filename)
+		err = root.Decode(&document)
+	}
+	if err != nil {
+		v.addError(ruleYAMLSyntax, "", fmt.Sprintf("Validation failed: invalid YAML format: %v", err))
+		v.errors[len(v.errors)-1].Line = syntaxErrorLine(err)
+		return nil, nil, false
+	}
+	return &root, document, true
+}
+
+// resolvePositions находит позиции полей находок; позиции, заданные при разборе, сохраняются
+func (v *Validator) resolvePositions(root *yaml.Node) {
+	for i := range v.errors {
+		if v.errors[i].Line != 0 {
+			continue
+		}
+		if node := v.errors[i].Path.ResolveNearest(root); node != nil {
+			v.errors[i].Line, v.errors[i].Column = node.Line, node.Column
+		}
+	}
+}
+
+// sortedKeys возвращает ключи в порядке сортировки, чтобы находки не зависели от порядка обхода map
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// syntaxErrorLine извлекает номер строки из ошибки yaml.v3 вида "yaml: line 3: ..."
+func syntaxErrorLine(err error) int {
+	var line int
+	if _, scanErr := fmt.Sscanf(strings.TrimPrefix(err.Error(), "yaml: "), "line %d:", &line); scanErr != nil {
+		return 0
+	}
+	return line
+}
+
+func (v *Validator) validateTopLevel(document map[string]interface{}, filename string) {
+	// Структурный этап: apiVersion, kind и metadata общие для всех типов
+	handler := v.validateCommonFields(document, filename)
+
+	// Тело документа проверяем только для известного kind, иначе все находки были бы следствием неверного kind
+	if handler != nil {
+		handler.validate(v, document, filename)
+		// Схема без свойств не описывает тело документа, и неизвестным оказалось бы всё
+		if handler.schema != nil && len(handler.schema.Properties) > 0 {
+			v.validateUnknownFields(document, SchemaFor(handler.gvk.APIVersion(), handler.gvk.Kind), "", filename)
+		}
+		v.validateWorkloadSelector(document, filename)
+		v.validatePodSecurity(document, filename)
+		v.validateCIS(document, filename)
+		v.validateHostPath(document, filename)
+		v.validateServiceAccountToken(document, filename)
+		v.validateQoS(document, filename)
+		v.validateProbeSanity(document, filename)
+	}
+	v.validateCustomRules(document, filename)
+	v.validateExternalRules(document, filename)
+	v.applyDependencies()
+}
+
+func (v *Validator) validateCommonFields(document map[string]interface{}, filename string) *kindHandler {
+	kindStr, _ := document["kind"].(string)
+	apiVersionStr, _ := document["apiVersion"].(string)
+	handlers := handlersFor(kindStr, v.options.RulesetVersion)
+
+	// apiVersion
+	if apiVersion, exists := document["apiVersion"]; !exists {
+		v.addError(ruleRequiredField, "apiVersion", fmt.Sprintf("%s: apiVersion is required", filename))
+	} else if _, ok := apiVersion.(string); !ok {
+		v.addError(ruleFieldType, "apiVersion", fmt.Sprintf("%s: apiVersion must be string", filename))
+	} else if expected := apiVersionsOf(handlers); len(handlers) > 0 && !containsString(expected, apiVersionStr) {
+		v.addError(ruleAPIVersion, "apiVersion", fmt.Sprintf("%s: apiVersion must be %s", filename, quoteList(expected)))
+		remediation := Remediation{Action: ActionSet, Allowed: expected}
+		if len(expected) == 1 {
+			remediation.Value = expected[0]
+		}
+		v.suggest(remediation)
+	}
+
+	// kind
+	if kind, exists := document["kind"]; !exists {
+		v.addError(ruleRequiredField, "kind", fmt.Sprintf("%s: kind is required", filename))
+	} else if _, ok := kind.(string); !ok {
+		v.addError(ruleFieldType, "kind", fmt.Sprintf("%s: kind must be string", filename))
+	} else if len(handlers) == 0 {
+		v.addError(ruleKind, "kind", fmt.Sprintf("%s: kind must be %s", filename, quoteList(registeredKinds(v.options.RulesetVersion))))
+	}
+
+	// metadata
+	if metadata, exists := document["metadata"]; !exists {
+		v.addError(ruleRequiredField, "metadata", fmt.Sprintf("%s: metadata is required", filename))
+	} else if metadataMap, ok := metadata.(map[string]interface{}); ok {
+		v.validateMetadata(metadataMap, filename)
+	} else {
+		v.addError(ruleFieldType, "metadata", fmt.Sprintf("%s: metadata must be an object", filename))
+	}
+
+	// Без kind проверяем документ как Pod — основной поддерживаемый тип
+	if _, exists := document["kind"]; !exists {
+		handlers = handlersFor("Pod", v.options.RulesetVersion)
+	}
+	if len(handlers) == 0 {
+		return nil
+	}
+	for _, h := range handlers {
+		if h.gvk.APIVersion() == apiVersionStr {
+			return h
+		}
+	}
+	return handlers[0]
+}
+
+// applyDependencies группирует находки, вызванные уже сработавшим правилом-предпосылкой
+// на том же поле или его предке, под находкой-первопричиной. Неверный тип поля считается
+// первопричиной для всех находок внутри этого поля.
+func (v *Validator) applyDependencies() {
+	causeOf := make([]int, len(v.errors))
+	grouped := false
+	for i := range v.errors {
+		causeOf[i] = v.rootCause(i)
+		grouped = grouped || causeOf[i] >= 0
+	}
+	if !grouped {
+		return
+	}
+
+	for i, cause := range causeOf {
+		if cause >= 0 {
+			v.errors[cause].Related = append(v.errors[cause].Related, v.errors[i])
+		}
+	}
+	var kept []Finding
+	for i, finding := range v.errors {
+		if causeOf[i] < 0 {
+			kept = append(kept, finding)
+		}
+	}
+	v.errors = kept
+}
+
+// rootCause возвращает индекс находки-первопричины для v.errors[i] либо -1
+func (v *Validator) rootCause(i int) int {
+	finding := v.errors[i]
+	rule, _ := FindRule(finding.RuleID)
+	for j, other := range v.errors {
+		if j == i || !finding.Path.HasPrefix(other.Path) {
+			continue
+		}
+		if other.RuleID == ruleFieldType && other.Path != finding.Path {
+			if cause := v.rootCause(j); cause >= 0 {
+				return cause
+			}
+			return j
+		}
+		if other.RuleID != finding.RuleID && containsString(rule.DependsOn, other.RuleID) {
+			if cause := v.rootCause(j); cause >= 0 {
+				return cause
+			}
+			return j
+		}
+	}
+	return -1
+}
+
+func (v *Validator) validatePod(document map[string]interface{}, filename string) {
+	// spec
+	if spec, exists := document["spec"]; !exists {
+		v.addError(ruleRequiredField, "spec", fmt.Sprintf("%s: spec is required", filename))
+	} else if specMap, ok := spec.(map[string]interface{}); ok {
+		v.validateSpec(specMap, filename)
+	} else {
+		v.addError(ruleFieldType, "spec", fmt.Sprintf("%s: spec must be an object", filename))
+	}
+}
+
+func (v *Validator) validateMetadata(metadata map[string]interface{}, filename string) {
+	filenameOnly := filepath.Base(filename)
+
+	// name
+	if name, exists := metadata["name"]; !exists {
+		v.addError(ruleRequiredField, "metadata.name", fmt.Sprintf("%s:4 name is required", filenameOnly))
+	} else if nameStr, ok := name.(string); !ok {
+		v.addError(ruleFieldType, "metadata.name", fmt.Sprintf("%s: metadata.name must be string", filename))
+	} else if nameStr == "" {
+		v.addError(ruleRequiredField, "metadata.name", fmt.Sprintf("%s:4 name is required", filenameOnly))
+	}
+
+	// namespace (optional)
+	if namespace, exists := metadata["namespace"]; exists {
+		if _, ok := namespace.(string); !ok {
+			v.addError(ruleFieldType, "metadata.namespace", fmt.Sprintf("%s: metadata.namespace must be string", filename))
+		}
+	}
+
+	// labels (optional)
+	if labels, exists := metadata["labels"]; exists {
+		if labelsMap, ok := labels.(map[string]interface{}); ok {
+			for _, key := range sortedKeys(labelsMap) {
+				value := labelsMap[key]
+				if _, ok := value.(string); !ok {
+					v.addError(ruleFieldType, FieldPath("metadata.labels").Field(key), fmt.Sprintf("%s: metadata.labels.%s must be string", filename, key))
+				}
+			}
+		} else {
+			v.addError(ruleFieldType, "metadata.labels", fmt.Sprintf("%s: metadata.labels must be an object", filename))
+		}
+	}
+}
+
+func (v *Validator) validateSpec(spec map[string]interface{}, filename string) {
+	// os (optional)
+	if os, exists := spec["os"]; exists {
+		v.validateOS(os, filename)
+	}
+
+	// containers
+	containersPath := v.specPath().Field("containers")
+	if containers, exists := spec["containers"]; !exists {
+		v.addError(ruleRequiredField, containersPath, fmt.Sprintf("%s: %s is required", filename, containersPath))
+	} else if containersList, ok := containers.([]interface{}); ok {
+		if len(containersList) == 0 {
+			v.addError(ruleRequiredField, containersPath, fmt.Sprintf("%s: at least one container is required", filename))
+		}
+		for i, container := range containersList {
+			if containerMap, ok := container.(map[string]interface{}); ok {
+				v.validateContainer(containerMap, i, filename)
+			} else {
+				v.addError(ruleFieldType, containersPath.Index(i), fmt.Sprintf("%s: %s must be an object", filename, containersPath.Index(i)))
+			}
+		}
+	} else {
+		v.addError(ruleFieldType, containersPath, fmt.Sprintf("%s: %s must be an array", filename, containersPath))
+	}
+}
+
+func (v *Validator) validateOS(os interface{}, filename string) {
+	filenameOnly := filepath.Base(filename)
+
+	if osMap, ok := os.(map[string]interface{}); ok {
+		if name, exists := osMap["name"]; !exists {
+			v.addError(ruleRequiredField, v.specPath().Field("os").Field("name"), fmt.Sprintf("%s: os.name is required", filename))
+		} else if nameStr, ok := name.(string); ok {
+			if nameStr != "linux" && nameStr != "windows" {
+				v.addError(ruleOSName, v.specPath().Field("os").Field("name"), fmt.Sprintf("%s:10 os has unsupported value '%s'", filenameOnly, nameStr))
+				v.suggest(Remediation{Action: ActionSet, Allowed: []string{"linux", "windows"}})
+			}
+		} else {
+			v.addError(ruleFieldType, v.specPath().Field("os").Field("name"), fmt.Sprintf("%s: os.name must be string", filename))
+		}
+	} else {
+		// Если os не объект, а что-то другое (например, строка)
+		if osStr, ok := os.(string); ok {
+			v.addError(ruleOSName, v.specPath().Field("os"), fmt.Sprintf("%s:10 os has unsupported value '%s'", filenameOnly, osStr))
+			// Строка с допустимым именем — частая ошибка вместо os: {name: ...}
+			if osStr == "linux" || osStr == "windows" {
+				v.suggest(Remediation{Action: ActionSet, Value: map[string]interface{}{"name": osStr}})
+			}
+		} else {
+			v.addError(ruleOSName, v.specPath().Field("os"), fmt.Sprintf("%s:10 os has unsupported value '%v'", filenameOnly, os))
+		}
+	}
+}
+
+func (v *Validator) validateContainer(container map[string]interface{}, index int, filename string) {
+	// name
+	if name, exists := container["name"]; !exists {
+		v.addError(ruleRequiredField, v.containerPath(index).Field("name"), fmt.Sprintf("%s: container[%d].name is required", filename, index))
+	} else if nameStr, ok := name.(string); ok {
+		// Проверка snake_case
+		if !snakeCaseRegex.MatchString(nameStr) {
+			v.addError(ruleContainerName, v.containerPath(index).Field("name"), fmt.Sprintf("%s: container[%d].name must be in snake_case format", filename, index))
+			remediation := Remediation{Action: ActionSet, Pattern: snakeCase}
+			if fixed := toSnakeCase(nameStr); fixed != "" {
+				remediation.Value = fixed
+			}
+			v.suggest(remediation)
+		}
+	} else {
+		v.addError(ruleFieldType, v.containerPath(index).Field("name"), fmt.Sprintf("%s: container[%d].name must be string", filename, index))
+	}
+
+	// image
+	if image, exists := container["image"]; !exists {
+		v.addError(ruleRequiredField, v.containerPath(index).Field("image"), fmt.Sprintf("%s: container[%d].image is required", filename, index))
+	} else if imageStr, ok := image.(string); ok {
+		if registries := v.allowedRegistries(); !fromRegistry(imageStr, registries) {
+			v.addError(ruleImageRegistry, v.containerPath(index).Field("image"), fmt.Sprintf("%s: container[%d].image must be in domain %s", filename, index, strings.Join(registries, " or ")))
+			v.suggest(Remediation{Action: ActionSet, Value: imageInRegistry(imageStr, registries), Pattern: registryPattern(registries)})
+		}
+		if problem := v.checkImageTag(imageStr); problem != "" {
+			v.addError(ruleImageTag, v.containerPath(index).Field("image"), fmt.Sprintf("%s: container[%d].image %s", filename, index, problem))
+			v.suggest(Remediation{Action: ActionSet, Pattern: `:[^/:]+$`})
+		}
+		v.validateImageDigest(imageStr, index, filename)
+	} else {
+		v.addError(ruleFieldType, v.containerPath(index).Field("image"), fmt.Sprintf("%s: container[%d].image must be string", filename, index))
+	}
+
+	// ports (optional)
+	if ports, exists := container["ports"]; exists {
+		if portsList, ok := ports.([]interface{}); ok {
+			for i, port := range portsList {
+				if portMap, ok := port.(map[string]interface{}); ok {
+					v.validateContainerPort(portMap, index, i, filename)
+				} else {
+					v.addError(ruleFieldType, v.containerPath(index).Field("ports").Index(i), fmt.Sprintf("%s: container[%d].ports[%d] must be an object", filename, index, i))
+				}
+			}
+		} else {
+			v.addError(ruleFieldType, v.containerPath(index).Field("ports"), fmt.Sprintf("%s: container[%d].ports must be an array", filename, index))
+		}
+	}
+
+	// resources
+	if resources, exists := container["resources"]; !exists {
+		v.addError(ruleRequiredField, v.containerPath(index).Field("resources"), fmt.Sprintf("%s: container[%d].resources is required", filename, index))
+	} else if resourcesMap, ok := resources.(map[string]interface{}); ok {
+		v.validateResources(resourcesMap, index, filename)
+		v.suggestResourceDefaults(resourcesMap, index, filename)
+	} else {
+		v.addError(ruleFieldType, v.containerPath(index).Field("resources"), fmt.Sprintf("%s: container[%d].resources must be an object", filename, index))
+	}
+
+	// readinessProbe (optional)
+	if probe, exists := container["readinessProbe"]; exists {
+		if probeMap, ok := probe.(map[string]interface{}); ok {
+			v.validateProbe(probeMap, index, "readinessProbe", filename)
+		} else {
+			v.addError(ruleFieldType, v.containerPath(index).Field("readinessProbe"), fmt.Sprintf("%s: container[%d].readinessProbe must be an object", filename, index))
+		}
+	}
+
+	// livenessProbe (optional)
+	if probe, exists := container["livenessProbe"]; exists {
+		if probeMap, ok := probe.(map[string]interface{}); ok {
+			v.validateProbe(probeMap, index, "livenessProbe", filename)
+		} else {
+			v.addError(ruleFieldType, v.containerPath(index).Field("livenessProbe"), fmt.Sprintf("%s: container[%d].livenessProbe must be an object", filename, index))
+		}
+	}
+}
+
+// portNumber возвращает номер порта; ok — значение числовое (числа YAML бывают и float64)
+func portNumber(value interface{}) (number float64, ok bool) {
+	switch val := value.(type) {
+	case int:
+		return float64(val), true
+	case float64:
+		return val, true
+	}
+	return 0, false
+}
+
+// portInRange сообщает, что номер порта от 1 до 65535
+func portInRange(port float64) bool {
+	return port > 0 && port < 65536
+}
+
+func (v *Validator) validateContainerPort(port map[string]interface{}, containerIndex, portIndex int, filename string) {
+	// containerPort
+	if containerPort, exists := port["containerPort"]; !exists {
+		v.addError(ruleRequiredField, v.containerPath(containerIndex).Field("ports").Index(portIndex).Field("containerPort"), fmt.Sprintf("%s: container[%d].ports[%d].containerPort is required", filename, containerIndex, portIndex))
+	} else {
+		if number, ok := portNumber(containerPort); !ok {
+			v.addError(ruleFieldType, v.containerPath(containerIndex).Field("ports").Index(portIndex).Field("containerPort"), fmt.Sprintf("%s: container[%d].ports[%d].containerPort must be integer", filename, containerIndex, portIndex))
+		} else if !portInRange(number) {
+			v.addError(rulePortRange, v.containerPath(containerIndex).Field("ports").Index(portIndex).Field("containerPort"), fmt.Sprintf("%s: container[%d].ports[%d].containerPort value out of range", filename, containerIndex, portIndex))
+		}
+	}
+
+	// protocol (optional)
+	if protocol, exists := port["protocol"]; exists {
+		if protocolStr, ok := protocol.(string); ok {
+			if protocolStr != "TCP" && protocolStr != "UDP" {
+				v.addError(rulePortProtocol, v.containerPath(containerIndex).Field("ports").Index(portIndex).Field("protocol"), fmt.Sprintf("%s: container[%d].ports[%d].protocol must be 'TCP' or 'UDP'", filename, containerIndex, portIndex))
+				remediation := Remediation{Action: ActionSet, Allowed: []string{"TCP", "UDP"}}
+				if upper := strings.ToUpper(protocolStr); upper == "TCP" || upper == "UDP" {
+					remediation.Value = upper
+				}
+				v.suggest(remediation)
+			}
+		} else {
+			v.addError(ruleFieldType, v.containerPath(containerIndex).Field("ports").Index(portIndex).Field("protocol"), fmt.Sprintf("%s: container[%d].ports[%d].protocol must be string", filename, containerIndex, portIndex))
+		}
+	}
+}
+
+func (v *Validator) validateResources(resources map[string]interface{}, containerIndex int, filename string) {
+	// requests (optional)
+	if requests, exists := resources["requests"]; exists {
+		if requestsMap, ok := requests.(map[string]interface{}); ok {
+			v.validateResourceRequirements(requestsMap, containerIndex, "requests", filename)
+		} else {
+			v.addError(ruleFieldType, v.containerPath(containerIndex).Field("resources").Field("requests"), fmt.Sprintf("%s: container[%d].resources.requests must be an object", filename, containerIndex))
+		}
+	}
+
+	// limits (optional)
+	if limits, exists := resources["limits"]; exists {
+		if limitsMap, ok := limits.(map[string]interface{}); ok {
+			v.validateResourceRequirements(limitsMap, containerIndex, "limits", filename)
+		} else {
+			v.addError(ruleFieldType, v.containerPath(containerIndex).Field("resources").Field("limits"), fmt.Sprintf("%s: container[%d].resources.limits must be an object", filename, containerIndex))
+		}
+	}
+}
+
+func (v *Validator) validateResourceRequirements(resources map[string]interface{}, containerIndex int, resourceType string, filename string) {
+	filenameOnly := filepath.Base(filename)
+
+	for _, key := range sortedKeys(resources) {
+		value := resources[key]
+		switch key {
+		case "cpu":
+			switch value.(type) {
+			case int:
+				// OK
+			case float64:
+				// OK - YAML numbers часто парсятся как float64
+			case string:
+				v.addError(ruleResourceCPU, v.containerPath(containerIndex).Field("resources").Field(resourceType).Field("cpu"), fmt.Sprintf("%s:27 cpu must be int", filenameOnly))
+				if n := integerValue(value); n != nil {
+					v.suggest(Remediation{Action: ActionSet, Value: n})
+				}
+			default:
+				v.addError(ruleResourceCPU, v.containerPath(containerIndex).Field("resources").Field(resourceType).Field("cpu"), fmt.Sprintf("%s:27 cpu must be int", filenameOnly))
+			}
+		case "memory":
+			if memoryStr, ok := value.(string); ok {
+				validSuffixes := []string{"Gi", "Mi", "Ki"}
+				valid := false
+				for _, suffix := range validSuffixes {
+					if strings.HasSuffix(memoryStr, suffix) {
+						valid = true
+						break
+					}
+				}
+				if !valid {
+					v.addError(ruleResourceMemory, v.containerPath(containerIndex).Field("resources").Field(resourceType).Field("memory"), fmt.Sprintf("%s: container[%d].resources.%s.memory must end with Gi, Mi, or Ki", filename, containerIndex, resourceType))
+					remediation := Remediation{Action: ActionSet, Pattern: `^[0-9]+(Ki|Mi|Gi)$`}
+					if fixed := memorySuffix(memoryStr); fixed != "" {
+						remediation.Value = fixed
+					}
+					v.suggest(remediation)
+				}
+			} else {
+				v.addError(ruleFieldType, v.containerPath(containerIndex).Field("resources").Field(resourceType).Field("memory"), fmt.Sprintf("%s: container[%d].resources.%s.memory must be string", filename, containerIndex, resourceType))
+			}
+		default:
+			v.addError(ruleResourceName, v.containerPath(containerIndex).Field("resources").Field(resourceType).Field(key), fmt.Sprintf("%s: container[%d].resources.%s.%s: unknown resource type", filename, containerIndex, resourceType, key))
+			v.suggest(Remediation{Action: ActionRemove})
+		}
+	}
+}
+
+func (v *Validator) validateProbe(probe map[string]interface{}, containerIndex int, probeType string, filename string) {
+	filenameOnly := filepath.Base(filename)
+
+	if httpGet, exists := probe["httpGet"]; !exists {
+		v.addError(ruleRequiredField, v.containerPath(containerIndex).Field(probeType).Field("httpGet"), fmt.Sprintf("%s: container[%d].%s.httpGet is required", filenameOnly, containerIndex, probeType))
+	} else if httpGetMap, ok := httpGet.(map[string]interface{}); ok {
+		// path
+		if path, exists := httpGetMap["path"]; !exists {
+			v.addError(ruleRequiredField, v.containerPath(containerIndex).Field(probeType).Field("httpGet").Field("path"), fmt.Sprintf("%s: container[%d].%s.httpGet.path is required", filenameOnly, containerIndex, probeType))
+		} else if pathStr, ok := path.(string); ok {
+			if !strings.HasPrefix(pathStr, "/") {
+				v.addError(ruleProbePath, v.containerPath(containerIndex).Field(probeType).Field("httpGet").Field("path"), fmt.Sprintf("%s: container[%d].%s.httpGet.path must be absolute", filenameOnly, containerIndex, probeType))
+				v.suggest(Remediation{Action: ActionSet, Value: "/" + pathStr, Pattern: "^/"})
+			}
+		} else {
+			v.addError(ruleFieldType, v.containerPath(containerIndex).Field(probeType).Field("httpGet").Field("path"), fmt.Sprintf("%s: container[%d].%s.httpGet.path must be string", filenameOnly, containerIndex, probeType))
+		}
+
+		// port
+		if port, exists := httpGetMap["port"]; !exists {
+			v.addError(ruleRequiredField, v.containerPath(containerIndex).Field(probeType).Field("httpGet").Field("port"), fmt.Sprintf("%s: container[%d].%s.httpGet.port is required", filenameOnly, containerIndex, probeType))
+		} else {
+			if number, ok := portNumber(port); !ok {
+				v.addError(ruleFieldType, v.containerPath(containerIndex).Field(probeType).Field("httpGet").Field("port"), fmt.Sprintf("%s: container[%d].%s.httpGet.port must be integer", filenameOnly, containerIndex, probeType))
+			} else if !portInRange(number) {
+				v.addError(rulePortRange, v.containerPath(containerIndex).Field(probeType).Field("httpGet").Field("port"), fmt.Sprintf("%s:20 port value out of range", filenameOnly))
+			}
+		}
+	} else {
+		v.addError(ruleFieldType, v.containerPath(containerIndex).Field(probeType).Field("httpGet"), fmt.Sprintf("%s: container[%d].%s.httpGet must be an object", filenameOnly, containerIndex, probeType))
+	}
+}
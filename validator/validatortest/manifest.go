@@ -0,0 +1,150 @@
+package validatortest
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+// Manifest — изменяемый манифест-фикстура; методы возвращают его же для цепочек вызовов
+type Manifest struct {
+	doc map[string]interface{}
+	err error
+}
+
+// NewManifest создаёт манифест с заданными apiVersion, kind и metadata.name
+func NewManifest(apiVersion, kind, name string) *Manifest {
+	return &Manifest{doc: map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata":   map[string]interface{}{"name": name},
+	}}
+}
+
+// Pod создаёт под, проходящий все встроенные проверки
+func Pod(name string) *Manifest {
+	return NewManifest("v1", "Pod", name).Set("spec.containers", []interface{}{Container("app")})
+}
+
+// Container возвращает корректный контейнер для использования в spec.containers
+func Container(name string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":  name,
+		"image": "registry.bigbrother.io/" + name + ":1.0.0",
+		"resources": map[string]interface{}{
+			"requests": map[string]interface{}{"cpu": 1, "memory": "128Mi"},
+			"limits":   map[string]interface{}{"cpu": 1, "memory": "128Mi"},
+		},
+	}
+}
+
+// Set записывает значение по пути, создавая недостающие отображения и элементы
+func (m *Manifest) Set(path validator.FieldPath, value interface{}) *Manifest {
+	if m.err != nil {
+		return m
+	}
+	segments, err := path.Segments()
+	if err != nil {
+		m.err = err
+		return m
+	}
+	if len(segments) == 0 {
+		m.err = fmt.Errorf("empty path")
+		return m
+	}
+	root, err := set(m.doc, segments, value)
+	if err != nil {
+		m.err = fmt.Errorf("set %s: %v", path, err)
+		return m
+	}
+	m.doc = root.(map[string]interface{})
+	return m
+}
+
+// Delete удаляет поле по пути, если оно существует
+func (m *Manifest) Delete(path validator.FieldPath) *Manifest {
+	if m.err != nil {
+		return m
+	}
+	segments, err := path.Segments()
+	if err != nil || len(segments) == 0 {
+		m.err = fmt.Errorf("delete %s: invalid path", path)
+		return m
+	}
+	var node interface{} = m.doc
+	for _, segment := range segments[:len(segments)-1] {
+		node = child(node, segment)
+	}
+	last := segments[len(segments)-1]
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if !last.IsIndex {
+			delete(n, last.Key)
+		}
+	case []interface{}:
+		if last.IsIndex && last.Index < len(n) {
+			m.err = fmt.Errorf("delete %s: deleting sequence items is not supported, set the parent instead", path)
+		}
+	}
+	return m
+}
+
+// YAML сериализует манифест
+func (m *Manifest) YAML() ([]byte, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return yaml.Marshal(m.doc)
+}
+
+func child(node interface{}, segment validator.PathSegment) interface{} {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if !segment.IsIndex {
+			return n[segment.Key]
+		}
+	case []interface{}:
+		if segment.IsIndex && segment.Index < len(n) {
+			return n[segment.Index]
+		}
+	}
+	return nil
+}
+
+func set(node interface{}, segments []validator.PathSegment, value interface{}) (interface{}, error) {
+	if len(segments) == 0 {
+		return value, nil
+	}
+	segment := segments[0]
+	if segment.IsIndex {
+		list, _ := node.([]interface{})
+		if node != nil && list == nil {
+			return nil, fmt.Errorf("[%d]: not a sequence", segment.Index)
+		}
+		for len(list) <= segment.Index {
+			list = append(list, nil)
+		}
+		item, err := set(list[segment.Index], segments[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		list[segment.Index] = item
+		return list, nil
+	}
+
+	object, _ := node.(map[string]interface{})
+	if node != nil && object == nil {
+		return nil, fmt.Errorf("%s: not a mapping", segment.Key)
+	}
+	if object == nil {
+		object = map[string]interface{}{}
+	}
+	item, err := set(object[segment.Key], segments[1:], value)
+	if err != nil {
+		return nil, err
+	}
+	object[segment.Key] = item
+	return object, nil
+}
@@ -0,0 +1,85 @@
+// Package validatortest содержит помощники для тестирования собственных правил и типов:
+// сравнение находок с golden-файлами и программную сборку манифестов-фикстур.
+package validatortest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+// UpdateEnv — переменная окружения, при значении 1 golden-файлы перезаписываются текущим результатом
+const UpdateEnv = "YAMLVALID_UPDATE_GOLDEN"
+
+// FormatFindings выводит находки построчно в стабильном виде "строка:столбец правило путь: сообщение"
+func FormatFindings(findings []validator.Finding) string {
+	var b strings.Builder
+	for _, finding := range findings {
+		fmt.Fprintf(&b, "%d:%d %s %s: %s\n", finding.Line, finding.Column, finding.RuleID, finding.Path, finding.Message)
+		for _, related := range finding.Related {
+			fmt.Fprintf(&b, "  %d:%d %s %s: %s\n", related.Line, related.Column, related.RuleID, related.Path, related.Message)
+		}
+	}
+	return b.String()
+}
+
+// AssertGolden сравнивает находки с golden-файлом
+func AssertGolden(t testing.TB, findings []validator.Finding, goldenPath string) {
+	t.Helper()
+
+	got := FormatFindings(findings)
+	if os.Getenv(UpdateEnv) == "1" {
+		if err := os.MkdirAll(filepath.Dir(goldenPath), 0o755); err != nil {
+			t.Fatalf("create golden dir: %v", err)
+		}
+		if err := os.WriteFile(goldenPath, []byte(got), 0o644); err != nil {
+			t.Fatalf("write golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("read golden file (run with %s=1 to create it): %v", UpdateEnv, err)
+	}
+	if got != string(want) {
+		t.Errorf("findings differ from %s (run with %s=1 to update)\n--- want\n%s--- got\n%s", goldenPath, UpdateEnv, want, got)
+	}
+}
+
+// AssertRules проверяет, что сработали ровно указанные правила в указанном порядке
+func AssertRules(t testing.TB, findings []validator.Finding, ruleIDs ...string) {
+	t.Helper()
+
+	got := make([]string, len(findings))
+	for i, finding := range findings {
+		got[i] = finding.RuleID
+	}
+	if strings.Join(got, ",") != strings.Join(ruleIDs, ",") {
+		t.Errorf("rules = %v, want %v\n%s", got, ruleIDs, FormatFindings(findings))
+	}
+}
+
+// ValidateFixture сериализует фикстуру и проверяет её тем же конвейером, что и CLI
+// без конфигурации: выполняются правила, включённые по умолчанию
+func ValidateFixture(t testing.TB, m *Manifest, filename string) []validator.Finding {
+	t.Helper()
+	return ValidateFixtureWith(t, m, filename, validator.RuleSelection{RulesetVersion: validator.CurrentRulesetVersion()})
+}
+
+// ValidateFixtureWith — ValidateFixture с выбором правил, как у флагов --group, --enable и --env;
+// RulesetVersion выбора должен быть задан, иначе не выполняется ни одно правило. Проверка идёт
+// под той же версией набора правил, что и выбор.
+func ValidateFixtureWith(t testing.TB, m *Manifest, filename string, selection validator.RuleSelection) []validator.Finding {
+	t.Helper()
+
+	data, err := m.YAML()
+	if err != nil {
+		t.Fatalf("marshal fixture: %v", err)
+	}
+	return validator.FilterFindings(validator.ValidateWith(data, filename, validator.Options{RulesetVersion: selection.RulesetVersion}), selection)
+}
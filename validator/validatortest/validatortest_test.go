@@ -0,0 +1,155 @@
+package validatortest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+// recorder — testing.TB, который запоминает ошибки вместо того, чтобы проваливать тест
+type recorder struct {
+	testing.TB
+	errors []string
+	fatal  bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+	r.fatal = true
+}
+
+func decode(t *testing.T, m *Manifest) map[string]interface{} {
+	t.Helper()
+	data, err := m.YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestManifestSet(t *testing.T) {
+	doc := decode(t, NewManifest("v1", "ConfigMap", "settings").
+		Set("data.LOG_LEVEL", "debug").
+		Set("metadata.labels.app", "web").
+		Set("spec.items[1].name", "second"))
+
+	if got := doc["data"].(map[string]interface{})["LOG_LEVEL"]; got != "debug" {
+		t.Errorf("data.LOG_LEVEL = %v", got)
+	}
+	metadata := doc["metadata"].(map[string]interface{})
+	if metadata["name"] != "settings" || metadata["labels"].(map[string]interface{})["app"] != "web" {
+		t.Errorf("metadata = %v", metadata)
+	}
+	items := doc["spec"].(map[string]interface{})["items"].([]interface{})
+	if len(items) != 2 || items[0] != nil || items[1].(map[string]interface{})["name"] != "second" {
+		t.Errorf("spec.items = %v", items)
+	}
+}
+
+func TestManifestErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest *Manifest
+		want     string
+	}{
+		{"index into a mapping", Pod("web").Set("metadata[0]", "x"), "not a sequence"},
+		{"key into a scalar", Pod("web").Set("kind.name", "x"), "not a mapping"},
+		{"empty path", Pod("web").Set("", "x"), "empty path"},
+		{"delete a sequence item", Pod("web").Delete("spec.containers[0]"), "not supported"},
+		// Первая ошибка сохраняется, последующие вызовы её не затирают
+		{"first error wins", Pod("web").Set("", "x").Set("kind.name", "y"), "empty path"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.manifest.YAML()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestManifestDelete(t *testing.T) {
+	doc := decode(t, Pod("web").Delete("metadata.name").Delete("spec.missing.field"))
+	if _, exists := doc["metadata"].(map[string]interface{})["name"]; exists {
+		t.Error("metadata.name was not deleted")
+	}
+}
+
+func TestPodPassesBuiltInRules(t *testing.T) {
+	AssertRules(t, ValidateFixture(t, Pod("web"), "pod.yaml"))
+}
+
+func TestFormatFindings(t *testing.T) {
+	findings := []validator.Finding{{
+		RuleID:  "YV001",
+		Path:    "spec.containers[0].image",
+		Message: "image is required",
+		Line:    7,
+		Column:  9,
+		Related: []validator.Finding{{RuleID: "YV002", Path: "spec", Message: "caused by", Line: 5, Column: 1}},
+	}}
+	want := "7:9 YV001 spec.containers[0].image: image is required\n  5:1 YV002 spec: caused by\n"
+	if got := FormatFindings(findings); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestAssertRules(t *testing.T) {
+	findings := []validator.Finding{{RuleID: "YV001"}, {RuleID: "YV002"}}
+
+	var r recorder
+	AssertRules(&r, findings, "YV001", "YV002")
+	if len(r.errors) != 0 {
+		t.Errorf("matching rules reported %v", r.errors)
+	}
+	AssertRules(&r, findings, "YV002", "YV001")
+	if len(r.errors) != 1 {
+		t.Errorf("rules in another order were not reported")
+	}
+}
+
+func TestAssertGolden(t *testing.T) {
+	findings := []validator.Finding{{RuleID: "YV001", Path: "kind", Message: "kind is required", Line: 1, Column: 1}}
+	golden := filepath.Join(t.TempDir(), "golden", "findings.txt")
+
+	var r recorder
+	AssertGolden(&r, findings, golden)
+	if !r.fatal || !strings.Contains(r.errors[0], UpdateEnv) {
+		t.Fatalf("missing golden file: got %v", r.errors)
+	}
+
+	t.Setenv(UpdateEnv, "1")
+	AssertGolden(t, findings, golden)
+	data, err := os.ReadFile(golden)
+	if err != nil || string(data) != FormatFindings(findings) {
+		t.Fatalf("golden file not written: %q, %v", data, err)
+	}
+
+	t.Setenv(UpdateEnv, "")
+	r = recorder{}
+	AssertGolden(&r, findings, golden)
+	if len(r.errors) != 0 {
+		t.Errorf("matching golden file reported %v", r.errors)
+	}
+	AssertGolden(&r, findings[:0], golden)
+	if len(r.errors) != 1 {
+		t.Errorf("changed findings were not reported")
+	}
+}
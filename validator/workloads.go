@@ -0,0 +1,313 @@
+package validator
+
+import "fmt"
+
+// podTemplateSchema описывает шаблон пода рабочей нагрузки; spec проверяется так же, как у Pod
+var podTemplateSchema = &Schema{
+	Type:        "object",
+	Description: "Template of the pods the controller creates.",
+	Required:    []string{"spec"},
+	Properties: map[string]*Schema{
+		"metadata": {
+			Type:        "object",
+			Description: "Metadata of the created pods.",
+			Properties: map[string]*Schema{
+				"labels":      {Type: "object", Description: "Labels of the created pods; they must match spec.selector."},
+				"annotations": {Type: "object", Description: "Annotations of the created pods."},
+			},
+		},
+		"spec": podSchema.Properties["spec"],
+	},
+}
+
+// deploymentSchema описывает поля Deployment apps/v1; шаблон пода проверяется кодом validatePodTemplate
+var deploymentSchema = &Schema{
+	Type:     "object",
+	Required: []string{"spec"},
+	Properties: map[string]*Schema{
+		"spec": {
+			Type:        "object",
+			Description: "Desired state of the deployment.",
+			Required:    []string{"selector", "template"},
+			Properties: map[string]*Schema{
+				"replicas": {Type: "integer", Description: "Number of desired pods.", Minimum: float(0)},
+				"selector": {Type: "object", Description: "Label selector of the pods managed by the deployment.", Rules: []string{ruleRequiredField, ruleLabelSelector}},
+				"template": podTemplateSchema,
+				"strategy": {
+					Type:        "object",
+					Description: "How existing pods are replaced with new ones.",
+					Properties: map[string]*Schema{
+						"type": {Type: "string", Description: "Strategy type.", Enum: []string{"RollingUpdate", "Recreate"}},
+						"rollingUpdate": {
+							Type:        "object",
+							Description: "Parameters of the rolling update.",
+							Properties: map[string]*Schema{
+								"maxSurge":       {Description: "Pods that can be created above the desired number, as a number or a percentage."},
+								"maxUnavailable": {Description: "Pods that can be unavailable during the update, as a number or a percentage."},
+							},
+						},
+					},
+				},
+				"minReadySeconds":         {Type: "integer", Description: "Seconds a new pod must be ready to be considered available.", Minimum: float(0)},
+				"revisionHistoryLimit":    {Type: "integer", Description: "Number of old ReplicaSets kept for rollback.", Minimum: float(0)},
+				"progressDeadlineSeconds": {Type: "integer", Description: "Seconds after which a stalled rollout is reported as failed.", Minimum: float(1)},
+				"paused":                  {Type: "boolean", Description: "Whether the rollout is paused."},
+			},
+		},
+	},
+}
+
+func (v *Validator) validateDeployment(document map[string]interface{}, filename string) {
+	v.validateWorkload(document, deploymentSchema, filename)
+}
+
+// validateWorkload проверяет spec рабочей нагрузки по схеме, а шаблон пода — проверками Pod
+func (v *Validator) validateWorkload(document map[string]interface{}, schema *Schema, filename string) {
+	spec, exists := document["spec"]
+	if !exists {
+		v.addError(ruleRequiredField, "spec", fmt.Sprintf("%s: spec is required", filename))
+		return
+	}
+	v.validateWorkloadSpec(spec, "spec", schema.Properties["spec"], filename)
+}
+
+// validateWorkloadSpec проверяет spec рабочей нагрузки по пути path; шаблоны пода и задания
+// внутри него проверяются так же, как сами Pod и Job
+func (v *Validator) validateWorkloadSpec(spec interface{}, path FieldPath, schema *Schema, filename string) {
+	specMap, ok := spec.(map[string]interface{})
+	if !ok {
+		v.addError(ruleFieldType, path, fmt.Sprintf("%s: %s must be an object", filename, path))
+		return
+	}
+
+	for _, name := range schema.Required {
+		if _, exists := specMap[name]; !exists {
+			v.addError(ruleRequiredField, path.Field(name), fmt.Sprintf("%s: %s is required", filename, path.Field(name)))
+		}
+	}
+	for _, name := range sortedKeys(specMap) {
+		property, ok := schema.Properties[name]
+		switch {
+		case name == "template":
+			v.validatePodTemplate(specMap[name], path.Field(name), filename)
+		case name == "jobTemplate":
+			v.validateJobTemplate(specMap[name], path.Field(name), filename)
+		case ok:
+			v.validateSchema(specMap[name], property, path.Field(name), filename)
+		}
+	}
+}
+
+// validatePodTemplate проверяет шаблон пода по пути path теми же проверками, что и Pod
+func (v *Validator) validatePodTemplate(template interface{}, path FieldPath, filename string) {
+	templateMap, ok := template.(map[string]interface{})
+	if !ok {
+		v.addError(ruleFieldType, path, fmt.Sprintf("%s: %s must be an object", filename, path))
+		return
+	}
+	if metadata, exists := templateMap["metadata"]; exists {
+		v.validateSchema(metadata, podTemplateSchema.Properties["metadata"], path.Field("metadata"), filename)
+	}
+
+	specPath := path.Field("spec")
+	if spec, exists := templateMap["spec"]; !exists {
+		v.addError(ruleRequiredField, specPath, fmt.Sprintf("%s: %s is required", filename, specPath))
+	} else if specMap, ok := spec.(map[string]interface{}); ok {
+		v.podSpec = specPath
+		v.validateSpec(specMap, filename)
+		v.podSpec = ""
+	} else {
+		v.addError(ruleFieldType, specPath, fmt.Sprintf("%s: %s must be an object", filename, specPath))
+	}
+}
+
+// persistentVolumeClaimSpecSchema описывает spec запроса тома: у PersistentVolumeClaim (storage.go) и в volumeClaimTemplates
+var persistentVolumeClaimSpecSchema = &Schema{
+	Type:        "object",
+	Description: "Desired characteristics of the volume.",
+	Required:    []string{"accessModes", "resources"},
+	Properties: map[string]*Schema{
+		"accessModes": {
+			Type:        "array",
+			Description: "Ways the volume can be mounted.",
+			Items:       &Schema{Type: "string", Enum: []string{"ReadWriteOnce", "ReadOnlyMany", "ReadWriteMany", "ReadWriteOncePod"}},
+		},
+		"resources": {
+			Type:        "object",
+			Description: "Minimum resources the volume must have.",
+			Required:    []string{"requests"},
+			Properties: map[string]*Schema{
+				"requests": {
+					Type:        "object",
+					Description: "Requested size of the volume.",
+					Required:    []string{"storage"},
+					Properties: map[string]*Schema{
+						"storage": {Description: "Size of the volume, e.g. 10Gi.", Rules: []string{ruleRequiredField, ruleFieldValue}},
+					},
+				},
+				"limits": {Type: "object", Description: "Maximum size of the volume."},
+			},
+		},
+		"storageClassName": {Type: "string", Description: "Storage class of the volume; an empty string requests a volume without a class."},
+		"volumeMode":       {Type: "string", Description: "Whether the volume is a filesystem or a raw block device.", Enum: []string{"Filesystem", "Block"}},
+		"volumeName":       {Type: "string", Description: "Name of the PersistentVolume to bind to."},
+		"selector":         {Type: "object", Description: "Labels of the PersistentVolumes that can be bound."},
+		"dataSource":       {Type: "object", Description: "Snapshot or volume to populate the new volume from."},
+	},
+}
+
+// statefulSetSchema описывает поля StatefulSet apps/v1
+var statefulSetSchema = &Schema{
+	Type:     "object",
+	Required: []string{"spec"},
+	Properties: map[string]*Schema{
+		"spec": {
+			Type:        "object",
+			Description: "Desired state of the stateful set.",
+			Required:    []string{"selector", "serviceName", "template"},
+			Properties: map[string]*Schema{
+				"replicas":    {Type: "integer", Description: "Number of desired pods.", Minimum: float(0)},
+				"selector":    {Type: "object", Description: "Label selector of the pods managed by the stateful set.", Rules: []string{ruleRequiredField, ruleLabelSelector}},
+				"serviceName": {Type: "string", Description: "Headless Service that gives the pods stable network identities.", Rules: []string{ruleRequiredField}},
+				"template":    podTemplateSchema,
+				"volumeClaimTemplates": {
+					Type:        "array",
+					Description: "Claims for per-pod volumes; each pod gets its own PersistentVolumeClaim.",
+					Items: &Schema{
+						Type:     "object",
+						Required: []string{"metadata", "spec"},
+						Properties: map[string]*Schema{
+							"metadata": {
+								Type:        "object",
+								Description: "Metadata of the claims; the name is used in volumeMounts.",
+								Required:    []string{"name"},
+								Properties: map[string]*Schema{
+									"name":        {Type: "string", Description: "Name of the claim template."},
+									"labels":      {Type: "object", Description: "Labels of the claims."},
+									"annotations": {Type: "object", Description: "Annotations of the claims."},
+								},
+							},
+							"spec": persistentVolumeClaimSpecSchema,
+						},
+					},
+				},
+				"updateStrategy": {
+					Type:        "object",
+					Description: "How pods are replaced when the template changes.",
+					Properties: map[string]*Schema{
+						"type": {Type: "string", Description: "Strategy type.", Enum: []string{"RollingUpdate", "OnDelete"}},
+						"rollingUpdate": {
+							Type:        "object",
+							Description: "Parameters of the rolling update; only for type RollingUpdate.",
+							Properties: map[string]*Schema{
+								"partition":      {Type: "integer", Description: "Pods with an ordinal below the partition keep the old revision.", Minimum: float(0)},
+								"maxUnavailable": {Description: "Pods that can be unavailable during the update, as a number or a percentage."},
+							},
+						},
+					},
+				},
+				"podManagementPolicy": {Type: "string", Description: "Whether pods are created and deleted in order.", Enum: []string{"OrderedReady", "Parallel"}},
+				"persistentVolumeClaimRetentionPolicy": {
+					Type:        "object",
+					Description: "Whether claims are deleted with the stateful set or on scale down.",
+					Properties: map[string]*Schema{
+						"whenDeleted": {Type: "string", Description: "What happens to the claims when the stateful set is deleted.", Enum: []string{"Retain", "Delete"}},
+						"whenScaled":  {Type: "string", Description: "What happens to the claims on scale down.", Enum: []string{"Retain", "Delete"}},
+					},
+				},
+				"ordinals": {
+					Type:        "object",
+					Description: "Numbering of the pods.",
+					Properties: map[string]*Schema{
+						"start": {Type: "integer", Description: "Ordinal of the first pod.", Minimum: float(0)},
+					},
+				},
+				"minReadySeconds":      {Type: "integer", Description: "Seconds a new pod must be ready to be considered available.", Minimum: float(0)},
+				"revisionHistoryLimit": {Type: "integer", Description: "Number of old revisions kept for rollback.", Minimum: float(0)},
+			},
+		},
+	},
+}
+
+func (v *Validator) validateStatefulSet(document map[string]interface{}, filename string) {
+	v.validateWorkload(document, statefulSetSchema, filename)
+
+	if name, ok := lookupPath(document, "spec.serviceName").(string); ok && name == "" {
+		v.addError(ruleRequiredField, "spec.serviceName", fmt.Sprintf("%s: spec.serviceName must not be empty", filename))
+	}
+	v.validateUpdateStrategy(document, filename)
+
+	templates, _ := lookupPath(document, "spec.volumeClaimTemplates").([]interface{})
+	names := map[string]bool{}
+	for i := range templates {
+		path := FieldPath("spec.volumeClaimTemplates").Index(i)
+		if name, _ := lookupPath(document, path.Field("metadata").Field("name")).(string); name != "" {
+			if names[name] {
+				v.addError(ruleFieldValue, path.Field("metadata").Field("name"), fmt.Sprintf("%s: %s: claim template '%s' is defined more than once", filename, path.Field("metadata").Field("name"), name))
+			}
+			names[name] = true
+		}
+		v.validateStorageRequest(document, path.Field("spec"), filename)
+		if spec, ok := lookupPath(document, path.Field("spec")).(map[string]interface{}); ok {
+			v.validateClaimSpec(spec, path.Field("spec"), filename)
+		}
+	}
+}
+
+// validateStorageRequest проверяет, что размер тома в resources.requests.storage — количество вроде 10Gi
+func (v *Validator) validateStorageRequest(document map[string]interface{}, spec FieldPath, filename string) {
+	path := spec.Field("resources").Field("requests").Field("storage")
+	storage := lookupPath(document, path)
+	if storage == nil {
+		return
+	}
+	if quantity, err := ParseQuantity(storage); err != nil || quantity.Sign() <= 0 {
+		v.addError(ruleFieldValue, path, fmt.Sprintf("%s: %s must be a positive quantity, e.g. 10Gi", filename, path))
+	}
+}
+
+// daemonSetSchema описывает поля DaemonSet apps/v1
+var daemonSetSchema = &Schema{
+	Type:     "object",
+	Required: []string{"spec"},
+	Properties: map[string]*Schema{
+		"spec": {
+			Type:        "object",
+			Description: "Desired state of the daemon set.",
+			Required:    []string{"selector", "template"},
+			Properties: map[string]*Schema{
+				"selector": {Type: "object", Description: "Label selector of the pods managed by the daemon set.", Rules: []string{ruleRequiredField, ruleLabelSelector}},
+				"template": podTemplateSchema,
+				"updateStrategy": {
+					Type:        "object",
+					Description: "How pods are replaced when the template changes.",
+					Properties: map[string]*Schema{
+						"type": {Type: "string", Description: "Strategy type.", Enum: []string{"RollingUpdate", "OnDelete"}},
+						"rollingUpdate": {
+							Type:        "object",
+							Description: "Parameters of the rolling update; only for type RollingUpdate.",
+							Properties: map[string]*Schema{
+								"maxUnavailable": {Description: "Nodes whose pod can be unavailable during the update, as a number or a percentage."},
+								"maxSurge":       {Description: "Nodes that can run an old and a new pod at once, as a number or a percentage."},
+							},
+						},
+					},
+				},
+				"minReadySeconds":      {Type: "integer", Description: "Seconds a new pod must be ready to be considered available.", Minimum: float(0)},
+				"revisionHistoryLimit": {Type: "integer", Description: "Number of old revisions kept for rollback.", Minimum: float(0)},
+			},
+		},
+	},
+}
+
+func (v *Validator) validateDaemonSet(document map[string]interface{}, filename string) {
+	v.validateWorkload(document, daemonSetSchema, filename)
+	v.validateUpdateStrategy(document, filename)
+}
+
+// validateUpdateStrategy проверяет, что параметры rollingUpdate заданы только для стратегии RollingUpdate
+func (v *Validator) validateUpdateStrategy(document map[string]interface{}, filename string) {
+	if strategy, _ := lookupPath(document, "spec.updateStrategy.type").(string); strategy == "OnDelete" && lookupPath(document, "spec.updateStrategy.rollingUpdate") != nil {
+		v.addError(ruleFieldValue, "spec.updateStrategy.rollingUpdate", fmt.Sprintf("%s: spec.updateStrategy.rollingUpdate is only allowed with type RollingUpdate", filename))
+	}
+}
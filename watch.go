@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Редакторы сохраняют файл несколькими событиями (запись, переименование временного файла);
+// проверка запускается, когда события стихли на это время
+const watchDebounce = 200 * time.Millisecond
+
+// runWatch проверяет пути и повторяет проверку после каждого сохранения YAML-файла в них.
+// Каждая проверка выполняется отдельным процессом с теми же флагами: так конфигурация
+// перечитывается, а отчёт и код выхода остаются такими же, как у обычного запуска.
+func runWatch(paths, args []string) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		fmt.Printf("Error starting watcher: %v\n", err)
+		os.Exit(exitIO)
+	}
+	defer watcher.Close()
+
+	for _, path := range paths {
+		if err := watchTree(watcher, path); err != nil {
+			fmt.Printf("Error watching %s: %v\n", path, err)
+			os.Exit(exitIO)
+		}
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		fmt.Printf("Error starting watcher: %v\n", err)
+		os.Exit(exitIO)
+	}
+	// Каталоги проверяются целиком, как с -r; файлы -r проверяет как есть
+	childArgs := append([]string{"-r"}, withoutFlag(args, "watch")...)
+	// Экран очищаем там же, где отчёт раскрашивается: в терминале, который понимает ANSI
+	clearScreen := useColor(false, os.Stdout)
+	validate := func(reason string) {
+		if clearScreen {
+			fmt.Print("\x1b[H\x1b[2J")
+		}
+		fmt.Fprintf(os.Stderr, "[%s] %s\n", time.Now().Format("15:04:05"), reason)
+		cmd := exec.Command(executable, childArgs...)
+		cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			if _, ok := err.(*exec.ExitError); !ok {
+				fmt.Printf("Error running validation: %v\n", err)
+			}
+		}
+		fmt.Fprintln(os.Stderr, "Watching for changes, press Ctrl+C to stop")
+	}
+	validate("validating " + strings.Join(paths, " "))
+
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	var changed string
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			// Новый каталог наблюдаем сразу, чтобы не пропустить файлы, созданные в нём
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					watchTree(watcher, event.Name)
+					continue
+				}
+			}
+			if ext := filepath.Ext(event.Name); (ext != ".yaml" && ext != ".yml") || !underPaths(event.Name, paths) {
+				continue
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) == 0 {
+				continue
+			}
+			changed = event.Name
+			timer.Reset(watchDebounce)
+		case <-timer.C:
+			validate(changed + " changed")
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			fmt.Fprintf(os.Stderr, "warning: watcher: %v\n", err)
+		}
+	}
+}
+
+// watchTree добавляет в наблюдение каталог со всеми вложенными, кроме скрытых (как scanDirectory);
+// для файла наблюдается содержащий его каталог, потому что редакторы заменяют файл при сохранении
+func watchTree(watcher *fsnotify.Watcher, root string) error {
+	info, err := os.Stat(root)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return watcher.Add(filepath.Dir(root))
+	}
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if path != root && strings.HasPrefix(entry.Name(), ".") {
+			return filepath.SkipDir
+		}
+		return watcher.Add(path)
+	})
+}
+
+// underPaths сообщает, что файл — один из путей или лежит в одном из каталогов
+func underPaths(name string, paths []string) bool {
+	for _, path := range paths {
+		rel, err := filepath.Rel(path, name)
+		if err == nil && (rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))) {
+			if info, err := os.Stat(path); rel == "." || (err == nil && info.IsDir()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// withoutFlag убирает из аргументов логический флаг name во всех формах записи: -name, --name, --name=value
+func withoutFlag(args []string, name string) []string {
+	var kept []string
+	for i, arg := range args {
+		if arg == "--" {
+			return append(kept, args[i:]...)
+		}
+		flagName := strings.TrimLeft(arg, "-")
+		if before, _, found := strings.Cut(flagName, "="); found {
+			flagName = before
+		}
+		if strings.HasPrefix(arg, "-") && flagName == name {
+			continue
+		}
+		kept = append(kept, arg)
+	}
+	return kept
+}
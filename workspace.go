@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/imartynov670-coder/my-go-Bormotov-Ilya/lesson2/validator"
+)
+
+type lspInitializeParams struct {
+	RootURI          string `json:"rootUri"`
+	WorkspaceFolders []struct {
+		URI string `json:"uri"`
+	} `json:"workspaceFolders"`
+	Capabilities struct {
+		Workspace struct {
+			DidChangeWatchedFiles struct {
+				DynamicRegistration bool `json:"dynamicRegistration"`
+			} `json:"didChangeWatchedFiles"`
+		} `json:"workspace"`
+	} `json:"capabilities"`
+}
+
+// workspaceRoots возвращает каталоги рабочей области; rootUri — для клиентов без workspaceFolders
+func (p lspInitializeParams) workspaceRoots() []string {
+	var roots []string
+	for _, folder := range p.WorkspaceFolders {
+		roots = append(roots, uriPath(folder.URI))
+	}
+	if len(roots) == 0 && p.RootURI != "" {
+		roots = append(roots, uriPath(p.RootURI))
+	}
+	return roots
+}
+
+// Тип изменения в workspace/didChangeWatchedFiles: файл удалён
+const lspFileDeleted = 3
+
+type lspWatchedFilesParams struct {
+	Changes []struct {
+		URI  string `json:"uri"`
+		Type int    `json:"type"`
+	} `json:"changes"`
+}
+
+// lspServerRequest — запрос сервера к редактору; ответ на него приходит без method и игнорируется
+type lspServerRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      string      `json:"id"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+// indexWorkspace читает YAML-файлы рабочей области по правилам scan из конфигурации
+func (l *lspServer) indexWorkspace() {
+	for _, root := range l.roots {
+		paths, err := scanDirectory(root, l.s.config.Scan)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: indexing %s: %v\n", root, err)
+		}
+		for _, path := range paths {
+			if data, err := os.ReadFile(path); err == nil {
+				l.files[path] = data
+			}
+		}
+	}
+}
+
+// watchFiles просит редактор сообщать об изменении YAML-файлов на диске
+func (l *lspServer) watchFiles() {
+	include := l.s.config.Scan.Include
+	if len(include) == 0 {
+		include = defaultScanInclude
+	}
+	extensions := make([]string, len(include))
+	for i, suffix := range include {
+		extensions[i] = strings.TrimPrefix(suffix, ".")
+	}
+	l.send(lspServerRequest{JSONRPC: "2.0", ID: "yamlvalid-watch", Method: "client/registerCapability", Params: map[string]interface{}{
+		"registrations": []map[string]interface{}{{
+			"id":     "yamlvalid-watch",
+			"method": "workspace/didChangeWatchedFiles",
+			"registerOptions": map[string]interface{}{
+				"watchers": []map[string]string{{"globPattern": "**/*.{" + strings.Join(extensions, ",") + "}"}},
+			},
+		}},
+	}})
+}
+
+// reloadFiles перечитывает изменённые на диске файлы рабочей области
+func (l *lspServer) reloadFiles(params lspWatchedFilesParams) {
+	for _, change := range params.Changes {
+		path := uriPath(change.URI)
+		if change.Type == lspFileDeleted || !l.inWorkspace(path) {
+			delete(l.files, path)
+			continue
+		}
+		if data, err := os.ReadFile(path); err == nil {
+			l.files[path] = data
+		} else {
+			delete(l.files, path)
+		}
+	}
+}
+
+// inWorkspace сообщает, попал бы файл в индекс рабочей области
+func (l *lspServer) inWorkspace(path string) bool {
+	for _, root := range l.roots {
+		if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") && scanIncludes(rel, l.s.config.Scan) {
+			return true
+		}
+	}
+	return false
+}
+
+// publish проверяет открытые документы и файлы рабочей области вместе с правилами связей
+// между ними и отправляет диагностики; файлы, в которых находки исчезли, очищаются
+func (l *lspServer) publish() {
+	// Каждая публикация — отдельный запуск: результаты Memo прошлой проверки могли устареть
+	validator.ResetCache()
+	// Содержимое по путям: файлы с диска, поверх — несохранённый текст открытых документов
+	contents := map[string][]byte{}
+	uris := map[string]string{}
+	for path, data := range l.files {
+		contents[path], uris[path] = data, l.fileURI(path)
+	}
+	for uri, data := range l.documents {
+		contents[uriPath(uri)], uris[uriPath(uri)] = data, uri
+	}
+	paths := make([]string, 0, len(contents))
+	for path := range contents {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	// Без рабочей области набор неполный, и ссылки на ресурсы из других файлов проверять нельзя
+	set := validator.DocumentSet{CheckReferences: len(l.roots) > 0}
+	for _, path := range paths {
+		set.Add(contents[path], path)
+	}
+	crossFile := set.Validate()
+
+	published := map[string]bool{}
+	for _, path := range paths {
+		uri := uris[path]
+		_, open := l.documents[uri]
+		var findings []validator.Finding
+		for _, finding := range l.s.validateDocument(contents[path], path) {
+			// Прочий YAML рабочей области, например конфигурация CI, не засоряет список проблем
+			if open || finding.RuleID != validator.RuleNotKubernetes {
+				findings = append(findings, finding)
+			}
+		}
+		findings = append(findings, validator.FilterFindings(crossFile[path], l.s.selection)...)
+		suppressions := validator.ParseSuppressions(contents[path])
+		findings = validator.Suppress(findings, suppressions)
+		findings = append(findings, validator.FilterFindings(validator.UnusedSuppressions(suppressions, path, l.s.selection), l.s.selection)...)
+		if len(findings) == 0 && !open && !l.published[uri] {
+			continue
+		}
+		l.sendDiagnostics(uri, contents[path], findings)
+		if len(findings) > 0 {
+			published[uri] = true
+		}
+		delete(l.published, uri)
+	}
+	for uri := range l.published {
+		l.sendDiagnostics(uri, nil, nil)
+	}
+	l.published = published
+}
+
+func (l *lspServer) sendDiagnostics(uri string, data []byte, findings []validator.Finding) {
+	lines := strings.Split(string(data), "\n")
+	diagnostics := []lspDiagnostic{}
+	for _, finding := range findings {
+		diagnostics = append(diagnostics, l.diagnostic(uri, finding, lines))
+	}
+	l.send(lspNotification{JSONRPC: "2.0", Method: "textDocument/publishDiagnostics", Params: map[string]interface{}{
+		"uri":         uri,
+		"diagnostics": diagnostics,
+	}})
+}
+
+// fileURI возвращает URI файла: тот, под которым его открыл редактор, либо file://
+func (l *lspServer) fileURI(path string) string {
+	for uri := range l.documents {
+		if uriPath(uri) == path {
+			return uri
+		}
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}